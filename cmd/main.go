@@ -1,10 +1,15 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 
 	"github.com/federicodosantos/image-smith/db"
 	"github.com/federicodosantos/image-smith/internal/bootstrap"
@@ -28,13 +33,32 @@ func main() {
 
 	b.InitApp()
 
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var workers sync.WaitGroup
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		b.RunWorkers(ctx)
+	}()
+
 	server := &http.Server{
 		Addr:    fmt.Sprintf(":%s", PORT),
 		Handler: mux,
 	}
 
+	go func() {
+		<-ctx.Done()
+		log.Print("shutting down...")
+		_ = server.Shutdown(context.Background())
+	}()
+
 	log.Printf("Running the server on port %s", PORT)
-	if err := server.ListenAndServe(); err != nil {
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Fatalf("cannot running the server : ")
 	}
+
+	// let the workers finish the jobs they already claimed
+	workers.Wait()
 }
@@ -0,0 +1,11 @@
+CREATE TABLE images (
+  id char(36) PRIMARY KEY,
+  owner_id char(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  storage_key VARCHAR(255) NOT NULL,
+  mime_type VARCHAR(50) NOT NULL,
+  size BIGINT NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_images_owner_id ON images(owner_id);
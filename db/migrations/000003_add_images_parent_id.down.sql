@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_images_parent_id;
+
+ALTER TABLE images DROP COLUMN IF EXISTS parent_id;
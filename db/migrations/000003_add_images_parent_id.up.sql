@@ -0,0 +1,3 @@
+ALTER TABLE images ADD COLUMN parent_id char(36) REFERENCES images(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_images_parent_id ON images(parent_id);
@@ -0,0 +1,12 @@
+CREATE TABLE refresh_tokens (
+  id char(36) PRIMARY KEY,
+  user_id char(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  family_id char(36) NOT NULL,
+  token_hash char(64) UNIQUE NOT NULL,
+  expires_at TIMESTAMP NOT NULL,
+  revoked_at TIMESTAMP,
+  replaced_by char(36),
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_refresh_tokens_family_id ON refresh_tokens(family_id);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_images_owner_id_created_at;
+
+ALTER TABLE images
+  DROP COLUMN IF EXISTS original_filename,
+  DROP COLUMN IF EXISTS width,
+  DROP COLUMN IF EXISTS height,
+  DROP COLUMN IF EXISTS checksum;
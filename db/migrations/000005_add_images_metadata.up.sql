@@ -0,0 +1,7 @@
+ALTER TABLE images
+  ADD COLUMN original_filename VARCHAR(255) NOT NULL DEFAULT '',
+  ADD COLUMN width INTEGER NOT NULL DEFAULT 0,
+  ADD COLUMN height INTEGER NOT NULL DEFAULT 0,
+  ADD COLUMN checksum char(64) NOT NULL DEFAULT '';
+
+CREATE INDEX idx_images_owner_id_created_at ON images(owner_id, created_at DESC);
@@ -0,0 +1,19 @@
+CREATE TABLE jobs (
+  id char(36) PRIMARY KEY,
+  owner_id char(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  image_id char(36) NOT NULL REFERENCES images(id) ON DELETE CASCADE,
+  type VARCHAR(50) NOT NULL,
+  payload JSONB NOT NULL,
+  status VARCHAR(20) NOT NULL,
+  attempts INTEGER NOT NULL DEFAULT 0,
+  max_attempts INTEGER NOT NULL,
+  run_at TIMESTAMP NOT NULL,
+  locked_until TIMESTAMP,
+  result_image_id char(36) REFERENCES images(id) ON DELETE SET NULL,
+  last_error TEXT,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_jobs_owner_id ON jobs(owner_id);
+CREATE INDEX idx_jobs_claimable ON jobs(run_at) WHERE status IN ('queued', 'running');
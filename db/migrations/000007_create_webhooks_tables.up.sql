@@ -0,0 +1,32 @@
+CREATE TABLE webhooks (
+  id char(36) PRIMARY KEY,
+  owner_id char(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  url TEXT NOT NULL,
+  secret VARCHAR(100) NOT NULL,
+  events TEXT[] NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_webhooks_owner_id ON webhooks(owner_id);
+
+CREATE TABLE webhook_deliveries (
+  id char(36) PRIMARY KEY,
+  webhook_id char(36) NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+  owner_id char(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  event VARCHAR(50) NOT NULL,
+  payload JSONB NOT NULL,
+  status VARCHAR(20) NOT NULL,
+  attempts INTEGER NOT NULL DEFAULT 0,
+  max_attempts INTEGER NOT NULL,
+  next_attempt_at TIMESTAMP NOT NULL,
+  locked_until TIMESTAMP,
+  response_status INTEGER,
+  last_error TEXT,
+  delivered_at TIMESTAMP,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_webhook_deliveries_webhook_id ON webhook_deliveries(webhook_id, created_at DESC);
+CREATE INDEX idx_webhook_deliveries_claimable ON webhook_deliveries(next_attempt_at) WHERE status IN ('pending', 'sending');
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS watermark_settings;
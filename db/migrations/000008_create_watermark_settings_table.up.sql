@@ -0,0 +1,14 @@
+CREATE TABLE watermark_settings (
+  owner_id char(36) PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+  image_id char(36) REFERENCES images(id) ON DELETE SET NULL,
+  text TEXT NOT NULL DEFAULT '',
+  color VARCHAR(9) NOT NULL DEFAULT '',
+  font_size DOUBLE PRECISION NOT NULL DEFAULT 0,
+  gravity VARCHAR(20) NOT NULL DEFAULT '',
+  margin INTEGER NOT NULL DEFAULT 0,
+  opacity DOUBLE PRECISION NOT NULL DEFAULT 0,
+  scale DOUBLE PRECISION NOT NULL DEFAULT 0,
+  tile BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
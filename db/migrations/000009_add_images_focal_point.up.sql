@@ -0,0 +1,3 @@
+ALTER TABLE images
+  ADD COLUMN focal_x DOUBLE PRECISION,
+  ADD COLUMN focal_y DOUBLE PRECISION;
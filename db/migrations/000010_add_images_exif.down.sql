@@ -0,0 +1,5 @@
+ALTER TABLE images
+  DROP COLUMN IF EXISTS camera_make,
+  DROP COLUMN IF EXISTS camera_model,
+  DROP COLUMN IF EXISTS taken_at,
+  DROP COLUMN IF EXISTS orientation;
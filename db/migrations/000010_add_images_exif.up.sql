@@ -0,0 +1,5 @@
+ALTER TABLE images
+  ADD COLUMN camera_make VARCHAR(255),
+  ADD COLUMN camera_model VARCHAR(255),
+  ADD COLUMN taken_at TIMESTAMP,
+  ADD COLUMN orientation SMALLINT NOT NULL DEFAULT 1;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_images_owner_id_checksum;
+ALTER TABLE images DROP COLUMN IF EXISTS checksum_duplicate;
+DROP TABLE IF EXISTS image_blobs;
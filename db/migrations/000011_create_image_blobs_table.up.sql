@@ -0,0 +1,30 @@
+-- image_blobs counts the image records sharing each stored blob, so a blob is
+-- only deleted with its last reference. Blobs are only shared between images
+-- of their owner, whose id their key starts with.
+CREATE TABLE image_blobs (
+  storage_key VARCHAR(255) PRIMARY KEY,
+  owner_id char(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  checksum char(64) NOT NULL,
+  ref_count INTEGER NOT NULL DEFAULT 1 CHECK (ref_count >= 0),
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_image_blobs_owner_id_checksum ON image_blobs(owner_id, checksum);
+
+-- content an owner uploaded more than once is kept as it is. Every copy but
+-- the oldest is flagged and left out of the unique index, so deduplication
+-- only ever returns the oldest copy.
+ALTER TABLE images ADD COLUMN checksum_duplicate BOOLEAN NOT NULL DEFAULT false;
+
+UPDATE images SET checksum_duplicate = true
+  FROM (
+    SELECT id, row_number() OVER (PARTITION BY owner_id, checksum ORDER BY created_at, id) AS copy
+    FROM images WHERE checksum <> ''
+  ) copies
+  WHERE images.id = copies.id AND copies.copy > 1;
+
+INSERT INTO image_blobs(storage_key, owner_id, checksum, ref_count)
+  SELECT storage_key, owner_id, checksum, count(*) FROM images GROUP BY storage_key, owner_id, checksum;
+
+-- images stored before checksums were recorded have an empty one
+CREATE UNIQUE INDEX idx_images_owner_id_checksum ON images(owner_id, checksum) WHERE checksum <> '' AND NOT checksum_duplicate;
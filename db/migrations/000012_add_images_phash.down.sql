@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_images_owner_id_phash;
+ALTER TABLE images DROP COLUMN IF EXISTS phash;
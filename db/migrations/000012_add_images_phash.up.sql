@@ -0,0 +1,5 @@
+-- phash is the 64 bit perceptual hash of the image; similarity searches
+-- compare it with bit_count, which needs PostgreSQL 14 or later.
+ALTER TABLE images ADD COLUMN phash BIGINT;
+
+CREATE INDEX idx_images_owner_id_phash ON images(owner_id, phash) WHERE phash IS NOT NULL;
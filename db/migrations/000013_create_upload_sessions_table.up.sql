@@ -0,0 +1,13 @@
+CREATE TABLE upload_sessions (
+  id char(36) PRIMARY KEY,
+  owner_id char(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  filename VARCHAR(255) NOT NULL DEFAULT '',
+  upload_length BIGINT NOT NULL,
+  upload_offset BIGINT NOT NULL DEFAULT 0,
+  chunk_keys TEXT[] NOT NULL DEFAULT '{}',
+  expires_at TIMESTAMP NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_upload_sessions_expires_at ON upload_sessions(expires_at);
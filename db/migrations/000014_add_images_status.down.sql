@@ -0,0 +1,5 @@
+DELETE FROM images WHERE status = 'pending';
+
+DROP INDEX IF EXISTS idx_images_pending_upload_expires_at;
+ALTER TABLE images DROP COLUMN IF EXISTS upload_expires_at;
+ALTER TABLE images DROP COLUMN IF EXISTS status;
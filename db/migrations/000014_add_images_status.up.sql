@@ -0,0 +1,6 @@
+-- pending images were handed a presigned upload url and stay hidden until the
+-- upload is completed; those still pending at upload_expires_at are reaped.
+ALTER TABLE images ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'ready';
+ALTER TABLE images ADD COLUMN upload_expires_at TIMESTAMP;
+
+CREATE INDEX idx_images_pending_upload_expires_at ON images(upload_expires_at) WHERE status = 'pending';
@@ -1,21 +1,36 @@
 package bootstrap
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/federicodosantos/image-smith/internal/delivery"
 	"github.com/federicodosantos/image-smith/internal/repository"
 	"github.com/federicodosantos/image-smith/internal/usecase"
+	"github.com/federicodosantos/image-smith/internal/worker"
+	"github.com/federicodosantos/image-smith/pkg/imaging"
 	"github.com/federicodosantos/image-smith/pkg/jwt"
+	"github.com/federicodosantos/image-smith/pkg/middleware"
+	"github.com/federicodosantos/image-smith/pkg/storage"
+	"github.com/federicodosantos/image-smith/pkg/urlsign"
 	"github.com/federicodosantos/image-smith/pkg/util"
+	"github.com/federicodosantos/image-smith/pkg/webhook"
 	"github.com/jmoiron/sqlx"
 )
 
 type Bootstrap struct {
-	db     *sqlx.DB
-	router *http.ServeMux
+	db         *sqlx.DB
+	router     *http.ServeMux
+	workers    *worker.Pool
+	dispatcher *worker.Dispatcher
+	reaper     *worker.Reaper
 }
 
 func NewBootstrap(db *sqlx.DB, router *http.ServeMux) *Bootstrap {
@@ -27,22 +42,272 @@ func NewBootstrap(db *sqlx.DB, router *http.ServeMux) *Bootstrap {
 
 func (b *Bootstrap) InitApp() {
 	// initialize jwt service
-	jwtService, err := jwt.NewJwt(os.Getenv("JWT_SECRET_KEY"), os.Getenv("JWT_EXPIRED"))
+	jwtService, err := newJwtService()
 	if err != nil {
-		log.Printf("cannot initialize jwt service due to %s", err.Error())
+		log.Fatalf("cannot initialize jwt service due to %s", err.Error())
 	}
 
+	// every decode of user supplied images is bounded by these limits
+	imaging.SetLimits(imagingLimits())
+
+	// initialize object storage
+	objectStorage, err := b.initStorage()
+	if err != nil {
+		log.Fatalf("cannot initialize storage due to %s", err.Error())
+	}
+
+	//initialize middlewares
+	authMiddleware := middleware.Authenticate(jwtService)
+
 	//initialize repositories
 	userRepo := repository.NewUserRepository(b.db)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(b.db)
+	imageRepo := repository.NewImageRepository(b.db)
+	jobRepo := repository.NewJobRepository(b.db)
+	webhookRepo := repository.NewWebhookRepository(b.db)
+	watermarkRepo := repository.NewWatermarkRepository(b.db)
+	uploadRepo := repository.NewUploadRepository(b.db)
 
 	//initialize usecases
-	userUsecase := usecase.NewUserUsecase(userRepo, jwtService)
+	refreshTokenTTL, err := time.ParseDuration(getEnv("REFRESH_TOKEN_EXPIRED", "720h"))
+	if err != nil {
+		log.Fatalf("invalid duration format for REFRESH_TOKEN_EXPIRED: %s", err.Error())
+	}
+
+	// resumable uploads are held to the same size as single ones
+	maxUploadBytes := int64(getEnvInt("MAX_UPLOAD_BYTES", 25<<20))
+
+	userUsecase := usecase.NewUserUsecase(userRepo, refreshTokenRepo, jwtService, refreshTokenTTL)
+	webhookUsecase := usecase.NewWebhookUsecase(webhookRepo, getEnvInt("WEBHOOK_MAX_ATTEMPTS", 8))
+	imageUsecase := usecase.NewImageUsecase(imageRepo, watermarkRepo, objectStorage, webhookUsecase)
+	jobUsecase := usecase.NewJobUsecase(jobRepo, imageRepo, getEnvInt("JOB_MAX_ATTEMPTS", 5))
+	watermarkUsecase := usecase.NewWatermarkUsecase(watermarkRepo, imageRepo)
+	uploadUsecase := usecase.NewUploadUsecase(uploadRepo, imageUsecase, objectStorage,
+		maxUploadBytes, getEnvDuration("UPLOAD_SESSION_TTL", "24h"))
+
+	//initialize workers
+	b.workers = worker.NewPool(jobRepo, imageUsecase, webhookUsecase, workerConfig())
+	b.dispatcher = worker.NewDispatcher(webhookRepo, webhook.NewClient(10*time.Second), workerConfig())
+	b.reaper = worker.NewReaper(uploadUsecase, imageUsecase, getEnvDuration("UPLOAD_REAPER_INTERVAL", "10m"))
 
 	//initialize handlers
-	userHandler := delivery.NewUserHandler(userUsecase)
+	userHandler := delivery.NewUserHandler(userUsecase, tokenCookieConfig(jwtService))
+	imageHandler := delivery.NewImageHandler(imageUsecase, jobUsecase, maxUploadBytes)
+	jobHandler := delivery.NewJobHandler(jobUsecase)
+	webhookHandler := delivery.NewWebhookHandler(webhookUsecase)
+	watermarkHandler := delivery.NewWatermarkHandler(watermarkUsecase)
+	uploadHandler := delivery.NewUploadHandler(uploadUsecase, int64(getEnvInt("MAX_UPLOAD_CHUNK_BYTES", 25<<20)))
 
 	//initialize routes
 	delivery.UserRoutes(b.router, userHandler)
+	delivery.ImageRoutes(b.router, imageHandler, authMiddleware)
+	delivery.JobRoutes(b.router, jobHandler, authMiddleware)
+	delivery.WebhookRoutes(b.router, webhookHandler, authMiddleware)
+	delivery.WatermarkRoutes(b.router, watermarkHandler, authMiddleware)
+	delivery.UploadRoutes(b.router, uploadHandler, authMiddleware)
+
+	if provider, ok := jwtService.(jwt.JWKSProvider); ok {
+		delivery.JWKSRoutes(b.router, provider)
+	}
+
+	// signed public urls use their own key so rotating it revokes every
+	// outstanding link without logging anyone out
+	if signer, err := urlsign.NewSigner(os.Getenv("IMAGE_URL_SIGNING_KEY")); err != nil {
+		log.Printf("signed image urls disabled: %s", err.Error())
+	} else {
+		baseURL := getEnv("PUBLIC_BASE_URL", "http://localhost:"+os.Getenv("APP_PORT"))
+		publicImageHandler := delivery.NewPublicImageHandler(imageUsecase, signer, baseURL)
+		delivery.PublicImageRoutes(b.router, publicImageHandler, authMiddleware)
+	}
 
 	util.HealthCheck(b.router, b.db)
 }
+
+// RunWorkers processes queued jobs and webhook deliveries and reaps expired
+// uploads until ctx is cancelled. It must be called after InitApp.
+func (b *Bootstrap) RunWorkers(ctx context.Context) {
+	if b.workers == nil || b.dispatcher == nil || b.reaper == nil {
+		return
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(3)
+
+	go func() {
+		defer wg.Done()
+		b.workers.Run(ctx)
+	}()
+
+	go func() {
+		defer wg.Done()
+		b.dispatcher.Run(ctx)
+	}()
+
+	go func() {
+		defer wg.Done()
+		b.reaper.Run(ctx)
+	}()
+
+	wg.Wait()
+}
+
+// imagingLimits reads the decoding limits; a variable set to 0 disables its
+// limit.
+func imagingLimits() imaging.Limits {
+	defaults := imaging.DefaultLimits
+
+	timeout, err := time.ParseDuration(getEnv("IMAGE_DECODE_TIMEOUT", defaults.DecodeTimeout.String()))
+	if err != nil {
+		log.Fatalf("invalid duration format for IMAGE_DECODE_TIMEOUT: %s", err.Error())
+	}
+
+	return imaging.Limits{
+		MaxWidth:      getEnvInt("IMAGE_MAX_WIDTH", defaults.MaxWidth),
+		MaxHeight:     getEnvInt("IMAGE_MAX_HEIGHT", defaults.MaxHeight),
+		MaxPixels:     int64(getEnvInt("IMAGE_MAX_MEGAPIXELS", int(defaults.MaxPixels/1_000_000))) * 1_000_000,
+		MaxFrames:     getEnvInt("IMAGE_MAX_FRAMES", defaults.MaxFrames),
+		MaxMemory:     int64(getEnvInt("IMAGE_MEMORY_BUDGET_MB", int(defaults.MaxMemory>>20))) << 20,
+		DecodeTimeout: timeout,
+	}
+}
+
+func workerConfig() worker.Config {
+	pollInterval, err := time.ParseDuration(getEnv("WORKER_POLL_INTERVAL", "1s"))
+	if err != nil {
+		log.Fatalf("invalid duration format for WORKER_POLL_INTERVAL: %s", err.Error())
+	}
+
+	lease, err := time.ParseDuration(getEnv("WORKER_LEASE", "5m"))
+	if err != nil {
+		log.Fatalf("invalid duration format for WORKER_LEASE: %s", err.Error())
+	}
+
+	return worker.Config{
+		Concurrency:  getEnvInt("WORKER_CONCURRENCY", 2),
+		PollInterval: pollInterval,
+		Lease:        lease,
+	}
+}
+
+// newJwtService selects the token signer from JWT_ALGORITHM. HS256 uses
+// JWT_SECRET_KEY; RS256 and EdDSA sign with JWT_PRIVATE_KEY_PATH and also
+// accept tokens from the comma separated JWT_PUBLIC_KEY_PATHS during rotation.
+func newJwtService() (jwt.JWTItf, error) {
+	switch algorithm := getEnv("JWT_ALGORITHM", "HS256"); algorithm {
+	case "HS256":
+		return jwt.NewJwt(os.Getenv("JWT_SECRET_KEY"), os.Getenv("JWT_EXPIRED"))
+	case "RS256", "EdDSA":
+		var publicKeyPaths []string
+		for _, path := range strings.Split(os.Getenv("JWT_PUBLIC_KEY_PATHS"), ",") {
+			if path = strings.TrimSpace(path); path != "" {
+				publicKeyPaths = append(publicKeyPaths, path)
+			}
+		}
+
+		asymmetric, err := jwt.NewAsymmetricJwt(algorithm, os.Getenv("JWT_PRIVATE_KEY_PATH"), publicKeyPaths, os.Getenv("JWT_EXPIRED"))
+		if err != nil {
+			return nil, err
+		}
+		return asymmetric, nil
+	default:
+		return nil, fmt.Errorf("unsupported jwt algorithm %q", algorithm)
+	}
+}
+
+// initStorage selects the object storage driver from STORAGE_DRIVER. The local
+// driver also serves its objects under /files/.
+func (b *Bootstrap) initStorage() (storage.Storage, error) {
+	switch driver := getEnv("STORAGE_DRIVER", "local"); driver {
+	case "local":
+		local, err := storage.NewLocalStorage(
+			getEnv("STORAGE_LOCAL_PATH", "./storage"),
+			getEnv("STORAGE_BASE_URL", "/files"),
+			os.Getenv("STORAGE_SIGNING_KEY"),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		b.router.Handle("GET /files/", http.StripPrefix("/files", local))
+		b.router.Handle("PUT /files/", http.StripPrefix("/files", local))
+		return local, nil
+	case "s3":
+		return storage.NewS3Storage(storage.S3Config{
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			Region:          os.Getenv("S3_REGION"),
+			Bucket:          os.Getenv("S3_BUCKET"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+			PublicURL:       os.Getenv("S3_PUBLIC_URL"),
+		}, nil)
+	case "supabase":
+		// Supabase Storage exposes an S3-compatible endpoint per project
+		supabaseURL := strings.TrimRight(os.Getenv("SUPABASE_URL"), "/")
+		bucket := os.Getenv("S3_BUCKET")
+
+		return storage.NewS3Storage(storage.S3Config{
+			Endpoint:        supabaseURL + "/storage/v1/s3",
+			Region:          os.Getenv("S3_REGION"),
+			Bucket:          bucket,
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+			PublicURL:       getEnv("S3_PUBLIC_URL", supabaseURL+"/storage/v1/object/public/"+bucket),
+		}, nil)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", driver)
+	}
+}
+
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+
+	return fallback
+}
+
+func getEnvInt(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Fatalf("invalid integer for %s: %s", key, err.Error())
+	}
+
+	return n
+}
+
+func getEnvDuration(key, fallback string) time.Duration {
+	d, err := time.ParseDuration(getEnv(key, fallback))
+	if err != nil {
+		log.Fatalf("invalid duration format for %s: %s", key, err.Error())
+	}
+
+	return d
+}
+
+func tokenCookieConfig(jwtService jwt.JWTItf) delivery.CookieConfig {
+	config := delivery.CookieConfig{
+		Domain:   os.Getenv("COOKIE_DOMAIN"),
+		Path:     getEnv("COOKIE_PATH", "/"),
+		Secure:   getEnv("COOKIE_SECURE", "true") == "true",
+		HttpOnly: getEnv("COOKIE_HTTP_ONLY", "true") == "true",
+		SameSite: http.SameSiteLaxMode,
+	}
+
+	switch strings.ToLower(os.Getenv("COOKIE_SAME_SITE")) {
+	case "strict":
+		config.SameSite = http.SameSiteStrictMode
+	case "none":
+		config.SameSite = http.SameSiteNoneMode
+	}
+
+	if jwtService != nil {
+		config.MaxAge = jwtService.ExpiresIn()
+	}
+
+	return config
+}
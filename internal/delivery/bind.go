@@ -0,0 +1,63 @@
+package delivery
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	response "github.com/federicodosantos/image-smith/pkg/response"
+	"github.com/federicodosantos/image-smith/pkg/validator"
+)
+
+// bindJSON decodes the request body into dst and validates it. On failure it
+// writes a 400 for malformed JSON or a 422 carrying the field errors, and
+// returns false.
+func bindJSON(w http.ResponseWriter, r *http.Request, dst any) bool {
+	if err := json.NewDecoder(r.Body).Decode(dst); err != nil {
+		response.FailedResponse(w, http.StatusBadRequest, err.Error(), nil)
+		return false
+	}
+
+	if errs := validator.Struct(dst); errs != nil {
+		response.FailedResponse(w, http.StatusUnprocessableEntity, customErr.ErrValidation.Error(), errs)
+		return false
+	}
+
+	return true
+}
+
+// limitBody caps the request body at n bytes; n <= 0 leaves it unlimited. A
+// declared Content-Length over the cap is answered with 413 before anything
+// is read and false is returned. Reads past the cap fail with
+// customErr.ErrPayloadTooLarge.
+func limitBody(w http.ResponseWriter, r *http.Request, n int64) bool {
+	if n <= 0 {
+		return true
+	}
+
+	if r.ContentLength > n {
+		response.FailedResponse(w, http.StatusRequestEntityTooLarge, customErr.ErrPayloadTooLarge.Error(), nil)
+		return false
+	}
+
+	r.Body = limitedBody{http.MaxBytesReader(w, r.Body, n)}
+	return true
+}
+
+type limitedBody struct {
+	io.ReadCloser
+}
+
+func (b limitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		err = fmt.Errorf("%w: the limit is %d bytes", customErr.ErrPayloadTooLarge, tooLarge.Limit)
+	}
+
+	return n, err
+}
@@ -0,0 +1,44 @@
+package delivery
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/federicodosantos/image-smith/pkg/middleware"
+)
+
+// CookieConfig controls how the jwt-token cookie is issued on login.
+type CookieConfig struct {
+	Domain   string
+	Path     string
+	Secure   bool
+	HttpOnly bool
+	SameSite http.SameSite
+	MaxAge   time.Duration
+}
+
+func (c CookieConfig) tokenCookie(value string, maxAge int) *http.Cookie {
+	path := c.Path
+	if path == "" {
+		path = "/"
+	}
+
+	return &http.Cookie{
+		Name:     middleware.TokenCookieName,
+		Value:    value,
+		Domain:   c.Domain,
+		Path:     path,
+		Secure:   c.Secure,
+		HttpOnly: c.HttpOnly,
+		SameSite: c.SameSite,
+		MaxAge:   maxAge,
+	}
+}
+
+func (c CookieConfig) setToken(w http.ResponseWriter, token string) {
+	http.SetCookie(w, c.tokenCookie(token, int(c.MaxAge.Seconds())))
+}
+
+func (c CookieConfig) clearToken(w http.ResponseWriter) {
+	http.SetCookie(w, c.tokenCookie("", -1))
+}
@@ -0,0 +1,610 @@
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/federicodosantos/image-smith/internal/dto"
+	"github.com/federicodosantos/image-smith/internal/usecase"
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	"github.com/federicodosantos/image-smith/pkg/middleware"
+	response "github.com/federicodosantos/image-smith/pkg/response"
+	"github.com/federicodosantos/image-smith/pkg/validator"
+)
+
+const imageFormField = "image"
+
+// renderCacheControl lets browsers keep a rendered variant for a day. Renders
+// are private because the route requires the owner's token.
+const renderCacheControl = "private, max-age=86400"
+
+// ImageHandler serves the owner's image routes. Upload bodies larger than
+// maxUploadBytes are rejected with 413; zero leaves them unlimited.
+type ImageHandler struct {
+	imageUsecase   usecase.IImageUsecase
+	jobUsecase     usecase.IJobUsecase
+	maxUploadBytes int64
+}
+
+func NewImageHandler(imageUsecase usecase.IImageUsecase, jobUsecase usecase.IJobUsecase, maxUploadBytes int64) *ImageHandler {
+	return &ImageHandler{imageUsecase: imageUsecase, jobUsecase: jobUsecase, maxUploadBytes: maxUploadBytes}
+}
+
+func ImageRoutes(router *http.ServeMux, imageHandler *ImageHandler, auth middleware.Middleware) {
+	router.Handle("POST /images", auth(http.HandlerFunc(imageHandler.Upload)))
+	router.Handle("POST /images/upload-url", auth(http.HandlerFunc(imageHandler.CreateUploadURL)))
+	router.Handle("POST /images/{id}/complete", auth(http.HandlerFunc(imageHandler.Complete)))
+	router.Handle("GET /images", auth(http.HandlerFunc(imageHandler.List)))
+	router.Handle("GET /images/{id}", auth(http.HandlerFunc(imageHandler.Get)))
+	router.Handle("DELETE /images/{id}", auth(http.HandlerFunc(imageHandler.Delete)))
+	router.Handle("POST /images/{id}/transform", auth(http.HandlerFunc(imageHandler.Transform)))
+	router.Handle("GET /images/{id}/render", auth(http.HandlerFunc(imageHandler.Render)))
+	router.Handle("GET /images/{id}/similar", auth(http.HandlerFunc(imageHandler.Similar)))
+	router.Handle("PUT /images/{id}/focal-point", auth(http.HandlerFunc(imageHandler.SetFocalPoint)))
+	router.Handle("DELETE /images/{id}/focal-point", auth(http.HandlerFunc(imageHandler.ClearFocalPoint)))
+}
+
+func (ih *ImageHandler) Upload(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		response.FailedResponse(w, http.StatusUnauthorized, customErr.ErrUserIdNotFound.Error(), nil)
+		return
+	}
+
+	if !limitBody(w, r, ih.maxUploadBytes) {
+		return
+	}
+
+	req, err := uploadRequest(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, customErr.ErrPayloadTooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		response.FailedResponse(w, status, err.Error(), nil)
+		return
+	}
+
+	image, err := ih.imageUsecase.Upload(r.Context(), userID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, customErr.ErrPayloadTooLarge):
+			response.FailedResponse(w, http.StatusRequestEntityTooLarge, err.Error(), nil)
+			return
+		case exceedsDecodeLimits(err):
+			response.FailedResponse(w, http.StatusUnprocessableEntity, err.Error(), nil)
+			return
+		case errors.Is(err, customErr.ErrUnsupportedFileFormat),
+			errors.Is(err, customErr.ErrImageFileRequired):
+			response.FailedResponse(w, http.StatusBadRequest, err.Error(), nil)
+			return
+		default:
+			response.FailedResponse(w, http.StatusInternalServerError, err.Error(), nil)
+			return
+		}
+	}
+
+	message := "successfully upload an image"
+	if image.Duplicate {
+		message = "image already uploaded"
+	}
+
+	response.SuccessResponse(w, http.StatusOK, message, image)
+}
+
+// CreateUploadURL answers 201 with a pending image and the URL to PUT its
+// content to. The declared size is held to the same cap as Upload bodies.
+func (ih *ImageHandler) CreateUploadURL(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		response.FailedResponse(w, http.StatusUnauthorized, customErr.ErrUserIdNotFound.Error(), nil)
+		return
+	}
+
+	var req *dto.ImageUploadURLRequest
+
+	if !bindJSON(w, r, &req) {
+		return
+	}
+
+	if ih.maxUploadBytes > 0 && req.Size > ih.maxUploadBytes {
+		response.FailedResponse(w, http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("%s: the limit is %d bytes", customErr.ErrPayloadTooLarge.Error(), ih.maxUploadBytes), nil)
+		return
+	}
+
+	upload, err := ih.imageUsecase.CreateUploadURL(r.Context(), userID, req)
+	if err != nil {
+		var fieldErrs validator.Errors
+		if errors.As(err, &fieldErrs) {
+			response.FailedResponse(w, http.StatusUnprocessableEntity, customErr.ErrValidation.Error(), fieldErrs)
+			return
+		}
+		response.FailedResponse(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	response.SuccessResponse(w, http.StatusCreated, "successfully create an upload url", upload)
+}
+
+// Complete checks the content uploaded to the URL of CreateUploadURL and
+// answers like Upload once the image is ready.
+func (ih *ImageHandler) Complete(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		response.FailedResponse(w, http.StatusUnauthorized, customErr.ErrUserIdNotFound.Error(), nil)
+		return
+	}
+
+	image, err := ih.imageUsecase.Complete(r.Context(), userID, r.PathValue("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, customErr.ErrImageNotFound):
+			response.FailedResponse(w, http.StatusNotFound, err.Error(), nil)
+			return
+		case errors.Is(err, customErr.ErrUploadIncomplete):
+			response.FailedResponse(w, http.StatusConflict, err.Error(), nil)
+			return
+		case errors.Is(err, customErr.ErrPayloadTooLarge):
+			response.FailedResponse(w, http.StatusRequestEntityTooLarge, err.Error(), nil)
+			return
+		case exceedsDecodeLimits(err):
+			response.FailedResponse(w, http.StatusUnprocessableEntity, err.Error(), nil)
+			return
+		case errors.Is(err, customErr.ErrUnsupportedFileFormat):
+			response.FailedResponse(w, http.StatusBadRequest, err.Error(), nil)
+			return
+		default:
+			response.FailedResponse(w, http.StatusInternalServerError, err.Error(), nil)
+			return
+		}
+	}
+
+	message := "successfully upload an image"
+	if image.Duplicate {
+		message = "image already uploaded"
+	}
+
+	response.SuccessResponse(w, http.StatusOK, message, image)
+}
+
+// Transform runs the transformation in the request, or with ?async=true
+// queues it and answers 202 with the job to poll at GET /jobs/{id}.
+func (ih *ImageHandler) Transform(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		response.FailedResponse(w, http.StatusUnauthorized, customErr.ErrUserIdNotFound.Error(), nil)
+		return
+	}
+
+	async, err := strconv.ParseBool(r.URL.Query().Get("async"))
+	if err != nil && r.URL.Query().Has("async") {
+		response.FailedResponse(w, http.StatusBadRequest, "async must be a boolean", nil)
+		return
+	}
+
+	var req *dto.ImageTransformRequest
+
+	if !bindJSON(w, r, &req) {
+		return
+	}
+
+	if async {
+		ih.enqueueTransform(w, r, userID, req)
+		return
+	}
+
+	image, err := ih.imageUsecase.Transform(r.Context(), userID, r.PathValue("id"), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, customErr.ErrInvalidTransformation):
+			response.FailedResponse(w, http.StatusBadRequest, err.Error(), nil)
+			return
+		case exceedsDecodeLimits(err):
+			response.FailedResponse(w, http.StatusUnprocessableEntity, err.Error(), nil)
+			return
+		case errors.Is(err, customErr.ErrImageNotFound):
+			response.FailedResponse(w, http.StatusNotFound, err.Error(), nil)
+			return
+		default:
+			response.FailedResponse(w, http.StatusInternalServerError, err.Error(), nil)
+			return
+		}
+	}
+
+	response.SuccessResponse(w, http.StatusOK, "successfully transform an image", image)
+}
+
+func (ih *ImageHandler) enqueueTransform(w http.ResponseWriter, r *http.Request, userID string, req *dto.ImageTransformRequest) {
+	job, err := ih.jobUsecase.EnqueueTransform(r.Context(), userID, r.PathValue("id"), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, customErr.ErrInvalidTransformation):
+			response.FailedResponse(w, http.StatusBadRequest, err.Error(), nil)
+			return
+		case errors.Is(err, customErr.ErrImageNotFound):
+			response.FailedResponse(w, http.StatusNotFound, err.Error(), nil)
+			return
+		default:
+			response.FailedResponse(w, http.StatusInternalServerError, err.Error(), nil)
+			return
+		}
+	}
+
+	w.Header().Set("Location", "/jobs/"+job.ID)
+	response.SuccessResponse(w, http.StatusAccepted, "successfully enqueue an image transformation", job)
+}
+
+func (ih *ImageHandler) Get(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		response.FailedResponse(w, http.StatusUnauthorized, customErr.ErrUserIdNotFound.Error(), nil)
+		return
+	}
+
+	image, err := ih.imageUsecase.Get(r.Context(), userID, r.PathValue("id"))
+	if err != nil {
+		if errors.Is(err, customErr.ErrImageNotFound) {
+			response.FailedResponse(w, http.StatusNotFound, err.Error(), nil)
+			return
+		}
+		response.FailedResponse(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	response.SuccessResponse(w, http.StatusOK, "successfully get an image", image)
+}
+
+func (ih *ImageHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		response.FailedResponse(w, http.StatusUnauthorized, customErr.ErrUserIdNotFound.Error(), nil)
+		return
+	}
+
+	req, errs := listRequest(r.URL.Query())
+	if errs == nil {
+		errs = validator.Struct(req)
+	}
+	if errs != nil {
+		response.FailedResponse(w, http.StatusUnprocessableEntity, customErr.ErrValidation.Error(), errs)
+		return
+	}
+
+	page, err := ih.imageUsecase.List(r.Context(), userID, req)
+	if err != nil {
+		var fieldErrs validator.Errors
+		if errors.As(err, &fieldErrs) {
+			response.FailedResponse(w, http.StatusUnprocessableEntity, customErr.ErrValidation.Error(), fieldErrs)
+			return
+		}
+		response.FailedResponse(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	response.PaginatedResponse(w, http.StatusOK, "successfully list images", page.Images, &response.Pagination{
+		Limit:      page.Limit,
+		HasMore:    page.HasMore,
+		NextCursor: page.NextCursor,
+	})
+}
+
+func (ih *ImageHandler) Render(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		response.FailedResponse(w, http.StatusUnauthorized, customErr.ErrUserIdNotFound.Error(), nil)
+		return
+	}
+
+	req, err := renderRequest(r)
+	if err != nil {
+		response.FailedResponse(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	rendered, err := ih.imageUsecase.Render(r.Context(), userID, r.PathValue("id"), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, customErr.ErrInvalidTransformation):
+			response.FailedResponse(w, http.StatusBadRequest, err.Error(), nil)
+			return
+		case exceedsDecodeLimits(err):
+			response.FailedResponse(w, http.StatusUnprocessableEntity, err.Error(), nil)
+			return
+		case errors.Is(err, customErr.ErrImageNotFound):
+			response.FailedResponse(w, http.StatusNotFound, err.Error(), nil)
+			return
+		default:
+			response.FailedResponse(w, http.StatusInternalServerError, err.Error(), nil)
+			return
+		}
+	}
+
+	writeRender(w, rendered, renderCacheControl)
+}
+
+// Similar lists the caller's images that look like the one in the path,
+// closest first.
+func (ih *ImageHandler) Similar(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		response.FailedResponse(w, http.StatusUnauthorized, customErr.ErrUserIdNotFound.Error(), nil)
+		return
+	}
+
+	req, errs := similarRequest(r.URL.Query())
+	if errs == nil {
+		errs = validator.Struct(req)
+	}
+	if errs != nil {
+		response.FailedResponse(w, http.StatusUnprocessableEntity, customErr.ErrValidation.Error(), errs)
+		return
+	}
+
+	images, err := ih.imageUsecase.Similar(r.Context(), userID, r.PathValue("id"), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, customErr.ErrImageNotFound):
+			response.FailedResponse(w, http.StatusNotFound, err.Error(), nil)
+		case errors.Is(err, customErr.ErrImageNotHashed):
+			response.FailedResponse(w, http.StatusUnprocessableEntity, err.Error(), nil)
+		default:
+			response.FailedResponse(w, http.StatusInternalServerError, err.Error(), nil)
+		}
+		return
+	}
+
+	response.SuccessResponse(w, http.StatusOK, "successfully find similar images", images)
+}
+
+// SetFocalPoint stores the point that cover resizes keep in frame.
+func (ih *ImageHandler) SetFocalPoint(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		response.FailedResponse(w, http.StatusUnauthorized, customErr.ErrUserIdNotFound.Error(), nil)
+		return
+	}
+
+	var req dto.FocalPointRequest
+
+	if !bindJSON(w, r, &req) {
+		return
+	}
+
+	image, err := ih.imageUsecase.SetFocalPoint(r.Context(), userID, r.PathValue("id"), &req)
+	if err != nil {
+		if errors.Is(err, customErr.ErrImageNotFound) {
+			response.FailedResponse(w, http.StatusNotFound, err.Error(), nil)
+			return
+		}
+		response.FailedResponse(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	response.SuccessResponse(w, http.StatusOK, "successfully set the focal point", image)
+}
+
+func (ih *ImageHandler) ClearFocalPoint(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		response.FailedResponse(w, http.StatusUnauthorized, customErr.ErrUserIdNotFound.Error(), nil)
+		return
+	}
+
+	image, err := ih.imageUsecase.ClearFocalPoint(r.Context(), userID, r.PathValue("id"))
+	if err != nil {
+		if errors.Is(err, customErr.ErrImageNotFound) {
+			response.FailedResponse(w, http.StatusNotFound, err.Error(), nil)
+			return
+		}
+		response.FailedResponse(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	response.SuccessResponse(w, http.StatusOK, "successfully clear the focal point", image)
+}
+
+func (ih *ImageHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		response.FailedResponse(w, http.StatusUnauthorized, customErr.ErrUserIdNotFound.Error(), nil)
+		return
+	}
+
+	if err := ih.imageUsecase.Delete(r.Context(), userID, r.PathValue("id")); err != nil {
+		if errors.Is(err, customErr.ErrImageNotFound) {
+			response.FailedResponse(w, http.StatusNotFound, err.Error(), nil)
+			return
+		}
+		response.FailedResponse(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	response.SuccessResponse(w, http.StatusOK, "successfully delete an image", nil)
+}
+
+// exceedsDecodeLimits reports whether err is one of the imaging.Limits
+// violations, which are answered with 422.
+func exceedsDecodeLimits(err error) bool {
+	return errors.Is(err, customErr.ErrImageDimensionsTooLarge) ||
+		errors.Is(err, customErr.ErrImageTooManyPixels) ||
+		errors.Is(err, customErr.ErrImageTooManyFrames) ||
+		errors.Is(err, customErr.ErrMemoryBudgetExceeded) ||
+		errors.Is(err, customErr.ErrDecodeTimeout)
+}
+
+// uploadRequest accepts either a multipart form with an "image" file field or
+// a raw image/* request body.
+func uploadRequest(r *http.Request) (*dto.ImageUploadRequest, error) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, customErr.ErrUnsupportedFileFormat
+	}
+
+	switch {
+	case mediaType == "multipart/form-data":
+		reader, err := r.MultipartReader()
+		if err != nil {
+			return nil, err
+		}
+
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				return nil, customErr.ErrImageFileRequired
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			if part.FormName() == imageFormField && part.FileName() != "" {
+				return &dto.ImageUploadRequest{Filename: part.FileName(), File: part}, nil
+			}
+		}
+	case strings.HasPrefix(mediaType, "image/"):
+		var filename string
+		if _, disposition, err := mime.ParseMediaType(r.Header.Get("Content-Disposition")); err == nil {
+			filename = disposition["filename"]
+		}
+
+		return &dto.ImageUploadRequest{Filename: filename, File: r.Body}, nil
+	default:
+		return nil, customErr.ErrUnsupportedFileFormat
+	}
+}
+
+// listRequest parses the GET /images query string. Values that are not
+// numbers, booleans or RFC 3339 timestamps are reported per parameter.
+func listRequest(query url.Values) (*dto.ImageListRequest, validator.Errors) {
+	errs := validator.Errors{}
+	req := &dto.ImageListRequest{
+		Cursor:   query.Get("cursor"),
+		Format:   query.Get("format"),
+		ParentID: query.Get("parent_id"),
+		Sort:     query.Get("sort"),
+		Order:    query.Get("order"),
+	}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			errs["limit"] = append(errs["limit"], "must be an integer")
+		}
+		req.Limit = limit
+	}
+
+	if value := query.Get("originals_only"); value != "" {
+		originalsOnly, err := strconv.ParseBool(value)
+		if err != nil {
+			errs["originals_only"] = append(errs["originals_only"], "must be a boolean")
+		}
+		req.OriginalsOnly = originalsOnly
+	}
+
+	for name, dst := range map[string]**int64{"min_size": &req.MinSize, "max_size": &req.MaxSize} {
+		if value := query.Get(name); value != "" {
+			size, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				errs[name] = append(errs[name], "must be an integer")
+				continue
+			}
+			*dst = &size
+		}
+	}
+
+	for name, dst := range map[string]**time.Time{"created_after": &req.CreatedAfter, "created_before": &req.CreatedBefore} {
+		if value := query.Get(name); value != "" {
+			at, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				errs[name] = append(errs[name], "must be an RFC 3339 timestamp")
+				continue
+			}
+			*dst = &at
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	return req, nil
+}
+
+func similarRequest(query url.Values) (*dto.SimilarImagesRequest, validator.Errors) {
+	errs := validator.Errors{}
+	req := &dto.SimilarImagesRequest{}
+
+	if value := query.Get("max_distance"); value != "" {
+		distance, err := strconv.Atoi(value)
+		if err != nil {
+			errs["max_distance"] = append(errs["max_distance"], "must be an integer")
+		}
+		req.MaxDistance = &distance
+	}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			errs["limit"] = append(errs["limit"], "must be an integer")
+		}
+		req.Limit = limit
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	return req, nil
+}
+
+// renderRequest reads the w, h, fit, gravity, bg, filter, fmt and q query
+// parameters.
+func renderRequest(r *http.Request) (*dto.ImageRenderRequest, error) {
+	query := r.URL.Query()
+	req := &dto.ImageRenderRequest{
+		Fit:         query.Get("fit"),
+		Gravity:     query.Get("gravity"),
+		Background:  query.Get("bg"),
+		Filter:      query.Get("filter"),
+		Format:      query.Get("fmt"),
+		IfNoneMatch: r.Header.Get("If-None-Match"),
+	}
+
+	for name, dst := range map[string]*int{"w": &req.Width, "h": &req.Height, "q": &req.Quality} {
+		if value := query.Get(name); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s must be an integer", customErr.ErrInvalidTransformation, name)
+			}
+			*dst = n
+		}
+	}
+
+	return req, nil
+}
+
+// writeRender streams a rendered variant, or answers 304 when the client
+// already holds it.
+func writeRender(w http.ResponseWriter, rendered *dto.ImageRenderResponse, cacheControl string) {
+	w.Header().Set("ETag", rendered.ETag)
+	w.Header().Set("Cache-Control", cacheControl)
+
+	if rendered.NotModified {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	defer rendered.Body.Close()
+
+	w.Header().Set("Content-Type", rendered.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(rendered.Size, 10))
+	w.WriteHeader(http.StatusOK)
+
+	io.Copy(w, rendered.Body)
+}
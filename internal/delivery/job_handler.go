@@ -0,0 +1,43 @@
+package delivery
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/federicodosantos/image-smith/internal/usecase"
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	"github.com/federicodosantos/image-smith/pkg/middleware"
+	response "github.com/federicodosantos/image-smith/pkg/response"
+)
+
+type JobHandler struct {
+	jobUsecase usecase.IJobUsecase
+}
+
+func NewJobHandler(jobUsecase usecase.IJobUsecase) *JobHandler {
+	return &JobHandler{jobUsecase: jobUsecase}
+}
+
+func JobRoutes(router *http.ServeMux, jobHandler *JobHandler, auth middleware.Middleware) {
+	router.Handle("GET /jobs/{id}", auth(http.HandlerFunc(jobHandler.Get)))
+}
+
+func (jh *JobHandler) Get(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		response.FailedResponse(w, http.StatusUnauthorized, customErr.ErrUserIdNotFound.Error(), nil)
+		return
+	}
+
+	job, err := jh.jobUsecase.Get(r.Context(), userID, r.PathValue("id"))
+	if err != nil {
+		if errors.Is(err, customErr.ErrJobNotFound) {
+			response.FailedResponse(w, http.StatusNotFound, err.Error(), nil)
+			return
+		}
+		response.FailedResponse(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	response.SuccessResponse(w, http.StatusOK, "successfully get a job", job)
+}
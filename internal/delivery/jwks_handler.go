@@ -0,0 +1,20 @@
+package delivery
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/federicodosantos/image-smith/pkg/jwt"
+)
+
+// JWKSRoutes publishes the token verification keys. The body is a plain JWK
+// set rather than an HttpResponse so gateways can consume it directly.
+func JWKSRoutes(router *http.ServeMux, provider jwt.JWKSProvider) {
+	router.HandleFunc("GET /.well-known/jwks.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		w.WriteHeader(http.StatusOK)
+
+		json.NewEncoder(w).Encode(provider.JWKS())
+	})
+}
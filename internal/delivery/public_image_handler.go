@@ -0,0 +1,131 @@
+package delivery
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/federicodosantos/image-smith/internal/dto"
+	"github.com/federicodosantos/image-smith/internal/usecase"
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	"github.com/federicodosantos/image-smith/pkg/middleware"
+	response "github.com/federicodosantos/image-smith/pkg/response"
+	"github.com/federicodosantos/image-smith/pkg/urlsign"
+)
+
+const DefaultSignedURLExpiry = time.Hour
+
+// PublicImageHandler mints signed image URLs for authenticated owners and
+// serves them to anyone holding a valid, unexpired signature.
+type PublicImageHandler struct {
+	imageUsecase usecase.IImageUsecase
+	signer       *urlsign.Signer
+	baseURL      string
+}
+
+func NewPublicImageHandler(imageUsecase usecase.IImageUsecase, signer *urlsign.Signer, baseURL string) *PublicImageHandler {
+	return &PublicImageHandler{
+		imageUsecase: imageUsecase,
+		signer:       signer,
+		baseURL:      strings.TrimRight(baseURL, "/"),
+	}
+}
+
+func PublicImageRoutes(router *http.ServeMux, publicImageHandler *PublicImageHandler, auth middleware.Middleware) {
+	router.Handle("POST /images/{id}/signed-url", auth(http.HandlerFunc(publicImageHandler.SignURL)))
+	router.HandleFunc("GET /public/images/{id}", publicImageHandler.Render)
+}
+
+func (ph *PublicImageHandler) SignURL(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		response.FailedResponse(w, http.StatusUnauthorized, customErr.ErrUserIdNotFound.Error(), nil)
+		return
+	}
+
+	var req *dto.SignedURLRequest
+
+	if !bindJSON(w, r, &req) {
+		return
+	}
+
+	image, err := ph.imageUsecase.Get(r.Context(), userID, r.PathValue("id"))
+	if err != nil {
+		if errors.Is(err, customErr.ErrImageNotFound) {
+			response.FailedResponse(w, http.StatusNotFound, err.Error(), nil)
+			return
+		}
+		response.FailedResponse(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	expiry := DefaultSignedURLExpiry
+	if req.ExpiresIn > 0 {
+		expiry = time.Duration(req.ExpiresIn) * time.Second
+	}
+	expiresAt := time.Now().Add(expiry).Truncate(time.Second)
+
+	query := url.Values{}
+	for name, value := range map[string]int{"w": req.Width, "h": req.Height, "q": req.Quality} {
+		if value != 0 {
+			query.Set(name, strconv.Itoa(value))
+		}
+	}
+	for name, value := range map[string]string{
+		"fit": req.Fit, "gravity": req.Gravity, "bg": req.Background, "filter": req.Filter, "fmt": req.Format,
+	} {
+		if value != "" {
+			query.Set(name, value)
+		}
+	}
+
+	path := "/public/images/" + url.PathEscape(image.ID)
+	signed := ph.signer.Sign(path, query, expiresAt)
+
+	response.SuccessResponse(w, http.StatusOK, "successfully sign an image url", &dto.SignedURLResponse{
+		URL:       ph.baseURL + path + "?" + signed.Encode(),
+		ExpiresAt: expiresAt,
+	})
+}
+
+// Render serves a signed URL. The signature covers the image and every
+// rendering parameter, so none of them can be altered by the holder.
+func (ph *PublicImageHandler) Render(w http.ResponseWriter, r *http.Request) {
+	expiresAt, err := ph.signer.Verify(r.URL.EscapedPath(), r.URL.Query(), time.Now())
+	if err != nil {
+		response.FailedResponse(w, http.StatusForbidden, err.Error(), nil)
+		return
+	}
+
+	req, err := renderRequest(r)
+	if err != nil {
+		response.FailedResponse(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	rendered, err := ph.imageUsecase.RenderPublic(r.Context(), r.PathValue("id"), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, customErr.ErrInvalidTransformation):
+			response.FailedResponse(w, http.StatusBadRequest, err.Error(), nil)
+			return
+		case exceedsDecodeLimits(err):
+			response.FailedResponse(w, http.StatusUnprocessableEntity, err.Error(), nil)
+			return
+		case errors.Is(err, customErr.ErrImageNotFound):
+			response.FailedResponse(w, http.StatusNotFound, err.Error(), nil)
+			return
+		default:
+			response.FailedResponse(w, http.StatusInternalServerError, err.Error(), nil)
+			return
+		}
+	}
+
+	// shared caches must not keep the image past the signature's expiry
+	maxAge := min(time.Until(expiresAt), 24*time.Hour)
+	writeRender(w, rendered, fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds())))
+}
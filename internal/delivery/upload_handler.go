@@ -0,0 +1,232 @@
+package delivery
+
+import (
+	"encoding/base64"
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/federicodosantos/image-smith/internal/dto"
+	"github.com/federicodosantos/image-smith/internal/usecase"
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	"github.com/federicodosantos/image-smith/pkg/middleware"
+	response "github.com/federicodosantos/image-smith/pkg/response"
+	"github.com/federicodosantos/image-smith/pkg/validator"
+)
+
+// tusVersion is the version of the tus resumable upload protocol the upload
+// routes follow.
+const tusVersion = "1.0.0"
+
+// chunkContentType is the only content type PATCH accepts, as tus requires.
+const chunkContentType = "application/offset+octet-stream"
+
+// UploadHandler serves resumable uploads following the core tus protocol:
+// POST /uploads creates an upload, HEAD reports its offset, PATCH appends a
+// chunk at that offset and POST /uploads/{id}/finalize turns the complete
+// upload into an image. Chunks larger than maxChunkBytes are rejected with
+// 413; zero leaves them unlimited.
+type UploadHandler struct {
+	uploadUsecase usecase.IUploadUsecase
+	maxChunkBytes int64
+}
+
+func NewUploadHandler(uploadUsecase usecase.IUploadUsecase, maxChunkBytes int64) *UploadHandler {
+	return &UploadHandler{uploadUsecase: uploadUsecase, maxChunkBytes: maxChunkBytes}
+}
+
+func UploadRoutes(router *http.ServeMux, uploadHandler *UploadHandler, auth middleware.Middleware) {
+	router.Handle("POST /uploads", auth(http.HandlerFunc(uploadHandler.Create)))
+	router.Handle("HEAD /uploads/{id}", auth(http.HandlerFunc(uploadHandler.Head)))
+	router.Handle("PATCH /uploads/{id}", auth(http.HandlerFunc(uploadHandler.Patch)))
+	router.Handle("POST /uploads/{id}/finalize", auth(http.HandlerFunc(uploadHandler.Finalize)))
+}
+
+// Create opens an upload of Upload-Length bytes. The file name is taken from
+// the filename key of Upload-Metadata.
+func (uh *UploadHandler) Create(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Tus-Resumable", tusVersion)
+
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		response.FailedResponse(w, http.StatusUnauthorized, customErr.ErrUserIdNotFound.Error(), nil)
+		return
+	}
+
+	req, errs := uploadCreateRequest(r.Header)
+	if errs == nil {
+		errs = validator.Struct(req)
+	}
+	if errs != nil {
+		response.FailedResponse(w, http.StatusUnprocessableEntity, customErr.ErrValidation.Error(), errs)
+		return
+	}
+
+	upload, err := uh.uploadUsecase.Create(r.Context(), userID, req)
+	if err != nil {
+		if errors.Is(err, customErr.ErrPayloadTooLarge) {
+			response.FailedResponse(w, http.StatusRequestEntityTooLarge, err.Error(), nil)
+			return
+		}
+		response.FailedResponse(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	w.Header().Set("Location", "/uploads/"+upload.ID)
+	setUploadHeaders(w, upload)
+	response.SuccessResponse(w, http.StatusCreated, "successfully create an upload", upload)
+}
+
+// Head reports how many bytes of the upload were received.
+func (uh *UploadHandler) Head(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Tus-Resumable", tusVersion)
+	w.Header().Set("Cache-Control", "no-store")
+
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	upload, err := uh.uploadUsecase.Get(r.Context(), userID, r.PathValue("id"))
+	if err != nil {
+		if errors.Is(err, customErr.ErrUploadNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	setUploadHeaders(w, upload)
+	w.WriteHeader(http.StatusOK)
+}
+
+// Patch appends the body at Upload-Offset, which must match the upload's
+// current offset, and answers 204 with the new offset.
+func (uh *UploadHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Tus-Resumable", tusVersion)
+
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		response.FailedResponse(w, http.StatusUnauthorized, customErr.ErrUserIdNotFound.Error(), nil)
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != chunkContentType {
+		response.FailedResponse(w, http.StatusUnsupportedMediaType, "content type must be "+chunkContentType, nil)
+		return
+	}
+
+	offset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		response.FailedResponse(w, http.StatusBadRequest, "Upload-Offset must be a non-negative integer", nil)
+		return
+	}
+
+	if !limitBody(w, r, uh.maxChunkBytes) {
+		return
+	}
+
+	upload, err := uh.uploadUsecase.WriteChunk(r.Context(), userID, r.PathValue("id"), offset, r.Body)
+	if err != nil {
+		switch {
+		case errors.Is(err, customErr.ErrUploadNotFound):
+			response.FailedResponse(w, http.StatusNotFound, err.Error(), nil)
+			return
+		case errors.Is(err, customErr.ErrUploadOffsetMismatch):
+			response.FailedResponse(w, http.StatusConflict, err.Error(), nil)
+			return
+		case errors.Is(err, customErr.ErrPayloadTooLarge):
+			response.FailedResponse(w, http.StatusRequestEntityTooLarge, err.Error(), nil)
+			return
+		default:
+			response.FailedResponse(w, http.StatusInternalServerError, err.Error(), nil)
+			return
+		}
+	}
+
+	setUploadHeaders(w, upload)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Finalize stores the complete upload as an image and answers like
+// POST /images.
+func (uh *UploadHandler) Finalize(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		response.FailedResponse(w, http.StatusUnauthorized, customErr.ErrUserIdNotFound.Error(), nil)
+		return
+	}
+
+	image, err := uh.uploadUsecase.Finalize(r.Context(), userID, r.PathValue("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, customErr.ErrUploadNotFound):
+			response.FailedResponse(w, http.StatusNotFound, err.Error(), nil)
+			return
+		case errors.Is(err, customErr.ErrUploadIncomplete):
+			response.FailedResponse(w, http.StatusConflict, err.Error(), nil)
+			return
+		case exceedsDecodeLimits(err):
+			response.FailedResponse(w, http.StatusUnprocessableEntity, err.Error(), nil)
+			return
+		case errors.Is(err, customErr.ErrUnsupportedFileFormat):
+			response.FailedResponse(w, http.StatusBadRequest, err.Error(), nil)
+			return
+		default:
+			response.FailedResponse(w, http.StatusInternalServerError, err.Error(), nil)
+			return
+		}
+	}
+
+	message := "successfully upload an image"
+	if image.Duplicate {
+		message = "image already uploaded"
+	}
+
+	response.SuccessResponse(w, http.StatusOK, message, image)
+}
+
+func setUploadHeaders(w http.ResponseWriter, upload *dto.UploadSessionResponse) {
+	w.Header().Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	w.Header().Set("Upload-Length", strconv.FormatInt(upload.Length, 10))
+	w.Header().Set("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+}
+
+// uploadCreateRequest reads Upload-Length and the filename from
+// Upload-Metadata, a comma separated list of keys each followed by a base64
+// value.
+func uploadCreateRequest(header http.Header) (*dto.UploadCreateRequest, validator.Errors) {
+	errs := validator.Errors{}
+	req := &dto.UploadCreateRequest{}
+
+	length, err := strconv.ParseInt(header.Get("Upload-Length"), 10, 64)
+	if err != nil {
+		errs["Upload-Length"] = append(errs["Upload-Length"], "must be an integer")
+	}
+	req.Length = length
+
+	for pair := range strings.SplitSeq(header.Get("Upload-Metadata"), ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key != "filename" {
+			continue
+		}
+
+		filename, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			errs["Upload-Metadata"] = append(errs["Upload-Metadata"], "filename must be base64 encoded")
+			continue
+		}
+		req.Filename = string(filename)
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	return req, nil
+}
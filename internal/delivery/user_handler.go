@@ -3,6 +3,7 @@ package delivery
 import (
 	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
 
 	"github.com/federicodosantos/image-smith/internal/dto"
@@ -13,21 +14,24 @@ import (
 
 type UserHandler struct {
 	userUsecase usecase.IUserUsecase
+	cookie      CookieConfig
 }
 
-func NewUserHandler(userUsecase usecase.IUserUsecase) *UserHandler {
-	return &UserHandler{userUsecase: userUsecase}
+func NewUserHandler(userUsecase usecase.IUserUsecase, cookie CookieConfig) *UserHandler {
+	return &UserHandler{userUsecase: userUsecase, cookie: cookie}
 }
 
 func UserRoutes(router *http.ServeMux, userHandler *UserHandler) {
 	router.HandleFunc("/auth/register", userHandler.Register)
+	router.HandleFunc("POST /auth/login", userHandler.Login)
+	router.HandleFunc("POST /auth/refresh", userHandler.Refresh)
+	router.HandleFunc("POST /auth/logout", userHandler.Logout)
 }
 
 func (uh *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 	var req *dto.UserRegisterRequest
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.FailedResponse(w, http.StatusBadRequest, err.Error(), nil)
+	if !bindJSON(w, r, &req) {
 		return
 	}
 
@@ -47,8 +51,7 @@ func (uh *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 func (uh *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 	var req *dto.UserLoginRequest
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response.FailedResponse(w, http.StatusBadRequest, err.Error(), nil)
+	if !bindJSON(w, r, &req) {
 		return
 	}
 
@@ -67,5 +70,52 @@ func (uh *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	uh.cookie.setToken(w, token.JWTToken)
+
 	response.SuccessResponse(w, http.StatusOK, "successfully login to account", token)
 }
+
+func (uh *UserHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req *dto.RefreshTokenRequest
+
+	if !bindJSON(w, r, &req) {
+		return
+	}
+
+	token, err := uh.userUsecase.Refresh(r.Context(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, customErr.ErrInvalidRefreshToken),
+			errors.Is(err, customErr.ErrRefreshTokenReused):
+			response.FailedResponse(w, http.StatusUnauthorized, err.Error(), nil)
+			return
+		default:
+			response.FailedResponse(w, http.StatusInternalServerError, err.Error(), nil)
+			return
+		}
+	}
+
+	uh.cookie.setToken(w, token.JWTToken)
+
+	response.SuccessResponse(w, http.StatusOK, "successfully refresh token", token)
+}
+
+// Logout clears the token cookie and, when a refresh token is sent in the
+// body, revokes its family.
+func (uh *UserHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	var req *dto.RefreshTokenRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.FailedResponse(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if err := uh.userUsecase.Logout(r.Context(), req); err != nil {
+		response.FailedResponse(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	uh.cookie.clearToken(w)
+
+	response.SuccessResponse(w, http.StatusOK, "successfully logout from account", nil)
+}
@@ -0,0 +1,93 @@
+package delivery
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/federicodosantos/image-smith/internal/dto"
+	"github.com/federicodosantos/image-smith/internal/usecase"
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	"github.com/federicodosantos/image-smith/pkg/middleware"
+	response "github.com/federicodosantos/image-smith/pkg/response"
+	"github.com/federicodosantos/image-smith/pkg/validator"
+)
+
+type WatermarkHandler struct {
+	watermarkUsecase usecase.IWatermarkUsecase
+}
+
+func NewWatermarkHandler(watermarkUsecase usecase.IWatermarkUsecase) *WatermarkHandler {
+	return &WatermarkHandler{watermarkUsecase: watermarkUsecase}
+}
+
+func WatermarkRoutes(router *http.ServeMux, watermarkHandler *WatermarkHandler, auth middleware.Middleware) {
+	router.Handle("GET /settings/watermark", auth(http.HandlerFunc(watermarkHandler.Get)))
+	router.Handle("PUT /settings/watermark", auth(http.HandlerFunc(watermarkHandler.Save)))
+	router.Handle("DELETE /settings/watermark", auth(http.HandlerFunc(watermarkHandler.Delete)))
+}
+
+func (wh *WatermarkHandler) Get(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		response.FailedResponse(w, http.StatusUnauthorized, customErr.ErrUserIdNotFound.Error(), nil)
+		return
+	}
+
+	settings, err := wh.watermarkUsecase.Get(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, customErr.ErrWatermarkNotFound) {
+			response.FailedResponse(w, http.StatusNotFound, err.Error(), nil)
+			return
+		}
+		response.FailedResponse(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	response.SuccessResponse(w, http.StatusOK, "successfully get watermark settings", settings)
+}
+
+func (wh *WatermarkHandler) Save(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		response.FailedResponse(w, http.StatusUnauthorized, customErr.ErrUserIdNotFound.Error(), nil)
+		return
+	}
+
+	var req *dto.WatermarkRequest
+
+	if !bindJSON(w, r, &req) {
+		return
+	}
+
+	settings, err := wh.watermarkUsecase.Save(r.Context(), userID, req)
+	if err != nil {
+		var fieldErrs validator.Errors
+		if errors.As(err, &fieldErrs) {
+			response.FailedResponse(w, http.StatusUnprocessableEntity, customErr.ErrValidation.Error(), fieldErrs)
+			return
+		}
+		response.FailedResponse(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	response.SuccessResponse(w, http.StatusOK, "successfully save watermark settings", settings)
+}
+
+func (wh *WatermarkHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		response.FailedResponse(w, http.StatusUnauthorized, customErr.ErrUserIdNotFound.Error(), nil)
+		return
+	}
+
+	if err := wh.watermarkUsecase.Delete(r.Context(), userID); err != nil {
+		if errors.Is(err, customErr.ErrWatermarkNotFound) {
+			response.FailedResponse(w, http.StatusNotFound, err.Error(), nil)
+			return
+		}
+		response.FailedResponse(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	response.SuccessResponse(w, http.StatusOK, "successfully delete watermark settings", nil)
+}
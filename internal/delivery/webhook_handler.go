@@ -0,0 +1,131 @@
+package delivery
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/federicodosantos/image-smith/internal/dto"
+	"github.com/federicodosantos/image-smith/internal/usecase"
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	"github.com/federicodosantos/image-smith/pkg/middleware"
+	response "github.com/federicodosantos/image-smith/pkg/response"
+	"github.com/federicodosantos/image-smith/pkg/validator"
+)
+
+type WebhookHandler struct {
+	webhookUsecase usecase.IWebhookUsecase
+}
+
+func NewWebhookHandler(webhookUsecase usecase.IWebhookUsecase) *WebhookHandler {
+	return &WebhookHandler{webhookUsecase: webhookUsecase}
+}
+
+func WebhookRoutes(router *http.ServeMux, webhookHandler *WebhookHandler, auth middleware.Middleware) {
+	router.Handle("POST /webhooks", auth(http.HandlerFunc(webhookHandler.Create)))
+	router.Handle("GET /webhooks", auth(http.HandlerFunc(webhookHandler.List)))
+	router.Handle("DELETE /webhooks/{id}", auth(http.HandlerFunc(webhookHandler.Delete)))
+	router.Handle("GET /webhooks/{id}/deliveries", auth(http.HandlerFunc(webhookHandler.ListDeliveries)))
+	router.Handle("POST /webhooks/{id}/deliveries/{delivery_id}/replay", auth(http.HandlerFunc(webhookHandler.Replay)))
+}
+
+func (wh *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		response.FailedResponse(w, http.StatusUnauthorized, customErr.ErrUserIdNotFound.Error(), nil)
+		return
+	}
+
+	var req *dto.WebhookRequest
+
+	if !bindJSON(w, r, &req) {
+		return
+	}
+
+	webhook, err := wh.webhookUsecase.Create(r.Context(), userID, req)
+	if err != nil {
+		var fieldErrs validator.Errors
+		if errors.As(err, &fieldErrs) {
+			response.FailedResponse(w, http.StatusUnprocessableEntity, customErr.ErrValidation.Error(), fieldErrs)
+			return
+		}
+		response.FailedResponse(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	response.SuccessResponse(w, http.StatusCreated, "successfully create a webhook", webhook)
+}
+
+func (wh *WebhookHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		response.FailedResponse(w, http.StatusUnauthorized, customErr.ErrUserIdNotFound.Error(), nil)
+		return
+	}
+
+	webhooks, err := wh.webhookUsecase.List(r.Context(), userID)
+	if err != nil {
+		response.FailedResponse(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	response.SuccessResponse(w, http.StatusOK, "successfully list webhooks", webhooks)
+}
+
+func (wh *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		response.FailedResponse(w, http.StatusUnauthorized, customErr.ErrUserIdNotFound.Error(), nil)
+		return
+	}
+
+	if err := wh.webhookUsecase.Delete(r.Context(), userID, r.PathValue("id")); err != nil {
+		if errors.Is(err, customErr.ErrWebhookNotFound) {
+			response.FailedResponse(w, http.StatusNotFound, err.Error(), nil)
+			return
+		}
+		response.FailedResponse(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	response.SuccessResponse(w, http.StatusOK, "successfully delete a webhook", nil)
+}
+
+func (wh *WebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		response.FailedResponse(w, http.StatusUnauthorized, customErr.ErrUserIdNotFound.Error(), nil)
+		return
+	}
+
+	deliveries, err := wh.webhookUsecase.ListDeliveries(r.Context(), userID, r.PathValue("id"))
+	if err != nil {
+		if errors.Is(err, customErr.ErrWebhookNotFound) {
+			response.FailedResponse(w, http.StatusNotFound, err.Error(), nil)
+			return
+		}
+		response.FailedResponse(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	response.SuccessResponse(w, http.StatusOK, "successfully list webhook deliveries", deliveries)
+}
+
+func (wh *WebhookHandler) Replay(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		response.FailedResponse(w, http.StatusUnauthorized, customErr.ErrUserIdNotFound.Error(), nil)
+		return
+	}
+
+	delivery, err := wh.webhookUsecase.Replay(r.Context(), userID, r.PathValue("id"), r.PathValue("delivery_id"))
+	if err != nil {
+		if errors.Is(err, customErr.ErrWebhookDeliveryNotFound) {
+			response.FailedResponse(w, http.StatusNotFound, err.Error(), nil)
+			return
+		}
+		response.FailedResponse(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	response.SuccessResponse(w, http.StatusAccepted, "successfully replay a webhook delivery", delivery)
+}
@@ -0,0 +1,224 @@
+package dto
+
+import (
+	"io"
+	"time"
+)
+
+type ImageUploadRequest struct {
+	Filename string
+	File     io.Reader
+}
+
+// ImageUploadResponse describes the stored image. Duplicate is set when the
+// caller already had an image with the same content, which is returned
+// instead of a new one.
+type ImageUploadResponse struct {
+	ID        string `json:"id"`
+	ImageURL  string `json:"image_url"`
+	Duplicate bool   `json:"duplicate"`
+}
+
+// ImageUploadURLRequest declares the file a client is about to upload
+// straight to storage. Its content is checked against Size and ContentType
+// when the upload is completed.
+type ImageUploadURLRequest struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type" validate:"required"`
+	Size        int64  `json:"size" validate:"required,min=1"`
+}
+
+// ImageUploadURLResponse is the pending image and the URL to PUT its content
+// to, sending ContentType, before ExpiresAt.
+type ImageUploadURLResponse struct {
+	ID          string    `json:"id"`
+	UploadURL   string    `json:"upload_url"`
+	Method      string    `json:"method"`
+	ContentType string    `json:"content_type"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+type ResizeRequest struct {
+	Width      int    `json:"width"`
+	Height     int    `json:"height"`
+	Fit        string `json:"fit"`
+	Gravity    string `json:"gravity"`
+	Background string `json:"background"`
+	Filter     string `json:"filter"`
+}
+
+type CropRequest struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+type RotateRequest struct {
+	Angle      float64 `json:"angle"`
+	Background string  `json:"background"`
+}
+
+type SharpenRequest struct {
+	Sigma     float64 `json:"sigma"`
+	Amount    float64 `json:"amount"`
+	Threshold float64 `json:"threshold"`
+}
+
+// EncodeRequest tunes the encoder of the output format. Options that do not
+// apply to it are ignored.
+type EncodeRequest struct {
+	Quality     int    `json:"quality"`
+	Progressive bool   `json:"progressive"`
+	Compression string `json:"compression"`
+	Colors      int    `json:"colors"`
+}
+
+type ImageTransformRequest struct {
+	Resize     *ResizeRequest    `json:"resize"`
+	Crop       *CropRequest      `json:"crop"`
+	Rotate     *RotateRequest    `json:"rotate"`
+	Flip       bool              `json:"flip"`
+	Flop       bool              `json:"flop"`
+	Grayscale  bool              `json:"grayscale"`
+	Blur       float64           `json:"blur"`
+	Sharpen    *SharpenRequest   `json:"sharpen"`
+	Brightness float64           `json:"brightness"`
+	Contrast   float64           `json:"contrast"`
+	Saturation float64           `json:"saturation"`
+	Gamma      float64           `json:"gamma"`
+	Watermark  *WatermarkRequest `json:"watermark"`
+	Convert    string            `json:"convert"`
+	Encode     *EncodeRequest    `json:"encode"`
+	Frame      *int              `json:"frame"`
+	// StripMetadata defaults to true; KeepICC keeps the color profile of a
+	// stripped image.
+	StripMetadata *bool `json:"strip_metadata"`
+	KeepICC       bool  `json:"keep_icc"`
+}
+
+// ImageTransformResponse describes the derived image. Duplicate is set when
+// an image with the same content already existed and is returned instead.
+type ImageTransformResponse struct {
+	ID        string  `json:"id"`
+	ParentID  *string `json:"parent_id"`
+	ImageURL  string  `json:"image_url"`
+	Duplicate bool    `json:"duplicate"`
+}
+
+// EXIF holds the fields read from an image's EXIF block on upload.
+type EXIF struct {
+	CameraMake  *string    `json:"camera_make"`
+	CameraModel *string    `json:"camera_model"`
+	TakenAt     *time.Time `json:"taken_at"`
+	Orientation int        `json:"orientation"`
+}
+
+// FocalPointRequest sets an image's point of interest as fractions of its
+// width and height.
+type FocalPointRequest struct {
+	X *float64 `json:"x" validate:"required,min=0,max=1"`
+	Y *float64 `json:"y" validate:"required,min=0,max=1"`
+}
+
+type FocalPoint struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+type ImageResponse struct {
+	ID               string      `json:"id"`
+	ParentID         *string     `json:"parent_id"`
+	OriginalFilename string      `json:"original_filename"`
+	MimeType         string      `json:"mime_type"`
+	Width            int         `json:"width"`
+	Height           int         `json:"height"`
+	Size             int64       `json:"size"`
+	Checksum         string      `json:"checksum"`
+	PerceptualHash   *string     `json:"perceptual_hash"`
+	FocalPoint       *FocalPoint `json:"focal_point"`
+	EXIF             *EXIF       `json:"exif"`
+	ImageURL         string      `json:"image_url"`
+	CreatedAt        time.Time   `json:"created_at"`
+	UpdatedAt        time.Time   `json:"updated_at"`
+}
+
+// SimilarImagesRequest holds the query parameters of GET /images/{id}/similar.
+// MaxDistance is the largest number of differing perceptual hash bits.
+type SimilarImagesRequest struct {
+	MaxDistance *int `json:"max_distance" validate:"omitempty,min=0,max=64"`
+	Limit       int  `json:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+// SimilarImageResponse is an image found by a similarity search and its
+// Hamming distance to the searched image.
+type SimilarImageResponse struct {
+	*ImageResponse
+	Distance int `json:"distance"`
+}
+
+// ImageListRequest holds the query parameters of GET /images. Parameters that
+// fail to parse are reported by the handler before validation runs.
+type ImageListRequest struct {
+	Cursor        string     `json:"cursor"`
+	Limit         int        `json:"limit" validate:"omitempty,min=1,max=100"`
+	Format        string     `json:"format"`
+	MinSize       *int64     `json:"min_size" validate:"omitempty,min=0"`
+	MaxSize       *int64     `json:"max_size" validate:"omitempty,min=0"`
+	CreatedAfter  *time.Time `json:"created_after"`
+	CreatedBefore *time.Time `json:"created_before"`
+	OriginalsOnly bool       `json:"originals_only"`
+	ParentID      string     `json:"parent_id"`
+	Sort          string     `json:"sort" validate:"omitempty,oneof=created_at size"`
+	Order         string     `json:"order" validate:"omitempty,oneof=asc desc"`
+}
+
+type ImageListResponse struct {
+	Images     []*ImageResponse
+	Limit      int
+	HasMore    bool
+	NextCursor string
+}
+
+// ImageRenderRequest holds the query parameters of GET /images/{id}/render.
+// IfNoneMatch carries the request's If-None-Match header.
+type ImageRenderRequest struct {
+	Width       int
+	Height      int
+	Fit         string
+	Gravity     string
+	Background  string
+	Filter      string
+	Format      string
+	Quality     int
+	IfNoneMatch string
+}
+
+// ImageRenderResponse is a rendered variant. Body is nil when NotModified is
+// set; otherwise the caller must close it.
+type ImageRenderResponse struct {
+	Body        io.ReadCloser
+	ContentType string
+	Size        int64
+	ETag        string
+	NotModified bool
+}
+
+// SignedURLRequest describes the rendering a signed URL grants access to.
+// ExpiresIn is in seconds.
+type SignedURLRequest struct {
+	Width      int    `json:"w" validate:"omitempty,min=0"`
+	Height     int    `json:"h" validate:"omitempty,min=0"`
+	Fit        string `json:"fit"`
+	Gravity    string `json:"gravity"`
+	Background string `json:"bg"`
+	Filter     string `json:"filter"`
+	Format     string `json:"fmt"`
+	Quality    int    `json:"q" validate:"omitempty,min=1,max=100"`
+	ExpiresIn  int    `json:"expires_in" validate:"omitempty,min=1,max=604800"`
+}
+
+type SignedURLResponse struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -0,0 +1,17 @@
+package dto
+
+import "time"
+
+type JobResponse struct {
+	ID            string    `json:"id"`
+	Type          string    `json:"type"`
+	Status        string    `json:"status"`
+	ImageID       string    `json:"image_id"`
+	ResultImageID *string   `json:"result_image_id"`
+	Attempts      int       `json:"attempts"`
+	MaxAttempts   int       `json:"max_attempts"`
+	LastError     *string   `json:"last_error"`
+	RunAt         time.Time `json:"run_at"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
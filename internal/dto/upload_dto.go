@@ -0,0 +1,18 @@
+package dto
+
+import "time"
+
+// UploadCreateRequest opens a resumable upload of Length bytes, read from the
+// Upload-Length and Upload-Metadata headers.
+type UploadCreateRequest struct {
+	Length   int64  `json:"length" validate:"required,min=1"`
+	Filename string `json:"filename" validate:"max=255"`
+}
+
+// UploadSessionResponse reports how much of a resumable upload was received.
+type UploadSessionResponse struct {
+	ID        string    `json:"id"`
+	Length    int64     `json:"length"`
+	Offset    int64     `json:"offset"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
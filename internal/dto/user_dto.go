@@ -3,23 +3,29 @@ package dto
 import "time"
 
 type UserRegisterRequest struct {
-	Name     string
-	Email    string
-	Password string
+	Name            string `json:"name" validate:"required,max=100"`
+	Email           string `json:"email" validate:"required,email,max=100"`
+	Password        string `json:"password" validate:"required,password"`
+	ConfirmPassword string `json:"confirm_password" validate:"required,eqfield=Password"`
 }
 
 type UserLoginRequest struct {
-	Email    string
-	Password string
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
 }
 
 type UserRegisterResponse struct {
-	ID        string
-	Name      string
-	Email     string
-	CreatedAt time.Time
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type UserLoginResponse struct {
-	JWTToken string
+	JWTToken     string `json:"jwt_token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
 }
@@ -0,0 +1,31 @@
+package dto
+
+import "time"
+
+// WatermarkRequest configures the watermark step of a transform and is also
+// the body that saves an account's defaults. Either ImageID or Text names the
+// overlay; in a transform every empty field falls back to the saved defaults.
+type WatermarkRequest struct {
+	ImageID  string  `json:"image_id" validate:"omitempty,max=36"`
+	Text     string  `json:"text" validate:"omitempty,max=200"`
+	Color    string  `json:"color" validate:"omitempty,max=11"`
+	FontSize float64 `json:"font_size" validate:"omitempty,min=0,max=512"`
+	Gravity  string  `json:"gravity" validate:"omitempty,oneof=center north south east west northeast northwest southeast southwest"`
+	Margin   int     `json:"margin" validate:"omitempty,min=0,max=8192"`
+	Opacity  float64 `json:"opacity" validate:"omitempty,min=0,max=1"`
+	Scale    float64 `json:"scale" validate:"omitempty,min=0,max=1"`
+	Tile     *bool   `json:"tile"`
+}
+
+type WatermarkResponse struct {
+	ImageID   *string   `json:"image_id"`
+	Text      string    `json:"text"`
+	Color     string    `json:"color"`
+	FontSize  float64   `json:"font_size"`
+	Gravity   string    `json:"gravity"`
+	Margin    int       `json:"margin"`
+	Opacity   float64   `json:"opacity"`
+	Scale     float64   `json:"scale"`
+	Tile      bool      `json:"tile"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,54 @@
+package model
+
+import "time"
+
+const (
+	// ImageStatusPending marks an image handed a presigned upload url whose
+	// content was not verified yet. Pending images are hidden from every
+	// read but the one completing them.
+	ImageStatusPending = "pending"
+	ImageStatusReady   = "ready"
+)
+
+// Image is the metadata of a stored image. Derived images produced by a
+// transformation point at their source through ParentID. FocalX and FocalY
+// are an optional point of interest, as fractions of the width and height,
+// that cover resizes keep in frame.
+//
+// CameraMake, CameraModel, TakenAt and Orientation are read from the EXIF
+// block on upload. Width and Height are the upright dimensions, already
+// swapped when the orientation rotates the image by a quarter turn.
+//
+// PHash is the perceptual hash of the upright pixels, stored as the signed
+// reinterpretation of imaging.PerceptualHash; it is nil for images stored
+// before hashing was introduced.
+//
+// ChecksumDuplicate flags the later copies of content an owner stored more
+// than once before checksums were unique per owner. They are never returned
+// by deduplication.
+//
+// UploadExpiresAt is only set while the image is pending.
+type Image struct {
+	ID                string     `db:"id"`
+	OwnerID           string     `db:"owner_id"`
+	ParentID          *string    `db:"parent_id"`
+	OriginalFilename  string     `db:"original_filename"`
+	StorageKey        string     `db:"storage_key"`
+	MimeType          string     `db:"mime_type"`
+	Width             int        `db:"width"`
+	Height            int        `db:"height"`
+	Size              int64      `db:"size"`
+	Checksum          string     `db:"checksum"`
+	ChecksumDuplicate bool       `db:"checksum_duplicate"`
+	FocalX            *float64   `db:"focal_x"`
+	FocalY            *float64   `db:"focal_y"`
+	CameraMake        *string    `db:"camera_make"`
+	CameraModel       *string    `db:"camera_model"`
+	TakenAt           *time.Time `db:"taken_at"`
+	Orientation       int        `db:"orientation"`
+	PHash             *int64     `db:"phash"`
+	Status            string     `db:"status"`
+	UploadExpiresAt   *time.Time `db:"upload_expires_at"`
+	CreatedAt         time.Time  `db:"created_at"`
+	UpdatedAt         time.Time  `db:"updated_at"`
+}
@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+const (
+	JobTypeTransform = "transform"
+
+	JobStatusQueued    = "queued"
+	JobStatusRunning   = "running"
+	JobStatusSucceeded = "succeeded"
+	JobStatusFailed    = "failed"
+)
+
+// Job is a unit of background work on an image. A running job whose
+// LockedUntil has passed is assumed abandoned by a crashed worker and can be
+// claimed again.
+type Job struct {
+	ID            string     `db:"id"`
+	OwnerID       string     `db:"owner_id"`
+	ImageID       string     `db:"image_id"`
+	Type          string     `db:"type"`
+	Payload       []byte     `db:"payload"`
+	Status        string     `db:"status"`
+	Attempts      int        `db:"attempts"`
+	MaxAttempts   int        `db:"max_attempts"`
+	RunAt         time.Time  `db:"run_at"`
+	LockedUntil   *time.Time `db:"locked_until"`
+	ResultImageID *string    `db:"result_image_id"`
+	LastError     *string    `db:"last_error"`
+	CreatedAt     time.Time  `db:"created_at"`
+	UpdatedAt     time.Time  `db:"updated_at"`
+}
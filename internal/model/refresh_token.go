@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// RefreshToken is a single-use token; each rotation revokes it and issues a
+// new token in the same family.
+type RefreshToken struct {
+	ID         string     `db:"id"`
+	UserID     string     `db:"user_id"`
+	FamilyID   string     `db:"family_id"`
+	TokenHash  string     `db:"token_hash"`
+	ExpiresAt  time.Time  `db:"expires_at"`
+	RevokedAt  *time.Time `db:"revoked_at"`
+	ReplacedBy *string    `db:"replaced_by"`
+	CreatedAt  time.Time  `db:"created_at"`
+}
@@ -0,0 +1,23 @@
+package model
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// UploadSession is a resumable upload in progress. Each accepted chunk is
+// stored as its own object and listed in ChunkKeys in upload order; Offset is
+// their total size. A session that is not finalized before ExpiresAt is
+// removed with its chunks.
+type UploadSession struct {
+	ID        string         `db:"id"`
+	OwnerID   string         `db:"owner_id"`
+	Filename  string         `db:"filename"`
+	Length    int64          `db:"upload_length"`
+	Offset    int64          `db:"upload_offset"`
+	ChunkKeys pq.StringArray `db:"chunk_keys"`
+	ExpiresAt time.Time      `db:"expires_at"`
+	CreatedAt time.Time      `db:"created_at"`
+	UpdatedAt time.Time      `db:"updated_at"`
+}
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// WatermarkSettings are an account's default watermark. A transform that asks
+// for a watermark falls back to these for every field it leaves empty. Either
+// ImageID or Text is set; zero values in the other fields select the built-in
+// defaults.
+type WatermarkSettings struct {
+	OwnerID   string    `db:"owner_id"`
+	ImageID   *string   `db:"image_id"`
+	Text      string    `db:"text"`
+	Color     string    `db:"color"`
+	FontSize  float64   `db:"font_size"`
+	Gravity   string    `db:"gravity"`
+	Margin    int       `db:"margin"`
+	Opacity   float64   `db:"opacity"`
+	Scale     float64   `db:"scale"`
+	Tile      bool      `db:"tile"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
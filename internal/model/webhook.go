@@ -0,0 +1,51 @@
+package model
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	EventImageUploaded    = "image.uploaded"
+	EventImageTransformed = "image.transformed"
+	EventImageDeleted     = "image.deleted"
+	EventJobFailed        = "job.failed"
+
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusSending   = "sending"
+	DeliveryStatusSucceeded = "succeeded"
+	DeliveryStatusFailed    = "failed"
+)
+
+// Webhook is an endpoint an account registered for a set of events. Secret
+// signs every delivery and is only shown to the owner when it is created.
+type Webhook struct {
+	ID        string         `db:"id"`
+	OwnerID   string         `db:"owner_id"`
+	URL       string         `db:"url"`
+	Secret    string         `db:"secret"`
+	Events    pq.StringArray `db:"events"`
+	CreatedAt time.Time      `db:"created_at"`
+	UpdatedAt time.Time      `db:"updated_at"`
+}
+
+// WebhookDelivery is one event sent to one webhook, and doubles as the
+// delivery log. A delivery stuck in sending past LockedUntil is retried.
+type WebhookDelivery struct {
+	ID             string     `db:"id"`
+	WebhookID      string     `db:"webhook_id"`
+	OwnerID        string     `db:"owner_id"`
+	Event          string     `db:"event"`
+	Payload        []byte     `db:"payload"`
+	Status         string     `db:"status"`
+	Attempts       int        `db:"attempts"`
+	MaxAttempts    int        `db:"max_attempts"`
+	NextAttemptAt  time.Time  `db:"next_attempt_at"`
+	LockedUntil    *time.Time `db:"locked_until"`
+	ResponseStatus *int       `db:"response_status"`
+	LastError      *string    `db:"last_error"`
+	DeliveredAt    *time.Time `db:"delivered_at"`
+	CreatedAt      time.Time  `db:"created_at"`
+	UpdatedAt      time.Time  `db:"updated_at"`
+}
@@ -0,0 +1,521 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/federicodosantos/image-smith/internal/model"
+	"github.com/federicodosantos/image-smith/internal/repository/query"
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// uniqueViolation is the Postgres error code of a unique index conflict.
+const uniqueViolation = "23505"
+
+// IImageRepository reads and writes image metadata. Every read and delete is
+// scoped to the owner, so an image belonging to someone else behaves exactly
+// like a missing one. GetImageOwner is the exception, for requests authorized
+// by a signed URL instead of a user.
+//
+// Blobs are content addressed by checksum within an owner: images with the
+// same content share one stored blob, whose references are counted in
+// image_blobs. Blobs are never shared between owners, so no image points at a
+// key naming someone else.
+//
+// Pending images, whose content was uploaded straight to storage and not
+// verified yet, are only visible to the pending methods.
+type IImageRepository interface {
+	CreateImage(ctx context.Context, image *model.Image) error
+	CreatePendingImage(ctx context.Context, image *model.Image) error
+	GetPendingImage(ctx context.Context, id string, ownerID string) (*model.Image, error)
+	CompleteImage(ctx context.Context, image *model.Image) error
+	ListExpiredPendingImages(ctx context.Context, now time.Time, limit int) ([]model.Image, error)
+	DeletePendingImage(ctx context.Context, id string, ownerID string) error
+	GetImageById(ctx context.Context, id string, ownerID string) (*model.Image, error)
+	GetImageOwner(ctx context.Context, id string) (string, error)
+	GetImageByChecksum(ctx context.Context, ownerID string, checksum string) (*model.Image, error)
+	FindSimilarImages(ctx context.Context, ownerID string, imageID string, hash int64, maxDistance int, limit int) ([]SimilarImage, error)
+	ListImages(ctx context.Context, filter ImageFilter) (*ImagePage, error)
+	UpdateFocalPoint(ctx context.Context, id string, ownerID string, x, y *float64) error
+	DeleteImage(ctx context.Context, id string, ownerID string) (bool, error)
+}
+
+const (
+	SortByCreatedAt = "created_at"
+	SortBySize      = "size"
+
+	SortAscending  = "asc"
+	SortDescending = "desc"
+)
+
+// ImageFilter selects one page of an owner's images. Zero values disable a
+// filter; SortBy defaults to created_at and Order to descending. Cursor is the
+// NextCursor of the previous page and is only valid with the same sort.
+type ImageFilter struct {
+	OwnerID       string
+	MimeType      string
+	MinSize       *int64
+	MaxSize       *int64
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	OriginalsOnly bool
+	ParentID      string
+	SortBy        string
+	Order         string
+	Limit         int
+	Cursor        string
+}
+
+type ImagePage struct {
+	Images     []model.Image
+	NextCursor string
+	HasMore    bool
+}
+
+// SimilarImage is an image found by FindSimilarImages, with the number of bits
+// in which its perceptual hash differs from the one searched for.
+type SimilarImage struct {
+	model.Image
+	Distance int `db:"distance"`
+}
+
+// imageCursor is the keyset position after the last image of a page: the
+// value of the sort column and the ID as a tie breaker.
+type imageCursor struct {
+	SortBy string `json:"s"`
+	Value  string `json:"v"`
+	ID     string `json:"id"`
+}
+
+type ImageRepository struct {
+	db *sqlx.DB
+}
+
+func NewImageRepository(db *sqlx.DB) IImageRepository {
+	return &ImageRepository{db: db}
+}
+
+// CreateImage records image and takes a reference to its blob in one
+// transaction. When a blob with the same checksum is already stored,
+// image.StorageKey is pointed at it and the caller may delete the one it
+// uploaded. It returns ErrDuplicateImage when the owner already has an image
+// with that checksum.
+func (i *ImageRepository) CreateImage(ctx context.Context, image *model.Image) error {
+	tx, err := i.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	storageKey, err := acquireBlob(ctx, tx, image)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, query.InsertImageQuery,
+		image.ID, image.OwnerID, image.ParentID, image.OriginalFilename, storageKey, image.MimeType,
+		image.Width, image.Height, image.Size, image.Checksum, image.CameraMake, image.CameraModel, image.TakenAt,
+		image.Orientation, image.PHash, image.CreatedAt, image.UpdatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return customErr.ErrDuplicateImage
+		}
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows != 1 {
+		return customErr.ErrRowsAffected
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	image.StorageKey = storageKey
+
+	return nil
+}
+
+// CreatePendingImage records an image whose content is still to be uploaded
+// to image.StorageKey. It stays pending until CompleteImage.
+func (i *ImageRepository) CreatePendingImage(ctx context.Context, image *model.Image) error {
+	result, err := i.db.ExecContext(ctx, query.InsertPendingImageQuery,
+		image.ID, image.OwnerID, image.OriginalFilename, image.StorageKey, image.MimeType, image.Size,
+		image.UploadExpiresAt, image.CreatedAt, image.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows != 1 {
+		return customErr.ErrRowsAffected
+	}
+
+	image.Status = model.ImageStatusPending
+
+	return nil
+}
+
+// GetPendingImage returns ErrImageNotFound for uploads that expired, even
+// before they are reaped.
+func (i *ImageRepository) GetPendingImage(ctx context.Context, id string, ownerID string) (*model.Image, error) {
+	var image model.Image
+
+	err := i.db.GetContext(ctx, &image, query.GetPendingImageQuery, id, ownerID, time.Now())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, customErr.ErrImageNotFound
+		}
+		return nil, err
+	}
+
+	return &image, nil
+}
+
+// CompleteImage stores what was read from the uploaded content of a pending
+// image, takes a reference to its blob and marks it ready, in one
+// transaction. Blobs are shared and duplicates reported like in CreateImage.
+// It returns ErrImageNotFound when the image is no longer pending, having
+// been completed or reaped in the meantime.
+func (i *ImageRepository) CompleteImage(ctx context.Context, image *model.Image) error {
+	tx, err := i.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	storageKey, err := acquireBlob(ctx, tx, image)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	result, err := tx.ExecContext(ctx, query.CompleteImageQuery,
+		storageKey, image.MimeType, image.Width, image.Height, image.Size, image.Checksum, image.CameraMake,
+		image.CameraModel, image.TakenAt, image.Orientation, image.PHash, now, image.ID, image.OwnerID)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return customErr.ErrDuplicateImage
+		}
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows != 1 {
+		return customErr.ErrImageNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	image.StorageKey = storageKey
+	image.Status = model.ImageStatusReady
+	image.UploadExpiresAt = nil
+	image.UpdatedAt = now
+
+	return nil
+}
+
+// ListExpiredPendingImages returns up to limit pending images whose upload
+// expired by now, oldest first.
+func (i *ImageRepository) ListExpiredPendingImages(ctx context.Context, now time.Time, limit int) ([]model.Image, error) {
+	images := []model.Image{}
+
+	if err := i.db.SelectContext(ctx, &images, query.ListExpiredPendingImagesQuery, now, limit); err != nil {
+		return nil, err
+	}
+
+	return images, nil
+}
+
+// DeletePendingImage removes an image that is still pending. Its content was
+// never counted as a blob, so nothing is released.
+func (i *ImageRepository) DeletePendingImage(ctx context.Context, id string, ownerID string) error {
+	result, err := i.db.ExecContext(ctx, query.DeletePendingImageQuery, id, ownerID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows != 1 {
+		return customErr.ErrImageNotFound
+	}
+
+	return nil
+}
+
+func (i *ImageRepository) GetImageById(ctx context.Context, id string, ownerID string) (*model.Image, error) {
+	var image model.Image
+
+	err := i.db.GetContext(ctx, &image, query.GetImageByIdQuery, id, ownerID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, customErr.ErrImageNotFound
+		}
+		return nil, err
+	}
+
+	return &image, nil
+}
+
+func (i *ImageRepository) GetImageOwner(ctx context.Context, id string) (string, error) {
+	var ownerID string
+
+	err := i.db.GetContext(ctx, &ownerID, query.GetImageOwnerQuery, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", customErr.ErrImageNotFound
+		}
+		return "", err
+	}
+
+	return ownerID, nil
+}
+
+func (i *ImageRepository) GetImageByChecksum(ctx context.Context, ownerID string, checksum string) (*model.Image, error) {
+	var image model.Image
+
+	err := i.db.GetContext(ctx, &image, query.GetImageByChecksumQuery, ownerID, checksum)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, customErr.ErrImageNotFound
+		}
+		return nil, err
+	}
+
+	return &image, nil
+}
+
+// FindSimilarImages returns up to limit of the owner's images, other than
+// imageID, whose perceptual hash is within maxDistance bits of hash, closest
+// first. The distance is computed by Postgres over the owner's hashed images.
+func (i *ImageRepository) FindSimilarImages(ctx context.Context, ownerID string, imageID string, hash int64, maxDistance int, limit int) ([]SimilarImage, error) {
+	images := []SimilarImage{}
+
+	err := i.db.SelectContext(ctx, &images, query.FindSimilarImagesQuery, ownerID, imageID, hash, maxDistance, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return images, nil
+}
+
+func (i *ImageRepository) ListImages(ctx context.Context, filter ImageFilter) (*ImagePage, error) {
+	if filter.Limit < 1 {
+		return nil, fmt.Errorf("invalid page limit %d", filter.Limit)
+	}
+
+	sortBy := filter.SortBy
+	if sortBy == "" {
+		sortBy = SortByCreatedAt
+	}
+	if sortBy != SortByCreatedAt && sortBy != SortBySize {
+		return nil, fmt.Errorf("unknown sort column %q", sortBy)
+	}
+
+	direction, comparison := "DESC", "<"
+	if filter.Order == SortAscending {
+		direction, comparison = "ASC", ">"
+	}
+
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	conditions := []string{"owner_id = " + arg(filter.OwnerID), "status = 'ready'"}
+
+	if filter.MimeType != "" {
+		conditions = append(conditions, "mime_type = "+arg(filter.MimeType))
+	}
+	if filter.MinSize != nil {
+		conditions = append(conditions, "size >= "+arg(*filter.MinSize))
+	}
+	if filter.MaxSize != nil {
+		conditions = append(conditions, "size <= "+arg(*filter.MaxSize))
+	}
+	if filter.CreatedAfter != nil {
+		conditions = append(conditions, "created_at >= "+arg(*filter.CreatedAfter))
+	}
+	if filter.CreatedBefore != nil {
+		conditions = append(conditions, "created_at < "+arg(*filter.CreatedBefore))
+	}
+	if filter.OriginalsOnly {
+		conditions = append(conditions, "parent_id IS NULL")
+	}
+	if filter.ParentID != "" {
+		conditions = append(conditions, "parent_id = "+arg(filter.ParentID))
+	}
+
+	if filter.Cursor != "" {
+		value, id, err := decodeImageCursor(filter.Cursor, sortBy)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions,
+			fmt.Sprintf("(%s, id) %s (%s, %s)", sortBy, comparison, arg(value), arg(id)))
+	}
+
+	statement := fmt.Sprintf("%s WHERE %s ORDER BY %s %s, id %s LIMIT %s",
+		query.ListImagesQuery, strings.Join(conditions, " AND "), sortBy, direction, direction, arg(filter.Limit+1))
+
+	images := []model.Image{}
+	if err := i.db.SelectContext(ctx, &images, statement, args...); err != nil {
+		return nil, err
+	}
+
+	page := &ImagePage{Images: images}
+	if len(images) > filter.Limit {
+		page.Images = images[:filter.Limit]
+		page.HasMore = true
+		page.NextCursor = encodeImageCursor(page.Images[filter.Limit-1], sortBy)
+	}
+
+	return page, nil
+}
+
+// UpdateFocalPoint stores the focal point of a ready image; nil coordinates
+// clear it.
+func (i *ImageRepository) UpdateFocalPoint(ctx context.Context, id string, ownerID string, x, y *float64) error {
+	result, err := i.db.ExecContext(ctx, query.UpdateFocalPointQuery, x, y, time.Now(), id, ownerID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows != 1 {
+		return customErr.ErrImageNotFound
+	}
+
+	return nil
+}
+
+// DeleteImage removes the image and releases its blob in one transaction. It
+// reports whether that was the blob's last reference, in which case the caller
+// deletes it from storage.
+func (i *ImageRepository) DeleteImage(ctx context.Context, id string, ownerID string) (bool, error) {
+	tx, err := i.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var storageKey string
+	if err := tx.GetContext(ctx, &storageKey, query.DeleteImageQuery, id, ownerID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, customErr.ErrImageNotFound
+		}
+		return false, err
+	}
+
+	var refs int
+	err = tx.GetContext(ctx, &refs, query.ReleaseBlobQuery, storageKey)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		// an untracked blob belongs to this image alone
+		refs = 0
+	case err != nil:
+		return false, err
+	case refs <= 0:
+		if _, err := tx.ExecContext(ctx, query.DeleteBlobQuery, storageKey); err != nil {
+			return false, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+
+	return refs <= 0, nil
+}
+
+// acquireBlob takes a reference to a blob of the owner with the checksum of
+// image, or records image.StorageKey as a new blob, and returns the key the
+// image must point at.
+func acquireBlob(ctx context.Context, tx *sqlx.Tx, image *model.Image) (string, error) {
+	storageKey := image.StorageKey
+
+	err := tx.GetContext(ctx, &storageKey, query.AcquireBlobQuery, image.OwnerID, image.Checksum)
+	if errors.Is(err, sql.ErrNoRows) {
+		_, err = tx.ExecContext(ctx, query.InsertBlobQuery, image.StorageKey, image.OwnerID, image.Checksum)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return storageKey, nil
+}
+
+func encodeImageCursor(image model.Image, sortBy string) string {
+	cursor := imageCursor{SortBy: sortBy, ID: image.ID}
+	if sortBy == SortBySize {
+		cursor.Value = strconv.FormatInt(image.Size, 10)
+	} else {
+		cursor.Value = image.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeImageCursor returns the typed sort value and ID held by encoded. A
+// cursor minted for a different sort column is rejected.
+func decodeImageCursor(encoded string, sortBy string) (any, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, "", customErr.ErrInvalidCursor
+	}
+
+	var cursor imageCursor
+	if err := json.Unmarshal(raw, &cursor); err != nil || cursor.SortBy != sortBy || cursor.ID == "" {
+		return nil, "", customErr.ErrInvalidCursor
+	}
+
+	if sortBy == SortBySize {
+		size, err := strconv.ParseInt(cursor.Value, 10, 64)
+		if err != nil {
+			return nil, "", customErr.ErrInvalidCursor
+		}
+		return size, cursor.ID, nil
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, cursor.Value)
+	if err != nil {
+		return nil, "", customErr.ErrInvalidCursor
+	}
+	return createdAt, cursor.ID, nil
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/federicodosantos/image-smith/internal/model"
+	"github.com/federicodosantos/image-smith/internal/repository/query"
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	"github.com/jmoiron/sqlx"
+)
+
+// IJobRepository stores background jobs. Reads for clients are owner-scoped;
+// the claim and state transitions are used by workers. A transition names the
+// attempt it ends and returns ErrJobLeaseLost when that attempt no longer holds
+// the job.
+type IJobRepository interface {
+	CreateJob(ctx context.Context, job *model.Job) error
+	GetJobById(ctx context.Context, id string, ownerID string) (*model.Job, error)
+	ClaimJob(ctx context.Context, lease time.Duration) (*model.Job, error)
+	FailAbandonedJobs(ctx context.Context, lastError string) ([]model.Job, error)
+	CompleteJob(ctx context.Context, id string, attempt int, resultImageID string) error
+	RetryJob(ctx context.Context, id string, attempt int, runAt time.Time, lastError string) error
+	FailJob(ctx context.Context, id string, attempt int, lastError string) error
+}
+
+type JobRepository struct {
+	db *sqlx.DB
+}
+
+func NewJobRepository(db *sqlx.DB) IJobRepository {
+	return &JobRepository{db: db}
+}
+
+func (j *JobRepository) CreateJob(ctx context.Context, job *model.Job) error {
+	result, err := j.db.ExecContext(ctx, query.InsertJobQuery,
+		job.ID, job.OwnerID, job.ImageID, job.Type, job.Payload, job.Status,
+		job.Attempts, job.MaxAttempts, job.RunAt, job.CreatedAt, job.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows != 1 {
+		return customErr.ErrRowsAffected
+	}
+
+	return nil
+}
+
+func (j *JobRepository) GetJobById(ctx context.Context, id string, ownerID string) (*model.Job, error) {
+	var job model.Job
+
+	err := j.db.GetContext(ctx, &job, query.GetJobByIdQuery, id, ownerID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, customErr.ErrJobNotFound
+		}
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+// ClaimJob marks the oldest due job as running for lease and returns it, or
+// returns nil when no job is due. Concurrent workers never claim the same job.
+func (j *JobRepository) ClaimJob(ctx context.Context, lease time.Duration) (*model.Job, error) {
+	var job model.Job
+	now := time.Now()
+
+	err := j.db.GetContext(ctx, &job, query.ClaimJobQuery, now.Add(lease), now)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+// FailAbandonedJobs fails the running jobs whose lease expired on their last
+// attempt with lastError, and returns them. ClaimJob never claims those again.
+func (j *JobRepository) FailAbandonedJobs(ctx context.Context, lastError string) ([]model.Job, error) {
+	jobs := []model.Job{}
+
+	if err := j.db.SelectContext(ctx, &jobs, query.FailAbandonedJobsQuery, lastError, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+func (j *JobRepository) CompleteJob(ctx context.Context, id string, attempt int, resultImageID string) error {
+	return j.exec(ctx, query.CompleteJobQuery, resultImageID, time.Now(), id, attempt)
+}
+
+func (j *JobRepository) RetryJob(ctx context.Context, id string, attempt int, runAt time.Time, lastError string) error {
+	return j.exec(ctx, query.RetryJobQuery, runAt, lastError, time.Now(), id, attempt)
+}
+
+func (j *JobRepository) FailJob(ctx context.Context, id string, attempt int, lastError string) error {
+	return j.exec(ctx, query.FailJobQuery, lastError, time.Now(), id, attempt)
+}
+
+func (j *JobRepository) exec(ctx context.Context, statement string, args ...any) error {
+	result, err := j.db.ExecContext(ctx, statement, args...)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows != 1 {
+		return customErr.ErrJobLeaseLost
+	}
+
+	return nil
+}
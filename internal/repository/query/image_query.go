@@ -0,0 +1,57 @@
+package query
+
+const (
+	InsertImageQuery = `INSERT INTO images(id, owner_id, parent_id, original_filename, storage_key, mime_type, width, height, size, checksum, camera_make, camera_model, taken_at, orientation, phash, created_at, updated_at) VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)`
+
+	// InsertPendingImageQuery records an image whose content is uploaded
+	// straight to storage; what is read from the content is filled in when it
+	// is completed.
+	InsertPendingImageQuery = `INSERT INTO images(id, owner_id, original_filename, storage_key, mime_type, size, status, upload_expires_at, created_at, updated_at)
+		VALUES($1, $2, $3, $4, $5, $6, 'pending', $7, $8, $9)`
+
+	GetImageByIdQuery = `SELECT * FROM images WHERE id = $1 AND owner_id = $2 AND status = 'ready'`
+
+	GetImageOwnerQuery = `SELECT owner_id FROM images WHERE id = $1 AND status = 'ready'`
+
+	// GetImageByChecksumQuery finds the copy of the content deduplication keeps.
+	GetImageByChecksumQuery = `SELECT * FROM images WHERE owner_id = $1 AND checksum = $2 AND status = 'ready' AND NOT checksum_duplicate`
+
+	// GetPendingImageQuery treats an expired upload as missing even before it
+	// is reaped.
+	GetPendingImageQuery = `SELECT * FROM images WHERE id = $1 AND owner_id = $2 AND status = 'pending' AND upload_expires_at > $3`
+
+	CompleteImageQuery = `UPDATE images SET status = 'ready', upload_expires_at = NULL, storage_key = $1, mime_type = $2, width = $3,
+		height = $4, size = $5, checksum = $6, camera_make = $7, camera_model = $8, taken_at = $9, orientation = $10, phash = $11, updated_at = $12
+		WHERE id = $13 AND owner_id = $14 AND status = 'pending'`
+
+	ListExpiredPendingImagesQuery = `SELECT * FROM images WHERE status = 'pending' AND upload_expires_at <= $1 ORDER BY upload_expires_at LIMIT $2`
+
+	DeletePendingImageQuery = `DELETE FROM images WHERE id = $1 AND owner_id = $2 AND status = 'pending'`
+
+	// ListImagesQuery is completed with the filter, keyset and ordering clauses
+	// built by ImageRepository.ListImages.
+	ListImagesQuery = `SELECT * FROM images`
+
+	// FindSimilarImagesQuery ranks the owner's other images by the Hamming
+	// distance between their perceptual hash and $3.
+	FindSimilarImagesQuery = `SELECT *, bit_count((phash # $3)::bit(64)) AS distance FROM images
+		WHERE owner_id = $1 AND id <> $2 AND status = 'ready' AND phash IS NOT NULL AND bit_count((phash # $3)::bit(64)) <= $4
+		ORDER BY distance, created_at DESC, id LIMIT $5`
+
+	UpdateFocalPointQuery = `UPDATE images SET focal_x = $1, focal_y = $2, updated_at = $3 WHERE id = $4 AND owner_id = $5 AND status = 'ready'`
+
+	DeleteImageQuery = `DELETE FROM images WHERE id = $1 AND owner_id = $2 RETURNING storage_key`
+
+	// AcquireBlobQuery takes a reference to a live blob of the owner with the
+	// checksum, returning its key. A blob released to zero in the meantime is
+	// skipped.
+	AcquireBlobQuery = `UPDATE image_blobs SET ref_count = ref_count + 1
+		WHERE storage_key = (SELECT storage_key FROM image_blobs WHERE owner_id = $1 AND checksum = $2 AND ref_count > 0 LIMIT 1) AND ref_count > 0
+		RETURNING storage_key`
+
+	InsertBlobQuery = `INSERT INTO image_blobs(storage_key, owner_id, checksum, ref_count) VALUES($1, $2, $3, 1)`
+
+	ReleaseBlobQuery = `UPDATE image_blobs SET ref_count = ref_count - 1 WHERE storage_key = $1 RETURNING ref_count`
+
+	DeleteBlobQuery = `DELETE FROM image_blobs WHERE storage_key = $1 AND ref_count = 0`
+)
@@ -0,0 +1,38 @@
+package query
+
+const (
+	InsertJobQuery = `INSERT INTO jobs(id, owner_id, image_id, type, payload, status, attempts, max_attempts, run_at, created_at, updated_at) VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+
+	GetJobByIdQuery = `SELECT * FROM jobs WHERE id = $1 AND owner_id = $2`
+
+	// ClaimJobQuery locks the oldest due job, skipping rows other workers have
+	// locked, and marks it running in the same statement. A job whose lease
+	// expired is only claimed again while it has attempts left.
+	ClaimJobQuery = `UPDATE jobs SET status = 'running', attempts = attempts + 1, locked_until = $1, updated_at = $2
+		WHERE id = (
+			SELECT id FROM jobs
+			WHERE (status = 'queued' AND run_at <= $2) OR (status = 'running' AND locked_until < $2 AND attempts < max_attempts)
+			ORDER BY run_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`
+
+	// FailAbandonedJobsQuery fails the jobs whose lease expired on their last
+	// attempt, such as those that crashed their worker.
+	FailAbandonedJobsQuery = `UPDATE jobs SET status = 'failed', locked_until = NULL, last_error = $1, updated_at = $2
+		WHERE status = 'running' AND locked_until < $2 AND attempts >= max_attempts
+		RETURNING *`
+
+	// CompleteJobQuery, RetryJobQuery and FailJobQuery only apply to the
+	// attempt holding the lease, so a worker whose job was claimed again
+	// cannot finish it a second time.
+	CompleteJobQuery = `UPDATE jobs SET status = 'succeeded', result_image_id = $1, locked_until = NULL, last_error = NULL, updated_at = $2
+		WHERE id = $3 AND status = 'running' AND attempts = $4`
+
+	RetryJobQuery = `UPDATE jobs SET status = 'queued', run_at = $1, locked_until = NULL, last_error = $2, updated_at = $3
+		WHERE id = $4 AND status = 'running' AND attempts = $5`
+
+	FailJobQuery = `UPDATE jobs SET status = 'failed', locked_until = NULL, last_error = $1, updated_at = $2
+		WHERE id = $3 AND status = 'running' AND attempts = $4`
+)
@@ -0,0 +1,11 @@
+package query
+
+const (
+	InsertRefreshTokenQuery = `INSERT INTO refresh_tokens(id, user_id, family_id, token_hash, expires_at, created_at) VALUES($1, $2, $3, $4, $5, $6)`
+
+	GetRefreshTokenByHashQuery = `SELECT * FROM refresh_tokens WHERE token_hash = $1`
+
+	RevokeRefreshTokenQuery = `UPDATE refresh_tokens SET revoked_at = $1, replaced_by = $2 WHERE id = $3 AND revoked_at IS NULL`
+
+	RevokeRefreshTokenFamilyQuery = `UPDATE refresh_tokens SET revoked_at = $1 WHERE family_id = $2 AND revoked_at IS NULL`
+)
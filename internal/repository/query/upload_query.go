@@ -0,0 +1,20 @@
+package query
+
+const (
+	InsertUploadSessionQuery = `INSERT INTO upload_sessions(id, owner_id, filename, upload_length, upload_offset, chunk_keys, expires_at, created_at, updated_at)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+
+	// GetUploadSessionQuery treats an expired session as missing even before
+	// it is cleaned up.
+	GetUploadSessionQuery = `SELECT * FROM upload_sessions WHERE id = $1 AND owner_id = $2 AND expires_at > $3`
+
+	// AppendUploadChunkQuery only applies when the session is still at the
+	// offset the chunk was written for, so of two racing chunks one wins.
+	AppendUploadChunkQuery = `UPDATE upload_sessions SET upload_offset = upload_offset + $1, chunk_keys = array_append(chunk_keys, $2),
+		expires_at = $3, updated_at = $4
+		WHERE id = $5 AND owner_id = $6 AND upload_offset = $7 AND upload_offset + $1 <= upload_length`
+
+	ListExpiredUploadSessionsQuery = `SELECT * FROM upload_sessions WHERE expires_at <= $1 ORDER BY expires_at LIMIT $2`
+
+	DeleteUploadSessionQuery = `DELETE FROM upload_sessions WHERE id = $1 AND owner_id = $2`
+)
@@ -0,0 +1,15 @@
+package query
+
+const (
+	GetWatermarkSettingsQuery = `SELECT * FROM watermark_settings WHERE owner_id = $1`
+
+	// UpsertWatermarkSettingsQuery keeps the original created_at when the
+	// settings are replaced.
+	UpsertWatermarkSettingsQuery = `INSERT INTO watermark_settings(owner_id, image_id, text, color, font_size, gravity, margin, opacity, scale, tile, created_at, updated_at)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (owner_id) DO UPDATE SET image_id = EXCLUDED.image_id, text = EXCLUDED.text, color = EXCLUDED.color,
+			font_size = EXCLUDED.font_size, gravity = EXCLUDED.gravity, margin = EXCLUDED.margin, opacity = EXCLUDED.opacity,
+			scale = EXCLUDED.scale, tile = EXCLUDED.tile, updated_at = EXCLUDED.updated_at`
+
+	DeleteWatermarkSettingsQuery = `DELETE FROM watermark_settings WHERE owner_id = $1`
+)
@@ -0,0 +1,39 @@
+package query
+
+const (
+	InsertWebhookQuery = `INSERT INTO webhooks(id, owner_id, url, secret, events, created_at, updated_at) VALUES($1, $2, $3, $4, $5, $6, $7)`
+
+	GetWebhookByIdQuery = `SELECT * FROM webhooks WHERE id = $1 AND owner_id = $2`
+
+	ListWebhooksQuery = `SELECT * FROM webhooks WHERE owner_id = $1 ORDER BY created_at`
+
+	ListWebhooksForEventQuery = `SELECT * FROM webhooks WHERE owner_id = $1 AND $2 = ANY(events)`
+
+	DeleteWebhookQuery = `DELETE FROM webhooks WHERE id = $1 AND owner_id = $2`
+
+	InsertWebhookDeliveryQuery = `INSERT INTO webhook_deliveries(id, webhook_id, owner_id, event, payload, status, attempts, max_attempts, next_attempt_at, created_at, updated_at) VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+
+	GetWebhookDeliveryByIdQuery = `SELECT * FROM webhook_deliveries WHERE id = $1 AND webhook_id = $2 AND owner_id = $3`
+
+	ListWebhookDeliveriesQuery = `SELECT * FROM webhook_deliveries WHERE webhook_id = $1 AND owner_id = $2 ORDER BY created_at DESC LIMIT $3`
+
+	// ClaimWebhookDeliveryQuery works like ClaimJobQuery.
+	ClaimWebhookDeliveryQuery = `UPDATE webhook_deliveries SET status = 'sending', attempts = attempts + 1, locked_until = $1, updated_at = $2
+		WHERE id = (
+			SELECT id FROM webhook_deliveries
+			WHERE (status = 'pending' AND next_attempt_at <= $2) OR (status = 'sending' AND locked_until < $2 AND attempts < max_attempts)
+			ORDER BY next_attempt_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`
+
+	// FailAbandonedWebhookDeliveriesQuery works like FailAbandonedJobsQuery.
+	FailAbandonedWebhookDeliveriesQuery = `UPDATE webhook_deliveries SET status = 'failed', locked_until = NULL, last_error = $1, updated_at = $2
+		WHERE status = 'sending' AND locked_until < $2 AND attempts >= max_attempts`
+
+	// UpdateWebhookDeliveryQuery only applies to the attempt holding the
+	// lease, like CompleteJobQuery.
+	UpdateWebhookDeliveryQuery = `UPDATE webhook_deliveries SET status = $1, next_attempt_at = $2, locked_until = NULL, response_status = $3, last_error = $4, delivered_at = $5, updated_at = $6
+		WHERE id = $7 AND status = 'sending' AND attempts = $8`
+)
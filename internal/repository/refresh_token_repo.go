@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/federicodosantos/image-smith/internal/model"
+	"github.com/federicodosantos/image-smith/internal/repository/query"
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	"github.com/jmoiron/sqlx"
+)
+
+type IRefreshTokenRepository interface {
+	CreateRefreshToken(ctx context.Context, token *model.RefreshToken) error
+	GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*model.RefreshToken, error)
+	RotateRefreshToken(ctx context.Context, oldID string, newToken *model.RefreshToken) error
+	RevokeTokenFamily(ctx context.Context, familyID string) error
+}
+
+type RefreshTokenRepository struct {
+	db *sqlx.DB
+}
+
+func NewRefreshTokenRepository(db *sqlx.DB) IRefreshTokenRepository {
+	return &RefreshTokenRepository{db: db}
+}
+
+func (r *RefreshTokenRepository) CreateRefreshToken(ctx context.Context, token *model.RefreshToken) error {
+	return insertRefreshToken(ctx, r.db, token)
+}
+
+func (r *RefreshTokenRepository) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*model.RefreshToken, error) {
+	var token model.RefreshToken
+
+	err := r.db.GetContext(ctx, &token, query.GetRefreshTokenByHashQuery, tokenHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, customErr.ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// RotateRefreshToken revokes oldID and stores newToken in one transaction. It
+// returns ErrRefreshTokenReused when oldID was already revoked, which happens
+// when two requests race with the same token.
+func (r *RefreshTokenRepository) RotateRefreshToken(ctx context.Context, oldID string, newToken *model.RefreshToken) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, query.RevokeRefreshTokenQuery, time.Now(), newToken.ID, oldID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows != 1 {
+		return customErr.ErrRefreshTokenReused
+	}
+
+	if err := insertRefreshToken(ctx, tx, newToken); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *RefreshTokenRepository) RevokeTokenFamily(ctx context.Context, familyID string) error {
+	_, err := r.db.ExecContext(ctx, query.RevokeRefreshTokenFamilyQuery, time.Now(), familyID)
+	return err
+}
+
+func insertRefreshToken(ctx context.Context, db sqlx.ExecerContext, token *model.RefreshToken) error {
+	result, err := db.ExecContext(ctx, query.InsertRefreshTokenQuery,
+		token.ID, token.UserID, token.FamilyID, token.TokenHash, token.ExpiresAt, token.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows != 1 {
+		return customErr.ErrRowsAffected
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/federicodosantos/image-smith/internal/model"
+	"github.com/federicodosantos/image-smith/internal/repository/query"
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	"github.com/jmoiron/sqlx"
+)
+
+// IUploadRepository stores the state of resumable uploads. Reads and writes
+// are scoped to the owner like images.
+type IUploadRepository interface {
+	CreateSession(ctx context.Context, session *model.UploadSession) error
+	GetSession(ctx context.Context, id string, ownerID string) (*model.UploadSession, error)
+	AppendChunk(ctx context.Context, session *model.UploadSession, size int64, chunkKey string, expiresAt time.Time) error
+	ListExpiredSessions(ctx context.Context, now time.Time, limit int) ([]model.UploadSession, error)
+	DeleteSession(ctx context.Context, id string, ownerID string) error
+}
+
+type UploadRepository struct {
+	db *sqlx.DB
+}
+
+func NewUploadRepository(db *sqlx.DB) IUploadRepository {
+	return &UploadRepository{db: db}
+}
+
+func (u *UploadRepository) CreateSession(ctx context.Context, session *model.UploadSession) error {
+	result, err := u.db.ExecContext(ctx, query.InsertUploadSessionQuery,
+		session.ID, session.OwnerID, session.Filename, session.Length, session.Offset, session.ChunkKeys,
+		session.ExpiresAt, session.CreatedAt, session.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows != 1 {
+		return customErr.ErrRowsAffected
+	}
+
+	return nil
+}
+
+// GetSession returns ErrUploadNotFound for sessions that expired, even before
+// they are cleaned up.
+func (u *UploadRepository) GetSession(ctx context.Context, id string, ownerID string) (*model.UploadSession, error) {
+	var session model.UploadSession
+
+	err := u.db.GetContext(ctx, &session, query.GetUploadSessionQuery, id, ownerID, time.Now())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, customErr.ErrUploadNotFound
+		}
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// AppendChunk records a chunk of size bytes stored under chunkKey at the
+// session's current offset and extends its expiry. It returns
+// ErrUploadOffsetMismatch when another chunk was recorded in the meantime, or
+// the chunk would end past the upload length.
+func (u *UploadRepository) AppendChunk(ctx context.Context, session *model.UploadSession, size int64, chunkKey string, expiresAt time.Time) error {
+	now := time.Now()
+
+	result, err := u.db.ExecContext(ctx, query.AppendUploadChunkQuery,
+		size, chunkKey, expiresAt, now, session.ID, session.OwnerID, session.Offset)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows != 1 {
+		return customErr.ErrUploadOffsetMismatch
+	}
+
+	session.Offset += size
+	session.ChunkKeys = append(session.ChunkKeys, chunkKey)
+	session.ExpiresAt = expiresAt
+	session.UpdatedAt = now
+
+	return nil
+}
+
+// ListExpiredSessions returns up to limit sessions that expired by now,
+// oldest first.
+func (u *UploadRepository) ListExpiredSessions(ctx context.Context, now time.Time, limit int) ([]model.UploadSession, error) {
+	sessions := []model.UploadSession{}
+
+	if err := u.db.SelectContext(ctx, &sessions, query.ListExpiredUploadSessionsQuery, now, limit); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+func (u *UploadRepository) DeleteSession(ctx context.Context, id string, ownerID string) error {
+	result, err := u.db.ExecContext(ctx, query.DeleteUploadSessionQuery, id, ownerID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows != 1 {
+		return customErr.ErrUploadNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/federicodosantos/image-smith/internal/model"
+	"github.com/federicodosantos/image-smith/internal/repository/query"
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	"github.com/jmoiron/sqlx"
+)
+
+// IWatermarkRepository stores each account's default watermark settings.
+type IWatermarkRepository interface {
+	GetSettings(ctx context.Context, ownerID string) (*model.WatermarkSettings, error)
+	SaveSettings(ctx context.Context, settings *model.WatermarkSettings) error
+	DeleteSettings(ctx context.Context, ownerID string) error
+}
+
+type WatermarkRepository struct {
+	db *sqlx.DB
+}
+
+func NewWatermarkRepository(db *sqlx.DB) IWatermarkRepository {
+	return &WatermarkRepository{db: db}
+}
+
+func (w *WatermarkRepository) GetSettings(ctx context.Context, ownerID string) (*model.WatermarkSettings, error) {
+	var settings model.WatermarkSettings
+
+	err := w.db.GetContext(ctx, &settings, query.GetWatermarkSettingsQuery, ownerID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, customErr.ErrWatermarkNotFound
+		}
+		return nil, err
+	}
+
+	return &settings, nil
+}
+
+// SaveSettings creates or replaces the settings of settings.OwnerID.
+func (w *WatermarkRepository) SaveSettings(ctx context.Context, settings *model.WatermarkSettings) error {
+	_, err := w.db.ExecContext(ctx, query.UpsertWatermarkSettingsQuery,
+		settings.OwnerID, settings.ImageID, settings.Text, settings.Color, settings.FontSize, settings.Gravity,
+		settings.Margin, settings.Opacity, settings.Scale, settings.Tile, settings.CreatedAt, settings.UpdatedAt)
+
+	return err
+}
+
+func (w *WatermarkRepository) DeleteSettings(ctx context.Context, ownerID string) error {
+	result, err := w.db.ExecContext(ctx, query.DeleteWatermarkSettingsQuery, ownerID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows != 1 {
+		return customErr.ErrWatermarkNotFound
+	}
+
+	return nil
+}
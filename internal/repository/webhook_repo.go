@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/federicodosantos/image-smith/internal/model"
+	"github.com/federicodosantos/image-smith/internal/repository/query"
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	"github.com/jmoiron/sqlx"
+)
+
+// MaxListedDeliveries caps how much of the delivery log ListDeliveries returns.
+const MaxListedDeliveries = 100
+
+// IWebhookRepository stores webhooks and their delivery log. Reads for
+// clients are owner-scoped; the claim and update are used by the dispatcher.
+type IWebhookRepository interface {
+	CreateWebhook(ctx context.Context, webhook *model.Webhook) error
+	GetWebhookById(ctx context.Context, id string, ownerID string) (*model.Webhook, error)
+	ListWebhooks(ctx context.Context, ownerID string) ([]model.Webhook, error)
+	ListWebhooksForEvent(ctx context.Context, ownerID string, event string) ([]model.Webhook, error)
+	DeleteWebhook(ctx context.Context, id string, ownerID string) error
+	CreateDelivery(ctx context.Context, delivery *model.WebhookDelivery) error
+	GetDeliveryById(ctx context.Context, id string, webhookID string, ownerID string) (*model.WebhookDelivery, error)
+	ListDeliveries(ctx context.Context, webhookID string, ownerID string) ([]model.WebhookDelivery, error)
+	ClaimDelivery(ctx context.Context, lease time.Duration) (*model.WebhookDelivery, error)
+	FailAbandonedDeliveries(ctx context.Context, lastError string) error
+	UpdateDelivery(ctx context.Context, delivery *model.WebhookDelivery) error
+}
+
+type WebhookRepository struct {
+	db *sqlx.DB
+}
+
+func NewWebhookRepository(db *sqlx.DB) IWebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+func (w *WebhookRepository) CreateWebhook(ctx context.Context, webhook *model.Webhook) error {
+	return w.exec(ctx, customErr.ErrRowsAffected, query.InsertWebhookQuery,
+		webhook.ID, webhook.OwnerID, webhook.URL, webhook.Secret, webhook.Events, webhook.CreatedAt, webhook.UpdatedAt)
+}
+
+func (w *WebhookRepository) GetWebhookById(ctx context.Context, id string, ownerID string) (*model.Webhook, error) {
+	var webhook model.Webhook
+
+	err := w.db.GetContext(ctx, &webhook, query.GetWebhookByIdQuery, id, ownerID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, customErr.ErrWebhookNotFound
+		}
+		return nil, err
+	}
+
+	return &webhook, nil
+}
+
+func (w *WebhookRepository) ListWebhooks(ctx context.Context, ownerID string) ([]model.Webhook, error) {
+	webhooks := []model.Webhook{}
+
+	if err := w.db.SelectContext(ctx, &webhooks, query.ListWebhooksQuery, ownerID); err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+func (w *WebhookRepository) ListWebhooksForEvent(ctx context.Context, ownerID string, event string) ([]model.Webhook, error) {
+	webhooks := []model.Webhook{}
+
+	if err := w.db.SelectContext(ctx, &webhooks, query.ListWebhooksForEventQuery, ownerID, event); err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+func (w *WebhookRepository) DeleteWebhook(ctx context.Context, id string, ownerID string) error {
+	return w.exec(ctx, customErr.ErrWebhookNotFound, query.DeleteWebhookQuery, id, ownerID)
+}
+
+func (w *WebhookRepository) CreateDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	return w.exec(ctx, customErr.ErrRowsAffected, query.InsertWebhookDeliveryQuery,
+		delivery.ID, delivery.WebhookID, delivery.OwnerID, delivery.Event, delivery.Payload, delivery.Status,
+		delivery.Attempts, delivery.MaxAttempts, delivery.NextAttemptAt, delivery.CreatedAt, delivery.UpdatedAt)
+}
+
+func (w *WebhookRepository) GetDeliveryById(ctx context.Context, id string, webhookID string, ownerID string) (*model.WebhookDelivery, error) {
+	var delivery model.WebhookDelivery
+
+	err := w.db.GetContext(ctx, &delivery, query.GetWebhookDeliveryByIdQuery, id, webhookID, ownerID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, customErr.ErrWebhookDeliveryNotFound
+		}
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+// ListDeliveries returns the most recent deliveries of a webhook, newest
+// first.
+func (w *WebhookRepository) ListDeliveries(ctx context.Context, webhookID string, ownerID string) ([]model.WebhookDelivery, error) {
+	deliveries := []model.WebhookDelivery{}
+
+	err := w.db.SelectContext(ctx, &deliveries, query.ListWebhookDeliveriesQuery, webhookID, ownerID, MaxListedDeliveries)
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// ClaimDelivery marks the oldest due delivery as sending for lease and
+// returns it, or returns nil when nothing is due.
+func (w *WebhookRepository) ClaimDelivery(ctx context.Context, lease time.Duration) (*model.WebhookDelivery, error) {
+	var delivery model.WebhookDelivery
+	now := time.Now()
+
+	err := w.db.GetContext(ctx, &delivery, query.ClaimWebhookDeliveryQuery, now.Add(lease), now)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+// FailAbandonedDeliveries fails the deliveries whose lease expired on their
+// last attempt with lastError. ClaimDelivery never claims those again.
+func (w *WebhookRepository) FailAbandonedDeliveries(ctx context.Context, lastError string) error {
+	_, err := w.db.ExecContext(ctx, query.FailAbandonedWebhookDeliveriesQuery, lastError, time.Now())
+	return err
+}
+
+// UpdateDelivery records the outcome of the claimed attempt of a delivery. It
+// returns ErrDeliveryLeaseLost if the lease expired and the delivery was
+// claimed again.
+func (w *WebhookRepository) UpdateDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	return w.exec(ctx, customErr.ErrDeliveryLeaseLost, query.UpdateWebhookDeliveryQuery,
+		delivery.Status, delivery.NextAttemptAt, delivery.ResponseStatus, delivery.LastError,
+		delivery.DeliveredAt, time.Now(), delivery.ID, delivery.Attempts)
+}
+
+// exec runs a statement that must affect exactly one row and returns
+// notFound otherwise.
+func (w *WebhookRepository) exec(ctx context.Context, notFound error, statement string, args ...any) error {
+	result, err := w.db.ExecContext(ctx, statement, args...)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows != 1 {
+		return notFound
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+
+	"github.com/federicodosantos/image-smith/pkg/imaging"
+)
+
+// PerceptualHashMaxSize is the largest blob kept in memory to compute its
+// perceptual hash. Larger images are stored without one.
+const PerceptualHashMaxSize = 32 << 20
+
+type inspectResult struct {
+	info  imaging.Info
+	phash *imaging.PerceptualHash
+	err   error
+}
+
+// blobReader passes an image through to storage while counting its bytes,
+// hashing it and inspecting its header, frames and metadata, so the blob is
+// only streamed once. Images within the decoding limits are then decoded to
+// compute their perceptual hash, as long as they are small enough to be kept
+// in memory on the way.
+type blobReader struct {
+	src    io.Reader
+	size   int64
+	hash   hash.Hash
+	pipe   *io.PipeWriter
+	result chan inspectResult
+}
+
+func newBlobReader(r io.Reader) *blobReader {
+	pr, pw := io.Pipe()
+
+	b := &blobReader{
+		src:    r,
+		hash:   sha256.New(),
+		pipe:   pw,
+		result: make(chan inspectResult, 1),
+	}
+
+	go func() {
+		data := &cappedBuffer{limit: PerceptualHashMaxSize}
+		info, err := imaging.Inspect(io.TeeReader(pr, data))
+		// keep draining so Read never blocks when inspection stops early
+		io.Copy(io.Discard, pr)
+
+		result := inspectResult{info: info, err: err}
+		if err == nil && !data.overflowed && imaging.CurrentLimits().Check(info) == nil {
+			// a hash is a nicety, so an image failing to decode is stored without
+			if img, _, err := imaging.Decode(&data.Buffer); err == nil {
+				phash := imaging.HashImage(img)
+				result.phash = &phash
+			}
+		}
+		b.result <- result
+	}()
+
+	return b
+}
+
+func (b *blobReader) Read(p []byte) (int, error) {
+	n, err := b.src.Read(p)
+	if n > 0 {
+		b.size += int64(n)
+		b.hash.Write(p[:n])
+		b.pipe.Write(p[:n])
+	}
+
+	return n, err
+}
+
+// finish ends the stream and returns what inspecting it found and the hex
+// SHA-256 of everything read.
+func (b *blobReader) finish() (inspectResult, string) {
+	b.pipe.Close()
+	result := <-b.result
+
+	return result, hex.EncodeToString(b.hash.Sum(nil))
+}
+
+// cappedBuffer keeps what is written to it until it grows past limit, then
+// drops it and ignores the rest.
+type cappedBuffer struct {
+	bytes.Buffer
+	limit      int
+	overflowed bool
+}
+
+func (c *cappedBuffer) Write(p []byte) (int, error) {
+	if c.overflowed {
+		return len(p), nil
+	}
+
+	if c.Len()+len(p) > c.limit {
+		c.overflowed = true
+		c.Buffer = bytes.Buffer{}
+		return len(p), nil
+	}
+
+	return c.Buffer.Write(p)
+}
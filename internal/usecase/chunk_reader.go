@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"context"
+	"io"
+
+	"github.com/federicodosantos/image-smith/pkg/storage"
+)
+
+// chunkReader reads the chunks of a resumable upload back to back as one
+// stream, opening each object only when the previous one is exhausted.
+type chunkReader struct {
+	ctx     context.Context
+	storage storage.Storage
+	keys    []string
+	current io.ReadCloser
+}
+
+func newChunkReader(ctx context.Context, storage storage.Storage, keys []string) *chunkReader {
+	return &chunkReader{ctx: ctx, storage: storage, keys: keys}
+}
+
+func (c *chunkReader) Read(p []byte) (int, error) {
+	for {
+		if c.current == nil {
+			if len(c.keys) == 0 {
+				return 0, io.EOF
+			}
+
+			chunk, err := c.storage.Get(c.ctx, c.keys[0])
+			if err != nil {
+				return 0, err
+			}
+			c.current, c.keys = chunk, c.keys[1:]
+		}
+
+		n, err := c.current.Read(p)
+		if err == io.EOF {
+			_ = c.current.Close()
+			c.current = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+
+		return n, err
+	}
+}
+
+// Close releases the chunk being read, if any.
+func (c *chunkReader) Close() error {
+	if c.current == nil {
+		return nil
+	}
+
+	err := c.current.Close()
+	c.current = nil
+
+	return err
+}
@@ -0,0 +1,1135 @@
+package usecase
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/federicodosantos/image-smith/internal/dto"
+	"github.com/federicodosantos/image-smith/internal/model"
+	"github.com/federicodosantos/image-smith/internal/repository"
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	"github.com/federicodosantos/image-smith/pkg/imaging"
+	"github.com/federicodosantos/image-smith/pkg/storage"
+	"github.com/federicodosantos/image-smith/pkg/validator"
+	"github.com/google/uuid"
+)
+
+// DefaultListLimit is the page size of List when the request does not set one.
+const DefaultListLimit = 20
+
+// DefaultSimilarDistance is the largest Hamming distance Similar accepts when
+// the request does not set one; around 10 of 64 bits still means the same
+// picture after resizing, recompression or small edits.
+const DefaultSimilarDistance = 10
+
+const (
+	// UploadURLExpiry is how long the URL returned by CreateUploadURL accepts
+	// the upload.
+	UploadURLExpiry = 15 * time.Minute
+	// PendingImageTTL is how long a pending image waits to be completed before
+	// it is reaped. It outlasts the URL so an upload started late can finish.
+	PendingImageTTL = time.Hour
+)
+
+// ImageURLExpiry is how long the image URLs in responses and events are
+// valid. Objects are only served through signed URLs.
+const ImageURLExpiry = time.Hour
+
+// expiredPendingBatch is how many expired pending images
+// ExpirePendingImages removes per query.
+const expiredPendingBatch = 100
+
+// pendingUploadPrefix is where presigned uploads land. Complete copies the
+// content to the image's own key, which no URL ever grants writes to.
+const pendingUploadPrefix = "pending/"
+
+type IImageUsecase interface {
+	Upload(ctx context.Context, userID string, req *dto.ImageUploadRequest) (*dto.ImageUploadResponse, error)
+	CreateUploadURL(ctx context.Context, userID string, req *dto.ImageUploadURLRequest) (*dto.ImageUploadURLResponse, error)
+	Complete(ctx context.Context, userID string, imageID string) (*dto.ImageUploadResponse, error)
+	Assemble(ctx context.Context, userID string, filename string, parts []string) (*dto.ImageUploadResponse, error)
+	ExpirePendingImages(ctx context.Context) (int, error)
+	Transform(ctx context.Context, userID string, imageID string, req *dto.ImageTransformRequest) (*dto.ImageTransformResponse, error)
+	Get(ctx context.Context, userID string, imageID string) (*dto.ImageResponse, error)
+	List(ctx context.Context, userID string, req *dto.ImageListRequest) (*dto.ImageListResponse, error)
+	Similar(ctx context.Context, userID string, imageID string, req *dto.SimilarImagesRequest) ([]*dto.SimilarImageResponse, error)
+	Render(ctx context.Context, userID string, imageID string, req *dto.ImageRenderRequest) (*dto.ImageRenderResponse, error)
+	RenderPublic(ctx context.Context, imageID string, req *dto.ImageRenderRequest) (*dto.ImageRenderResponse, error)
+	SetFocalPoint(ctx context.Context, userID string, imageID string, req *dto.FocalPointRequest) (*dto.ImageResponse, error)
+	ClearFocalPoint(ctx context.Context, userID string, imageID string) (*dto.ImageResponse, error)
+	Delete(ctx context.Context, userID string, imageID string) error
+}
+
+type ImageUsecase struct {
+	imageRepo     repository.IImageRepository
+	watermarkRepo repository.IWatermarkRepository
+	storage       storage.Storage
+	events        EventPublisher
+}
+
+func NewImageUsecase(imageRepo repository.IImageRepository, watermarkRepo repository.IWatermarkRepository,
+	storage storage.Storage, events EventPublisher) IImageUsecase {
+	return &ImageUsecase{imageRepo: imageRepo, watermarkRepo: watermarkRepo, storage: storage, events: events}
+}
+
+func (i *ImageUsecase) Upload(ctx context.Context, userID string, req *dto.ImageUploadRequest) (*dto.ImageUploadResponse, error) {
+	if req == nil || req.File == nil {
+		return nil, customErr.ErrImageFileRequired
+	}
+
+	// sniff the real format instead of trusting the client header
+	reader := bufio.NewReaderSize(req.File, 512)
+	head, err := reader.Peek(512)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	format, ok := imaging.Sniff(head)
+	if !ok {
+		return nil, customErr.ErrUnsupportedFileFormat
+	}
+
+	image := newImage(userID, format.MimeType(), format.Extension())
+	image.OriginalFilename = req.Filename
+
+	stored, duplicate, err := i.store(ctx, image, reader, i.imageRepo.CreateImage)
+	if err != nil {
+		return nil, err
+	}
+
+	imageURL, err := i.imageURL(ctx, stored)
+	if err != nil {
+		return nil, err
+	}
+
+	if !duplicate {
+		i.publishImage(ctx, userID, model.EventImageUploaded, stored)
+	}
+
+	return &dto.ImageUploadResponse{
+		ID:        stored.ID,
+		ImageURL:  imageURL,
+		Duplicate: duplicate,
+	}, nil
+}
+
+// CreateUploadURL records a pending image and returns a URL the client PUTs
+// its content to, straight to storage. The image stays hidden until Complete.
+func (i *ImageUsecase) CreateUploadURL(ctx context.Context, userID string, req *dto.ImageUploadURLRequest) (*dto.ImageUploadURLResponse, error) {
+	format, err := imaging.ParseFormat(req.ContentType)
+	if err != nil {
+		return nil, validator.Errors{"content_type": {"must be a supported image type"}}
+	}
+
+	image := newImage(userID, format.MimeType(), format.Extension())
+	image.OriginalFilename = req.Filename
+	image.Size = req.Size
+	expiresAt := image.CreatedAt.Add(PendingImageTTL)
+	image.UploadExpiresAt = &expiresAt
+
+	uploadURL, err := i.storage.SignedUploadURL(ctx, pendingUploadKey(image.ID), image.Size, UploadURLExpiry)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := i.imageRepo.CreatePendingImage(ctx, image); err != nil {
+		return nil, err
+	}
+
+	return &dto.ImageUploadURLResponse{
+		ID:          image.ID,
+		UploadURL:   uploadURL,
+		Method:      "PUT",
+		ContentType: image.MimeType,
+		ExpiresAt:   image.CreatedAt.Add(UploadURLExpiry),
+	}, nil
+}
+
+// Complete verifies the content uploaded for a pending image and stores it
+// under the image's key, making it ready as if it had been sent to Upload.
+// Until the declared size arrived it returns ErrUploadIncomplete; content that
+// is larger, not an image, of another type than declared or over the decoding
+// limits is rejected and may be uploaded again while the URL is valid. When
+// the owner already has the same content, the pending image is dropped and the
+// existing one returned.
+func (i *ImageUsecase) Complete(ctx context.Context, userID string, imageID string) (*dto.ImageUploadResponse, error) {
+	image, err := i.imageRepo.GetPendingImage(ctx, imageID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	uploadKey := pendingUploadKey(image.ID)
+
+	object, err := i.storage.Stat(ctx, uploadKey)
+	if errors.Is(err, customErr.ErrObjectNotFound) {
+		return nil, fmt.Errorf("%w: nothing was uploaded yet", customErr.ErrUploadIncomplete)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case object.Size < image.Size:
+		return nil, fmt.Errorf("%w: %d of %d bytes received", customErr.ErrUploadIncomplete, object.Size, image.Size)
+	case object.Size > image.Size:
+		return nil, fmt.Errorf("%w: %d bytes were uploaded, %d declared", customErr.ErrPayloadTooLarge, object.Size, image.Size)
+	}
+
+	content, err := i.storage.Get(ctx, uploadKey)
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+
+	reader := bufio.NewReaderSize(content, 512)
+	head, err := reader.Peek(512)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	format, ok := imaging.Sniff(head)
+	if !ok {
+		return nil, customErr.ErrUnsupportedFileFormat
+	}
+	if format.MimeType() != image.MimeType {
+		return nil, fmt.Errorf("%w: the content is %s, %s was declared", customErr.ErrUnsupportedFileFormat, format.MimeType(), image.MimeType)
+	}
+
+	stored, duplicate, err := i.store(ctx, image, reader, i.imageRepo.CompleteImage)
+	if err != nil {
+		return nil, err
+	}
+
+	if duplicate {
+		if err := i.imageRepo.DeletePendingImage(ctx, image.ID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	// an upload landing here later is never read again and is swept by
+	// ExpirePendingImages
+	_ = i.storage.Delete(ctx, uploadKey)
+
+	imageURL, err := i.imageURL(ctx, stored)
+	if err != nil {
+		return nil, err
+	}
+
+	if !duplicate {
+		i.publishImage(ctx, userID, model.EventImageUploaded, stored)
+	}
+
+	return &dto.ImageUploadResponse{
+		ID:        stored.ID,
+		ImageURL:  imageURL,
+		Duplicate: duplicate,
+	}, nil
+}
+
+// Assemble stores the objects at parts, joined in order, as an image like
+// Upload. The storage backend joins them under the image's key; the result is
+// then read back to be hashed and checked against the decoding limits, and
+// removed when it is rejected or a duplicate. The parts are left in place.
+func (i *ImageUsecase) Assemble(ctx context.Context, userID string, filename string, parts []string) (*dto.ImageUploadResponse, error) {
+	head := make([]byte, 512)
+	chunks := newChunkReader(ctx, i.storage, parts)
+	n, err := io.ReadFull(chunks, head)
+	chunks.Close()
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+
+	format, ok := imaging.Sniff(head[:n])
+	if !ok {
+		return nil, customErr.ErrUnsupportedFileFormat
+	}
+
+	image := newImage(userID, format.MimeType(), format.Extension())
+	image.OriginalFilename = filename
+
+	if err := i.storage.Compose(ctx, image.StorageKey, parts, image.MimeType); err != nil {
+		return nil, err
+	}
+
+	content, err := i.storage.Get(ctx, image.StorageKey)
+	if err != nil {
+		_ = i.storage.Delete(ctx, image.StorageKey)
+		return nil, err
+	}
+
+	blob := newBlobReader(content)
+	_, err = io.Copy(io.Discard, blob)
+	content.Close()
+	inspected, checksum := blob.finish()
+	if err != nil {
+		_ = i.storage.Delete(ctx, image.StorageKey)
+		return nil, err
+	}
+
+	stored, duplicate, err := i.keep(ctx, image, blob.size, inspected, checksum, i.imageRepo.CreateImage)
+	if err != nil {
+		return nil, err
+	}
+
+	imageURL, err := i.imageURL(ctx, stored)
+	if err != nil {
+		return nil, err
+	}
+
+	if !duplicate {
+		i.publishImage(ctx, userID, model.EventImageUploaded, stored)
+	}
+
+	return &dto.ImageUploadResponse{
+		ID:        stored.ID,
+		ImageURL:  imageURL,
+		Duplicate: duplicate,
+	}, nil
+}
+
+// ExpirePendingImages removes the pending images that were not completed in
+// time, with whatever was uploaded for them, and returns how many were
+// removed. An image completed meanwhile is left alone. Uploads older than any
+// pending image can be, such as ones sent again after Complete, are removed
+// too.
+func (i *ImageUsecase) ExpirePendingImages(ctx context.Context) (int, error) {
+	removed := 0
+	now := time.Now()
+
+	for {
+		images, err := i.imageRepo.ListExpiredPendingImages(ctx, now, expiredPendingBatch)
+		if err != nil {
+			return removed, err
+		}
+
+		for _, image := range images {
+			// the record goes first, so content that was just completed is
+			// never deleted from under it
+			err := i.imageRepo.DeletePendingImage(ctx, image.ID, image.OwnerID)
+			if errors.Is(err, customErr.ErrImageNotFound) {
+				continue
+			}
+			if err != nil {
+				return removed, err
+			}
+
+			if err := i.storage.Delete(ctx, pendingUploadKey(image.ID)); err != nil {
+				return removed, err
+			}
+			removed++
+		}
+
+		if len(images) < expiredPendingBatch {
+			break
+		}
+	}
+
+	uploads, err := i.storage.List(ctx, pendingUploadPrefix)
+	if err != nil {
+		return removed, err
+	}
+
+	// an upload is made while its URL is valid, so one older than the pending
+	// TTL belongs to no pending image
+	for _, upload := range uploads {
+		if upload.ModTime.After(now.Add(-PendingImageTTL)) {
+			continue
+		}
+		if err := i.storage.Delete(ctx, upload.Key); err != nil {
+			return removed, err
+		}
+	}
+
+	return removed, nil
+}
+
+func (i *ImageUsecase) Transform(ctx context.Context, userID string, imageID string, req *dto.ImageTransformRequest) (*dto.ImageTransformResponse, error) {
+	opts, err := transformOptions(req)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := i.imageRepo.GetImageById(ctx, imageID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Resize != nil {
+		opts.Resize.Focal = focalPoint(source, opts.Crop)
+	}
+
+	if req.Watermark != nil {
+		if opts.Watermark, err = i.watermark(ctx, userID, req.Watermark); err != nil {
+			return nil, err
+		}
+	}
+
+	object, err := i.storage.Get(ctx, source.StorageKey)
+	if err != nil {
+		return nil, err
+	}
+	defer object.Close()
+
+	var buf bytes.Buffer
+	format, err := imaging.Transform(object, &buf, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	derived := newImage(userID, format.MimeType(), format.Extension())
+	derived.ParentID = &source.ID
+	derived.OriginalFilename = source.OriginalFilename
+
+	stored, duplicate, err := i.store(ctx, derived, &buf, i.imageRepo.CreateImage)
+	if err != nil {
+		return nil, err
+	}
+
+	imageURL, err := i.imageURL(ctx, stored)
+	if err != nil {
+		return nil, err
+	}
+
+	if !duplicate {
+		i.publishImage(ctx, userID, model.EventImageTransformed, stored)
+	}
+
+	return &dto.ImageTransformResponse{
+		ID:        stored.ID,
+		ParentID:  stored.ParentID,
+		ImageURL:  imageURL,
+		Duplicate: duplicate,
+	}, nil
+}
+
+func (i *ImageUsecase) Get(ctx context.Context, userID string, imageID string) (*dto.ImageResponse, error) {
+	image, err := i.imageRepo.GetImageById(ctx, imageID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.imageResponse(ctx, image)
+}
+
+// List returns one page of the caller's images. Filter values that are
+// syntactically valid but cannot be applied are reported as validator.Errors.
+func (i *ImageUsecase) List(ctx context.Context, userID string, req *dto.ImageListRequest) (*dto.ImageListResponse, error) {
+	filter, err := imageFilter(userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := i.imageRepo.ListImages(ctx, filter)
+	if err != nil {
+		if errors.Is(err, customErr.ErrInvalidCursor) {
+			return nil, validator.Errors{"cursor": {err.Error()}}
+		}
+		return nil, err
+	}
+
+	images := make([]*dto.ImageResponse, 0, len(page.Images))
+	for idx := range page.Images {
+		image, err := i.imageResponse(ctx, &page.Images[idx])
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, image)
+	}
+
+	return &dto.ImageListResponse{
+		Images:     images,
+		Limit:      filter.Limit,
+		HasMore:    page.HasMore,
+		NextCursor: page.NextCursor,
+	}, nil
+}
+
+// Similar returns the caller's other images that look like imageID, closest
+// first, comparing their perceptual hashes. It returns ErrImageNotHashed for
+// images stored before hashing was introduced.
+func (i *ImageUsecase) Similar(ctx context.Context, userID string, imageID string, req *dto.SimilarImagesRequest) ([]*dto.SimilarImageResponse, error) {
+	image, err := i.imageRepo.GetImageById(ctx, imageID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if image.PHash == nil {
+		return nil, customErr.ErrImageNotHashed
+	}
+
+	maxDistance, limit := DefaultSimilarDistance, DefaultListLimit
+	if req != nil {
+		if req.MaxDistance != nil {
+			maxDistance = *req.MaxDistance
+		}
+		limit = cmp.Or(req.Limit, limit)
+	}
+
+	found, err := i.imageRepo.FindSimilarImages(ctx, userID, image.ID, *image.PHash, maxDistance, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	similar := make([]*dto.SimilarImageResponse, 0, len(found))
+	for idx := range found {
+		image, err := i.imageResponse(ctx, &found[idx].Image)
+		if err != nil {
+			return nil, err
+		}
+		similar = append(similar, &dto.SimilarImageResponse{
+			ImageResponse: image,
+			Distance:      found[idx].Distance,
+		})
+	}
+
+	return similar, nil
+}
+
+// Render returns the image transformed by the request's parameters. Variants
+// are cached in storage under a key derived from the source checksum and the
+// canonical parameters, so each distinct rendering is only computed once.
+func (i *ImageUsecase) Render(ctx context.Context, userID string, imageID string, req *dto.ImageRenderRequest) (*dto.ImageRenderResponse, error) {
+	if req == nil {
+		req = &dto.ImageRenderRequest{}
+	}
+
+	image, err := i.imageRepo.GetImageById(ctx, imageID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	opts, err := renderOptions(image, req)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := renderHash(image, opts)
+	etag := `"` + hash + `"`
+	if etagMatches(req.IfNoneMatch, etag) {
+		return &dto.ImageRenderResponse{ETag: etag, NotModified: true}, nil
+	}
+
+	key := renderPrefix(image) + hash + opts.Format.Extension()
+	rendered := &dto.ImageRenderResponse{ContentType: opts.Format.MimeType(), ETag: etag}
+
+	if info, err := i.storage.Stat(ctx, key); err == nil {
+		if body, err := i.storage.Get(ctx, key); err == nil {
+			rendered.Body = body
+			rendered.Size = info.Size
+			return rendered, nil
+		}
+	} else if !errors.Is(err, customErr.ErrObjectNotFound) {
+		return nil, err
+	}
+
+	object, err := i.storage.Get(ctx, image.StorageKey)
+	if err != nil {
+		return nil, err
+	}
+	defer object.Close()
+
+	var buf bytes.Buffer
+	if _, err := imaging.Transform(object, &buf, opts); err != nil {
+		return nil, err
+	}
+
+	// caching is best effort: a failed write only costs a re-render later
+	if err := i.storage.Put(ctx, key, bytes.NewReader(buf.Bytes()), rendered.ContentType); err != nil {
+		log.Printf("cannot cache rendered image %s: %s", key, err.Error())
+	}
+
+	rendered.Body = io.NopCloser(&buf)
+	rendered.Size = int64(buf.Len())
+
+	return rendered, nil
+}
+
+// RenderPublic renders an image for a signed URL, which names the image but
+// not its owner; the caller must have verified the signature.
+func (i *ImageUsecase) RenderPublic(ctx context.Context, imageID string, req *dto.ImageRenderRequest) (*dto.ImageRenderResponse, error) {
+	ownerID, err := i.imageRepo.GetImageOwner(ctx, imageID)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.Render(ctx, ownerID, imageID, req)
+}
+
+// SetFocalPoint stores the point that cover resizes of the image keep in
+// frame; req is expected to be validated by the caller. Renders cached before
+// the change are not reused because the focal point is part of their key.
+func (i *ImageUsecase) SetFocalPoint(ctx context.Context, userID string, imageID string, req *dto.FocalPointRequest) (*dto.ImageResponse, error) {
+	return i.updateFocalPoint(ctx, userID, imageID, req.X, req.Y)
+}
+
+// ClearFocalPoint removes the image's focal point so cover resizes fall back
+// to centering.
+func (i *ImageUsecase) ClearFocalPoint(ctx context.Context, userID string, imageID string) (*dto.ImageResponse, error) {
+	return i.updateFocalPoint(ctx, userID, imageID, nil, nil)
+}
+
+func (i *ImageUsecase) updateFocalPoint(ctx context.Context, userID string, imageID string, x, y *float64) (*dto.ImageResponse, error) {
+	if err := i.imageRepo.UpdateFocalPoint(ctx, imageID, userID, x, y); err != nil {
+		return nil, err
+	}
+
+	image, err := i.imageRepo.GetImageById(ctx, imageID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.imageResponse(ctx, image)
+}
+
+// Delete removes the image record and its cached renders, and its blob when no
+// other image shares it. Images derived from it are kept and lose their parent
+// reference.
+func (i *ImageUsecase) Delete(ctx context.Context, userID string, imageID string) error {
+	image, err := i.imageRepo.GetImageById(ctx, imageID, userID)
+	if err != nil {
+		return err
+	}
+
+	lastReference, err := i.imageRepo.DeleteImage(ctx, image.ID, userID)
+	if err != nil {
+		return err
+	}
+
+	renders, err := i.storage.List(ctx, renderPrefix(image))
+	if err != nil {
+		return err
+	}
+
+	for _, render := range renders {
+		if err := i.storage.Delete(ctx, render.Key); err != nil {
+			return err
+		}
+	}
+
+	if lastReference {
+		if err := i.storage.Delete(ctx, image.StorageKey); err != nil {
+			return err
+		}
+	}
+
+	i.publishImage(ctx, userID, model.EventImageDeleted, image)
+
+	return nil
+}
+
+// publish notifies webhooks without failing the operation that already
+// succeeded; a lost notification is logged instead.
+func (i *ImageUsecase) publish(ctx context.Context, userID string, event string, data any) {
+	if err := i.events.Publish(ctx, userID, event, data); err != nil {
+		log.Printf("cannot publish %s for user %s: %s", event, userID, err.Error())
+	}
+}
+
+// publishImage publishes event with image as its data, logging what cannot be
+// sent like publish.
+func (i *ImageUsecase) publishImage(ctx context.Context, userID string, event string, image *model.Image) {
+	data, err := i.imageResponse(ctx, image)
+	if err != nil {
+		log.Printf("cannot publish %s for user %s: %s", event, userID, err.Error())
+		return
+	}
+
+	i.publish(ctx, userID, event, data)
+}
+
+// imageURL returns a URL of the image's content valid for ImageURLExpiry.
+func (i *ImageUsecase) imageURL(ctx context.Context, image *model.Image) (string, error) {
+	return i.storage.SignedURL(ctx, image.StorageKey, ImageURLExpiry)
+}
+
+func (i *ImageUsecase) imageResponse(ctx context.Context, image *model.Image) (*dto.ImageResponse, error) {
+	imageURL, err := i.imageURL(ctx, image)
+	if err != nil {
+		return nil, err
+	}
+
+	var focal *dto.FocalPoint
+	if image.FocalX != nil && image.FocalY != nil {
+		focal = &dto.FocalPoint{X: *image.FocalX, Y: *image.FocalY}
+	}
+
+	var exif *dto.EXIF
+	if image.CameraMake != nil || image.CameraModel != nil || image.TakenAt != nil || image.Orientation > 1 {
+		exif = &dto.EXIF{
+			CameraMake:  image.CameraMake,
+			CameraModel: image.CameraModel,
+			TakenAt:     image.TakenAt,
+			Orientation: image.Orientation,
+		}
+	}
+
+	var phash *string
+	if image.PHash != nil {
+		encoded := imaging.PerceptualHash(*image.PHash).String()
+		phash = &encoded
+	}
+
+	return &dto.ImageResponse{
+		ID:               image.ID,
+		ParentID:         image.ParentID,
+		OriginalFilename: image.OriginalFilename,
+		MimeType:         image.MimeType,
+		Width:            image.Width,
+		Height:           image.Height,
+		Size:             image.Size,
+		Checksum:         image.Checksum,
+		PerceptualHash:   phash,
+		FocalPoint:       focal,
+		EXIF:             exif,
+		ImageURL:         imageURL,
+		CreatedAt:        image.CreatedAt,
+		UpdatedAt:        image.UpdatedAt,
+	}, nil
+}
+
+// store writes the blob for image, hashing it on the way, and saves its
+// metadata, including the EXIF fields, with record. The blob is removed again
+// when the image cannot be decoded, exceeds the decoding limits or record
+// fails. When the owner already has an image with the same content, that
+// record is returned with duplicate set and nothing new is kept; a blob the
+// owner already stored with the same content is shared rather than kept twice.
+func (i *ImageUsecase) store(ctx context.Context, image *model.Image, r io.Reader,
+	record func(ctx context.Context, image *model.Image) error) (stored *model.Image, duplicate bool, err error) {
+	uploadedKey := image.StorageKey
+
+	blob := newBlobReader(r)
+	err = i.storage.Put(ctx, uploadedKey, blob, image.MimeType)
+	inspected, checksum := blob.finish()
+	if err != nil {
+		return nil, false, err
+	}
+
+	return i.keep(ctx, image, blob.size, inspected, checksum, record)
+}
+
+// keep does what store does once the blob of image, size bytes read as
+// inspected and checksum, is under the image's key.
+func (i *ImageUsecase) keep(ctx context.Context, image *model.Image, size int64, inspected inspectResult, checksum string,
+	record func(ctx context.Context, image *model.Image) error) (stored *model.Image, duplicate bool, err error) {
+	uploadedKey := image.StorageKey
+
+	if err := describe(image, size, inspected, checksum); err != nil {
+		_ = i.storage.Delete(ctx, uploadedKey)
+		return nil, false, err
+	}
+
+	err = record(ctx, image)
+	if errors.Is(err, customErr.ErrDuplicateImage) {
+		_ = i.storage.Delete(ctx, uploadedKey)
+
+		existing, err := i.imageRepo.GetImageByChecksum(ctx, image.OwnerID, checksum)
+		if err != nil {
+			return nil, false, err
+		}
+		return existing, true, nil
+	}
+	if err != nil {
+		_ = i.storage.Delete(ctx, uploadedKey)
+		return nil, false, err
+	}
+
+	// the record points at a blob that was already stored
+	if image.StorageKey != uploadedKey {
+		_ = i.storage.Delete(ctx, uploadedKey)
+	}
+
+	return image, false, nil
+}
+
+// describe fills in image from what was found reading its content of size
+// bytes. Content that is not an image, or that could not be decoded later
+// because it exceeds the decoding limits, is rejected before it is recorded.
+func describe(image *model.Image, size int64, inspected inspectResult, checksum string) error {
+	info := inspected.info
+	if inspected.err != nil {
+		return customErr.ErrUnsupportedFileFormat
+	}
+
+	if err := imaging.CurrentLimits().Check(info); err != nil {
+		return err
+	}
+
+	exif := info.Metadata.ParseEXIF()
+
+	image.Size = size
+	image.Width = info.Config.Width
+	image.Height = info.Config.Height
+	if exif.Orientation.Transposed() {
+		image.Width, image.Height = image.Height, image.Width
+	}
+	image.Checksum = checksum
+	if inspected.phash != nil {
+		phash := int64(*inspected.phash)
+		image.PHash = &phash
+	}
+	image.Orientation = int(exif.Orientation)
+	image.TakenAt = exif.TakenAt
+	if exif.Make != "" {
+		image.CameraMake = &exif.Make
+	}
+	if exif.Model != "" {
+		image.CameraModel = &exif.Model
+	}
+
+	return nil
+}
+
+// pendingUploadKey is where the presigned upload of a pending image lands.
+func pendingUploadKey(imageID string) string {
+	return pendingUploadPrefix + imageID
+}
+
+func newImage(userID string, mimeType string, ext string) *model.Image {
+	id := uuid.NewString()
+	now := time.Now()
+
+	return &model.Image{
+		ID:         id,
+		OwnerID:    userID,
+		StorageKey: fmt.Sprintf("%s/%s%s", userID, id, ext),
+		MimeType:   mimeType,
+		Status:     model.ImageStatusReady,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+}
+
+func transformOptions(req *dto.ImageTransformRequest) (imaging.Options, error) {
+	var opts imaging.Options
+	if req == nil {
+		return opts, customErr.ErrInvalidTransformation
+	}
+
+	if req.Crop != nil {
+		opts.Crop = &imaging.Crop{X: req.Crop.X, Y: req.Crop.Y, Width: req.Crop.Width, Height: req.Crop.Height}
+	}
+
+	if r := req.Resize; r != nil {
+		resize, err := resizeOptions(r.Width, r.Height, r.Fit, r.Gravity, r.Background, r.Filter)
+		if err != nil {
+			return opts, err
+		}
+		opts.Resize = resize
+	}
+
+	if req.Rotate != nil {
+		background, err := imaging.ParseColor(req.Rotate.Background)
+		if err != nil {
+			return opts, err
+		}
+		opts.Rotate = &imaging.Rotate{Angle: req.Rotate.Angle, Background: background}
+	}
+
+	if req.Sharpen != nil {
+		opts.Sharpen = &imaging.Sharpen{Sigma: req.Sharpen.Sigma, Amount: req.Sharpen.Amount, Threshold: req.Sharpen.Threshold}
+	}
+
+	opts.Frame = req.Frame
+	opts.Metadata = metadataPolicy(req.StripMetadata, req.KeepICC)
+	opts.Flip = req.Flip
+	opts.Flop = req.Flop
+	opts.Blur = req.Blur
+	opts.Adjust = imaging.Adjust{
+		Brightness: req.Brightness,
+		Contrast:   req.Contrast,
+		Saturation: req.Saturation,
+		Gamma:      req.Gamma,
+		Grayscale:  req.Grayscale,
+	}
+
+	if req.Convert != "" {
+		format, err := imaging.ParseFormat(req.Convert)
+		if err != nil {
+			return opts, err
+		}
+		opts.Format = format
+	}
+
+	if e := req.Encode; e != nil {
+		opts.Encode = imaging.EncodeOptions{
+			Quality:     e.Quality,
+			Progressive: e.Progressive,
+			Compression: imaging.Compression(strings.ToLower(e.Compression)),
+			Colors:      e.Colors,
+		}
+	}
+
+	// the watermark is resolved once the source is loaded, so a request with
+	// nothing else is not empty
+	if req.Watermark != nil && opts == (imaging.Options{}) {
+		return opts, nil
+	}
+
+	return opts, opts.Validate()
+}
+
+// metadataPolicy maps the request flags to the policy. Derived images are
+// stripped unless the request opts out, and asking for either explicitly
+// counts as a transformation so a plain stripped copy can be made.
+func metadataPolicy(strip *bool, keepICC bool) imaging.MetadataPolicy {
+	switch {
+	case strip != nil && !*strip:
+		return imaging.MetadataKeep
+	case keepICC:
+		return imaging.MetadataKeepICC
+	case strip != nil:
+		return imaging.MetadataStrip
+	default:
+		return ""
+	}
+}
+
+// resizeOptions parses the named resize parameters shared by transformations
+// and renders.
+func resizeOptions(width, height int, fit, gravity, background, filter string) (*imaging.Resize, error) {
+	resize := &imaging.Resize{Width: width, Height: height}
+
+	var err error
+	if resize.Fit, err = imaging.ParseFit(fit); err != nil {
+		return nil, err
+	}
+	if resize.Filter, err = imaging.ParseFilter(filter); err != nil {
+		return nil, err
+	}
+	if resize.Background, err = imaging.ParseColor(background); err != nil {
+		return nil, err
+	}
+
+	// an explicit gravity overrides the image's focal point, so it is only
+	// set when given
+	if gravity != "" {
+		if resize.Gravity, err = imaging.ParseGravity(gravity); err != nil {
+			return nil, err
+		}
+	}
+
+	return resize, nil
+}
+
+// focalPoint returns the image's focal point relative to the area left by
+// crop, or nil when there is none or the crop excludes it.
+func focalPoint(image *model.Image, crop *imaging.Crop) *imaging.Focal {
+	if image.FocalX == nil || image.FocalY == nil {
+		return nil
+	}
+
+	focal := &imaging.Focal{X: *image.FocalX, Y: *image.FocalY}
+	if crop == nil {
+		return focal
+	}
+
+	// cropping needs the stored dimensions to translate the point
+	if image.Width == 0 || image.Height == 0 || crop.Width == 0 || crop.Height == 0 {
+		return nil
+	}
+
+	focal.X = (focal.X*float64(image.Width) - float64(crop.X)) / float64(crop.Width)
+	focal.Y = (focal.Y*float64(image.Height) - float64(crop.Y)) / float64(crop.Height)
+	if focal.X < 0 || focal.X > 1 || focal.Y < 0 || focal.Y > 1 {
+		return nil
+	}
+
+	return focal
+}
+
+// watermark merges req with the account's saved watermark and loads the
+// overlay it names: one of the user's images or rendered text.
+func (i *ImageUsecase) watermark(ctx context.Context, userID string, req *dto.WatermarkRequest) (*imaging.Watermark, error) {
+	saved, err := i.watermarkRepo.GetSettings(ctx, userID)
+	if errors.Is(err, customErr.ErrWatermarkNotFound) {
+		saved = &model.WatermarkSettings{}
+	} else if err != nil {
+		return nil, err
+	}
+
+	settings := mergeWatermark(saved, req)
+
+	if settings.Gravity == "" {
+		settings.Gravity = string(DefaultWatermarkGravity)
+	}
+	gravity, err := imaging.ParseGravity(settings.Gravity)
+	if err != nil {
+		return nil, err
+	}
+
+	mark := &imaging.Watermark{
+		Gravity: gravity,
+		Margin:  settings.Margin,
+		Opacity: cmp.Or(settings.Opacity, DefaultWatermarkOpacity),
+		Scale:   settings.Scale,
+		Tile:    settings.Tile,
+	}
+
+	switch {
+	case settings.ImageID != nil:
+		overlay, err := i.imageRepo.GetImageById(ctx, *settings.ImageID, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		object, err := i.storage.Get(ctx, overlay.StorageKey)
+		if err != nil {
+			return nil, err
+		}
+		defer object.Close()
+
+		if mark.Image, _, err = imaging.Decode(object); err != nil {
+			return nil, err
+		}
+	case settings.Text != "":
+		color, err := imaging.ParseColor(cmp.Or(settings.Color, DefaultWatermarkColor))
+		if err != nil {
+			return nil, err
+		}
+
+		if mark.Image, err = imaging.Text(settings.Text, cmp.Or(settings.FontSize, DefaultWatermarkFontSize), color); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: watermark needs an image_id or text", customErr.ErrInvalidTransformation)
+	}
+
+	return mark, nil
+}
+
+func imageFilter(userID string, req *dto.ImageListRequest) (repository.ImageFilter, error) {
+	filter := repository.ImageFilter{OwnerID: userID, Limit: DefaultListLimit}
+	if req == nil {
+		return filter, nil
+	}
+
+	errs := validator.Errors{}
+
+	if req.Format != "" {
+		format, err := imaging.ParseFormat(req.Format)
+		if err != nil {
+			errs["format"] = append(errs["format"], "is not a supported format")
+		}
+		filter.MimeType = format.MimeType()
+	}
+
+	if req.MinSize != nil && req.MaxSize != nil && *req.MinSize > *req.MaxSize {
+		errs["max_size"] = append(errs["max_size"], "must not be less than min_size")
+	}
+
+	if req.CreatedAfter != nil && req.CreatedBefore != nil && !req.CreatedAfter.Before(*req.CreatedBefore) {
+		errs["created_before"] = append(errs["created_before"], "must be after created_after")
+	}
+
+	if req.OriginalsOnly && req.ParentID != "" {
+		errs["parent_id"] = append(errs["parent_id"], "cannot be combined with originals_only")
+	}
+
+	if len(errs) > 0 {
+		return filter, errs
+	}
+
+	if req.Limit > 0 {
+		filter.Limit = req.Limit
+	}
+
+	filter.MinSize = req.MinSize
+	filter.MaxSize = req.MaxSize
+	filter.CreatedAfter = req.CreatedAfter
+	filter.CreatedBefore = req.CreatedBefore
+	filter.OriginalsOnly = req.OriginalsOnly
+	filter.ParentID = req.ParentID
+	filter.SortBy = req.Sort
+	filter.Order = req.Order
+	filter.Cursor = req.Cursor
+
+	return filter, nil
+}
+
+// renderOptions resolves the render request against the source image. The
+// output format is always set so that an empty request re-encodes the source
+// and the options are fully canonical.
+func renderOptions(image *model.Image, req *dto.ImageRenderRequest) (imaging.Options, error) {
+	var opts imaging.Options
+
+	format, err := imaging.ParseFormat(image.MimeType)
+	if req.Format != "" {
+		format, err = imaging.ParseFormat(req.Format)
+	} else if err == nil && !format.Encodable() {
+		// read only formats are served as PNG, like transformations
+		format = imaging.FormatPNG
+	}
+	if err != nil {
+		return opts, err
+	}
+	opts.Format = format
+
+	if req.Width != 0 || req.Height != 0 {
+		resize, err := resizeOptions(req.Width, req.Height, req.Fit, req.Gravity, req.Background, req.Filter)
+		if err != nil {
+			return opts, err
+		}
+		resize.Focal = focalPoint(image, nil)
+		opts.Resize = resize
+	}
+
+	if req.Quality < 0 || req.Quality > 100 {
+		return opts, fmt.Errorf("%w: quality must be between 1 and 100", customErr.ErrInvalidTransformation)
+	}
+
+	// quality only changes JPEG output, so drop it elsewhere to share variants
+	if format == imaging.FormatJPEG {
+		opts.Encode.Quality = req.Quality
+	}
+
+	return opts, opts.Validate()
+}
+
+// renderHash identifies a variant by its source content and canonical
+// parameters.
+func renderHash(image *model.Image, opts imaging.Options) string {
+	canonical := fmt.Sprintf("fmt=%s&q=%d", opts.Format, opts.Encode.Quality)
+	if r := opts.Resize; r != nil {
+		resize := fmt.Sprintf("w=%d&h=%d&fit=%s&g=%s&bg=%02x%02x%02x%02x&filter=%s&",
+			r.Width, r.Height, r.Fit, r.Gravity, r.Background.R, r.Background.G, r.Background.B, r.Background.A, r.Filter)
+		if r.Focal != nil {
+			resize += fmt.Sprintf("focal=%g,%g&", r.Focal.X, r.Focal.Y)
+		}
+		canonical = resize + canonical
+	}
+
+	// renders cached before EXIF orientation was applied kept the stored
+	// rotation, so rotated photos get keys of their own
+	if image.Orientation > 1 {
+		canonical = fmt.Sprintf("orientation=%d&", image.Orientation) + canonical
+	}
+
+	sum := sha256.Sum256([]byte(image.ID + "\n" + image.Checksum + "\n" + canonical))
+	return hex.EncodeToString(sum[:])
+}
+
+func renderPrefix(image *model.Image) string {
+	return fmt.Sprintf("%s/renders/%s/", image.OwnerID, image.ID)
+}
+
+// etagMatches reports whether an If-None-Match header value lists etag.
+func etagMatches(header string, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/federicodosantos/image-smith/internal/dto"
+	"github.com/federicodosantos/image-smith/internal/model"
+	"github.com/federicodosantos/image-smith/internal/repository"
+	"github.com/google/uuid"
+)
+
+type IJobUsecase interface {
+	EnqueueTransform(ctx context.Context, userID string, imageID string, req *dto.ImageTransformRequest) (*dto.JobResponse, error)
+	Get(ctx context.Context, userID string, jobID string) (*dto.JobResponse, error)
+}
+
+type JobUsecase struct {
+	jobRepo     repository.IJobRepository
+	imageRepo   repository.IImageRepository
+	maxAttempts int
+}
+
+func NewJobUsecase(jobRepo repository.IJobRepository, imageRepo repository.IImageRepository, maxAttempts int) IJobUsecase {
+	return &JobUsecase{jobRepo: jobRepo, imageRepo: imageRepo, maxAttempts: maxAttempts}
+}
+
+// EnqueueTransform validates the request and the source image up front, so
+// the caller gets the same 400/404 as the synchronous transform, and queues
+// the work for the worker pool.
+func (j *JobUsecase) EnqueueTransform(ctx context.Context, userID string, imageID string, req *dto.ImageTransformRequest) (*dto.JobResponse, error) {
+	if _, err := transformOptions(req); err != nil {
+		return nil, err
+	}
+
+	source, err := j.imageRepo.GetImageById(ctx, imageID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	job := &model.Job{
+		ID:          uuid.NewString(),
+		OwnerID:     userID,
+		ImageID:     source.ID,
+		Type:        model.JobTypeTransform,
+		Payload:     payload,
+		Status:      model.JobStatusQueued,
+		MaxAttempts: j.maxAttempts,
+		RunAt:       now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := j.jobRepo.CreateJob(ctx, job); err != nil {
+		return nil, err
+	}
+
+	return jobResponse(job), nil
+}
+
+func (j *JobUsecase) Get(ctx context.Context, userID string, jobID string) (*dto.JobResponse, error) {
+	job, err := j.jobRepo.GetJobById(ctx, jobID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return jobResponse(job), nil
+}
+
+func jobResponse(job *model.Job) *dto.JobResponse {
+	return &dto.JobResponse{
+		ID:            job.ID,
+		Type:          job.Type,
+		Status:        job.Status,
+		ImageID:       job.ImageID,
+		ResultImageID: job.ResultImageID,
+		Attempts:      job.Attempts,
+		MaxAttempts:   job.MaxAttempts,
+		LastError:     job.LastError,
+		RunAt:         job.RunAt,
+		CreatedAt:     job.CreatedAt,
+		UpdatedAt:     job.UpdatedAt,
+	}
+}
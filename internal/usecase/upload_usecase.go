@@ -0,0 +1,219 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/federicodosantos/image-smith/internal/dto"
+	"github.com/federicodosantos/image-smith/internal/model"
+	"github.com/federicodosantos/image-smith/internal/repository"
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	"github.com/federicodosantos/image-smith/pkg/storage"
+	"github.com/google/uuid"
+)
+
+// expiredSessionBatch is how many expired upload sessions ExpireSessions
+// removes per query.
+const expiredSessionBatch = 100
+
+// IUploadUsecase runs resumable uploads: a session is created for the full
+// length, chunks are written at the current offset, and the complete upload
+// is finalized into an image like a regular upload.
+type IUploadUsecase interface {
+	Create(ctx context.Context, userID string, req *dto.UploadCreateRequest) (*dto.UploadSessionResponse, error)
+	Get(ctx context.Context, userID string, uploadID string) (*dto.UploadSessionResponse, error)
+	WriteChunk(ctx context.Context, userID string, uploadID string, offset int64, r io.Reader) (*dto.UploadSessionResponse, error)
+	Finalize(ctx context.Context, userID string, uploadID string) (*dto.ImageUploadResponse, error)
+	ExpireSessions(ctx context.Context) (int, error)
+}
+
+// UploadUsecase keeps each chunk as its own storage object until the upload
+// is finalized. Sessions accept uploads of at most maxLength bytes, zero
+// leaving them unlimited, and expire ttl after their last chunk.
+type UploadUsecase struct {
+	uploadRepo   repository.IUploadRepository
+	imageUsecase IImageUsecase
+	storage      storage.Storage
+	maxLength    int64
+	ttl          time.Duration
+}
+
+func NewUploadUsecase(uploadRepo repository.IUploadRepository, imageUsecase IImageUsecase, storage storage.Storage,
+	maxLength int64, ttl time.Duration) IUploadUsecase {
+	return &UploadUsecase{uploadRepo: uploadRepo, imageUsecase: imageUsecase, storage: storage, maxLength: maxLength, ttl: ttl}
+}
+
+func (u *UploadUsecase) Create(ctx context.Context, userID string, req *dto.UploadCreateRequest) (*dto.UploadSessionResponse, error) {
+	if u.maxLength > 0 && req.Length > u.maxLength {
+		return nil, fmt.Errorf("%w: the limit is %d bytes", customErr.ErrPayloadTooLarge, u.maxLength)
+	}
+
+	now := time.Now()
+	session := &model.UploadSession{
+		ID:        uuid.NewString(),
+		OwnerID:   userID,
+		Filename:  req.Filename,
+		Length:    req.Length,
+		ChunkKeys: []string{},
+		ExpiresAt: now.Add(u.ttl),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := u.uploadRepo.CreateSession(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return uploadSessionResponse(session), nil
+}
+
+func (u *UploadUsecase) Get(ctx context.Context, userID string, uploadID string) (*dto.UploadSessionResponse, error) {
+	session, err := u.uploadRepo.GetSession(ctx, uploadID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return uploadSessionResponse(session), nil
+}
+
+// WriteChunk stores r as the chunk starting at offset, which must be the
+// session's current offset. A chunk that would run past the upload length is
+// rejected with ErrPayloadTooLarge, and one that fails midway is discarded,
+// so the client resumes from the offset reported afterwards.
+func (u *UploadUsecase) WriteChunk(ctx context.Context, userID string, uploadID string, offset int64, r io.Reader) (*dto.UploadSessionResponse, error) {
+	session, err := u.uploadRepo.GetSession(ctx, uploadID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if offset != session.Offset {
+		return nil, fmt.Errorf("%w: the upload is at offset %d", customErr.ErrUploadOffsetMismatch, session.Offset)
+	}
+
+	remaining := session.Length - session.Offset
+	chunk := &countingReader{r: io.LimitReader(r, remaining+1)}
+	key := fmt.Sprintf("%s%020d-%s", uploadPrefix(session), offset, uuid.NewString())
+
+	if err := u.storage.Put(ctx, key, chunk, "application/octet-stream"); err != nil {
+		_ = u.storage.Delete(ctx, key)
+		return nil, err
+	}
+
+	if chunk.n > remaining {
+		_ = u.storage.Delete(ctx, key)
+		return nil, fmt.Errorf("%w: %d bytes remain in the upload", customErr.ErrPayloadTooLarge, remaining)
+	}
+
+	if chunk.n == 0 {
+		_ = u.storage.Delete(ctx, key)
+		return uploadSessionResponse(session), nil
+	}
+
+	if err := u.uploadRepo.AppendChunk(ctx, session, chunk.n, key, time.Now().Add(u.ttl)); err != nil {
+		_ = u.storage.Delete(ctx, key)
+		return nil, err
+	}
+
+	return uploadSessionResponse(session), nil
+}
+
+// Finalize has the storage backend assemble the chunks into an image, checked
+// like any upload, and removes the session. It returns ErrUploadIncomplete
+// until every byte was written.
+func (u *UploadUsecase) Finalize(ctx context.Context, userID string, uploadID string) (*dto.ImageUploadResponse, error) {
+	session, err := u.uploadRepo.GetSession(ctx, uploadID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.Offset != session.Length {
+		return nil, fmt.Errorf("%w: %d of %d bytes received", customErr.ErrUploadIncomplete, session.Offset, session.Length)
+	}
+
+	image, err := u.imageUsecase.Assemble(ctx, userID, session.Filename, session.ChunkKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.remove(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return image, nil
+}
+
+// ExpireSessions removes the sessions that expired and their chunks, and
+// returns how many were removed.
+func (u *UploadUsecase) ExpireSessions(ctx context.Context) (int, error) {
+	removed := 0
+
+	for {
+		sessions, err := u.uploadRepo.ListExpiredSessions(ctx, time.Now(), expiredSessionBatch)
+		if err != nil {
+			return removed, err
+		}
+
+		for _, session := range sessions {
+			if err := u.remove(ctx, &session); err != nil {
+				return removed, err
+			}
+			removed++
+		}
+
+		if len(sessions) < expiredSessionBatch {
+			return removed, nil
+		}
+	}
+}
+
+// remove deletes the chunks of session, including any a failed write left
+// behind, then the session itself. A session already removed by a
+// concurrent call is not an error.
+func (u *UploadUsecase) remove(ctx context.Context, session *model.UploadSession) error {
+	chunks, err := u.storage.List(ctx, uploadPrefix(session))
+	if err != nil {
+		return err
+	}
+
+	for _, chunk := range chunks {
+		if err := u.storage.Delete(ctx, chunk.Key); err != nil {
+			return err
+		}
+	}
+
+	err = u.uploadRepo.DeleteSession(ctx, session.ID, session.OwnerID)
+	if errors.Is(err, customErr.ErrUploadNotFound) {
+		return nil
+	}
+
+	return err
+}
+
+func uploadPrefix(session *model.UploadSession) string {
+	return fmt.Sprintf("%s/uploads/%s/", session.OwnerID, session.ID)
+}
+
+func uploadSessionResponse(session *model.UploadSession) *dto.UploadSessionResponse {
+	return &dto.UploadSessionResponse{
+		ID:        session.ID,
+		Length:    session.Length,
+		Offset:    session.Offset,
+		ExpiresAt: session.ExpiresAt,
+	}
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+
+	return n, err
+}
@@ -2,6 +2,11 @@ package usecase
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"time"
 
 	"github.com/federicodosantos/image-smith/internal/dto"
@@ -17,15 +22,25 @@ import (
 type IUserUsecase interface {
 	Register(ctx context.Context, req *dto.UserRegisterRequest) (*dto.UserRegisterResponse, error)
 	Login(ctx context.Context, req *dto.UserLoginRequest) (*dto.UserLoginResponse, error)
+	Refresh(ctx context.Context, req *dto.RefreshTokenRequest) (*dto.UserLoginResponse, error)
+	Logout(ctx context.Context, req *dto.RefreshTokenRequest) error
 }
 
 type UserUsecase struct {
-	userRepo repository.IUserRepository
-	jwt      jwt.JWTItf
+	userRepo         repository.IUserRepository
+	refreshTokenRepo repository.IRefreshTokenRepository
+	jwt              jwt.JWTItf
+	refreshTokenTTL  time.Duration
 }
 
-func NewUserUsecase(userRepo repository.IUserRepository, jwt jwt.JWTItf) IUserUsecase {
-	return &UserUsecase{userRepo: userRepo, jwt: jwt}
+func NewUserUsecase(userRepo repository.IUserRepository, refreshTokenRepo repository.IRefreshTokenRepository,
+	jwt jwt.JWTItf, refreshTokenTTL time.Duration) IUserUsecase {
+	return &UserUsecase{
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		jwt:              jwt,
+		refreshTokenTTL:  refreshTokenTTL,
+	}
 }
 
 func (u *UserUsecase) Register(ctx context.Context, req *dto.UserRegisterRequest) (*dto.UserRegisterResponse, error) {
@@ -73,12 +88,111 @@ func (u *UserUsecase) Login(ctx context.Context, req *dto.UserLoginRequest) (*dt
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		return nil, customErr.ErrIncorrectPassword
+	}
+
+	// every login starts a new refresh token family
+	refreshToken, err := u.issueRefreshToken(ctx, user.ID, uuid.NewString(), "")
+	if err != nil {
+		return nil, err
+	}
+
+	return u.tokenPair(user.ID, refreshToken)
+}
+
+// Refresh exchanges a refresh token for a new access/refresh pair. Presenting
+// a token that was already rotated means it leaked, so the whole family is
+// revoked and its holder has to log in again.
+func (u *UserUsecase) Refresh(ctx context.Context, req *dto.RefreshTokenRequest) (*dto.UserLoginResponse, error) {
+	if req == nil || req.RefreshToken == "" {
+		return nil, customErr.ErrInvalidRefreshToken
+	}
+
+	current, err := u.refreshTokenRepo.GetRefreshTokenByHash(ctx, hashToken(req.RefreshToken))
+	if err != nil {
 		return nil, err
 	}
 
-	token, err := u.jwt.CreateToken(user.ID)
+	if current.RevokedAt != nil {
+		if err := u.refreshTokenRepo.RevokeTokenFamily(ctx, current.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, customErr.ErrRefreshTokenReused
+	}
+
+	if time.Now().After(current.ExpiresAt) {
+		return nil, customErr.ErrInvalidRefreshToken
+	}
+
+	refreshToken, err := u.issueRefreshToken(ctx, current.UserID, current.FamilyID, current.ID)
+	if err != nil {
+		if errors.Is(err, customErr.ErrRefreshTokenReused) {
+			_ = u.refreshTokenRepo.RevokeTokenFamily(ctx, current.FamilyID)
+		}
+		return nil, err
+	}
+
+	return u.tokenPair(current.UserID, refreshToken)
+}
+
+// Logout revokes the family of the given refresh token, if any.
+func (u *UserUsecase) Logout(ctx context.Context, req *dto.RefreshTokenRequest) error {
+	if req == nil || req.RefreshToken == "" {
+		return nil
+	}
+
+	current, err := u.refreshTokenRepo.GetRefreshTokenByHash(ctx, hashToken(req.RefreshToken))
+	if err != nil {
+		if errors.Is(err, customErr.ErrInvalidRefreshToken) {
+			return nil
+		}
+		return err
+	}
+
+	return u.refreshTokenRepo.RevokeTokenFamily(ctx, current.FamilyID)
+}
+
+// issueRefreshToken stores a new refresh token in familyID, rotating previousID
+// out when it is set, and returns the plain token.
+func (u *UserUsecase) issueRefreshToken(ctx context.Context, userID string, familyID string, previousID string) (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	plain := base64.RawURLEncoding.EncodeToString(raw)
+
+	now := time.Now()
+	token := &model.RefreshToken{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(plain),
+		ExpiresAt: now.Add(u.refreshTokenTTL),
+		CreatedAt: now,
+	}
+
+	if previousID == "" {
+		err := u.refreshTokenRepo.CreateRefreshToken(ctx, token)
+		return plain, err
+	}
+
+	err := u.refreshTokenRepo.RotateRefreshToken(ctx, previousID, token)
+	return plain, err
+}
+
+func (u *UserUsecase) tokenPair(userID string, refreshToken string) (*dto.UserLoginResponse, error) {
+	token, err := u.jwt.CreateToken(userID)
+	if err != nil {
+		return nil, err
+	}
 
 	return &dto.UserLoginResponse{
-		JWTToken: token,
+		JWTToken:     token,
+		RefreshToken: refreshToken,
 	}, nil
 }
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
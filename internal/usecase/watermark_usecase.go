@@ -0,0 +1,142 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/federicodosantos/image-smith/internal/dto"
+	"github.com/federicodosantos/image-smith/internal/model"
+	"github.com/federicodosantos/image-smith/internal/repository"
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	"github.com/federicodosantos/image-smith/pkg/imaging"
+	"github.com/federicodosantos/image-smith/pkg/validator"
+)
+
+// Built-in watermark defaults for fields neither the transform nor the saved
+// settings set.
+const (
+	DefaultWatermarkGravity  = imaging.GravitySouthEast
+	DefaultWatermarkOpacity  = 0.5
+	DefaultWatermarkFontSize = 48
+	DefaultWatermarkColor    = "#ffffff"
+)
+
+type IWatermarkUsecase interface {
+	Get(ctx context.Context, userID string) (*dto.WatermarkResponse, error)
+	Save(ctx context.Context, userID string, req *dto.WatermarkRequest) (*dto.WatermarkResponse, error)
+	Delete(ctx context.Context, userID string) error
+}
+
+type WatermarkUsecase struct {
+	watermarkRepo repository.IWatermarkRepository
+	imageRepo     repository.IImageRepository
+}
+
+func NewWatermarkUsecase(watermarkRepo repository.IWatermarkRepository, imageRepo repository.IImageRepository) IWatermarkUsecase {
+	return &WatermarkUsecase{watermarkRepo: watermarkRepo, imageRepo: imageRepo}
+}
+
+func (wu *WatermarkUsecase) Get(ctx context.Context, userID string) (*dto.WatermarkResponse, error) {
+	settings, err := wu.watermarkRepo.GetSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return watermarkResponse(settings), nil
+}
+
+// Save replaces the account defaults. Unlike a transform, the request must
+// name its overlay itself and the image has to belong to the user.
+func (wu *WatermarkUsecase) Save(ctx context.Context, userID string, req *dto.WatermarkRequest) (*dto.WatermarkResponse, error) {
+	errs := validator.Errors{}
+
+	if (req.ImageID == "") == (req.Text == "") {
+		errs["image_id"] = append(errs["image_id"], "exactly one of image_id or text is required")
+	}
+
+	if req.Color != "" {
+		if _, err := imaging.ParseColor(req.Color); err != nil {
+			errs["color"] = append(errs["color"], "must be a hex color")
+		}
+	}
+
+	if req.ImageID != "" {
+		if _, err := wu.imageRepo.GetImageById(ctx, req.ImageID, userID); err != nil {
+			if !errors.Is(err, customErr.ErrImageNotFound) {
+				return nil, err
+			}
+			errs["image_id"] = append(errs["image_id"], "does not exist")
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	now := time.Now()
+	settings := mergeWatermark(&model.WatermarkSettings{OwnerID: userID, CreatedAt: now}, req)
+	settings.UpdatedAt = now
+
+	if err := wu.watermarkRepo.SaveSettings(ctx, &settings); err != nil {
+		return nil, err
+	}
+
+	return watermarkResponse(&settings), nil
+}
+
+func (wu *WatermarkUsecase) Delete(ctx context.Context, userID string) error {
+	return wu.watermarkRepo.DeleteSettings(ctx, userID)
+}
+
+// mergeWatermark overrides the saved settings with every field req sets.
+// Naming an overlay replaces both the saved image and text.
+func mergeWatermark(settings *model.WatermarkSettings, req *dto.WatermarkRequest) model.WatermarkSettings {
+	merged := *settings
+
+	if req.ImageID != "" || req.Text != "" {
+		merged.ImageID, merged.Text = nil, req.Text
+		if req.ImageID != "" {
+			merged.ImageID = &req.ImageID
+		}
+	}
+
+	if req.Color != "" {
+		merged.Color = req.Color
+	}
+	if req.FontSize != 0 {
+		merged.FontSize = req.FontSize
+	}
+	if req.Gravity != "" {
+		merged.Gravity = req.Gravity
+	}
+	if req.Margin != 0 {
+		merged.Margin = req.Margin
+	}
+	if req.Opacity != 0 {
+		merged.Opacity = req.Opacity
+	}
+	if req.Scale != 0 {
+		merged.Scale = req.Scale
+	}
+	if req.Tile != nil {
+		merged.Tile = *req.Tile
+	}
+
+	return merged
+}
+
+func watermarkResponse(settings *model.WatermarkSettings) *dto.WatermarkResponse {
+	return &dto.WatermarkResponse{
+		ImageID:   settings.ImageID,
+		Text:      settings.Text,
+		Color:     settings.Color,
+		FontSize:  settings.FontSize,
+		Gravity:   settings.Gravity,
+		Margin:    settings.Margin,
+		Opacity:   settings.Opacity,
+		Scale:     settings.Scale,
+		Tile:      settings.Tile,
+		UpdatedAt: settings.UpdatedAt,
+	}
+}
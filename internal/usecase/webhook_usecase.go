@@ -0,0 +1,204 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/federicodosantos/image-smith/internal/dto"
+	"github.com/federicodosantos/image-smith/internal/model"
+	"github.com/federicodosantos/image-smith/internal/repository"
+	"github.com/federicodosantos/image-smith/pkg/validator"
+	"github.com/google/uuid"
+)
+
+// webhookEvents lists the events a webhook may subscribe to.
+var webhookEvents = []string{
+	model.EventImageUploaded,
+	model.EventImageTransformed,
+	model.EventImageDeleted,
+	model.EventJobFailed,
+}
+
+// EventPublisher notifies an account's webhooks that event happened. data is
+// sent as the event's data field.
+type EventPublisher interface {
+	Publish(ctx context.Context, ownerID string, event string, data any) error
+}
+
+type IWebhookUsecase interface {
+	EventPublisher
+	Create(ctx context.Context, userID string, req *dto.WebhookRequest) (*dto.WebhookResponse, error)
+	List(ctx context.Context, userID string) ([]*dto.WebhookResponse, error)
+	Delete(ctx context.Context, userID string, webhookID string) error
+	ListDeliveries(ctx context.Context, userID string, webhookID string) ([]*dto.WebhookDeliveryResponse, error)
+	Replay(ctx context.Context, userID string, webhookID string, deliveryID string) (*dto.WebhookDeliveryResponse, error)
+}
+
+type WebhookUsecase struct {
+	webhookRepo repository.IWebhookRepository
+	maxAttempts int
+}
+
+func NewWebhookUsecase(webhookRepo repository.IWebhookRepository, maxAttempts int) IWebhookUsecase {
+	return &WebhookUsecase{webhookRepo: webhookRepo, maxAttempts: maxAttempts}
+}
+
+func (wu *WebhookUsecase) Create(ctx context.Context, userID string, req *dto.WebhookRequest) (*dto.WebhookResponse, error) {
+	for _, event := range req.Events {
+		if !slices.Contains(webhookEvents, event) {
+			return nil, validator.Errors{"events": {fmt.Sprintf("unknown event %q", event)}}
+		}
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	webhook := &model.Webhook{
+		ID:        uuid.NewString(),
+		OwnerID:   userID,
+		URL:       req.URL,
+		Secret:    "whsec_" + hex.EncodeToString(secret),
+		Events:    slices.Compact(slices.Sorted(slices.Values(req.Events))),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := wu.webhookRepo.CreateWebhook(ctx, webhook); err != nil {
+		return nil, err
+	}
+
+	response := webhookResponse(webhook)
+	response.Secret = webhook.Secret
+
+	return response, nil
+}
+
+func (wu *WebhookUsecase) List(ctx context.Context, userID string) ([]*dto.WebhookResponse, error) {
+	webhooks, err := wu.webhookRepo.ListWebhooks(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.WebhookResponse, 0, len(webhooks))
+	for i := range webhooks {
+		responses = append(responses, webhookResponse(&webhooks[i]))
+	}
+
+	return responses, nil
+}
+
+func (wu *WebhookUsecase) Delete(ctx context.Context, userID string, webhookID string) error {
+	return wu.webhookRepo.DeleteWebhook(ctx, webhookID, userID)
+}
+
+func (wu *WebhookUsecase) ListDeliveries(ctx context.Context, userID string, webhookID string) ([]*dto.WebhookDeliveryResponse, error) {
+	if _, err := wu.webhookRepo.GetWebhookById(ctx, webhookID, userID); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := wu.webhookRepo.ListDeliveries(ctx, webhookID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.WebhookDeliveryResponse, 0, len(deliveries))
+	for i := range deliveries {
+		responses = append(responses, deliveryResponse(&deliveries[i]))
+	}
+
+	return responses, nil
+}
+
+// Replay queues a new delivery with the exact payload of an earlier one. The
+// original entry stays in the log untouched.
+func (wu *WebhookUsecase) Replay(ctx context.Context, userID string, webhookID string, deliveryID string) (*dto.WebhookDeliveryResponse, error) {
+	original, err := wu.webhookRepo.GetDeliveryById(ctx, deliveryID, webhookID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery := wu.newDelivery(original.WebhookID, userID, original.Event, original.Payload)
+	if err := wu.webhookRepo.CreateDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+
+	return deliveryResponse(delivery), nil
+}
+
+// Publish queues a delivery of event for every webhook of ownerID subscribed
+// to it. The dispatcher sends them in the background.
+func (wu *WebhookUsecase) Publish(ctx context.Context, ownerID string, event string, data any) error {
+	webhooks, err := wu.webhookRepo.ListWebhooksForEvent(ctx, ownerID, event)
+	if err != nil || len(webhooks) == 0 {
+		return err
+	}
+
+	payload, err := json.Marshal(dto.WebhookEvent{
+		ID:        uuid.NewString(),
+		Type:      event,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, webhook := range webhooks {
+		if err := wu.webhookRepo.CreateDelivery(ctx, wu.newDelivery(webhook.ID, ownerID, event, payload)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (wu *WebhookUsecase) newDelivery(webhookID string, ownerID string, event string, payload []byte) *model.WebhookDelivery {
+	now := time.Now()
+
+	return &model.WebhookDelivery{
+		ID:            uuid.NewString(),
+		WebhookID:     webhookID,
+		OwnerID:       ownerID,
+		Event:         event,
+		Payload:       payload,
+		Status:        model.DeliveryStatusPending,
+		MaxAttempts:   wu.maxAttempts,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+}
+
+func webhookResponse(webhook *model.Webhook) *dto.WebhookResponse {
+	return &dto.WebhookResponse{
+		ID:        webhook.ID,
+		URL:       webhook.URL,
+		Events:    webhook.Events,
+		CreatedAt: webhook.CreatedAt,
+	}
+}
+
+func deliveryResponse(delivery *model.WebhookDelivery) *dto.WebhookDeliveryResponse {
+	return &dto.WebhookDeliveryResponse{
+		ID:             delivery.ID,
+		WebhookID:      delivery.WebhookID,
+		Event:          delivery.Event,
+		Payload:        delivery.Payload,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		MaxAttempts:    delivery.MaxAttempts,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		ResponseStatus: delivery.ResponseStatus,
+		LastError:      delivery.LastError,
+		DeliveredAt:    delivery.DeliveredAt,
+		CreatedAt:      delivery.CreatedAt,
+	}
+}
@@ -0,0 +1,141 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/federicodosantos/image-smith/internal/model"
+	"github.com/federicodosantos/image-smith/internal/repository"
+	"github.com/federicodosantos/image-smith/pkg/webhook"
+)
+
+// Dispatcher claims queued webhook deliveries and POSTs them to their
+// endpoints, retrying failures with the same backoff as jobs.
+type Dispatcher struct {
+	webhookRepo repository.IWebhookRepository
+	client      *http.Client
+	config      Config
+}
+
+func NewDispatcher(webhookRepo repository.IWebhookRepository, client *http.Client, config Config) *Dispatcher {
+	return &Dispatcher{webhookRepo: webhookRepo, client: client, config: config}
+}
+
+// Run starts the senders and blocks until ctx is cancelled and every
+// delivery in progress has finished.
+func (d *Dispatcher) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	for range d.config.Concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for ctx.Err() == nil {
+				processed, err := d.RunOnce(ctx)
+				if err != nil {
+					log.Printf("cannot claim webhook delivery: %s", err.Error())
+				}
+
+				if processed {
+					continue
+				}
+
+				select {
+				case <-ctx.Done():
+				case <-time.After(d.config.PollInterval):
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+// RunOnce fails the deliveries abandoned on their last attempt, then claims
+// and sends a single delivery. It reports whether a delivery was due.
+func (d *Dispatcher) RunOnce(ctx context.Context) (bool, error) {
+	if err := d.webhookRepo.FailAbandonedDeliveries(ctx, errAbandoned.Error()); err != nil {
+		return false, err
+	}
+
+	delivery, err := d.webhookRepo.ClaimDelivery(ctx, d.config.Lease)
+	if err != nil || delivery == nil {
+		return false, err
+	}
+
+	ctx = context.WithoutCancel(ctx)
+
+	status, err := d.send(ctx, delivery)
+	now := time.Now()
+
+	delivery.ResponseStatus = nil
+	if status != 0 {
+		delivery.ResponseStatus = &status
+	}
+
+	switch {
+	case err == nil:
+		delivery.Status = model.DeliveryStatusSucceeded
+		delivery.LastError = nil
+		delivery.DeliveredAt = &now
+	case delivery.Attempts >= delivery.MaxAttempts:
+		lastError := err.Error()
+		delivery.Status = model.DeliveryStatusFailed
+		delivery.LastError = &lastError
+	default:
+		lastError := err.Error()
+		delivery.Status = model.DeliveryStatusPending
+		delivery.LastError = &lastError
+		delivery.NextAttemptAt = now.Add(Backoff(delivery.Attempts))
+	}
+
+	if err := d.webhookRepo.UpdateDelivery(ctx, delivery); err != nil {
+		log.Printf("cannot record webhook delivery %s: %s", delivery.ID, err.Error())
+	}
+
+	return true, nil
+}
+
+// send POSTs the delivery and returns the response status. Anything but a
+// 2xx answer is an error.
+func (d *Dispatcher) send(ctx context.Context, delivery *model.WebhookDelivery) (int, error) {
+	hook, err := d.webhookRepo.GetWebhookById(ctx, delivery.WebhookID, delivery.OwnerID)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ImageSmith-Webhook/1.0")
+	req.Header.Set(webhook.EventHeader, delivery.Event)
+	req.Header.Set(webhook.DeliveryHeader, delivery.ID)
+	req.Header.Set(webhook.TimestampHeader, fmt.Sprint(now.Unix()))
+	req.Header.Set(webhook.SignatureHeader, webhook.Sign(hook.Secret, now, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// drain a little of the body so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,51 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/federicodosantos/image-smith/internal/usecase"
+)
+
+// Reaper periodically removes uploads that were abandoned: resumable upload
+// sessions that expired before they were finalized, and pending images whose
+// presigned upload was never completed.
+type Reaper struct {
+	uploadUsecase usecase.IUploadUsecase
+	imageUsecase  usecase.IImageUsecase
+	interval      time.Duration
+}
+
+func NewReaper(uploadUsecase usecase.IUploadUsecase, imageUsecase usecase.IImageUsecase, interval time.Duration) *Reaper {
+	return &Reaper{uploadUsecase: uploadUsecase, imageUsecase: imageUsecase, interval: interval}
+}
+
+// Run reaps every interval until ctx is cancelled.
+func (r *Reaper) Run(ctx context.Context) {
+	for ctx.Err() == nil {
+		removed, err := r.RunOnce(ctx)
+		if err != nil {
+			log.Printf("cannot reap expired uploads: %s", err.Error())
+		}
+		if removed > 0 {
+			log.Printf("reaped %d expired uploads", removed)
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(r.interval):
+		}
+	}
+}
+
+// RunOnce removes what expired so far and reports how many uploads were
+// removed. A failure of one kind of upload does not keep the other from being
+// reaped.
+func (r *Reaper) RunOnce(ctx context.Context) (int, error) {
+	sessions, sessionsErr := r.uploadUsecase.ExpireSessions(ctx)
+	pending, pendingErr := r.imageUsecase.ExpirePendingImages(ctx)
+
+	return sessions + pending, errors.Join(sessionsErr, pendingErr)
+}
@@ -0,0 +1,189 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/federicodosantos/image-smith/internal/dto"
+	"github.com/federicodosantos/image-smith/internal/model"
+	"github.com/federicodosantos/image-smith/internal/repository"
+	"github.com/federicodosantos/image-smith/internal/usecase"
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+)
+
+const (
+	baseBackoff = 10 * time.Second
+	maxBackoff  = 10 * time.Minute
+)
+
+// errAbandoned is the failure of jobs and webhook deliveries whose last
+// attempt never finished.
+var errAbandoned = errors.New("the worker stopped during the last attempt")
+
+type Config struct {
+	// Concurrency is the number of jobs processed at the same time.
+	Concurrency int
+	// PollInterval is how long an idle worker waits before claiming again.
+	PollInterval time.Duration
+	// Lease is how long a claimed job stays locked before another worker may
+	// assume its worker died and claim it again.
+	Lease time.Duration
+}
+
+// Pool claims jobs from Postgres and runs them on the image usecase.
+type Pool struct {
+	jobRepo      repository.IJobRepository
+	imageUsecase usecase.IImageUsecase
+	events       usecase.EventPublisher
+	config       Config
+}
+
+func NewPool(jobRepo repository.IJobRepository, imageUsecase usecase.IImageUsecase, events usecase.EventPublisher, config Config) *Pool {
+	return &Pool{jobRepo: jobRepo, imageUsecase: imageUsecase, events: events, config: config}
+}
+
+// Run starts the workers and blocks until ctx is cancelled and every job in
+// progress has finished.
+func (p *Pool) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	for range p.config.Concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.work(ctx)
+		}()
+	}
+
+	wg.Wait()
+}
+
+func (p *Pool) work(ctx context.Context) {
+	for ctx.Err() == nil {
+		processed, err := p.RunOnce(ctx)
+		if err != nil {
+			log.Printf("cannot claim job: %s", err.Error())
+		}
+
+		if processed {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(p.config.PollInterval):
+		}
+	}
+}
+
+// RunOnce fails the jobs abandoned on their last attempt, then claims and
+// processes a single job. It reports whether a job was due. A claimed job is
+// allowed to finish even if ctx is cancelled meanwhile.
+func (p *Pool) RunOnce(ctx context.Context) (bool, error) {
+	abandoned, err := p.jobRepo.FailAbandonedJobs(ctx, errAbandoned.Error())
+	if err != nil {
+		return false, err
+	}
+
+	for idx := range abandoned {
+		p.publishFailure(ctx, &abandoned[idx], errAbandoned)
+	}
+
+	job, err := p.jobRepo.ClaimJob(ctx, p.config.Lease)
+	if err != nil || job == nil {
+		return false, err
+	}
+
+	p.process(context.WithoutCancel(ctx), job)
+	return true, nil
+}
+
+func (p *Pool) process(ctx context.Context, job *model.Job) {
+	resultImageID, err := p.execute(ctx, job)
+	if err == nil {
+		if err := p.jobRepo.CompleteJob(ctx, job.ID, job.Attempts, resultImageID); err != nil {
+			log.Printf("cannot complete job %s: %s", job.ID, err.Error())
+		}
+		return
+	}
+
+	if permanent(err) || job.Attempts >= job.MaxAttempts {
+		if err := p.jobRepo.FailJob(ctx, job.ID, job.Attempts, err.Error()); err != nil {
+			log.Printf("cannot fail job %s: %s", job.ID, err.Error())
+			return
+		}
+		p.publishFailure(ctx, job, err)
+		return
+	}
+
+	runAt := time.Now().Add(Backoff(job.Attempts))
+	if err := p.jobRepo.RetryJob(ctx, job.ID, job.Attempts, runAt, err.Error()); err != nil {
+		log.Printf("cannot retry job %s: %s", job.ID, err.Error())
+	}
+}
+
+func (p *Pool) publishFailure(ctx context.Context, job *model.Job, cause error) {
+	lastError := cause.Error()
+
+	err := p.events.Publish(ctx, job.OwnerID, model.EventJobFailed, &dto.JobResponse{
+		ID:          job.ID,
+		Type:        job.Type,
+		Status:      model.JobStatusFailed,
+		ImageID:     job.ImageID,
+		Attempts:    job.Attempts,
+		MaxAttempts: job.MaxAttempts,
+		LastError:   &lastError,
+		RunAt:       job.RunAt,
+		CreatedAt:   job.CreatedAt,
+		UpdatedAt:   time.Now(),
+	})
+	if err != nil {
+		log.Printf("cannot publish failure of job %s: %s", job.ID, err.Error())
+	}
+}
+
+func (p *Pool) execute(ctx context.Context, job *model.Job) (string, error) {
+	switch job.Type {
+	case model.JobTypeTransform:
+		var req dto.ImageTransformRequest
+		if err := json.Unmarshal(job.Payload, &req); err != nil {
+			return "", fmt.Errorf("%w: %v", customErr.ErrInvalidTransformation, err)
+		}
+
+		result, err := p.imageUsecase.Transform(ctx, job.OwnerID, job.ImageID, &req)
+		if err != nil {
+			return "", err
+		}
+		return result.ID, nil
+	default:
+		return "", fmt.Errorf("unknown job type %q", job.Type)
+	}
+}
+
+// Backoff returns the delay before retrying a job that failed its attempt-th
+// try. It doubles from 10 seconds up to 10 minutes.
+func Backoff(attempt int) time.Duration {
+	delay := baseBackoff
+	for i := 1; i < attempt && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+
+	return min(delay, maxBackoff)
+}
+
+// permanent reports whether retrying cannot change the outcome. A decode
+// timeout is retried since it can be caused by load rather than the image.
+func permanent(err error) bool {
+	return errors.Is(err, customErr.ErrInvalidTransformation) ||
+		errors.Is(err, customErr.ErrImageNotFound) ||
+		errors.Is(err, customErr.ErrUnsupportedFileFormat) ||
+		errors.Is(err, customErr.ErrImageDimensionsTooLarge) ||
+		errors.Is(err, customErr.ErrImageTooManyPixels) ||
+		errors.Is(err, customErr.ErrImageTooManyFrames) ||
+		errors.Is(err, customErr.ErrMemoryBudgetExceeded)
+}
@@ -10,4 +10,37 @@ var (
 	ErrIncorrectPassword = errors.New("incorrect password")
 	ErrDatabase          = errors.New("database error")
 	ErrRowsAffected      = errors.New("error due to there is no or more than 1 affected column")
+
+	ErrValidation              = errors.New("validation failed")
+	ErrUserIdNotFound          = errors.New("User Id Not Found in Context")
+	ErrInvalidToken            = errors.New("invalid or expired token")
+	ErrInvalidRefreshToken     = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused      = errors.New("refresh token reuse detected")
+	ErrImageNotFound           = errors.New("image not found")
+	ErrDuplicateImage          = errors.New("image already uploaded")
+	ErrImageNotHashed          = errors.New("image has no perceptual hash")
+	ErrImageFileRequired       = errors.New("image file is required")
+	ErrUnsupportedFileFormat   = errors.New("unsupported file format")
+	ErrInvalidTransformation   = errors.New("invalid transformation parameters")
+	ErrObjectNotFound          = errors.New("object not found")
+	ErrInvalidCursor           = errors.New("invalid cursor")
+	ErrInvalidSignature        = errors.New("invalid signature")
+	ErrSignatureExpired        = errors.New("signed url has expired")
+	ErrJobNotFound             = errors.New("job not found")
+	ErrJobLeaseLost            = errors.New("job lease expired and it was claimed again")
+	ErrWebhookNotFound         = errors.New("webhook not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+	ErrDeliveryLeaseLost       = errors.New("webhook delivery lease expired and it was claimed again")
+	ErrForbiddenDestination    = errors.New("webhook destination is not a public address")
+	ErrWatermarkNotFound       = errors.New("watermark settings not found")
+	ErrUploadNotFound          = errors.New("upload not found")
+	ErrUploadOffsetMismatch    = errors.New("upload offset does not match")
+	ErrUploadIncomplete        = errors.New("upload is not complete")
+
+	ErrPayloadTooLarge         = errors.New("request body is too large")
+	ErrImageDimensionsTooLarge = errors.New("image width or height exceeds the limit")
+	ErrImageTooManyPixels      = errors.New("image exceeds the megapixel limit")
+	ErrImageTooManyFrames      = errors.New("image has too many frames")
+	ErrDecodeTimeout           = errors.New("image decoding timed out")
+	ErrMemoryBudgetExceeded    = errors.New("image exceeds the decoding memory budget")
 )
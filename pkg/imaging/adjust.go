@@ -0,0 +1,64 @@
+package imaging
+
+import (
+	"image"
+	"math"
+)
+
+// Adjust holds the tonal corrections. Brightness, Contrast and Saturation are
+// percentages in [-100, 100] where zero leaves the channel untouched; -100
+// saturation removes all color. Gamma brightens the midtones above 1 and
+// darkens them below 1, with zero meaning no correction. Grayscale keeps only
+// the luma of each pixel.
+type Adjust struct {
+	Brightness float64
+	Contrast   float64
+	Saturation float64
+	Gamma      float64
+	Grayscale  bool
+}
+
+func (a Adjust) isZero() bool {
+	return a == Adjust{}
+}
+
+// adjust applies brightness, contrast and gamma through a lookup table, then
+// saturation and grayscale per pixel.
+func adjust(img image.Image, a Adjust) *image.NRGBA {
+	dst := toNRGBA(img)
+
+	var table [256]uint8
+	for v := range table {
+		value := float64(v)/255 + a.Brightness/100
+		value = (value-0.5)*(1+a.Contrast/100) + 0.5
+		if a.Gamma > 0 {
+			value = math.Pow(math.Max(value, 0), 1/a.Gamma)
+		}
+		table[v] = clampUint8(value * 255)
+	}
+
+	saturation := 1 + a.Saturation/100
+
+	for i := 0; i < len(dst.Pix); i += 4 {
+		r, g, b := float64(table[dst.Pix[i]]), float64(table[dst.Pix[i+1]]), float64(table[dst.Pix[i+2]])
+
+		if a.Saturation != 0 {
+			l := luma(r, g, b)
+			r, g, b = l+(r-l)*saturation, l+(g-l)*saturation, l+(b-l)*saturation
+		}
+
+		if a.Grayscale {
+			l := luma(r, g, b)
+			r, g, b = l, l, l
+		}
+
+		dst.Pix[i], dst.Pix[i+1], dst.Pix[i+2] = clampUint8(r), clampUint8(g), clampUint8(b)
+	}
+
+	return dst
+}
+
+// luma weights the channels with the Rec. 601 coefficients.
+func luma(r, g, b float64) float64 {
+	return 0.299*r + 0.587*g + 0.114*b
+}
@@ -0,0 +1,269 @@
+package imaging
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"io"
+
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+)
+
+// MaxAnimationPixels caps the frame count multiplied by the frame area of an
+// animation, before and after the transformation, so a small file cannot
+// expand into gigabytes of frames.
+const MaxAnimationPixels = 100_000_000
+
+// Animation is a sequence of frames that each cover the whole canvas. Delays
+// are in hundredths of a second and Disposals hold the GIF disposal method of
+// each frame. LoopCount follows image/gif: 0 loops forever, -1 plays once.
+type Animation struct {
+	Frames    []image.Image
+	Delays    []int
+	Disposals []byte
+	LoopCount int
+}
+
+func checkAnimationSize(frames int, size image.Point) error {
+	if int64(frames)*int64(size.X)*int64(size.Y) > MaxAnimationPixels {
+		return fmt.Errorf("%w: animation of %d frames of %dx%d exceeds %d pixels",
+			customErr.ErrInvalidTransformation, frames, size.X, size.Y, MaxAnimationPixels)
+	}
+
+	return nil
+}
+
+// transformAnimation applies opts to every frame of anim.
+func transformAnimation(anim *Animation, opts Options) (*Animation, error) {
+	out := &Animation{
+		Frames:    make([]image.Image, 0, len(anim.Frames)),
+		Delays:    anim.Delays,
+		Disposals: anim.Disposals,
+		LoopCount: anim.LoopCount,
+	}
+
+	for i, frame := range anim.Frames {
+		img, err := Apply(frame, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		// a resize or rotation can grow every frame, so check the output too
+		if i == 0 {
+			if err := checkAnimationSize(len(anim.Frames), img.Bounds().Size()); err != nil {
+				return nil, err
+			}
+		}
+
+		out.Frames = append(out.Frames, img)
+	}
+
+	return out, nil
+}
+
+// decodeGIFAnimation decodes every frame of a GIF and composites each onto the
+// canvas left by the previous ones, following their disposal methods, so the
+// frames can be transformed independently.
+func decodeGIFAnimation(r io.Reader) (*Animation, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := gif.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	frames, err := countGIFFrames(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	// reject oversized animations before any frame is allocated
+	if err := checkAnimationSize(frames, image.Pt(config.Width, config.Height)); err != nil {
+		return nil, err
+	}
+
+	g, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := image.Rect(0, 0, g.Config.Width, g.Config.Height)
+	anim := &Animation{
+		Frames:    make([]image.Image, 0, len(g.Image)),
+		Delays:    g.Delay,
+		Disposals: g.Disposal,
+		LoopCount: g.LoopCount,
+	}
+
+	canvas := image.NewNRGBA(bounds)
+	var previous *image.NRGBA
+
+	for i, frame := range g.Image {
+		disposal := byte(0)
+		if i < len(g.Disposal) {
+			disposal = g.Disposal[i]
+		}
+
+		if disposal == gif.DisposalPrevious {
+			previous = image.NewNRGBA(bounds)
+			copy(previous.Pix, canvas.Pix)
+		}
+
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+
+		coalesced := image.NewNRGBA(bounds)
+		copy(coalesced.Pix, canvas.Pix)
+		anim.Frames = append(anim.Frames, coalesced)
+
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, frame.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			canvas = previous
+		}
+	}
+
+	return anim, nil
+}
+
+// countGIFFrames walks the GIF block structure and counts the image
+// descriptors without decoding any pixels.
+func countGIFFrames(r io.Reader) (int, error) {
+	errMalformed := errors.New("gif: malformed block structure")
+	br := bufio.NewReader(r)
+
+	// a short read means a truncated file; other errors are the reader's own
+	check := func(err error) error {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return errMalformed
+		}
+		return err
+	}
+
+	skipColorTable := func(flags byte) error {
+		if flags&0x80 == 0 {
+			return nil
+		}
+		_, err := br.Discard(3 << (flags&0x07 + 1))
+		return check(err)
+	}
+
+	// skipSubBlocks advances past a sequence of length prefixed sub-blocks
+	skipSubBlocks := func() error {
+		for {
+			n, err := br.ReadByte()
+			if err != nil {
+				return check(err)
+			}
+			if n == 0 {
+				return nil
+			}
+			if _, err := br.Discard(int(n)); err != nil {
+				return check(err)
+			}
+		}
+	}
+
+	header := make([]byte, 13)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return 0, check(err)
+	}
+	if err := skipColorTable(header[10]); err != nil {
+		return 0, err
+	}
+
+	frames := 0
+	for {
+		block, err := br.ReadByte()
+		if err == io.EOF {
+			// like image/gif, tolerate a missing trailer
+			return frames, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		switch block {
+		case 0x21:
+			if _, err := br.ReadByte(); err != nil {
+				return 0, check(err)
+			}
+			if err := skipSubBlocks(); err != nil {
+				return 0, err
+			}
+		case 0x2c:
+			descriptor := make([]byte, 9)
+			if _, err := io.ReadFull(br, descriptor); err != nil {
+				return 0, check(err)
+			}
+			if err := skipColorTable(descriptor[8]); err != nil {
+				return 0, err
+			}
+			// LZW minimum code size, then the image data
+			if _, err := br.ReadByte(); err != nil {
+				return 0, check(err)
+			}
+			if err := skipSubBlocks(); err != nil {
+				return 0, err
+			}
+			frames++
+		case 0x3b:
+			return frames, nil
+		default:
+			return 0, errMalformed
+		}
+	}
+}
+
+// encodeGIFAnimation writes every frame as a full canvas image. Frames keep
+// their delay and disposal method, which still composite correctly because
+// each frame already holds everything visible at that point.
+func encodeGIFAnimation(w io.Writer, anim *Animation, opts EncodeOptions) error {
+	colors := cmp.Or(opts.Colors, 256)
+	bounds := image.Rect(0, 0, anim.Frames[0].Bounds().Dx(), anim.Frames[0].Bounds().Dy())
+
+	g := &gif.GIF{
+		Image:     make([]*image.Paletted, 0, len(anim.Frames)),
+		Delay:     anim.Delays,
+		Disposal:  anim.Disposals,
+		LoopCount: anim.LoopCount,
+	}
+
+	for _, frame := range anim.Frames {
+		g.Image = append(g.Image, quantize(frame, bounds, colors))
+	}
+
+	return gif.EncodeAll(w, g)
+}
+
+// quantize dithers img onto the Plan 9 palette cut to colors entries, keeping
+// the last entry for transparency when img has transparent pixels.
+func quantize(img image.Image, bounds image.Rectangle, colors int) *image.Paletted {
+	p := color.Palette(palette.Plan9[:colors])
+	if !opaque(img) {
+		p = append(palette.Plan9[:colors-1:colors-1], color.Transparent)
+	}
+
+	dst := image.NewPaletted(bounds, p)
+	draw.FloydSteinberg.Draw(dst, bounds, img, img.Bounds().Min)
+
+	return dst
+}
+
+func opaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+
+	return false
+}
@@ -0,0 +1,106 @@
+package imaging
+
+import (
+	"image"
+	"math"
+)
+
+// MaxSigma caps the standard deviation of blur and sharpen kernels, which
+// bounds their cost to roughly 120 samples per pixel and axis.
+const MaxSigma = 20
+
+// Sharpen is an unsharp mask: each channel moves away from its gaussian
+// blurred value by Amount times the difference, unless the difference is
+// below Threshold (0-255). A zero Amount selects 1.
+type Sharpen struct {
+	Sigma     float64
+	Amount    float64
+	Threshold float64
+}
+
+// blur convolves img with a gaussian of the given standard deviation,
+// clamping at the edges.
+func blur(img image.Image, sigma float64) *image.NRGBA {
+	src := toNRGBA(img)
+
+	radius := int(math.Ceil(3 * sigma))
+	kernel := make([]float64, 2*radius+1)
+
+	var sum float64
+	for i := range kernel {
+		d := float64(i - radius)
+		kernel[i] = math.Exp(-d * d / (2 * sigma * sigma))
+		sum += kernel[i]
+	}
+	for i := range kernel {
+		kernel[i] /= sum
+	}
+
+	return convolveAxis(convolveAxis(src, kernel, true), kernel, false)
+}
+
+// convolveAxis applies a symmetric kernel along one axis with the same
+// alpha-weighted accumulation as resampling.
+func convolveAxis(src *image.NRGBA, kernel []float64, horizontal bool) *image.NRGBA {
+	bounds := src.Rect
+	dst := image.NewNRGBA(bounds)
+	radius := len(kernel) / 2
+
+	for y := range bounds.Dy() {
+		for x := range bounds.Dx() {
+			var r, g, b, a float64
+
+			for k, w := range kernel {
+				sx, sy := x, y
+				if horizontal {
+					sx = min(max(x+k-radius, 0), bounds.Dx()-1)
+				} else {
+					sy = min(max(y+k-radius, 0), bounds.Dy()-1)
+				}
+
+				i := src.PixOffset(sx, sy)
+				pa := float64(src.Pix[i+3]) * w
+				r += float64(src.Pix[i]) * pa
+				g += float64(src.Pix[i+1]) * pa
+				b += float64(src.Pix[i+2]) * pa
+				a += pa
+			}
+
+			j := dst.PixOffset(x, y)
+			if a > 0 {
+				dst.Pix[j] = clampUint8(r / a)
+				dst.Pix[j+1] = clampUint8(g / a)
+				dst.Pix[j+2] = clampUint8(b / a)
+			}
+			dst.Pix[j+3] = clampUint8(a)
+		}
+	}
+
+	return dst
+}
+
+func sharpen(img image.Image, s Sharpen) *image.NRGBA {
+	src := toNRGBA(img)
+	dst := blur(src, s.Sigma)
+
+	amount := s.Amount
+	if amount == 0 {
+		amount = 1
+	}
+
+	for i := 0; i < len(src.Pix); i += 4 {
+		for c := range 3 {
+			original := float64(src.Pix[i+c])
+			diff := original - float64(dst.Pix[i+c])
+
+			if math.Abs(diff) < s.Threshold {
+				dst.Pix[i+c] = src.Pix[i+c]
+				continue
+			}
+			dst.Pix[i+c] = clampUint8(original + amount*diff)
+		}
+		dst.Pix[i+3] = src.Pix[i+3]
+	}
+
+	return dst
+}
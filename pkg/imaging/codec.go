@@ -0,0 +1,245 @@
+package imaging
+
+import (
+	"cmp"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"slices"
+	"sync"
+
+	"golang.org/x/image/bmp"
+	"golang.org/x/image/tiff"
+	"golang.org/x/image/webp"
+)
+
+// Codec reads and optionally writes one image format. Registering a codec
+// makes its format available to uploads, conversions and renders; a codec
+// without Encode is read only, and images in its format are re-encoded as PNG
+// unless another output format is requested.
+type Codec struct {
+	Format    Format
+	MimeType  string
+	Extension string
+	// Aliases are alternative names accepted by ParseFormat, such as "jpg".
+	Aliases []string
+	// Magic are the leading bytes that identify the format. A '?' matches
+	// any byte.
+	Magic        []string
+	Decode       func(r io.Reader) (image.Image, error)
+	DecodeConfig func(r io.Reader) (image.Config, error)
+	Encode       func(w io.Writer, img image.Image, opts EncodeOptions) error
+	// DecodeAnimation and EncodeAnimation are set by formats that can hold
+	// more than one frame. Transformations of animated sources run on every
+	// frame when the output codec can encode animations.
+	DecodeAnimation func(r io.Reader) (*Animation, error)
+	EncodeAnimation func(w io.Writer, anim *Animation, opts EncodeOptions) error
+	// CountFrames reads the whole stream and counts its frames without
+	// decoding them; formats without it hold a single frame.
+	CountFrames func(r io.Reader) (int, error)
+	// ReadMetadata extracts the metadata blocks from an encoded image, and
+	// WriteMetadata embeds them into the output of Encode. Formats without
+	// them neither report nor keep metadata.
+	ReadMetadata  func(data []byte) Metadata
+	WriteMetadata func(data []byte, meta Metadata) ([]byte, error)
+}
+
+var registry = struct {
+	sync.RWMutex
+	codecs []Codec
+}{}
+
+// RegisterCodec adds a codec, or replaces the one registered for the same
+// format, for example to swap a read only codec for one that also encodes.
+func RegisterCodec(codec Codec) {
+	if codec.Format == "" || codec.Decode == nil || codec.DecodeConfig == nil || len(codec.Magic) == 0 {
+		panic("imaging: codec needs a format, magic and decoders")
+	}
+
+	codec.Format = Format(normalizeFormatName(string(codec.Format)))
+	if codec.MimeType == "" {
+		codec.MimeType = "image/" + string(codec.Format)
+	}
+	if codec.Extension == "" {
+		codec.Extension = "." + string(codec.Format)
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	for i := range registry.codecs {
+		if registry.codecs[i].Format == codec.Format {
+			registry.codecs[i] = codec
+			return
+		}
+	}
+
+	registry.codecs = append(registry.codecs, codec)
+}
+
+// LookupCodec finds a codec by format name, alias or MIME type.
+func LookupCodec(name string) (Codec, bool) {
+	name = normalizeFormatName(name)
+
+	registry.RLock()
+	defer registry.RUnlock()
+
+	for _, codec := range registry.codecs {
+		if string(codec.Format) == name || codec.MimeType == name || slices.Contains(codec.Aliases, name) {
+			return codec, true
+		}
+	}
+
+	return Codec{}, false
+}
+
+// Codecs returns the registered codecs in registration order.
+func Codecs() []Codec {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	return slices.Clone(registry.codecs)
+}
+
+func sniff(head []byte) (Codec, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	for _, codec := range registry.codecs {
+		for _, magic := range codec.Magic {
+			if matchMagic(magic, head) {
+				return codec, true
+			}
+		}
+	}
+
+	return Codec{}, false
+}
+
+func matchMagic(magic string, head []byte) bool {
+	if len(head) < len(magic) {
+		return false
+	}
+
+	for i := range len(magic) {
+		if magic[i] != '?' && magic[i] != head[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func maxMagicLength() int {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	n := 0
+	for _, codec := range registry.codecs {
+		for _, magic := range codec.Magic {
+			n = max(n, len(magic))
+		}
+	}
+
+	return n
+}
+
+func init() {
+	RegisterCodec(Codec{
+		Format:        FormatPNG,
+		Magic:         []string{"\x89PNG\r\n\x1a\n"},
+		Decode:        png.Decode,
+		DecodeConfig:  png.DecodeConfig,
+		Encode:        encodePNG,
+		ReadMetadata:  readPNGMetadata,
+		WriteMetadata: writePNGMetadata,
+	})
+
+	RegisterCodec(Codec{
+		Format:        FormatJPEG,
+		Extension:     ".jpg",
+		Aliases:       []string{"jpg"},
+		Magic:         []string{"\xff\xd8"},
+		Decode:        jpeg.Decode,
+		DecodeConfig:  jpeg.DecodeConfig,
+		Encode:        encodeJPEG,
+		ReadMetadata:  readJPEGMetadata,
+		WriteMetadata: writeJPEGMetadata,
+	})
+
+	RegisterCodec(Codec{
+		Format:       FormatGIF,
+		Magic:        []string{"GIF87a", "GIF89a"},
+		Decode:       gif.Decode,
+		DecodeConfig: gif.DecodeConfig,
+		Encode: func(w io.Writer, img image.Image, opts EncodeOptions) error {
+			return gif.Encode(w, img, &gif.Options{NumColors: cmp.Or(opts.Colors, 256)})
+		},
+		DecodeAnimation: decodeGIFAnimation,
+		EncodeAnimation: encodeGIFAnimation,
+		CountFrames:     countGIFFrames,
+	})
+
+	// golang.org/x/image only decodes WebP; an encoder can be registered over
+	// this codec without touching any caller
+	RegisterCodec(Codec{
+		Format:       FormatWebP,
+		Magic:        []string{"RIFF????WEBPVP8"},
+		Decode:       webp.Decode,
+		DecodeConfig: webp.DecodeConfig,
+		ReadMetadata: readWebPMetadata,
+	})
+
+	RegisterCodec(Codec{
+		Format:       FormatBMP,
+		Magic:        []string{"BM????\x00\x00\x00\x00"},
+		Decode:       bmp.Decode,
+		DecodeConfig: bmp.DecodeConfig,
+		Encode: func(w io.Writer, img image.Image, _ EncodeOptions) error {
+			return bmp.Encode(w, img)
+		},
+	})
+
+	RegisterCodec(Codec{
+		Format:       FormatTIFF,
+		Extension:    ".tif",
+		Aliases:      []string{"tif"},
+		Magic:        []string{"II\x2a\x00", "MM\x00\x2a"},
+		Decode:       tiff.Decode,
+		DecodeConfig: tiff.DecodeConfig,
+		Encode: func(w io.Writer, img image.Image, opts EncodeOptions) error {
+			options := &tiff.Options{Compression: tiff.Deflate, Predictor: true}
+			if opts.Compression == CompressionNone {
+				options = &tiff.Options{Compression: tiff.Uncompressed}
+			}
+			return tiff.Encode(w, img, options)
+		},
+	})
+}
+
+func encodePNG(w io.Writer, img image.Image, opts EncodeOptions) error {
+	encoder := png.Encoder{CompressionLevel: png.DefaultCompression}
+
+	switch opts.Compression {
+	case CompressionNone:
+		encoder.CompressionLevel = png.NoCompression
+	case CompressionFast:
+		encoder.CompressionLevel = png.BestSpeed
+	case CompressionBest:
+		encoder.CompressionLevel = png.BestCompression
+	}
+
+	return encoder.Encode(w, img)
+}
+
+func encodeJPEG(w io.Writer, img image.Image, opts EncodeOptions) error {
+	quality := cmp.Or(opts.Quality, defaultJPEGQuality)
+
+	if opts.Progressive {
+		return encodeProgressiveJPEG(w, img, quality)
+	}
+
+	return jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
+}
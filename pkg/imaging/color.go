@@ -0,0 +1,34 @@
+package imaging
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+)
+
+// ParseColor reads a hex color such as "#fff", "#ffffff" or "#ffffff80". The
+// leading # is optional and an empty name or "transparent" is fully
+// transparent.
+func ParseColor(name string) (color.NRGBA, error) {
+	hex := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(name)), "#")
+	if hex == "" || hex == "transparent" {
+		return color.NRGBA{}, nil
+	}
+
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || len(hex) != 8 {
+		return color.NRGBA{}, fmt.Errorf("%w: invalid color %q", customErr.ErrInvalidTransformation, name)
+	}
+
+	return color.NRGBA{R: uint8(value >> 24), G: uint8(value >> 16), B: uint8(value >> 8), A: uint8(value)}, nil
+}
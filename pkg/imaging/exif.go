@@ -0,0 +1,250 @@
+package imaging
+
+import (
+	"encoding/binary"
+	"errors"
+	"image"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Orientation is the EXIF orientation tag: how the stored pixels have to be
+// transformed to display the image upright. Values 5 to 8 swap the width and
+// the height.
+type Orientation int
+
+const (
+	OrientationNormal     Orientation = 1
+	OrientationFlopped    Orientation = 2
+	OrientationRotated180 Orientation = 3
+	OrientationFlipped    Orientation = 4
+	OrientationTransposed Orientation = 5
+	OrientationRotated90  Orientation = 6
+	OrientationTransverse Orientation = 7
+	OrientationRotated270 Orientation = 8
+)
+
+// Transposed reports whether displaying the image swaps its dimensions.
+func (o Orientation) Transposed() bool {
+	return o >= OrientationTransposed && o <= OrientationRotated270
+}
+
+// EXIF holds the tags read from an image's EXIF block. Width and Height are
+// the pixel dimensions recorded by the camera, zero when absent.
+type EXIF struct {
+	Make        string
+	Model       string
+	TakenAt     *time.Time
+	Width       int
+	Height      int
+	Orientation Orientation
+}
+
+const (
+	tagMake             = 0x010f
+	tagModel            = 0x0110
+	tagOrientation      = 0x0112
+	tagExifIFD          = 0x8769
+	tagDateTimeOriginal = 0x9003
+	tagPixelXDimension  = 0xa002
+	tagPixelYDimension  = 0xa003
+)
+
+const (
+	typeASCII = 2
+	typeShort = 3
+	typeLong  = 4
+)
+
+// maxASCII caps the characters kept of a text tag, which can be as long as
+// the whole EXIF block.
+const maxASCII = 255
+
+var errMalformedEXIF = errors.New("exif: malformed block")
+
+// ParseEXIF reads the camera, capture time, dimensions and orientation from a
+// TIFF structured EXIF block, without the "Exif\0\0" header of JPEG files.
+// Tags that are missing or malformed are left zero; the orientation defaults
+// to normal.
+func ParseEXIF(data []byte) (EXIF, error) {
+	exif := EXIF{Orientation: OrientationNormal}
+
+	t, err := newTIFFReader(data)
+	if err != nil {
+		return exif, err
+	}
+
+	var exifIFD uint32
+	t.entries(t.firstIFD(), func(tag, typ uint16, value []byte) {
+		switch tag {
+		case tagMake:
+			exif.Make = t.ascii(typ, value)
+		case tagModel:
+			exif.Model = t.ascii(typ, value)
+		case tagOrientation:
+			if o := Orientation(t.uint(typ, value)); o >= OrientationNormal && o <= OrientationRotated270 {
+				exif.Orientation = o
+			}
+		case tagExifIFD:
+			exifIFD = uint32(t.uint(typ, value))
+		}
+	})
+
+	if exifIFD != 0 {
+		t.entries(exifIFD, func(tag, typ uint16, value []byte) {
+			switch tag {
+			case tagDateTimeOriginal:
+				// the capture time carries no zone, so it is kept as recorded
+				if taken, err := time.Parse("2006:01:02 15:04:05", t.ascii(typ, value)); err == nil {
+					exif.TakenAt = &taken
+				}
+			case tagPixelXDimension:
+				exif.Width = t.uint(typ, value)
+			case tagPixelYDimension:
+				exif.Height = t.uint(typ, value)
+			}
+		})
+	}
+
+	return exif, nil
+}
+
+// resetOrientation returns a copy of an EXIF block whose orientation tag says
+// normal, for images whose pixels were already turned upright.
+func resetOrientation(data []byte) []byte {
+	out := append([]byte(nil), data...)
+
+	t, err := newTIFFReader(out)
+	if err != nil {
+		return out
+	}
+
+	t.entries(t.firstIFD(), func(tag, typ uint16, value []byte) {
+		if tag == tagOrientation && typ == typeShort && len(value) >= 2 {
+			t.order.PutUint16(value, uint16(OrientationNormal))
+		}
+	})
+
+	return out
+}
+
+type tiffReader struct {
+	data  []byte
+	order binary.ByteOrder
+}
+
+func newTIFFReader(data []byte) (*tiffReader, error) {
+	if len(data) < 8 {
+		return nil, errMalformedEXIF
+	}
+
+	switch string(data[:4]) {
+	case "II\x2a\x00":
+		return &tiffReader{data: data, order: binary.LittleEndian}, nil
+	case "MM\x00\x2a":
+		return &tiffReader{data: data, order: binary.BigEndian}, nil
+	default:
+		return nil, errMalformedEXIF
+	}
+}
+
+func (t *tiffReader) firstIFD() uint32 {
+	return t.order.Uint32(t.data[4:8])
+}
+
+// entries calls fn with every entry of the IFD at offset. value is a slice of
+// the block holding the entry's values, so writes to it patch the block.
+// Entries pointing outside the block are skipped.
+func (t *tiffReader) entries(offset uint32, fn func(tag, typ uint16, value []byte)) {
+	if uint64(offset)+2 > uint64(len(t.data)) {
+		return
+	}
+
+	n := int(t.order.Uint16(t.data[offset:]))
+	for i := range n {
+		entry := uint64(offset) + 2 + uint64(i)*12
+		if entry+12 > uint64(len(t.data)) {
+			return
+		}
+
+		tag := t.order.Uint16(t.data[entry:])
+		typ := t.order.Uint16(t.data[entry+2:])
+		count := t.order.Uint32(t.data[entry+4:])
+
+		var size uint64
+		switch typ {
+		case typeASCII:
+			size = uint64(count)
+		case typeShort:
+			size = 2 * uint64(count)
+		case typeLong:
+			size = 4 * uint64(count)
+		default:
+			continue
+		}
+
+		start := entry + 8
+		if size > 4 {
+			start = uint64(t.order.Uint32(t.data[entry+8:]))
+		}
+		if start+size > uint64(len(t.data)) {
+			continue
+		}
+
+		fn(tag, typ, t.data[start:start+size])
+	}
+}
+
+func (t *tiffReader) uint(typ uint16, value []byte) int {
+	switch {
+	case typ == typeShort && len(value) >= 2:
+		return int(t.order.Uint16(value))
+	case typ == typeLong && len(value) >= 4:
+		return int(t.order.Uint32(value))
+	default:
+		return 0
+	}
+}
+
+func (t *tiffReader) ascii(typ uint16, value []byte) string {
+	if typ != typeASCII {
+		return ""
+	}
+
+	s, _, _ := strings.Cut(string(value), "\x00")
+	// the tag should be ASCII but cameras and editors write anything
+	s = strings.TrimSpace(strings.ToValidUTF8(s, ""))
+	if utf8.RuneCountInString(s) > maxASCII {
+		s = strings.TrimSpace(string([]rune(s)[:maxASCII]))
+	}
+
+	return s
+}
+
+// orient turns the decoded pixels upright according to o.
+func orient(img image.Image, o Orientation) image.Image {
+	if o <= OrientationNormal || o > OrientationRotated270 {
+		return img
+	}
+
+	src := toNRGBA(img)
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+
+	switch o {
+	case OrientationFlopped:
+		return flop(src)
+	case OrientationRotated180:
+		return remap(src, w, h, func(x, y int) (int, int) { return w - 1 - x, h - 1 - y })
+	case OrientationFlipped:
+		return flip(src)
+	case OrientationTransposed:
+		return remap(src, h, w, func(x, y int) (int, int) { return y, x })
+	case OrientationRotated90:
+		return remap(src, h, w, func(x, y int) (int, int) { return y, h - 1 - x })
+	case OrientationTransverse:
+		return remap(src, h, w, func(x, y int) (int, int) { return w - 1 - y, h - 1 - x })
+	default:
+		return remap(src, h, w, func(x, y int) (int, int) { return w - 1 - y, x })
+	}
+}
@@ -0,0 +1,213 @@
+package imaging
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"image"
+	"io"
+	"strings"
+
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+)
+
+// Format is an encoded image format the pipeline can read, and usually write.
+// Its value is the name of a registered Codec.
+type Format string
+
+const (
+	FormatPNG  Format = "png"
+	FormatJPEG Format = "jpeg"
+	FormatGIF  Format = "gif"
+	FormatWebP Format = "webp"
+	FormatBMP  Format = "bmp"
+	FormatTIFF Format = "tiff"
+)
+
+const defaultJPEGQuality = 90
+
+// Compression is the PNG compression level.
+type Compression string
+
+const (
+	CompressionDefault Compression = "default"
+	CompressionNone    Compression = "none"
+	CompressionFast    Compression = "fast"
+	CompressionBest    Compression = "best"
+)
+
+// EncodeOptions tunes the encoder. Each codec reads the fields that apply to
+// it and ignores the rest, so one set of options can be used for any output
+// format; zero values select the codec's default.
+type EncodeOptions struct {
+	// Quality is the JPEG quality from 1 to 100.
+	Quality int
+	// Progressive writes a progressive JPEG.
+	Progressive bool
+	// Compression is the PNG compression level. TIFF only distinguishes
+	// none from the others.
+	Compression Compression
+	// Colors is the GIF palette size from 1 to 256.
+	Colors int
+}
+
+func (o EncodeOptions) validate() error {
+	if o.Quality < 0 || o.Quality > 100 {
+		return fmt.Errorf("%w: quality must be between 1 and 100", customErr.ErrInvalidTransformation)
+	}
+
+	switch o.Compression {
+	case "", CompressionDefault, CompressionNone, CompressionFast, CompressionBest:
+	default:
+		return fmt.Errorf("%w: unsupported compression %q", customErr.ErrInvalidTransformation, o.Compression)
+	}
+
+	if o.Colors < 0 || o.Colors > 256 {
+		return fmt.Errorf("%w: colors must be between 1 and 256", customErr.ErrInvalidTransformation)
+	}
+
+	return nil
+}
+
+// ParseFormat resolves a user supplied format name, alias or MIME type such
+// as "PNG", "jpg" or "image/webp" to a registered format.
+func ParseFormat(name string) (Format, error) {
+	codec, ok := LookupCodec(name)
+	if !ok {
+		return "", fmt.Errorf("%w: unsupported format %q", customErr.ErrInvalidTransformation, name)
+	}
+
+	return codec.Format, nil
+}
+
+// MimeType returns the content type for the format.
+func (f Format) MimeType() string {
+	if codec, ok := LookupCodec(string(f)); ok {
+		return codec.MimeType
+	}
+
+	return "image/" + string(f)
+}
+
+// Extension returns the file extension, including the dot, for the format.
+func (f Format) Extension() string {
+	if codec, ok := LookupCodec(string(f)); ok {
+		return codec.Extension
+	}
+
+	return "." + string(f)
+}
+
+// Encodable reports whether the format can be written, not only read.
+func (f Format) Encodable() bool {
+	codec, ok := LookupCodec(string(f))
+	return ok && codec.Encode != nil
+}
+
+// Sniff identifies the format from the leading bytes of an encoded image.
+func Sniff(head []byte) (Format, bool) {
+	codec, ok := sniff(head)
+	if !ok {
+		return "", false
+	}
+
+	return codec.Format, true
+}
+
+// Decode reads an image in any of the registered formats and turns it upright
+// according to its EXIF orientation. The image is checked against the current
+// Limits before it is decoded.
+func Decode(r io.Reader) (image.Image, Format, error) {
+	codec, rr, err := sniffReader(r)
+	if err != nil {
+		return nil, "", err
+	}
+
+	data, cost, err := readLimited(rr)
+	if err != nil {
+		return nil, "", err
+	}
+
+	img, _, err := decodeStill(codec, data, cost)
+	if err != nil {
+		return nil, "", decodeError(err)
+	}
+
+	return img, codec.Format, nil
+}
+
+// decodeStill decodes the first frame of data with codec within cost,
+// applies the EXIF orientation and returns the metadata blocks it read.
+func decodeStill(codec Codec, data []byte, cost decodeCost) (image.Image, Metadata, error) {
+	var meta Metadata
+	if codec.ReadMetadata != nil {
+		meta = codec.ReadMetadata(data)
+	}
+
+	img, err := limitedDecode(cost, func() (image.Image, error) {
+		return codec.Decode(bytes.NewReader(data))
+	})
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+
+	return orient(img, meta.ParseEXIF().Orientation), meta, nil
+}
+
+// DecodeConfig reads the dimensions and color model of an image in any of the
+// registered formats without decoding the pixels.
+func DecodeConfig(r io.Reader) (image.Config, Format, error) {
+	codec, rr, err := sniffReader(r)
+	if err != nil {
+		return image.Config{}, "", err
+	}
+
+	config, err := codec.DecodeConfig(rr)
+	if err != nil {
+		return image.Config{}, "", fmt.Errorf("failed to decode image config: %v", err)
+	}
+
+	return config, codec.Format, nil
+}
+
+// Encode writes img to w in the given format.
+func Encode(w io.Writer, img image.Image, format Format, opts EncodeOptions) error {
+	codec, ok := LookupCodec(string(format))
+	if !ok {
+		return fmt.Errorf("%w: unsupported format %q", customErr.ErrInvalidTransformation, format)
+	}
+
+	if codec.Encode == nil {
+		return fmt.Errorf("%w: %s can be read but not written", customErr.ErrInvalidTransformation, format)
+	}
+
+	return codec.Encode(w, img, opts)
+}
+
+// sniffReader identifies the codec of r and returns a reader that still
+// yields the sniffed bytes.
+func sniffReader(r io.Reader) (Codec, io.Reader, error) {
+	rr, ok := r.(interface {
+		io.Reader
+		Peek(int) ([]byte, error)
+	})
+	if !ok {
+		rr = bufio.NewReader(r)
+	}
+
+	head, err := rr.Peek(maxMagicLength())
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return Codec{}, nil, fmt.Errorf("failed to decode image: %v", err)
+	}
+
+	codec, ok := sniff(head)
+	if !ok {
+		return Codec{}, nil, customErr.ErrUnsupportedFileFormat
+	}
+
+	return codec, rr, nil
+}
+
+func normalizeFormatName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
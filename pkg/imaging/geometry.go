@@ -0,0 +1,139 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+)
+
+// Rotate turns the image clockwise by Angle degrees. Multiples of 90 are
+// exact; any other angle grows the canvas to fit the rotated image and fills
+// the uncovered corners with Background. A transparent background turns black
+// in formats without alpha such as JPEG.
+type Rotate struct {
+	Angle      float64
+	Background color.NRGBA
+}
+
+func rotate(img image.Image, r Rotate) (image.Image, error) {
+	angle := math.Mod(r.Angle, 360)
+	if angle < 0 {
+		angle += 360
+	}
+
+	src := toNRGBA(img)
+	width, height := src.Rect.Dx(), src.Rect.Dy()
+
+	switch angle {
+	case 0:
+		return img, nil
+	case 90:
+		return remap(src, height, width, func(x, y int) (int, int) { return y, height - 1 - x }), nil
+	case 180:
+		return remap(src, width, height, func(x, y int) (int, int) { return width - 1 - x, height - 1 - y }), nil
+	case 270:
+		return remap(src, height, width, func(x, y int) (int, int) { return width - 1 - y, x }), nil
+	}
+
+	sin, cos := math.Sincos(angle * math.Pi / 180)
+	w, h := float64(width), float64(height)
+
+	// the small epsilon keeps float noise from adding a column or row
+	dstWidth := int(math.Ceil(math.Abs(w*cos) + math.Abs(h*sin) - 1e-6))
+	dstHeight := int(math.Ceil(math.Abs(w*sin) + math.Abs(h*cos) - 1e-6))
+
+	if dstWidth > MaxDimension || dstHeight > MaxDimension {
+		return nil, fmt.Errorf("%w: rotated image would exceed %d pixels", customErr.ErrInvalidTransformation, MaxDimension)
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	centerX, centerY := float64(dstWidth)/2, float64(dstHeight)/2
+
+	for y := range dstHeight {
+		for x := range dstWidth {
+			// rotate the pixel center back counter-clockwise into the source
+			dx, dy := float64(x)+0.5-centerX, float64(y)+0.5-centerY
+			sx := dx*cos + dy*sin + w/2
+			sy := -dx*sin + dy*cos + h/2
+
+			c := bilinear(src, sx, sy, r.Background)
+			i := dst.PixOffset(x, y)
+			dst.Pix[i], dst.Pix[i+1], dst.Pix[i+2], dst.Pix[i+3] = c.R, c.G, c.B, c.A
+		}
+	}
+
+	return dst, nil
+}
+
+// flip mirrors the image top to bottom.
+func flip(img image.Image) *image.NRGBA {
+	src := toNRGBA(img)
+	height := src.Rect.Dy()
+
+	return remap(src, src.Rect.Dx(), height, func(x, y int) (int, int) { return x, height - 1 - y })
+}
+
+// flop mirrors the image left to right.
+func flop(img image.Image) *image.NRGBA {
+	src := toNRGBA(img)
+	width := src.Rect.Dx()
+
+	return remap(src, width, src.Rect.Dy(), func(x, y int) (int, int) { return width - 1 - x, y })
+}
+
+// remap builds a width x height image whose pixel (x, y) is copied from the
+// source pixel returned by from.
+func remap(src *image.NRGBA, width, height int, from func(x, y int) (int, int)) *image.NRGBA {
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+
+	for y := range height {
+		for x := range width {
+			sx, sy := from(x, y)
+			copy(dst.Pix[dst.PixOffset(x, y):][:4], src.Pix[src.PixOffset(sx, sy):][:4])
+		}
+	}
+
+	return dst
+}
+
+// bilinear samples src at the continuous position (x, y), where pixel
+// centers sit at half coordinates. Samples outside the image take the
+// background color, which anti-aliases the edges of a rotated image.
+func bilinear(src *image.NRGBA, x, y float64, background color.NRGBA) color.NRGBA {
+	x0, y0 := math.Floor(x-0.5), math.Floor(y-0.5)
+	tx, ty := x-0.5-x0, y-0.5-y0
+
+	samples := [4]struct {
+		x, y   int
+		weight float64
+	}{
+		{int(x0), int(y0), (1 - tx) * (1 - ty)},
+		{int(x0) + 1, int(y0), tx * (1 - ty)},
+		{int(x0), int(y0) + 1, (1 - tx) * ty},
+		{int(x0) + 1, int(y0) + 1, tx * ty},
+	}
+
+	var r, g, b, a float64
+	for _, s := range samples {
+		c := background
+		if image.Pt(s.x, s.y).In(src.Rect) {
+			i := src.PixOffset(s.x, s.y)
+			c = color.NRGBA{R: src.Pix[i], G: src.Pix[i+1], B: src.Pix[i+2], A: src.Pix[i+3]}
+		}
+
+		pa := float64(c.A) * s.weight
+		r += float64(c.R) * pa
+		g += float64(c.G) * pa
+		b += float64(c.B) * pa
+		a += pa
+	}
+
+	if a == 0 {
+		return color.NRGBA{}
+	}
+
+	return color.NRGBA{R: clampUint8(r / a), G: clampUint8(g / a), B: clampUint8(b / a), A: clampUint8(a)}
+}
@@ -0,0 +1,66 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+)
+
+// Gravity names the edge or corner an overlay or crop is anchored to.
+type Gravity string
+
+const (
+	GravityCenter    Gravity = "center"
+	GravityNorth     Gravity = "north"
+	GravitySouth     Gravity = "south"
+	GravityEast      Gravity = "east"
+	GravityWest      Gravity = "west"
+	GravityNorthEast Gravity = "northeast"
+	GravityNorthWest Gravity = "northwest"
+	GravitySouthEast Gravity = "southeast"
+	GravitySouthWest Gravity = "southwest"
+)
+
+// ParseGravity validates a user supplied gravity. An empty name means
+// GravityCenter.
+func ParseGravity(name string) (Gravity, error) {
+	switch gravity := Gravity(strings.ToLower(strings.TrimSpace(name))); gravity {
+	case "":
+		return GravityCenter, nil
+	case GravityCenter, GravityNorth, GravitySouth, GravityEast, GravityWest,
+		GravityNorthEast, GravityNorthWest, GravitySouthEast, GravitySouthWest:
+		return gravity, nil
+	default:
+		return "", fmt.Errorf("%w: unsupported gravity %q", customErr.ErrInvalidTransformation, name)
+	}
+}
+
+func (g Gravity) valid() bool {
+	_, err := ParseGravity(string(g))
+	return err == nil
+}
+
+// place returns the top left corner of an inner area of the given size
+// anchored inside outer, kept margin pixels away from the anchored edges.
+func (g Gravity) place(outer image.Rectangle, inner image.Point, margin int) image.Point {
+	x := outer.Min.X + (outer.Dx()-inner.X)/2
+	y := outer.Min.Y + (outer.Dy()-inner.Y)/2
+
+	name := string(g)
+	if strings.HasSuffix(name, "west") {
+		x = outer.Min.X + margin
+	}
+	if strings.HasSuffix(name, "east") {
+		x = outer.Max.X - inner.X - margin
+	}
+	if strings.HasPrefix(name, "north") {
+		y = outer.Min.Y + margin
+	}
+	if strings.HasPrefix(name, "south") {
+		y = outer.Max.Y - inner.Y - margin
+	}
+
+	return image.Pt(x, y)
+}
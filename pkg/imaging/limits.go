@@ -0,0 +1,279 @@
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"sync"
+	"time"
+
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+)
+
+// Limits bounds what a single image may cost to decode. Every limit except the
+// timeout is checked against the header before any pixel is decoded, so a
+// small file declaring a huge canvas is rejected cheaply. Zero disables a
+// limit.
+type Limits struct {
+	MaxWidth  int
+	MaxHeight int
+	// MaxPixels caps the area of one frame.
+	MaxPixels int64
+	MaxFrames int
+	// MaxMemory caps the estimated size of all decoded frames in bytes, both
+	// of one image and of all images being decoded at once. Decodes wait for
+	// memory to be free within their timeout.
+	MaxMemory     int64
+	DecodeTimeout time.Duration
+}
+
+// DefaultLimits are in effect until SetLimits is called.
+var DefaultLimits = Limits{
+	MaxWidth:      16384,
+	MaxHeight:     16384,
+	MaxPixels:     100_000_000,
+	MaxFrames:     1000,
+	MaxMemory:     1 << 30,
+	DecodeTimeout: 30 * time.Second,
+}
+
+var limits = struct {
+	sync.RWMutex
+	Limits
+}{Limits: DefaultLimits}
+
+// SetLimits replaces the limits applied by Transform, Decode and Check.
+func SetLimits(l Limits) {
+	limits.Lock()
+	defer limits.Unlock()
+
+	limits.Limits = l
+}
+
+// CurrentLimits returns the limits in effect.
+func CurrentLimits() Limits {
+	limits.RLock()
+	defer limits.RUnlock()
+
+	return limits.Limits
+}
+
+// Info describes an encoded image from its header and block structure,
+// without decoding its pixels.
+type Info struct {
+	Format   Format
+	Config   image.Config
+	Frames   int
+	Metadata Metadata
+}
+
+// DecodedSize estimates the memory the decoded frames take: four bytes per
+// pixel, or eight for 16 bit color models.
+func (i Info) DecodedSize() int64 {
+	bytesPerPixel := int64(4)
+	switch i.Config.ColorModel {
+	case color.RGBA64Model, color.NRGBA64Model, color.Gray16Model:
+		bytesPerPixel = 8
+	}
+
+	return int64(i.Config.Width) * int64(i.Config.Height) * int64(max(i.Frames, 1)) * bytesPerPixel
+}
+
+// Check reports the first limit info exceeds, wrapping the matching error of
+// pkg/error.
+func (l Limits) Check(info Info) error {
+	width, height := info.Config.Width, info.Config.Height
+
+	if (l.MaxWidth > 0 && width > l.MaxWidth) || (l.MaxHeight > 0 && height > l.MaxHeight) {
+		return fmt.Errorf("%w: %dx%d is larger than %dx%d", customErr.ErrImageDimensionsTooLarge, width, height, l.MaxWidth, l.MaxHeight)
+	}
+
+	if l.MaxPixels > 0 && int64(width)*int64(height) > l.MaxPixels {
+		return fmt.Errorf("%w: %dx%d is more than %d pixels", customErr.ErrImageTooManyPixels, width, height, l.MaxPixels)
+	}
+
+	if l.MaxFrames > 0 && info.Frames > l.MaxFrames {
+		return fmt.Errorf("%w: %d frames is more than %d", customErr.ErrImageTooManyFrames, info.Frames, l.MaxFrames)
+	}
+
+	if size := info.DecodedSize(); l.MaxMemory > 0 && size > l.MaxMemory {
+		return fmt.Errorf("%w: decoding needs about %d bytes, the budget is %d", customErr.ErrMemoryBudgetExceeded, size, l.MaxMemory)
+	}
+
+	return nil
+}
+
+// Inspect reads r to the end and describes the image. The metadata is looked
+// for in the first metadataScanSize bytes, where formats put it.
+func Inspect(r io.Reader) (Info, error) {
+	codec, rr, err := sniffReader(r)
+	if err != nil {
+		return Info{}, err
+	}
+
+	head := &headBuffer{limit: metadataScanSize}
+	tee := io.TeeReader(rr, head)
+
+	info := Info{Format: codec.Format, Frames: 1}
+	if codec.CountFrames != nil {
+		// counting walks the whole stream, so the header is decoded from the
+		// copy kept on the way
+		if info.Frames, err = codec.CountFrames(tee); err != nil {
+			return Info{}, fmt.Errorf("failed to decode image: %v", err)
+		}
+		info.Config, err = codec.DecodeConfig(bytes.NewReader(head.Bytes()))
+	} else {
+		info.Config, err = codec.DecodeConfig(tee)
+	}
+	if err != nil {
+		return Info{}, fmt.Errorf("failed to decode image config: %v", err)
+	}
+
+	// drain the rest so callers streaming r elsewhere never block
+	if _, err := io.Copy(head, rr); err != nil {
+		return Info{}, fmt.Errorf("failed to decode image: %v", err)
+	}
+
+	if codec.ReadMetadata != nil {
+		info.Metadata = codec.ReadMetadata(head.Bytes())
+	}
+
+	return info, nil
+}
+
+// metadataScanSize is how much of a stream Inspect keeps to look for
+// metadata.
+const metadataScanSize = 1 << 20
+
+// headBuffer keeps the first limit bytes written to it and discards the rest.
+type headBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (h *headBuffer) Write(p []byte) (int, error) {
+	if room := h.limit - h.Len(); room > 0 {
+		h.Buffer.Write(p[:min(room, len(p))])
+	}
+
+	return len(p), nil
+}
+
+// decodeCost is what decoding an image checked by readLimited may take.
+type decodeCost struct {
+	timeout time.Duration
+	// memory is the estimated size of the decoded frames, taken from budget.
+	memory int64
+	budget int64
+}
+
+// readLimited reads the encoded image and checks it against the current
+// limits before anything is decoded. It returns the cost to decode it with.
+func readLimited(r io.Reader) ([]byte, decodeCost, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, decodeCost{}, fmt.Errorf("failed to decode image: %v", err)
+	}
+
+	info, err := Inspect(bytes.NewReader(data))
+	if err != nil {
+		return nil, decodeCost{}, err
+	}
+
+	l := CurrentLimits()
+	if err := l.Check(info); err != nil {
+		return nil, decodeCost{}, err
+	}
+
+	return data, decodeCost{timeout: l.DecodeTimeout, memory: info.DecodedSize(), budget: l.MaxMemory}, nil
+}
+
+// decoding tracks the memory of the decoders running, including those given
+// up on after their timeout, which keep their memory until they return.
+var decoding = struct {
+	sync.Mutex
+	used int64
+	// freed is closed when memory is released.
+	freed chan struct{}
+}{}
+
+// reserve waits until the memory of a decode fits in budget next to the
+// decoders running, or until expired fires. A decode is always let through
+// when nothing else is decoding.
+func reserve(memory int64, budget int64, expired <-chan time.Time) bool {
+	for {
+		decoding.Lock()
+		if decoding.used == 0 || decoding.used+memory <= budget {
+			decoding.used += memory
+			decoding.Unlock()
+			return true
+		}
+
+		if decoding.freed == nil {
+			decoding.freed = make(chan struct{})
+		}
+		freed := decoding.freed
+		decoding.Unlock()
+
+		select {
+		case <-freed:
+		case <-expired:
+			return false
+		}
+	}
+}
+
+func release(memory int64) {
+	decoding.Lock()
+	defer decoding.Unlock()
+
+	decoding.used -= memory
+	if decoding.freed != nil {
+		close(decoding.freed)
+		decoding.freed = nil
+	}
+}
+
+// limitedDecode runs decode once its memory fits in the budget and gives up
+// after the timeout, which includes the wait. The decoder cannot be
+// interrupted, so it finishes in the background holding its share of the
+// budget, and its result is dropped.
+func limitedDecode[T any](cost decodeCost, decode func() (T, error)) (T, error) {
+	var zero T
+	var expired <-chan time.Time
+	if cost.timeout > 0 {
+		timer := time.NewTimer(cost.timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	if cost.budget > 0 {
+		if !reserve(cost.memory, cost.budget, expired) {
+			return zero, fmt.Errorf("%w: no decoding memory was free within %s", customErr.ErrDecodeTimeout, cost.timeout)
+		}
+	}
+
+	type result struct {
+		value T
+		err   error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		if cost.budget > 0 {
+			defer release(cost.memory)
+		}
+
+		value, err := decode()
+		done <- result{value: value, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.value, res.err
+	case <-expired:
+		return zero, fmt.Errorf("%w: gave up after %s", customErr.ErrDecodeTimeout, cost.timeout)
+	}
+}
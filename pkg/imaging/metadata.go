@@ -0,0 +1,359 @@
+package imaging
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"slices"
+
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+)
+
+// Metadata holds the raw metadata blocks of an encoded image. EXIF is the
+// TIFF structured block, XMP the XML packet and ICC the color profile; each is
+// nil when the image has none.
+type Metadata struct {
+	EXIF []byte
+	XMP  []byte
+	ICC  []byte
+}
+
+// ParseEXIF parses the EXIF block, returning the defaults when there is none
+// or it is malformed.
+func (m Metadata) ParseEXIF() EXIF {
+	exif, _ := ParseEXIF(m.EXIF)
+	return exif
+}
+
+// MetadataPolicy decides which metadata blocks of the source are written to a
+// transformed image. The pixels are always turned upright first, so a kept
+// EXIF block has its orientation reset to normal.
+type MetadataPolicy string
+
+const (
+	// MetadataStrip drops every block. It is also what an empty policy does,
+	// so derived images never leak camera or location details by default.
+	MetadataStrip MetadataPolicy = "strip"
+	// MetadataKeepICC keeps only the color profile, which the pixels still
+	// depend on because decoding does not convert them to sRGB.
+	MetadataKeepICC MetadataPolicy = "keep_icc"
+	// MetadataKeep keeps the EXIF, XMP and ICC blocks.
+	MetadataKeep MetadataPolicy = "keep"
+)
+
+func (p MetadataPolicy) valid() bool {
+	switch p {
+	case "", MetadataStrip, MetadataKeepICC, MetadataKeep:
+		return true
+	default:
+		return false
+	}
+}
+
+// filter returns the blocks of m the policy keeps.
+func (p MetadataPolicy) filter(m Metadata) Metadata {
+	switch p {
+	case MetadataKeep:
+		if m.EXIF != nil {
+			m.EXIF = resetOrientation(m.EXIF)
+		}
+		return m
+	case MetadataKeepICC:
+		return Metadata{ICC: m.ICC}
+	default:
+		return Metadata{}
+	}
+}
+
+func (m Metadata) empty() bool {
+	return m.EXIF == nil && m.XMP == nil && m.ICC == nil
+}
+
+// ReadMetadata reads the metadata blocks of an image in any registered format
+// that carries them. A truncated image yields the blocks found before the end,
+// so the leading part of a large file is enough.
+func ReadMetadata(r io.Reader) (Metadata, Format, error) {
+	codec, rr, err := sniffReader(r)
+	if err != nil {
+		return Metadata{}, "", err
+	}
+
+	if codec.ReadMetadata == nil {
+		return Metadata{}, codec.Format, nil
+	}
+
+	data, err := io.ReadAll(rr)
+	if err != nil {
+		return Metadata{}, "", fmt.Errorf("failed to read image metadata: %v", err)
+	}
+
+	return codec.ReadMetadata(data), codec.Format, nil
+}
+
+// writeMetadata runs encode and embeds meta in its output when the codec can
+// hold metadata; other formats are written without it.
+func writeMetadata(w io.Writer, codec Codec, meta Metadata, encode func(io.Writer) error) error {
+	if meta.empty() || codec.WriteMetadata == nil {
+		return encode(w)
+	}
+
+	var buf bytes.Buffer
+	if err := encode(&buf); err != nil {
+		return err
+	}
+
+	out, err := codec.WriteMetadata(buf.Bytes(), meta)
+	if err != nil {
+		return fmt.Errorf("%w: cannot embed metadata: %v", customErr.ErrInvalidTransformation, err)
+	}
+
+	_, err = w.Write(out)
+	return err
+}
+
+const (
+	jpegEXIFHeader = "Exif\x00\x00"
+	jpegXMPHeader  = "http://ns.adobe.com/xap/1.0/\x00"
+	jpegICCHeader  = "ICC_PROFILE\x00"
+
+	// jpegMaxSegment is the largest payload of a JPEG marker segment.
+	jpegMaxSegment = 0xffff - 2
+	pngXMPKeyword  = "XML:com.adobe.xmp"
+)
+
+// readJPEGMetadata walks the marker segments before the first scan.
+func readJPEGMetadata(data []byte) Metadata {
+	var meta Metadata
+	icc := map[byte][]byte{}
+
+	for pos := 2; pos+4 <= len(data) && data[pos] == 0xff; {
+		marker := data[pos+1]
+		if marker == 0xd8 || marker == 0x01 || (marker >= 0xd0 && marker <= 0xd7) {
+			pos += 2
+			continue
+		}
+		if marker == 0xda || marker == 0xd9 {
+			break
+		}
+
+		end := pos + 2 + int(binary.BigEndian.Uint16(data[pos+2:]))
+		if end > len(data) {
+			break
+		}
+		payload := data[pos+4 : end]
+		pos = end
+
+		switch {
+		case marker == 0xe1 && bytes.HasPrefix(payload, []byte(jpegEXIFHeader)):
+			meta.EXIF = slices.Clone(payload[len(jpegEXIFHeader):])
+		case marker == 0xe1 && bytes.HasPrefix(payload, []byte(jpegXMPHeader)):
+			meta.XMP = slices.Clone(payload[len(jpegXMPHeader):])
+		case marker == 0xe2 && bytes.HasPrefix(payload, []byte(jpegICCHeader)) && len(payload) > len(jpegICCHeader)+2:
+			// profiles larger than a segment are split into numbered chunks
+			icc[payload[len(jpegICCHeader)]] = payload[len(jpegICCHeader)+2:]
+		}
+	}
+
+	for seq := byte(1); icc[seq] != nil; seq++ {
+		meta.ICC = append(meta.ICC, icc[seq]...)
+	}
+
+	return meta
+}
+
+// writeJPEGMetadata inserts the metadata segments right after the SOI marker.
+func writeJPEGMetadata(data []byte, meta Metadata) ([]byte, error) {
+	if len(data) < 2 || data[0] != 0xff || data[1] != 0xd8 {
+		return nil, fmt.Errorf("not a JPEG stream")
+	}
+
+	var segments bytes.Buffer
+	segment := func(marker byte, parts ...[]byte) error {
+		n := 0
+		for _, part := range parts {
+			n += len(part)
+		}
+		if n > jpegMaxSegment {
+			return fmt.Errorf("metadata block of %d bytes does not fit a segment", n)
+		}
+
+		segments.Write([]byte{0xff, marker})
+		binary.Write(&segments, binary.BigEndian, uint16(n+2))
+		for _, part := range parts {
+			segments.Write(part)
+		}
+		return nil
+	}
+
+	if meta.EXIF != nil {
+		if err := segment(0xe1, []byte(jpegEXIFHeader), meta.EXIF); err != nil {
+			return nil, err
+		}
+	}
+
+	if meta.XMP != nil {
+		if err := segment(0xe1, []byte(jpegXMPHeader), meta.XMP); err != nil {
+			return nil, err
+		}
+	}
+
+	if meta.ICC != nil {
+		chunk := jpegMaxSegment - len(jpegICCHeader) - 2
+		chunks := (len(meta.ICC) + chunk - 1) / chunk
+		if chunks > 255 {
+			return nil, fmt.Errorf("color profile of %d bytes is too large", len(meta.ICC))
+		}
+
+		for seq := range chunks {
+			part := meta.ICC[seq*chunk : min((seq+1)*chunk, len(meta.ICC))]
+			if err := segment(0xe2, []byte(jpegICCHeader), []byte{byte(seq + 1), byte(chunks)}, part); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return slices.Concat(data[:2], segments.Bytes(), data[2:]), nil
+}
+
+// readPNGMetadata reads the eXIf, iTXt XMP and iCCP chunks.
+func readPNGMetadata(data []byte) Metadata {
+	var meta Metadata
+
+	for pos := 8; pos+8 <= len(data); {
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		kind := string(data[pos+4 : pos+8])
+		if length < 0 || pos+12+length > len(data) {
+			break
+		}
+		chunk := data[pos+8 : pos+8+length]
+		pos += 12 + length
+
+		switch kind {
+		case "eXIf":
+			meta.EXIF = slices.Clone(chunk)
+		case "iCCP":
+			// profile name, compression method, then the zlib stream
+			if _, rest, ok := bytes.Cut(chunk, []byte{0}); ok && len(rest) > 1 {
+				if icc, err := inflate(rest[1:]); err == nil {
+					meta.ICC = icc
+				}
+			}
+		case "iTXt":
+			keyword, rest, ok := bytes.Cut(chunk, []byte{0})
+			if !ok || string(keyword) != pngXMPKeyword || len(rest) < 2 {
+				continue
+			}
+			compressed := rest[0] == 1
+			// skip the compression method, language tag and translated keyword
+			_, rest, _ = bytes.Cut(rest[2:], []byte{0})
+			_, text, ok := bytes.Cut(rest, []byte{0})
+			if !ok {
+				continue
+			}
+			if compressed {
+				if xmp, err := inflate(text); err == nil {
+					meta.XMP = xmp
+				}
+			} else {
+				meta.XMP = slices.Clone(text)
+			}
+		case "IEND":
+			return meta
+		}
+	}
+
+	return meta
+}
+
+// writePNGMetadata inserts the metadata chunks right after IHDR, which puts
+// them before PLTE and IDAT as the PNG specification requires.
+func writePNGMetadata(data []byte, meta Metadata) ([]byte, error) {
+	const ihdrEnd = 8 + 12 + 13
+	if len(data) < ihdrEnd || string(data[12:16]) != "IHDR" {
+		return nil, fmt.Errorf("not a PNG stream")
+	}
+
+	var chunks bytes.Buffer
+	chunk := func(kind string, parts ...[]byte) {
+		body := slices.Concat(parts...)
+		binary.Write(&chunks, binary.BigEndian, uint32(len(body)))
+		crc := crc32.NewIEEE()
+		crc.Write([]byte(kind))
+		crc.Write(body)
+		chunks.WriteString(kind)
+		chunks.Write(body)
+		binary.Write(&chunks, binary.BigEndian, crc.Sum32())
+	}
+
+	if meta.ICC != nil {
+		var profile bytes.Buffer
+		zw := zlib.NewWriter(&profile)
+		zw.Write(meta.ICC)
+		zw.Close()
+		chunk("iCCP", []byte("icc\x00\x00"), profile.Bytes())
+	}
+
+	if meta.EXIF != nil {
+		chunk("eXIf", meta.EXIF)
+	}
+
+	if meta.XMP != nil {
+		// uncompressed, with empty language tag and translated keyword
+		chunk("iTXt", []byte(pngXMPKeyword+"\x00\x00\x00\x00\x00"), meta.XMP)
+	}
+
+	return slices.Concat(data[:ihdrEnd], chunks.Bytes(), data[ihdrEnd:]), nil
+}
+
+// readWebPMetadata reads the EXIF, XMP and ICCP chunks of a RIFF container.
+func readWebPMetadata(data []byte) Metadata {
+	var meta Metadata
+
+	for pos := 12; pos+8 <= len(data); {
+		kind := string(data[pos : pos+4])
+		length := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		if length < 0 || pos+8+length > len(data) {
+			break
+		}
+		chunk := data[pos+8 : pos+8+length]
+		// chunks are padded to an even size
+		pos += 8 + length + length&1
+
+		switch kind {
+		case "EXIF":
+			// some writers keep the JPEG style header
+			meta.EXIF = slices.Clone(bytes.TrimPrefix(chunk, []byte(jpegEXIFHeader)))
+		case "XMP ":
+			meta.XMP = slices.Clone(chunk)
+		case "ICCP":
+			meta.ICC = slices.Clone(chunk)
+		}
+	}
+
+	return meta
+}
+
+// maxInflatedMetadata bounds a compressed PNG metadata block once inflated, so
+// a few bytes of zlib stream cannot expand into gigabytes.
+const maxInflatedMetadata = 4 << 20
+
+func inflate(data []byte) ([]byte, error) {
+	zr, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	out, err := io.ReadAll(io.LimitReader(zr, maxInflatedMetadata+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(out) > maxInflatedMetadata {
+		return nil, fmt.Errorf("metadata block exceeds %d bytes", maxInflatedMetadata)
+	}
+
+	return out, nil
+}
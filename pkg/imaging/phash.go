@@ -0,0 +1,92 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"math/bits"
+	"slices"
+)
+
+// PerceptualHash is a 64 bit pHash of an image's appearance. Images that look
+// alike, whatever their size, format or compression, have hashes a small
+// Hamming distance apart.
+type PerceptualHash uint64
+
+// Distance returns the number of bits in which h and other differ.
+func (h PerceptualHash) Distance(other PerceptualHash) int {
+	return bits.OnesCount64(uint64(h ^ other))
+}
+
+func (h PerceptualHash) String() string {
+	return fmt.Sprintf("%016x", uint64(h))
+}
+
+const (
+	// phashSample is the side of the gray grid the image is shrunk to.
+	phashSample = 32
+	// phashFrequencies is the side of the block of lowest DCT frequencies
+	// that make up the hash.
+	phashFrequencies = 8
+)
+
+// HashImage computes the pHash of img. The image is shrunk to a 32x32 gray
+// grid and turned into frequencies with a discrete cosine transform; each of
+// the 8x8 lowest frequencies sets its bit when it is above their median, so
+// the hash follows the coarse structure and ignores fine detail and overall
+// brightness.
+func HashImage(img image.Image) PerceptualHash {
+	small := resample(img, phashSample, phashSample, linearFilter)
+
+	var gray [phashSample][phashSample]float64
+	for y := range phashSample {
+		for x := range phashSample {
+			i := small.PixOffset(x, y)
+			gray[y][x] = luma(float64(small.Pix[i]), float64(small.Pix[i+1]), float64(small.Pix[i+2]))
+		}
+	}
+
+	var cosines [phashFrequencies][phashSample]float64
+	for u := range phashFrequencies {
+		for x := range phashSample {
+			cosines[u][x] = math.Cos(float64(2*x+1) * float64(u) * math.Pi / (2 * phashSample))
+		}
+	}
+
+	// the transform is separable: rows first, then the columns of the
+	// frequencies kept
+	var rows [phashSample][phashFrequencies]float64
+	for y := range phashSample {
+		for u := range phashFrequencies {
+			for x := range phashSample {
+				rows[y][u] += cosines[u][x] * gray[y][x]
+			}
+		}
+	}
+
+	coefficients := make([]float64, 0, phashFrequencies*phashFrequencies)
+	for v := range phashFrequencies {
+		for u := range phashFrequencies {
+			var sum float64
+			for y := range phashSample {
+				sum += cosines[v][y] * rows[y][u]
+			}
+			coefficients = append(coefficients, sum)
+		}
+	}
+
+	// the first coefficient is the average brightness, which would skew the
+	// median
+	sorted := slices.Clone(coefficients[1:])
+	slices.Sort(sorted)
+	median := sorted[len(sorted)/2]
+
+	var hash PerceptualHash
+	for i, c := range coefficients {
+		if c > median {
+			hash |= 1 << (63 - i)
+		}
+	}
+
+	return hash
+}
@@ -0,0 +1,470 @@
+package imaging
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"math"
+	"strings"
+
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+)
+
+// MaxDimension caps the width and height a transformation may produce.
+const MaxDimension = 8192
+
+// Fit controls how a resize with both dimensions treats the aspect ratio.
+type Fit string
+
+const (
+	// FitFill stretches the image to exactly width x height.
+	FitFill Fit = "fill"
+	// FitInside scales the image to the largest size that fits within
+	// width x height while keeping its aspect ratio.
+	FitInside Fit = "inside"
+	// FitOutside scales the image to the smallest size that covers
+	// width x height while keeping its aspect ratio.
+	FitOutside Fit = "outside"
+	// FitCover scales like FitOutside, then crops the overflow so the result
+	// is exactly width x height.
+	FitCover Fit = "cover"
+	// FitContain scales like FitInside, then letterboxes the image onto an
+	// exactly width x height canvas.
+	FitContain Fit = "contain"
+)
+
+// ParseFit validates a user supplied fit mode. An empty name means FitFill.
+func ParseFit(name string) (Fit, error) {
+	switch fit := Fit(strings.ToLower(strings.TrimSpace(name))); fit {
+	case "":
+		return FitFill, nil
+	case FitFill, FitInside, FitOutside, FitCover, FitContain:
+		return fit, nil
+	default:
+		return "", fmt.Errorf("%w: unsupported fit %q", customErr.ErrInvalidTransformation, name)
+	}
+}
+
+// Focal is a point of interest given as fractions of the image width and
+// height, so (0.5, 0.5) is the center.
+type Focal struct {
+	X float64
+	Y float64
+}
+
+// Resize scales the image. Gravity anchors the area FitCover keeps and where
+// FitContain places the image; when it is empty a cover crop is centered on
+// Focal if set, and both modes otherwise anchor to the center. Background
+// fills the FitContain letterbox.
+type Resize struct {
+	Width      int
+	Height     int
+	Fit        Fit
+	Gravity    Gravity
+	Focal      *Focal
+	Background color.NRGBA
+	Filter     Filter
+}
+
+type Crop struct {
+	X      int
+	Y      int
+	Width  int
+	Height int
+}
+
+// Options describes the operations to apply. They always run in the order
+// crop, resize, rotate, flip, flop, adjust, blur, sharpen, watermark, convert
+// regardless of how the request listed them, so crop coordinates refer to the
+// original image. Blur is the gaussian sigma in pixels, zero for none. Encode
+// tunes the encoder of the output format.
+//
+// Animated sources are transformed frame by frame when the output format can
+// hold an animation. Frame instead extracts the frame at that zero based
+// index as a still before the other operations run.
+//
+// Stills are turned upright according to their EXIF orientation when they
+// are decoded, and Metadata decides which of the source's metadata blocks are
+// written to the output.
+type Options struct {
+	Frame     *int
+	Crop      *Crop
+	Resize    *Resize
+	Rotate    *Rotate
+	Flip      bool
+	Flop      bool
+	Adjust    Adjust
+	Blur      float64
+	Sharpen   *Sharpen
+	Watermark *Watermark
+	Format    Format
+	Encode    EncodeOptions
+	Metadata  MetadataPolicy
+}
+
+// Validate checks the options that can be verified without the source image.
+func (o Options) Validate() error {
+	if o.Crop == nil && o.Resize == nil && o.Rotate == nil && !o.Flip && !o.Flop &&
+		o.Adjust.isZero() && o.Blur == 0 && o.Sharpen == nil && o.Watermark == nil && o.Format == "" &&
+		o.Encode == (EncodeOptions{}) && o.Frame == nil && o.Metadata == "" {
+		return fmt.Errorf("%w: no transformation requested", customErr.ErrInvalidTransformation)
+	}
+
+	if o.Frame != nil && *o.Frame < 0 {
+		return fmt.Errorf("%w: frame must not be negative", customErr.ErrInvalidTransformation)
+	}
+
+	if c := o.Crop; c != nil {
+		if c.X < 0 || c.Y < 0 || c.Width <= 0 || c.Height <= 0 {
+			return fmt.Errorf("%w: crop must have a non-negative origin and positive size", customErr.ErrInvalidTransformation)
+		}
+	}
+
+	if r := o.Resize; r != nil {
+		if r.Width < 0 || r.Height < 0 || (r.Width == 0 && r.Height == 0) {
+			return fmt.Errorf("%w: resize needs a positive width or height", customErr.ErrInvalidTransformation)
+		}
+
+		if r.Width > MaxDimension || r.Height > MaxDimension {
+			return fmt.Errorf("%w: resize dimensions cannot exceed %d", customErr.ErrInvalidTransformation, MaxDimension)
+		}
+
+		if _, err := ParseFit(string(r.Fit)); err != nil {
+			return err
+		}
+
+		if r.Gravity != "" && !r.Gravity.valid() {
+			return fmt.Errorf("%w: unsupported gravity %q", customErr.ErrInvalidTransformation, r.Gravity)
+		}
+
+		if _, ok := r.Filter.filter(); !ok {
+			return fmt.Errorf("%w: unsupported filter %q", customErr.ErrInvalidTransformation, r.Filter)
+		}
+
+		if f := r.Focal; f != nil && (!between(f.X, 0, 1) || !between(f.Y, 0, 1)) {
+			return fmt.Errorf("%w: focal point must be between 0 and 1", customErr.ErrInvalidTransformation)
+		}
+	}
+
+	if r := o.Rotate; r != nil && (math.IsNaN(r.Angle) || math.IsInf(r.Angle, 0)) {
+		return fmt.Errorf("%w: rotate angle must be a finite number", customErr.ErrInvalidTransformation)
+	}
+
+	if !between(o.Blur, 0, MaxSigma) {
+		return fmt.Errorf("%w: blur must be between 0 and %d", customErr.ErrInvalidTransformation, MaxSigma)
+	}
+
+	if s := o.Sharpen; s != nil {
+		if s.Sigma <= 0 || !between(s.Sigma, 0, MaxSigma) {
+			return fmt.Errorf("%w: sharpen sigma must be greater than 0 and at most %d", customErr.ErrInvalidTransformation, MaxSigma)
+		}
+
+		if !between(s.Amount, 0, 10) || !between(s.Threshold, 0, 255) {
+			return fmt.Errorf("%w: sharpen amount must be between 0 and 10 and threshold between 0 and 255", customErr.ErrInvalidTransformation)
+		}
+	}
+
+	a := o.Adjust
+	if !between(a.Brightness, -100, 100) || !between(a.Contrast, -100, 100) || !between(a.Saturation, -100, 100) {
+		return fmt.Errorf("%w: brightness, contrast and saturation must be between -100 and 100", customErr.ErrInvalidTransformation)
+	}
+
+	if a.Gamma != 0 && !between(a.Gamma, 0.1, 10) {
+		return fmt.Errorf("%w: gamma must be between 0.1 and 10", customErr.ErrInvalidTransformation)
+	}
+
+	if o.Watermark != nil {
+		if err := o.Watermark.validate(); err != nil {
+			return err
+		}
+	}
+
+	if o.Format != "" && !o.Format.Encodable() {
+		return fmt.Errorf("%w: %s can be read but not written", customErr.ErrInvalidTransformation, o.Format)
+	}
+
+	if !o.Metadata.valid() {
+		return fmt.Errorf("%w: unsupported metadata policy %q", customErr.ErrInvalidTransformation, o.Metadata)
+	}
+
+	return o.Encode.validate()
+}
+
+// Apply runs every step except the conversion on img.
+func Apply(img image.Image, opts Options) (image.Image, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	if opts.Crop != nil {
+		cropped, err := crop(img, *opts.Crop)
+		if err != nil {
+			return nil, err
+		}
+		img = cropped
+	}
+
+	if opts.Resize != nil {
+		resized, err := resize(img, *opts.Resize)
+		if err != nil {
+			return nil, err
+		}
+		img = resized
+	}
+
+	if opts.Rotate != nil {
+		rotated, err := rotate(img, *opts.Rotate)
+		if err != nil {
+			return nil, err
+		}
+		img = rotated
+	}
+
+	if opts.Flip {
+		img = flip(img)
+	}
+
+	if opts.Flop {
+		img = flop(img)
+	}
+
+	if !opts.Adjust.isZero() {
+		img = adjust(img, opts.Adjust)
+	}
+
+	if opts.Blur > 0 {
+		img = blur(img, opts.Blur)
+	}
+
+	if opts.Sharpen != nil {
+		img = sharpen(img, *opts.Sharpen)
+	}
+
+	if opts.Watermark != nil {
+		watermarked, err := watermark(img, *opts.Watermark)
+		if err != nil {
+			return nil, err
+		}
+		img = watermarked
+	}
+
+	return img, nil
+}
+
+// Transform decodes the image read from r, applies opts and encodes the result
+// to w. Without an output format the source format is kept, or PNG is used
+// when the source format cannot be written. It returns the format that was
+// written.
+func Transform(r io.Reader, w io.Writer, opts Options) (Format, error) {
+	if err := opts.Validate(); err != nil {
+		return "", err
+	}
+
+	codec, rr, err := sniffReader(r)
+	if err != nil {
+		return "", err
+	}
+
+	format := codec.Format
+	if opts.Format != "" {
+		format = opts.Format
+	} else if !format.Encodable() {
+		format = FormatPNG
+	}
+	output, _ := LookupCodec(string(format))
+
+	data, cost, err := readLimited(rr)
+	if err != nil {
+		return "", err
+	}
+
+	var img image.Image
+	var meta Metadata
+	if codec.DecodeAnimation != nil {
+		anim, err := limitedDecode(cost, func() (*Animation, error) {
+			return codec.DecodeAnimation(bytes.NewReader(data))
+		})
+		if err != nil {
+			return "", decodeError(err)
+		}
+
+		if opts.Frame == nil && len(anim.Frames) > 1 && output.EncodeAnimation != nil {
+			if anim, err = transformAnimation(anim, opts); err != nil {
+				return "", err
+			}
+
+			if err := output.EncodeAnimation(w, anim, opts.Encode); err != nil {
+				return "", err
+			}
+
+			return format, nil
+		}
+
+		if img, err = frame(anim.Frames, opts.Frame); err != nil {
+			return "", err
+		}
+	} else {
+		if img, meta, err = decodeStill(codec, data, cost); err != nil {
+			return "", decodeError(err)
+		}
+
+		if img, err = frame([]image.Image{img}, opts.Frame); err != nil {
+			return "", err
+		}
+	}
+
+	img, err = Apply(img, opts)
+	if err != nil {
+		return "", err
+	}
+
+	err = writeMetadata(w, output, opts.Metadata.filter(meta), func(w io.Writer) error {
+		return Encode(w, img, format, opts.Encode)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return format, nil
+}
+
+// frame picks the frame at index, or the first one when index is nil.
+func frame(frames []image.Image, index *int) (image.Image, error) {
+	i := 0
+	if index != nil {
+		i = *index
+	}
+
+	if i >= len(frames) {
+		return nil, fmt.Errorf("%w: frame %d is out of range, the image has %d", customErr.ErrInvalidTransformation, i, len(frames))
+	}
+
+	return frames[i], nil
+}
+
+// decodeError keeps the errors raised while decoding that callers act on,
+// such as the animation size limit or the timeout, and hides the decoder's
+// own.
+func decodeError(err error) error {
+	if errors.Is(err, customErr.ErrInvalidTransformation) || errors.Is(err, customErr.ErrDecodeTimeout) {
+		return err
+	}
+
+	return fmt.Errorf("failed to decode image: %v", err)
+}
+
+func crop(img image.Image, c Crop) (image.Image, error) {
+	bounds := img.Bounds()
+	rect := image.Rect(c.X, c.Y, c.X+c.Width, c.Y+c.Height).Add(bounds.Min)
+
+	if !rect.In(bounds) {
+		return nil, fmt.Errorf("%w: crop area is outside of the %dx%d image",
+			customErr.ErrInvalidTransformation, bounds.Dx(), bounds.Dy())
+	}
+
+	return toNRGBA(subImage(img, rect)), nil
+}
+
+func resize(img image.Image, r Resize) (image.Image, error) {
+	bounds := img.Bounds()
+	width, height := r.Width, r.Height
+	f, _ := r.Filter.filter()
+
+	// a missing dimension keeps the source aspect ratio, whatever the fit
+	if width == 0 || height == 0 {
+		if width == 0 {
+			width = int(math.Max(1, math.Round(float64(bounds.Dx())*float64(height)/float64(bounds.Dy()))))
+		}
+		if height == 0 {
+			height = int(math.Max(1, math.Round(float64(bounds.Dy())*float64(width)/float64(bounds.Dx()))))
+		}
+		r.Fit = FitFill
+	}
+
+	scaleX, scaleY := float64(width)/float64(bounds.Dx()), float64(height)/float64(bounds.Dy())
+
+	switch r.Fit {
+	case FitInside, FitContain:
+		width, height = scaled(bounds, math.Min(scaleX, scaleY))
+	case FitOutside:
+		width, height = scaled(bounds, math.Max(scaleX, scaleY))
+	case FitCover:
+		img = coverArea(img, r, math.Max(scaleX, scaleY))
+	}
+
+	if width > MaxDimension || height > MaxDimension {
+		return nil, fmt.Errorf("%w: resize dimensions cannot exceed %d", customErr.ErrInvalidTransformation, MaxDimension)
+	}
+
+	var resized image.Image = img
+	if width != img.Bounds().Dx() || height != img.Bounds().Dy() {
+		resized = resample(img, width, height, f)
+	}
+
+	if r.Fit == FitContain && (width != r.Width || height != r.Height) {
+		return letterbox(resized, r), nil
+	}
+
+	return resized, nil
+}
+
+// scaled returns the size of bounds multiplied by scale, at least 1x1.
+func scaled(bounds image.Rectangle, scale float64) (int, int) {
+	return int(math.Max(1, math.Round(float64(bounds.Dx())*scale))),
+		int(math.Max(1, math.Round(float64(bounds.Dy())*scale)))
+}
+
+// coverArea crops the part of img that a cover resize at scale keeps, so
+// only that part has to be resampled.
+func coverArea(img image.Image, r Resize, scale float64) image.Image {
+	bounds := img.Bounds()
+	size := image.Pt(
+		min(bounds.Dx(), max(1, int(math.Round(float64(r.Width)/scale)))),
+		min(bounds.Dy(), max(1, int(math.Round(float64(r.Height)/scale)))),
+	)
+
+	var origin image.Point
+	switch {
+	case r.Gravity == "" && r.Focal != nil:
+		origin = image.Pt(
+			bounds.Min.X+min(max(int(math.Round(r.Focal.X*float64(bounds.Dx())))-size.X/2, 0), bounds.Dx()-size.X),
+			bounds.Min.Y+min(max(int(math.Round(r.Focal.Y*float64(bounds.Dy())))-size.Y/2, 0), bounds.Dy()-size.Y),
+		)
+	default:
+		origin = cmp.Or(r.Gravity, GravityCenter).place(bounds, size, 0)
+	}
+
+	return subImage(img, image.Rectangle{Min: origin, Max: origin.Add(size)})
+}
+
+// letterbox centers img, or anchors it by gravity, on a Width x Height canvas
+// filled with the background color.
+func letterbox(img image.Image, r Resize) *image.NRGBA {
+	canvas := image.NewNRGBA(image.Rect(0, 0, r.Width, r.Height))
+	draw.Draw(canvas, canvas.Bounds(), image.NewUniform(r.Background), image.Point{}, draw.Src)
+
+	at := cmp.Or(r.Gravity, GravityCenter).place(canvas.Bounds(), img.Bounds().Size(), 0)
+	draw.Draw(canvas, image.Rectangle{Min: at, Max: at.Add(img.Bounds().Size())}, img, img.Bounds().Min, draw.Over)
+
+	return canvas
+}
+
+// between reports whether v lies in [lo, hi]; NaN never does.
+func between(v, lo, hi float64) bool {
+	return v >= lo && v <= hi
+}
+
+func subImage(img image.Image, rect image.Rectangle) image.Image {
+	if s, ok := img.(interface {
+		SubImage(r image.Rectangle) image.Image
+	}); ok {
+		return s.SubImage(rect)
+	}
+
+	return toNRGBA(img).SubImage(rect.Sub(img.Bounds().Min))
+}
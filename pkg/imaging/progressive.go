@@ -0,0 +1,428 @@
+package imaging
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"math"
+)
+
+// The standard library only writes baseline JPEGs, so progressive output is
+// produced here. It uses 4:4:4 sampling, the tables from Annex K of the JPEG
+// specification and spectral selection without successive approximation: a DC
+// scan followed by two AC bands per component, which is enough for browsers to
+// paint a coarse preview early.
+
+// unzig maps the zig-zag index of a coefficient to its natural index.
+var unzig = [64]int{
+	0, 1, 8, 16, 9, 2, 3, 10,
+	17, 24, 32, 25, 18, 11, 4, 5,
+	12, 19, 26, 33, 40, 48, 41, 34,
+	27, 20, 13, 6, 7, 14, 21, 28,
+	35, 42, 49, 56, 57, 50, 43, 36,
+	29, 22, 15, 23, 30, 37, 44, 51,
+	58, 59, 52, 45, 38, 31, 39, 46,
+	53, 60, 61, 54, 47, 55, 62, 63,
+}
+
+// unscaledQuant are the luminance and chrominance tables in zig-zag order.
+var unscaledQuant = [2][64]int{
+	{
+		16, 11, 12, 14, 12, 10, 16, 14,
+		13, 14, 18, 17, 16, 19, 24, 40,
+		26, 24, 22, 22, 24, 49, 35, 37,
+		29, 40, 58, 51, 61, 60, 57, 51,
+		56, 55, 64, 72, 92, 78, 64, 68,
+		87, 69, 55, 56, 80, 109, 81, 87,
+		95, 98, 103, 104, 103, 62, 77, 113,
+		121, 112, 100, 120, 92, 101, 103, 99,
+	},
+	{
+		17, 18, 18, 24, 21, 24, 47, 26,
+		26, 47, 99, 66, 56, 66, 99, 99,
+		99, 99, 99, 99, 99, 99, 99, 99,
+		99, 99, 99, 99, 99, 99, 99, 99,
+		99, 99, 99, 99, 99, 99, 99, 99,
+		99, 99, 99, 99, 99, 99, 99, 99,
+		99, 99, 99, 99, 99, 99, 99, 99,
+		99, 99, 99, 99, 99, 99, 99, 99,
+	},
+}
+
+type huffmanSpec struct {
+	counts [16]byte
+	values []byte
+}
+
+// huffmanSpecs are the luminance DC, luminance AC, chrominance DC and
+// chrominance AC tables. The AC tables contain EOB (0x00) and ZRL (0xf0),
+// the only end-of-band symbols the encoder emits.
+var huffmanSpecs = [4]huffmanSpec{
+	{
+		[16]byte{0, 1, 5, 1, 1, 1, 1, 1, 1, 0, 0, 0, 0, 0, 0, 0},
+		[]byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11},
+	},
+	{
+		[16]byte{0, 2, 1, 3, 3, 2, 4, 3, 5, 5, 4, 4, 0, 0, 1, 125},
+		[]byte{
+			0x01, 0x02, 0x03, 0x00, 0x04, 0x11, 0x05, 0x12,
+			0x21, 0x31, 0x41, 0x06, 0x13, 0x51, 0x61, 0x07,
+			0x22, 0x71, 0x14, 0x32, 0x81, 0x91, 0xa1, 0x08,
+			0x23, 0x42, 0xb1, 0xc1, 0x15, 0x52, 0xd1, 0xf0,
+			0x24, 0x33, 0x62, 0x72, 0x82, 0x09, 0x0a, 0x16,
+			0x17, 0x18, 0x19, 0x1a, 0x25, 0x26, 0x27, 0x28,
+			0x29, 0x2a, 0x34, 0x35, 0x36, 0x37, 0x38, 0x39,
+			0x3a, 0x43, 0x44, 0x45, 0x46, 0x47, 0x48, 0x49,
+			0x4a, 0x53, 0x54, 0x55, 0x56, 0x57, 0x58, 0x59,
+			0x5a, 0x63, 0x64, 0x65, 0x66, 0x67, 0x68, 0x69,
+			0x6a, 0x73, 0x74, 0x75, 0x76, 0x77, 0x78, 0x79,
+			0x7a, 0x83, 0x84, 0x85, 0x86, 0x87, 0x88, 0x89,
+			0x8a, 0x92, 0x93, 0x94, 0x95, 0x96, 0x97, 0x98,
+			0x99, 0x9a, 0xa2, 0xa3, 0xa4, 0xa5, 0xa6, 0xa7,
+			0xa8, 0xa9, 0xaa, 0xb2, 0xb3, 0xb4, 0xb5, 0xb6,
+			0xb7, 0xb8, 0xb9, 0xba, 0xc2, 0xc3, 0xc4, 0xc5,
+			0xc6, 0xc7, 0xc8, 0xc9, 0xca, 0xd2, 0xd3, 0xd4,
+			0xd5, 0xd6, 0xd7, 0xd8, 0xd9, 0xda, 0xe1, 0xe2,
+			0xe3, 0xe4, 0xe5, 0xe6, 0xe7, 0xe8, 0xe9, 0xea,
+			0xf1, 0xf2, 0xf3, 0xf4, 0xf5, 0xf6, 0xf7, 0xf8,
+			0xf9, 0xfa,
+		},
+	},
+	{
+		[16]byte{0, 3, 1, 1, 1, 1, 1, 1, 1, 1, 1, 0, 0, 0, 0, 0},
+		[]byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11},
+	},
+	{
+		[16]byte{0, 2, 1, 2, 4, 4, 3, 4, 7, 5, 4, 4, 0, 1, 2, 119},
+		[]byte{
+			0x00, 0x01, 0x02, 0x03, 0x11, 0x04, 0x05, 0x21,
+			0x31, 0x06, 0x12, 0x41, 0x51, 0x07, 0x61, 0x71,
+			0x13, 0x22, 0x32, 0x81, 0x08, 0x14, 0x42, 0x91,
+			0xa1, 0xb1, 0xc1, 0x09, 0x23, 0x33, 0x52, 0xf0,
+			0x15, 0x62, 0x72, 0xd1, 0x0a, 0x16, 0x24, 0x34,
+			0xe1, 0x25, 0xf1, 0x17, 0x18, 0x19, 0x1a, 0x26,
+			0x27, 0x28, 0x29, 0x2a, 0x35, 0x36, 0x37, 0x38,
+			0x39, 0x3a, 0x43, 0x44, 0x45, 0x46, 0x47, 0x48,
+			0x49, 0x4a, 0x53, 0x54, 0x55, 0x56, 0x57, 0x58,
+			0x59, 0x5a, 0x63, 0x64, 0x65, 0x66, 0x67, 0x68,
+			0x69, 0x6a, 0x73, 0x74, 0x75, 0x76, 0x77, 0x78,
+			0x79, 0x7a, 0x82, 0x83, 0x84, 0x85, 0x86, 0x87,
+			0x88, 0x89, 0x8a, 0x92, 0x93, 0x94, 0x95, 0x96,
+			0x97, 0x98, 0x99, 0x9a, 0xa2, 0xa3, 0xa4, 0xa5,
+			0xa6, 0xa7, 0xa8, 0xa9, 0xaa, 0xb2, 0xb3, 0xb4,
+			0xb5, 0xb6, 0xb7, 0xb8, 0xb9, 0xba, 0xc2, 0xc3,
+			0xc4, 0xc5, 0xc6, 0xc7, 0xc8, 0xc9, 0xca, 0xd2,
+			0xd3, 0xd4, 0xd5, 0xd6, 0xd7, 0xd8, 0xd9, 0xda,
+			0xe2, 0xe3, 0xe4, 0xe5, 0xe6, 0xe7, 0xe8, 0xe9,
+			0xea, 0xf2, 0xf3, 0xf4, 0xf5, 0xf6, 0xf7, 0xf8,
+			0xf9, 0xfa,
+		},
+	},
+}
+
+// acBands are the spectral selections of the AC scans.
+var acBands = [][2]int{{1, 5}, {6, 63}}
+
+// dctCos[x][u] is cos((2x+1)uπ/16) scaled by the DCT normalization C(u)/2.
+var dctCos = func() (table [8][8]float64) {
+	for x := range 8 {
+		for u := range 8 {
+			c := 0.5
+			if u == 0 {
+				c = 0.5 / math.Sqrt2
+			}
+			table[x][u] = c * math.Cos(float64(2*x+1)*float64(u)*math.Pi/16)
+		}
+	}
+	return table
+}()
+
+type huffmanCode struct {
+	code uint32
+	size uint8
+}
+
+func (s huffmanSpec) codes() (table [256]huffmanCode) {
+	code, k := uint32(0), 0
+	for size, count := range s.counts {
+		for range count {
+			table[s.values[k]] = huffmanCode{code: code, size: uint8(size + 1)}
+			code++
+			k++
+		}
+		code <<= 1
+	}
+	return table
+}
+
+// bitWriter writes entropy coded data, stuffing a zero byte after every 0xff.
+type bitWriter struct {
+	w     *bufio.Writer
+	bits  uint32
+	nBits uint8
+}
+
+func (b *bitWriter) write(bits uint32, n uint8) {
+	b.bits = b.bits<<n | bits&(1<<n-1)
+	b.nBits += n
+	for b.nBits >= 8 {
+		c := byte(b.bits >> (b.nBits - 8))
+		b.w.WriteByte(c)
+		if c == 0xff {
+			b.w.WriteByte(0)
+		}
+		b.nBits -= 8
+	}
+}
+
+// flush pads the last byte with one bits, as the specification requires
+// before a marker.
+func (b *bitWriter) flush() {
+	if b.nBits > 0 {
+		b.write(1<<(8-b.nBits)-1, 8-b.nBits)
+	}
+	b.bits = 0
+}
+
+func (b *bitWriter) emit(table *[256]huffmanCode, symbol byte) {
+	code := table[symbol]
+	b.write(code.code, code.size)
+}
+
+// emitValue writes the symbol carrying run and the magnitude category of v,
+// followed by the bits of v.
+func (b *bitWriter) emitValue(table *[256]huffmanCode, run int, v int32) {
+	magnitude, bits := v, uint32(v)
+	if v < 0 {
+		magnitude, bits = -v, uint32(v-1)
+	}
+
+	size := uint8(0)
+	for ; magnitude > 0; magnitude >>= 1 {
+		size++
+	}
+
+	b.emit(table, byte(run<<4)|size)
+	b.write(bits, size)
+}
+
+type jpegComponent struct {
+	id     byte
+	table  int // 0 for luminance, 1 for chrominance
+	coeffs [][64]int32
+}
+
+func encodeProgressiveJPEG(w io.Writer, img image.Image, quality int) error {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width == 0 || height == 0 || width > math.MaxUint16 || height > math.MaxUint16 {
+		return fmt.Errorf("cannot encode a %dx%d image as jpeg", width, height)
+	}
+
+	var quant [2][64]int32
+	scale := 200 - 2*quality
+	if quality < 50 {
+		scale = 5000 / quality
+	}
+	for i := range quant {
+		for k, q := range unscaledQuant[i] {
+			quant[i][k] = int32(min(max((q*scale+50)/100, 1), 255))
+		}
+	}
+
+	components := jpegComponents(img, quant)
+
+	bw := bufio.NewWriter(w)
+	bits := &bitWriter{w: bw}
+
+	bw.Write([]byte{0xff, 0xd8})
+
+	tables := 1
+	if len(components) > 1 {
+		tables = 2
+	}
+
+	writeSegment(bw, 0xdb, func(seg []byte) []byte {
+		for i := range tables {
+			seg = append(seg, byte(i))
+			for _, q := range quant[i] {
+				seg = append(seg, byte(q))
+			}
+		}
+		return seg
+	})
+
+	writeSegment(bw, 0xc2, func(seg []byte) []byte {
+		seg = append(seg, 8, byte(height>>8), byte(height), byte(width>>8), byte(width), byte(len(components)))
+		for _, c := range components {
+			seg = append(seg, c.id, 0x11, byte(c.table))
+		}
+		return seg
+	})
+
+	writeSegment(bw, 0xc4, func(seg []byte) []byte {
+		for i := range 2 * tables {
+			spec := huffmanSpecs[i]
+			seg = append(seg, byte(i%2)<<4|byte(i/2))
+			seg = append(seg, spec.counts[:]...)
+			seg = append(seg, spec.values...)
+		}
+		return seg
+	})
+
+	var codes [4][256]huffmanCode
+	for i := range codes {
+		codes[i] = huffmanSpecs[i].codes()
+	}
+
+	// the DC scan interleaves every component, one block each per MCU
+	writeScan(bw, components, 0, 0)
+	predictors := make([]int32, len(components))
+	for block := range components[0].coeffs {
+		for i, c := range components {
+			dc := c.coeffs[block][0]
+			bits.emitValue(&codes[2*c.table], 0, dc-predictors[i])
+			predictors[i] = dc
+		}
+	}
+	bits.flush()
+
+	for _, band := range acBands {
+		for _, c := range components {
+			writeScan(bw, []jpegComponent{c}, band[0], band[1])
+			table := &codes[2*c.table+1]
+
+			for _, block := range c.coeffs {
+				run := 0
+				for k := band[0]; k <= band[1]; k++ {
+					v := block[k]
+					if v == 0 {
+						run++
+						continue
+					}
+					for ; run > 15; run -= 16 {
+						bits.emit(table, 0xf0)
+					}
+					bits.emitValue(table, run, v)
+					run = 0
+				}
+				if run > 0 {
+					bits.emit(table, 0x00)
+				}
+			}
+			bits.flush()
+		}
+	}
+
+	bw.Write([]byte{0xff, 0xd9})
+
+	return bw.Flush()
+}
+
+// jpegComponents converts img to quantized DCT blocks in raster order, one
+// set per component. Gray images are written with a single component.
+func jpegComponents(img image.Image, quant [2][64]int32) []jpegComponent {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	blocksX, blocksY := (width+7)/8, (height+7)/8
+
+	var planes [][]float64
+	var components []jpegComponent
+
+	if gray, ok := img.(*image.Gray); ok {
+		plane := make([]float64, width*height)
+		for y := range height {
+			for x := range width {
+				plane[y*width+x] = float64(gray.GrayAt(bounds.Min.X+x, bounds.Min.Y+y).Y)
+			}
+		}
+		planes = [][]float64{plane}
+		components = []jpegComponent{{id: 1, table: 0}}
+	} else {
+		// like image/jpeg, transparency is dropped by compositing onto black
+		rgba := image.NewRGBA(image.Rect(0, 0, width, height))
+		draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+
+		planes = [][]float64{make([]float64, width*height), make([]float64, width*height), make([]float64, width*height)}
+		for i := 0; i < width*height; i++ {
+			yy, cb, cr := color.RGBToYCbCr(rgba.Pix[4*i], rgba.Pix[4*i+1], rgba.Pix[4*i+2])
+			planes[0][i], planes[1][i], planes[2][i] = float64(yy), float64(cb), float64(cr)
+		}
+		components = []jpegComponent{{id: 1, table: 0}, {id: 2, table: 1}, {id: 3, table: 1}}
+	}
+
+	for i, plane := range planes {
+		c := &components[i]
+		c.coeffs = make([][64]int32, blocksX*blocksY)
+
+		var samples, rows [64]float64
+		for by := range blocksY {
+			for bx := range blocksX {
+				// partial blocks repeat the last row and column
+				for y := range 8 {
+					sy := min(by*8+y, height-1)
+					for x := range 8 {
+						sx := min(bx*8+x, width-1)
+						samples[y*8+x] = plane[sy*width+sx] - 128
+					}
+				}
+
+				fdct(&samples, &rows)
+
+				block := &c.coeffs[by*blocksX+bx]
+				for k, n := range unzig {
+					block[k] = int32(math.Round(samples[n] / float64(quant[c.table][k])))
+				}
+			}
+		}
+	}
+
+	return components
+}
+
+// fdct replaces the 8x8 samples with their DCT coefficients, using tmp as
+// scratch space.
+func fdct(samples, tmp *[64]float64) {
+	for y := range 8 {
+		for u := range 8 {
+			var sum float64
+			for x := range 8 {
+				sum += samples[y*8+x] * dctCos[x][u]
+			}
+			tmp[y*8+u] = sum
+		}
+	}
+
+	for u := range 8 {
+		for v := range 8 {
+			var sum float64
+			for y := range 8 {
+				sum += tmp[y*8+u] * dctCos[y][v]
+			}
+			samples[v*8+u] = sum
+		}
+	}
+}
+
+// writeSegment writes a marker segment whose payload is built by fill.
+func writeSegment(w *bufio.Writer, marker byte, fill func(seg []byte) []byte) {
+	payload := fill(nil)
+	length := len(payload) + 2
+	w.Write([]byte{0xff, marker, byte(length >> 8), byte(length)})
+	w.Write(payload)
+}
+
+// writeScan writes the start of scan header for components and the spectral
+// selection [ss, se].
+func writeScan(w *bufio.Writer, components []jpegComponent, ss, se int) {
+	writeSegment(w, 0xda, func(seg []byte) []byte {
+		seg = append(seg, byte(len(components)))
+		for _, c := range components {
+			seg = append(seg, c.id, byte(c.table)<<4|byte(c.table))
+		}
+		return append(seg, byte(ss), byte(se), 0)
+	})
+}
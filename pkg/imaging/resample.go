@@ -0,0 +1,229 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"math"
+	"strings"
+
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+)
+
+// Filter selects the resampling kernel of a resize.
+type Filter string
+
+const (
+	FilterNearest    Filter = "nearest"
+	FilterBilinear   Filter = "bilinear"
+	FilterCatmullRom Filter = "catmullrom"
+	FilterLanczos    Filter = "lanczos"
+)
+
+// ParseFilter validates a user supplied filter name. An empty name means
+// FilterBilinear and "catmull-rom" is accepted as an alias.
+func ParseFilter(name string) (Filter, error) {
+	switch filter := Filter(strings.ToLower(strings.TrimSpace(name))); filter {
+	case "":
+		return FilterBilinear, nil
+	case "catmull-rom":
+		return FilterCatmullRom, nil
+	case FilterNearest, FilterBilinear, FilterCatmullRom, FilterLanczos:
+		return filter, nil
+	default:
+		return "", fmt.Errorf("%w: unsupported filter %q", customErr.ErrInvalidTransformation, name)
+	}
+}
+
+func (f Filter) filter() (filter, bool) {
+	switch f {
+	case FilterNearest:
+		return nearestFilter, true
+	case "", FilterBilinear:
+		return linearFilter, true
+	case FilterCatmullRom:
+		return catmullRomFilter, true
+	case FilterLanczos:
+		return lanczosFilter, true
+	default:
+		return filter{}, false
+	}
+}
+
+// filter is a separable resampling kernel evaluated over [-support, support].
+// A zero support picks the nearest source pixel instead of filtering.
+type filter struct {
+	support float64
+	kernel  func(x float64) float64
+}
+
+var nearestFilter = filter{}
+
+var linearFilter = filter{
+	support: 1,
+	kernel: func(x float64) float64 {
+		x = math.Abs(x)
+		if x < 1 {
+			return 1 - x
+		}
+		return 0
+	},
+}
+
+// catmullRomFilter is the cubic convolution with B = 0 and C = 0.5.
+var catmullRomFilter = filter{
+	support: 2,
+	kernel: func(x float64) float64 {
+		x = math.Abs(x)
+		switch {
+		case x < 1:
+			return (1.5*x-2.5)*x*x + 1
+		case x < 2:
+			return ((-0.5*x+2.5)*x-4)*x + 2
+		default:
+			return 0
+		}
+	},
+}
+
+// lanczosFilter is the three lobed Lanczos window.
+var lanczosFilter = filter{
+	support: 3,
+	kernel: func(x float64) float64 {
+		x = math.Abs(x)
+		if x < 3 {
+			return sinc(x) * sinc(x/3)
+		}
+		return 0
+	},
+}
+
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+
+	x *= math.Pi
+	return math.Sin(x) / x
+}
+
+type weight struct {
+	index  int
+	weight float64
+}
+
+// toNRGBA copies img into an NRGBA image whose bounds start at the origin.
+func toNRGBA(img image.Image) *image.NRGBA {
+	bounds := img.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, bounds.Min, draw.Src)
+	return dst
+}
+
+func precomputeWeights(dstSize, srcSize int, f filter) [][]weight {
+	ratio := float64(srcSize) / float64(dstSize)
+	scale := math.Max(ratio, 1)
+	radius := math.Ceil(scale * f.support)
+
+	weights := make([][]weight, dstSize)
+	for v := range dstSize {
+		if f.support == 0 {
+			weights[v] = []weight{{index: min(int((float64(v)+0.5)*ratio), srcSize-1), weight: 1}}
+			continue
+		}
+
+		center := (float64(v)+0.5)*ratio - 0.5
+		begin := max(int(math.Ceil(center-radius)), 0)
+		end := min(int(math.Floor(center+radius)), srcSize-1)
+
+		var sum float64
+		for u := begin; u <= end; u++ {
+			w := f.kernel((float64(u) - center) / scale)
+			if w != 0 {
+				weights[v] = append(weights[v], weight{index: u, weight: w})
+				sum += w
+			}
+		}
+
+		if sum == 0 {
+			nearest := min(max(int(math.Round(center)), 0), srcSize-1)
+			weights[v] = []weight{{index: nearest, weight: 1}}
+			continue
+		}
+
+		for i := range weights[v] {
+			weights[v][i].weight /= sum
+		}
+	}
+
+	return weights
+}
+
+// resample scales src to width x height, filtering each axis separately with
+// alpha-weighted accumulation so transparent pixels do not bleed color.
+func resample(src image.Image, width, height int, f filter) *image.NRGBA {
+	img := toNRGBA(src)
+	bounds := img.Bounds()
+
+	if bounds.Dx() != width {
+		img = resampleAxis(img, width, bounds.Dy(), precomputeWeights(width, bounds.Dx(), f), true)
+	}
+
+	if bounds.Dy() != height {
+		img = resampleAxis(img, width, height, precomputeWeights(height, bounds.Dy(), f), false)
+	}
+
+	return img
+}
+
+func resampleAxis(src *image.NRGBA, width, height int, weights [][]weight, horizontal bool) *image.NRGBA {
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+
+	for y := range height {
+		for x := range width {
+			var r, g, b, a float64
+			var ws []weight
+			if horizontal {
+				ws = weights[x]
+			} else {
+				ws = weights[y]
+			}
+
+			for _, w := range ws {
+				var i int
+				if horizontal {
+					i = src.PixOffset(w.index, y)
+				} else {
+					i = src.PixOffset(x, w.index)
+				}
+
+				pa := float64(src.Pix[i+3]) * w.weight
+				r += float64(src.Pix[i]) * pa
+				g += float64(src.Pix[i+1]) * pa
+				b += float64(src.Pix[i+2]) * pa
+				a += pa
+			}
+
+			j := dst.PixOffset(x, y)
+			if a > 0 {
+				dst.Pix[j] = clampUint8(r / a)
+				dst.Pix[j+1] = clampUint8(g / a)
+				dst.Pix[j+2] = clampUint8(b / a)
+			}
+			dst.Pix[j+3] = clampUint8(a)
+		}
+	}
+
+	return dst
+}
+
+func clampUint8(v float64) uint8 {
+	switch {
+	case v <= 0:
+		return 0
+	case v >= 255:
+		return 255
+	default:
+		return uint8(v + 0.5)
+	}
+}
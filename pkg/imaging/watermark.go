@@ -0,0 +1,149 @@
+package imaging
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	// MaxWatermarkText caps the number of characters a text watermark renders.
+	MaxWatermarkText = 200
+	// MaxFontSize caps the pixel size of a text watermark.
+	MaxFontSize = 512
+	// MinWatermarkTile is the smallest side of the square a tiled watermark
+	// and its margin may cover, which bounds how many tiles are drawn.
+	MinWatermarkTile = 16
+)
+
+// Watermark overlays Image on the base. Scale sets the overlay width as a
+// fraction of the base width, keeping its aspect ratio; zero draws it at its
+// own size. Opacity is in (0, 1]. With Tile the overlay repeats across the
+// whole base, Margin pixels apart, and Gravity is ignored; a tile with its
+// margin must cover at least MinWatermarkTile by MinWatermarkTile pixels once
+// scaled.
+type Watermark struct {
+	Image   image.Image
+	Gravity Gravity
+	Margin  int
+	Opacity float64
+	Scale   float64
+	Tile    bool
+}
+
+func (w Watermark) validate() error {
+	if w.Image == nil {
+		return fmt.Errorf("%w: watermark needs an image or text", customErr.ErrInvalidTransformation)
+	}
+
+	if !w.Gravity.valid() {
+		return fmt.Errorf("%w: unsupported gravity %q", customErr.ErrInvalidTransformation, w.Gravity)
+	}
+
+	if w.Margin < 0 || w.Margin > MaxDimension {
+		return fmt.Errorf("%w: watermark margin must be between 0 and %d", customErr.ErrInvalidTransformation, MaxDimension)
+	}
+
+	if w.Opacity <= 0 || !between(w.Opacity, 0, 1) {
+		return fmt.Errorf("%w: watermark opacity must be greater than 0 and at most 1", customErr.ErrInvalidTransformation)
+	}
+
+	if !between(w.Scale, 0, 1) {
+		return fmt.Errorf("%w: watermark scale must be between 0 and 1", customErr.ErrInvalidTransformation)
+	}
+
+	return nil
+}
+
+func watermark(img image.Image, w Watermark) (*image.NRGBA, error) {
+	dst := toNRGBA(img)
+	bounds := dst.Bounds()
+
+	mark := w.Image
+	if w.Scale > 0 {
+		width := max(1, int(math.Round(float64(bounds.Dx())*w.Scale)))
+		height := max(1, int(math.Round(float64(mark.Bounds().Dy())*float64(width)/float64(mark.Bounds().Dx()))))
+		mark = resample(mark, width, height, linearFilter)
+	}
+
+	size := mark.Bounds().Size()
+	mask := image.NewUniform(color.Alpha{A: clampUint8(w.Opacity * 255)})
+
+	overlay := func(at image.Point) {
+		draw.DrawMask(dst, image.Rectangle{Min: at, Max: at.Add(size)}, mark, mark.Bounds().Min, mask, image.Point{}, draw.Over)
+	}
+
+	if !w.Tile {
+		overlay(w.Gravity.place(bounds, size, w.Margin))
+		return dst, nil
+	}
+
+	stepX, stepY := size.X+w.Margin, size.Y+w.Margin
+	if stepX*stepY < MinWatermarkTile*MinWatermarkTile {
+		return nil, fmt.Errorf("%w: tiled watermark with its margin must cover at least %dx%d pixels",
+			customErr.ErrInvalidTransformation, MinWatermarkTile, MinWatermarkTile)
+	}
+
+	for y := w.Margin; y < bounds.Dy(); y += stepY {
+		for x := w.Margin; x < bounds.Dx(); x += stepX {
+			overlay(image.Pt(x, y))
+		}
+	}
+
+	return dst, nil
+}
+
+var watermarkFont = sync.OnceValues(func() (*opentype.Font, error) {
+	return opentype.Parse(gobold.TTF)
+})
+
+// Text renders a single line with the bundled Go Bold font on a transparent
+// image sized to fit it, ready to be used as a watermark.
+func Text(text string, size float64, c color.NRGBA) (image.Image, error) {
+	text = strings.TrimSpace(text)
+	if text == "" || utf8.RuneCountInString(text) > MaxWatermarkText {
+		return nil, fmt.Errorf("%w: watermark text must have 1 to %d characters", customErr.ErrInvalidTransformation, MaxWatermarkText)
+	}
+
+	if size <= 0 || !between(size, 0, MaxFontSize) {
+		return nil, fmt.Errorf("%w: font size must be greater than 0 and at most %d", customErr.ErrInvalidTransformation, MaxFontSize)
+	}
+
+	f, err := watermarkFont()
+	if err != nil {
+		return nil, err
+	}
+
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return nil, err
+	}
+	defer face.Close()
+
+	drawer := font.Drawer{Src: image.NewUniform(c), Face: face}
+	metrics := face.Metrics()
+
+	width := drawer.MeasureString(text).Ceil()
+	height := (metrics.Ascent + metrics.Descent).Ceil()
+	if width > MaxDimension || height > MaxDimension {
+		return nil, fmt.Errorf("%w: watermark text is too large", customErr.ErrInvalidTransformation)
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, max(width, 1), max(height, 1)))
+	drawer.Dst = dst
+	drawer.Dot = fixed.Point26_6{Y: metrics.Ascent}
+	drawer.DrawString(text)
+
+	return dst, nil
+}
@@ -0,0 +1,285 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWK is a single public key in JSON Web Key format.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS is the document served at /.well-known/jwks.json.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKSProvider is implemented by signers whose verification keys can be
+// published.
+type JWKSProvider interface {
+	JWKS() JWKS
+}
+
+type verificationKey struct {
+	method jwt.SigningMethod
+	public crypto.PublicKey
+	jwk    JWK
+}
+
+// AsymmetricJWT signs tokens with an RSA (RS256) or Ed25519 (EdDSA) private
+// key and stamps them with the key's "kid". Tokens are verified against every
+// configured public key, so a previous key can stay valid while a new one is
+// rolled out.
+type AsymmetricJWT struct {
+	signingKid string
+	method     jwt.SigningMethod
+	privateKey crypto.Signer
+	keys       map[string]verificationKey
+	ExpireTime time.Duration
+}
+
+// NewAsymmetricJwt loads the signing key from privateKeyPath and any extra
+// verification keys from publicKeyPaths. algorithm must be RS256 or EdDSA and
+// match the signing key type.
+func NewAsymmetricJwt(algorithm string, privateKeyPath string, publicKeyPaths []string, ExpireTime string) (*AsymmetricJWT, error) {
+	exp, err := time.ParseDuration(ExpireTime)
+	if err != nil {
+		return nil, fmt.Errorf("invalid duration format for expireTime: %v", err)
+	}
+
+	privateKey, err := loadPrivateKey(privateKeyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	signing, err := newVerificationKey(privateKey.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	if signing.method.Alg() != algorithm {
+		return nil, fmt.Errorf("signing key is a %s key but algorithm %s was configured", signing.method.Alg(), algorithm)
+	}
+
+	a := &AsymmetricJWT{
+		signingKid: signing.jwk.Kid,
+		method:     signing.method,
+		privateKey: privateKey,
+		keys:       map[string]verificationKey{signing.jwk.Kid: signing},
+		ExpireTime: exp,
+	}
+
+	for _, path := range publicKeyPaths {
+		public, err := loadPublicKey(path)
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := newVerificationKey(public)
+		if err != nil {
+			return nil, err
+		}
+		a.keys[key.jwk.Kid] = key
+	}
+
+	return a, nil
+}
+
+// CreateToken implements JWTItf.
+func (a *AsymmetricJWT) CreateToken(userID string) (string, error) {
+	if a.ExpireTime <= 0 {
+		return "", fmt.Errorf("jwt expire time must be greater than 0")
+	}
+
+	claims := &UserClaim{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(a.ExpireTime)),
+		},
+		UserID: userID,
+	}
+
+	token := jwt.NewWithClaims(a.method, claims)
+	token.Header["kid"] = a.signingKid
+
+	signedToken, err := token.SignedString(a.privateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %v", err)
+	}
+
+	return signedToken, nil
+}
+
+// VerifyToken implements JWTItf.
+func (a *AsymmetricJWT) VerifyToken(tokenString string) (string, error) {
+	var claims UserClaim
+
+	token, err := jwt.ParseWithClaims(tokenString, &claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+
+		key, ok := a.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown key id %q", kid)
+		}
+
+		if t.Method.Alg() != key.method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method %s", t.Method.Alg())
+		}
+
+		return key.public, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse token: %v", err)
+	}
+
+	if !token.Valid {
+		return "", fmt.Errorf("invalid token")
+	}
+
+	return claims.UserID, nil
+}
+
+// ExpiresIn implements JWTItf.
+func (a *AsymmetricJWT) ExpiresIn() time.Duration {
+	return a.ExpireTime
+}
+
+// JWKS implements JWKSProvider. The signing key is listed first.
+func (a *AsymmetricJWT) JWKS() JWKS {
+	jwks := JWKS{Keys: []JWK{a.keys[a.signingKid].jwk}}
+
+	kids := make([]string, 0, len(a.keys))
+	for kid := range a.keys {
+		if kid != a.signingKid {
+			kids = append(kids, kid)
+		}
+	}
+	sort.Strings(kids)
+
+	for _, kid := range kids {
+		jwks.Keys = append(jwks.Keys, a.keys[kid].jwk)
+	}
+
+	return jwks
+}
+
+func newVerificationKey(public crypto.PublicKey) (verificationKey, error) {
+	b64 := base64.RawURLEncoding.EncodeToString
+
+	var key verificationKey
+	var thumbprint []byte
+
+	switch public := public.(type) {
+	case *rsa.PublicKey:
+		n := b64(public.N.Bytes())
+		e := b64(big.NewInt(int64(public.E)).Bytes())
+
+		key = verificationKey{
+			method: jwt.SigningMethodRS256,
+			public: public,
+			jwk:    JWK{Kty: "RSA", Use: "sig", Alg: jwt.SigningMethodRS256.Alg(), N: n, E: e},
+		}
+		thumbprint, _ = json.Marshal(map[string]string{"e": e, "kty": "RSA", "n": n})
+	case ed25519.PublicKey:
+		x := b64(public)
+
+		key = verificationKey{
+			method: jwt.SigningMethodEdDSA,
+			public: public,
+			jwk:    JWK{Kty: "OKP", Use: "sig", Alg: jwt.SigningMethodEdDSA.Alg(), Crv: "Ed25519", X: x},
+		}
+		thumbprint, _ = json.Marshal(map[string]string{"crv": "Ed25519", "kty": "OKP", "x": x})
+	default:
+		return key, fmt.Errorf("unsupported key type %T", public)
+	}
+
+	// the kid is the RFC 7638 thumbprint, so it never needs configuring
+	sum := sha256.Sum256(thumbprint)
+	key.jwk.Kid = b64(sum[:])
+
+	return key, nil
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %v", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found in %s", path)
+	}
+
+	return block, nil
+}
+
+func loadPrivateKey(path string) (crypto.Signer, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if block.Type == "RSA PRIVATE KEY" {
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key %s: %v", path, err)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+
+	return signer, nil
+}
+
+// loadPublicKey accepts a public key or, for convenience, a private key whose
+// public half should be trusted.
+func loadPublicKey(path string) (crypto.PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case block.Type == "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	case strings.HasSuffix(block.Type, "PRIVATE KEY"):
+		signer, err := loadPrivateKey(path)
+		if err != nil {
+			return nil, err
+		}
+		return signer.Public(), nil
+	default:
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse public key %s: %v", path, err)
+		}
+		return key, nil
+	}
+}
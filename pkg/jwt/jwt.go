@@ -10,6 +10,7 @@ import (
 type JWTItf interface {
 	CreateToken(userID string) (string, error)
 	VerifyToken(tokenString string) (string, error)
+	ExpiresIn() time.Duration
 }
 
 type JWT struct {
@@ -63,7 +64,7 @@ func (j *JWT) VerifyToken(tokenString string) (string, error) {
 
 	token, err := jwt.ParseWithClaims(tokenString, &claims, func(t *jwt.Token) (interface{}, error) {
 		return []byte(j.SecretKey), nil
-	})
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 	if err != nil {
 		return "", fmt.Errorf("failed to parse token: %v", err)
 	}
@@ -74,3 +75,8 @@ func (j *JWT) VerifyToken(tokenString string) (string, error) {
 
 	return claims.UserID, nil
 }
+
+// ExpiresIn implements JWTItf.
+func (j *JWT) ExpiresIn() time.Duration {
+	return j.ExpireTime
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	"github.com/federicodosantos/image-smith/pkg/jwt"
+	response "github.com/federicodosantos/image-smith/pkg/response"
+)
+
+// TokenCookieName is the cookie that carries the JWT for browser clients.
+const TokenCookieName = "jwt-token"
+
+// Middleware wraps an http.Handler with additional behaviour.
+type Middleware func(http.Handler) http.Handler
+
+type contextKey string
+
+const userIDKey contextKey = "userID"
+
+// Authenticate rejects requests without a valid token and stores the token's
+// user ID in the request context. The token is read from the Authorization
+// bearer header first and from the jwt-token cookie otherwise.
+func Authenticate(jwtService jwt.JWTItf) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := tokenFromRequest(r)
+			if token == "" {
+				response.FailedResponse(w, http.StatusUnauthorized, customErr.ErrUserIdNotFound.Error(), nil)
+				return
+			}
+
+			userID, err := jwtService.VerifyToken(token)
+			if err != nil || userID == "" {
+				response.FailedResponse(w, http.StatusUnauthorized, customErr.ErrInvalidToken.Error(), nil)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(ContextWithUserID(r.Context(), userID)))
+		})
+	}
+}
+
+// ContextWithUserID returns a copy of ctx carrying the authenticated user ID.
+func ContextWithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDKey, userID)
+}
+
+// UserIDFromContext returns the user ID stored by Authenticate.
+func UserIDFromContext(ctx context.Context) (string, bool) {
+	userID, ok := ctx.Value(userIDKey).(string)
+	return userID, ok && userID != ""
+}
+
+func tokenFromRequest(r *http.Request) string {
+	if header := r.Header.Get("Authorization"); header != "" {
+		scheme, token, ok := strings.Cut(header, " ")
+		if ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+		return ""
+	}
+
+	if cookie, err := r.Cookie(TokenCookieName); err == nil {
+		return cookie.Value
+	}
+
+	return ""
+}
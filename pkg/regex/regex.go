@@ -5,26 +5,38 @@ import (
 	"regexp"
 )
 
+var passwordRules = []struct {
+	pattern *regexp.Regexp
+	err     error
+}{
+	{regexp.MustCompile(`[a-z]`), errors.New("Password must contain at least one lowercase letter")},
+	{regexp.MustCompile(`[A-Z]`), errors.New("Password must contain at least one uppercase letter")},
+	{regexp.MustCompile(`\d`), errors.New("Password must contain at least one number")},
+	{regexp.MustCompile(`[@$!%*?&#]`), errors.New("Password must contain at least one special character")},
+}
+
+// Password returns the first rule pass breaks, or nil.
 func Password(pass string) error {
-	if len(pass) < 8 {
-		return errors.New("Password must be at least 8 characters long")
+	if errs := PasswordErrors(pass); len(errs) > 0 {
+		return errs[0]
 	}
 
-	if !regexp.MustCompile(`[a-z]`).MatchString(pass) {
-		return errors.New("Password must contain at least one lowercase letter")
-	}
+	return nil
+}
 
-	if !regexp.MustCompile(`[A-Z]`).MatchString(pass) {
-		return errors.New("Password must contain at least one uppercase letter")
-	}
+// PasswordErrors returns every rule pass breaks.
+func PasswordErrors(pass string) []error {
+	var errs []error
 
-	if !regexp.MustCompile(`\d`).MatchString(pass) {
-		return errors.New("Password must contain at least one number")
+	if len(pass) < 8 {
+		errs = append(errs, errors.New("Password must be at least 8 characters long"))
 	}
 
-	if !regexp.MustCompile(`[@$!%*?&#]`).MatchString(pass) {
-		return errors.New("Password must contain at least one special character")
+	for _, rule := range passwordRules {
+		if !rule.pattern.MatchString(pass) {
+			errs = append(errs, rule.err)
+		}
 	}
 
-	return nil
+	return errs
 }
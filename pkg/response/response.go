@@ -6,9 +6,18 @@ import (
 )
 
 type HttpResponse struct {
-	Status  int    `json:"status"`
-	Message string `json:"message"`
-	Data    any    `json:"obj,omitempty"`
+	Status     int         `json:"status"`
+	Message    string      `json:"message"`
+	Data       any         `json:"obj,omitempty"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+}
+
+// Pagination describes a cursor-paginated page. NextCursor is passed back as
+// the cursor query parameter to fetch the following page.
+type Pagination struct {
+	Limit      int    `json:"limit"`
+	HasMore    bool   `json:"has_more"`
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 func SuccessResponse(w http.ResponseWriter, status int, message string, data any) {
@@ -36,3 +45,17 @@ func FailedResponse(w http.ResponseWriter, status int, message string, data any)
 
 	json.NewEncoder(w).Encode(errorResponse)
 }
+
+func PaginatedResponse(w http.ResponseWriter, status int, message string, data any, pagination *Pagination) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	response := HttpResponse{
+		Status:     status,
+		Message:    message,
+		Data:       data,
+		Pagination: pagination,
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,323 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+)
+
+// LocalStorage keeps objects on the local filesystem under Root. It is meant
+// for development and tests; ServeHTTP exposes the objects under BaseURL to
+// holders of a signed URL.
+type LocalStorage struct {
+	Root       string
+	BaseURL    string
+	SigningKey []byte
+}
+
+func NewLocalStorage(root string, baseURL string, signingKey string) (*LocalStorage, error) {
+	if signingKey == "" {
+		return nil, fmt.Errorf("local storage requires a signing key")
+	}
+
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %v", err)
+	}
+
+	return &LocalStorage{
+		Root:       root,
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		SigningKey: []byte(signingKey),
+	}, nil
+}
+
+// Put implements Storage.
+func (l *LocalStorage) Put(ctx context.Context, key string, r io.Reader, contentType string) error {
+	return l.write(key, func(w io.Writer) error {
+		_, err := io.Copy(w, r)
+		return err
+	})
+}
+
+// Compose implements Storage.
+func (l *LocalStorage) Compose(ctx context.Context, key string, sources []string, contentType string) error {
+	return l.write(key, func(w io.Writer) error {
+		for _, source := range sources {
+			if err := l.copyObject(ctx, w, source); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (l *LocalStorage) copyObject(ctx context.Context, w io.Writer, key string) error {
+	object, err := l.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer object.Close()
+
+	_, err = io.Copy(w, object)
+	return err
+}
+
+// write replaces the object at key with what fill writes, so readers never
+// see it partially written.
+func (l *LocalStorage) write(key string, fill func(w io.Writer) error) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create object directory: %v", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := fill(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// Get implements Storage.
+func (l *LocalStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, customErr.ErrObjectNotFound
+		}
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// Delete implements Storage.
+func (l *LocalStorage) Delete(ctx context.Context, key string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// Stat implements Storage.
+func (l *LocalStorage) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, customErr.ErrObjectNotFound
+		}
+		return nil, err
+	}
+
+	if info.IsDir() {
+		return nil, customErr.ErrObjectNotFound
+	}
+
+	return l.objectInfo(key, info), nil
+}
+
+// List implements Storage.
+func (l *LocalStorage) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+
+	err := filepath.WalkDir(l.Root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".upload-") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(l.Root, path)
+		if err != nil {
+			return err
+		}
+
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		objects = append(objects, *l.objectInfo(key, info))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+
+	return objects, nil
+}
+
+// SignedURL implements Storage.
+func (l *LocalStorage) SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	return l.signedURL(key, key, nil, expiry)
+}
+
+// SignedUploadURL implements Storage. The URL is only valid for PUT requests
+// to ServeHTTP; the size is part of the signed query.
+func (l *LocalStorage) SignedUploadURL(ctx context.Context, key string, size int64, expiry time.Duration) (string, error) {
+	sizeParam := strconv.FormatInt(size, 10)
+
+	query := url.Values{}
+	query.Set("size", sizeParam)
+
+	return l.signedURL(key, uploadSubject(key, sizeParam), query, expiry)
+}
+
+// signedURL returns the URL of key with query, signed over subject, which
+// tells read and upload signatures apart.
+func (l *LocalStorage) signedURL(key string, subject string, query url.Values, expiry time.Duration) (string, error) {
+	if len(l.SigningKey) == 0 {
+		return "", fmt.Errorf("local storage has no signing key configured")
+	}
+
+	expires := strconv.FormatInt(time.Now().Add(expiry).Unix(), 10)
+
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("expires", expires)
+	query.Set("signature", l.sign(subject, expires))
+
+	return l.URL(key) + "?" + query.Encode(), nil
+}
+
+// URL implements Storage.
+func (l *LocalStorage) URL(key string) string {
+	return l.BaseURL + "/" + key
+}
+
+// ServeHTTP serves objects by key to requests with a valid, unexpired
+// signature from SignedURL. PUT stores the body under key and needs a
+// signature from SignedUploadURL instead; a body larger than the signed size
+// is rejected and nothing is kept.
+func (l *LocalStorage) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(r.URL.Path, "/")
+
+	subject := key
+	if r.Method == http.MethodPut {
+		subject = uploadSubject(key, r.URL.Query().Get("size"))
+	}
+
+	signature := r.URL.Query().Get("signature")
+	expires := r.URL.Query().Get("expires")
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > unix ||
+		!hmac.Equal([]byte(signature), []byte(l.sign(subject, expires))) {
+		http.Error(w, "invalid or expired signature", http.StatusForbidden)
+		return
+	}
+
+	if r.Method == http.MethodPut {
+		size, err := strconv.ParseInt(r.URL.Query().Get("size"), 10, 64)
+		if err != nil || r.ContentLength > size {
+			http.Error(w, "body is larger than the signed size", http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		body := http.MaxBytesReader(w, r.Body, size)
+		if err := l.Put(r.Context(), key, body, r.Header.Get("Content-Type")); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				http.Error(w, "body is larger than the signed size", http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if _, err := l.Stat(r.Context(), key); err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	path, _ := l.path(key)
+	http.ServeFile(w, r, path)
+}
+
+func (l *LocalStorage) sign(key string, expires string) string {
+	mac := hmac.New(sha256.New, l.SigningKey)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// uploadSubject is what upload signatures of key for size bytes are computed
+// over.
+func uploadSubject(key string, size string) string {
+	return http.MethodPut + "\n" + key + "\n" + size
+}
+
+func (l *LocalStorage) objectInfo(key string, info fs.FileInfo) *ObjectInfo {
+	return &ObjectInfo{
+		Key:         key,
+		Size:        info.Size(),
+		ContentType: mime.TypeByExtension(filepath.Ext(key)),
+		ModTime:     info.ModTime(),
+	}
+}
+
+func (l *LocalStorage) path(key string) (string, error) {
+	clean := filepath.Clean("/" + key)
+	if clean == "/" {
+		return "", fmt.Errorf("invalid object key %q", key)
+	}
+
+	return filepath.Join(l.Root, filepath.FromSlash(clean)), nil
+}
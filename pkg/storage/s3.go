@@ -0,0 +1,412 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+)
+
+// S3Config configures an S3-compatible object store such as AWS S3, MinIO or
+// Supabase Storage's S3 endpoint. Objects are addressed path-style as
+// <Endpoint>/<Bucket>/<key>.
+type S3Config struct {
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// PublicURL is the base used by URL; it defaults to <Endpoint>/<Bucket>.
+	PublicURL string
+}
+
+// S3Storage talks to an S3-compatible API over plain HTTP using SigV4.
+type S3Storage struct {
+	config S3Config
+	client *http.Client
+}
+
+func NewS3Storage(config S3Config, client *http.Client) (*S3Storage, error) {
+	if config.Endpoint == "" || config.Bucket == "" {
+		return nil, fmt.Errorf("s3 storage requires an endpoint and a bucket")
+	}
+
+	if config.AccessKeyID == "" || config.SecretAccessKey == "" {
+		return nil, fmt.Errorf("s3 storage requires access credentials")
+	}
+
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+
+	config.Endpoint = strings.TrimRight(config.Endpoint, "/")
+	if config.PublicURL == "" {
+		config.PublicURL = config.Endpoint + "/" + config.Bucket
+	}
+	config.PublicURL = strings.TrimRight(config.PublicURL, "/")
+
+	if client == nil {
+		client = &http.Client{Timeout: 60 * time.Second}
+	}
+
+	return &S3Storage{config: config, client: client}, nil
+}
+
+// Put implements Storage. A request needs its length and payload hash up
+// front, so the body is read a part at a time: objects shorter than a part
+// are sent in one request, larger ones as a multipart upload.
+func (s *S3Storage) Put(ctx context.Context, key string, r io.Reader, contentType string) error {
+	part := make([]byte, partSize)
+
+	n, err := io.ReadFull(r, part)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return s.putObject(ctx, key, part[:n], contentType)
+	}
+	if err != nil {
+		return err
+	}
+
+	upload, err := s.createMultipartUpload(ctx, key, contentType)
+	if err != nil {
+		return err
+	}
+
+	for {
+		if err := upload.putPart(ctx, part[:n]); err != nil {
+			return upload.abort(ctx, err)
+		}
+
+		n, err = io.ReadFull(r, part)
+		if err == io.EOF {
+			break
+		}
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return upload.abort(ctx, err)
+		}
+	}
+
+	if err := upload.complete(ctx); err != nil {
+		return upload.abort(ctx, err)
+	}
+
+	return nil
+}
+
+// Compose implements Storage. Sources large enough to be a part are copied
+// by S3 itself; smaller ones, and the start of the next source when that is
+// needed to fill a part, are read into a buffer of less than a part first.
+// Sources too small together to need a multipart upload are joined in one.
+func (s *S3Storage) Compose(ctx context.Context, key string, sources []string, contentType string) error {
+	sizes := make([]int64, len(sources))
+	var total int64
+	for i, source := range sources {
+		info, err := s.Stat(ctx, source)
+		if err != nil {
+			return err
+		}
+		sizes[i] = info.Size
+		total += info.Size
+	}
+
+	var buf bytes.Buffer
+
+	if total < minPartSize {
+		for i, source := range sources {
+			if err := s.getRange(ctx, source, 0, sizes[i], &buf); err != nil {
+				return err
+			}
+		}
+		return s.putObject(ctx, key, buf.Bytes(), contentType)
+	}
+
+	upload, err := s.createMultipartUpload(ctx, key, contentType)
+	if err != nil {
+		return err
+	}
+
+	for i, source := range sources {
+		var offset int64
+
+		// a buffered part is topped up from the start of this source
+		if buf.Len() > 0 {
+			offset = min(int64(minPartSize-buf.Len()), sizes[i])
+			if err := s.getRange(ctx, source, 0, offset, &buf); err != nil {
+				return upload.abort(ctx, err)
+			}
+
+			if buf.Len() >= minPartSize {
+				if err := upload.putPart(ctx, buf.Bytes()); err != nil {
+					return upload.abort(ctx, err)
+				}
+				buf.Reset()
+			}
+		}
+
+		switch rest := sizes[i] - offset; {
+		case rest >= minPartSize:
+			err = upload.copyPart(ctx, source, offset, sizes[i])
+		case rest > 0:
+			err = s.getRange(ctx, source, offset, sizes[i], &buf)
+		}
+		if err != nil {
+			return upload.abort(ctx, err)
+		}
+	}
+
+	if buf.Len() > 0 {
+		if err := upload.putPart(ctx, buf.Bytes()); err != nil {
+			return upload.abort(ctx, err)
+		}
+	}
+
+	if err := upload.complete(ctx); err != nil {
+		return upload.abort(ctx, err)
+	}
+
+	return nil
+}
+
+// getRange writes the bytes of the object at key from offset up to end to w.
+func (s *S3Storage) getRange(ctx context.Context, key string, offset int64, end int64, w io.Writer) error {
+	if offset >= end {
+		return nil
+	}
+
+	req, err := s.newRequest(ctx, http.MethodGet, key, nil, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, end-1))
+
+	res, err := s.do(req, nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	n, err := io.Copy(w, io.LimitReader(res.Body, end-offset))
+	if err != nil {
+		return err
+	}
+	if n != end-offset {
+		return fmt.Errorf("s3 returned %d of %d bytes of %s", n, end-offset, key)
+	}
+
+	return nil
+}
+
+func (s *S3Storage) putObject(ctx context.Context, key string, body []byte, contentType string) error {
+	req, err := s.newRequest(ctx, http.MethodPut, key, nil, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	res, err := s.do(req, body)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+
+	return nil
+}
+
+// Get implements Storage.
+func (s *S3Storage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := s.newRequest(ctx, http.MethodGet, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.do(req, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Body, nil
+}
+
+// Delete implements Storage.
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	req, err := s.newRequest(ctx, http.MethodDelete, key, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := s.do(req, nil)
+	if err != nil {
+		if errors.Is(err, customErr.ErrObjectNotFound) {
+			return nil
+		}
+		return err
+	}
+	res.Body.Close()
+
+	return nil
+}
+
+// Stat implements Storage.
+func (s *S3Storage) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	req, err := s.newRequest(ctx, http.MethodHead, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.do(req, nil)
+	if err != nil {
+		return nil, err
+	}
+	res.Body.Close()
+
+	size, _ := strconv.ParseInt(res.Header.Get("Content-Length"), 10, 64)
+	modTime, _ := http.ParseTime(res.Header.Get("Last-Modified"))
+
+	return &ObjectInfo{
+		Key:         key,
+		Size:        size,
+		ContentType: res.Header.Get("Content-Type"),
+		ModTime:     modTime,
+	}, nil
+}
+
+type listBucketResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// List implements Storage.
+func (s *S3Storage) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+	var token string
+
+	for {
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("prefix", prefix)
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		req, err := s.newRequest(ctx, http.MethodGet, "", query, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := s.do(req, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var result listBucketResult
+		err = xml.NewDecoder(res.Body).Decode(&result)
+		res.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode list response: %v", err)
+		}
+
+		for _, content := range result.Contents {
+			objects = append(objects, ObjectInfo{
+				Key:     content.Key,
+				Size:    content.Size,
+				ModTime: content.LastModified,
+			})
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// SignedURL implements Storage.
+func (s *S3Storage) SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	return s.presign(http.MethodGet, key, expiry, time.Now(), nil)
+}
+
+// SignedUploadURL implements Storage. The content length is a signed header,
+// so S3 rejects a PUT of any other size.
+func (s *S3Storage) SignedUploadURL(ctx context.Context, key string, size int64, expiry time.Duration) (string, error) {
+	return s.presign(http.MethodPut, key, expiry, time.Now(), map[string]string{
+		"content-length": strconv.FormatInt(size, 10),
+	})
+}
+
+// URL implements Storage.
+func (s *S3Storage) URL(key string) string {
+	return s.config.PublicURL + "/" + uriEncode(key, false)
+}
+
+func (s *S3Storage) objectURL(key string, query url.Values) (*url.URL, error) {
+	u, err := url.Parse(s.config.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid s3 endpoint: %v", err)
+	}
+
+	u.Path = u.Path + "/" + s.config.Bucket
+	if key != "" {
+		u.Path += "/" + key
+	}
+	u.RawPath = uriEncode(u.Path, false)
+	u.RawQuery = canonicalQuery(query)
+
+	return u, nil
+}
+
+func (s *S3Storage) newRequest(ctx context.Context, method string, key string, query url.Values, body []byte) (*http.Request, error) {
+	u, err := s.objectURL(key, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	return http.NewRequestWithContext(ctx, method, u.String(), reader)
+}
+
+func (s *S3Storage) do(req *http.Request, body []byte) (*http.Response, error) {
+	s.sign(req, body, time.Now())
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode == http.StatusNotFound {
+		res.Body.Close()
+		return nil, customErr.ErrObjectNotFound
+	}
+
+	if res.StatusCode >= http.StatusBadRequest {
+		message, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		res.Body.Close()
+		return nil, fmt.Errorf("s3 %s %s failed with status %d: %s",
+			req.Method, req.URL.Path, res.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	return res, nil
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
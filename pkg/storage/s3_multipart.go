@@ -0,0 +1,187 @@
+package storage
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const (
+	// partSize is the size of the parts large objects are uploaded in.
+	partSize = 8 << 20
+	// minPartSize is the smallest part S3 accepts, except for the last one.
+	minPartSize = 5 << 20
+)
+
+type initiateMultipartUploadResult struct {
+	UploadID string `xml:"UploadId"`
+}
+
+type completedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+type completeMultipartUpload struct {
+	XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+	Parts   []completedPart `xml:"Part"`
+}
+
+// s3Error is the body S3 answers with when a request fails. Completing a
+// multipart upload can fail after the 200 status has been sent.
+type s3Error struct {
+	XMLName xml.Name
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// copyPartResult is the body of a copied part, or an s3Error when the copy
+// failed after the 200 status was sent.
+type copyPartResult struct {
+	XMLName xml.Name
+	ETag    string `xml:"ETag"`
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// multipartUpload is an S3 multipart upload in progress.
+type multipartUpload struct {
+	storage *S3Storage
+	key     string
+	id      string
+	parts   []completedPart
+}
+
+func (s *S3Storage) createMultipartUpload(ctx context.Context, key string, contentType string) (*multipartUpload, error) {
+	req, err := s.newRequest(ctx, http.MethodPost, key, url.Values{"uploads": {""}}, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	res, err := s.do(req, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	var result initiateMultipartUploadResult
+	if err := xml.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode multipart upload response: %v", err)
+	}
+
+	if result.UploadID == "" {
+		return nil, fmt.Errorf("s3 returned no multipart upload id for %s", key)
+	}
+
+	return &multipartUpload{storage: s, key: key, id: result.UploadID}, nil
+}
+
+// putPart uploads data as the next part.
+func (m *multipartUpload) putPart(ctx context.Context, data []byte) error {
+	number := len(m.parts) + 1
+
+	query := url.Values{}
+	query.Set("partNumber", strconv.Itoa(number))
+	query.Set("uploadId", m.id)
+
+	req, err := m.storage.newRequest(ctx, http.MethodPut, m.key, query, data)
+	if err != nil {
+		return err
+	}
+
+	res, err := m.storage.do(req, data)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+
+	m.parts = append(m.parts, completedPart{PartNumber: number, ETag: res.Header.Get("ETag")})
+
+	return nil
+}
+
+// copyPart copies the bytes of the object at source from offset up to end
+// as the next part.
+func (m *multipartUpload) copyPart(ctx context.Context, source string, offset int64, end int64) error {
+	number := len(m.parts) + 1
+
+	query := url.Values{}
+	query.Set("partNumber", strconv.Itoa(number))
+	query.Set("uploadId", m.id)
+
+	req, err := m.storage.newRequest(ctx, http.MethodPut, m.key, query, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Amz-Copy-Source", "/"+m.storage.config.Bucket+"/"+uriEncode(source, false))
+	req.Header.Set("X-Amz-Copy-Source-Range", fmt.Sprintf("bytes=%d-%d", offset, end-1))
+
+	res, err := m.storage.do(req, nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	var result copyPartResult
+	if err := xml.NewDecoder(res.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode copy part response: %v", err)
+	}
+
+	if result.XMLName.Local == "Error" {
+		return fmt.Errorf("s3 failed to copy %s into %s: %s: %s", source, m.key, result.Code, result.Message)
+	}
+
+	m.parts = append(m.parts, completedPart{PartNumber: number, ETag: result.ETag})
+
+	return nil
+}
+
+// complete joins the uploaded parts into the object.
+func (m *multipartUpload) complete(ctx context.Context) error {
+	body, err := xml.Marshal(completeMultipartUpload{Parts: m.parts})
+	if err != nil {
+		return err
+	}
+
+	req, err := m.storage.newRequest(ctx, http.MethodPost, m.key, url.Values{"uploadId": {m.id}}, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/xml")
+
+	res, err := m.storage.do(req, body)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	var result s3Error
+	if err := xml.NewDecoder(res.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode complete multipart upload response: %v", err)
+	}
+
+	if result.XMLName.Local == "Error" {
+		return fmt.Errorf("s3 failed to complete multipart upload of %s: %s: %s", m.key, result.Code, result.Message)
+	}
+
+	return nil
+}
+
+// abort discards the uploaded parts and returns err. It runs even when ctx
+// is cancelled, as the parts would otherwise be kept and billed.
+func (m *multipartUpload) abort(ctx context.Context, err error) error {
+	req, reqErr := m.storage.newRequest(context.WithoutCancel(ctx), http.MethodDelete, m.key, url.Values{"uploadId": {m.id}}, nil)
+	if reqErr != nil {
+		return err
+	}
+
+	if res, reqErr := m.storage.do(req, nil); reqErr == nil {
+		res.Body.Close()
+	}
+
+	return err
+}
@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// ObjectInfo describes a stored object.
+type ObjectInfo struct {
+	Key         string
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+}
+
+// Storage is the object store used to persist image blobs. Keys are slash
+// separated paths such as "<user-id>/<image-id>.png".
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, contentType string) error
+	// Compose writes the objects at sources, joined in order, to key. The
+	// store assembles them itself, without passing them through the caller.
+	Compose(ctx context.Context, key string, sources []string, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+	List(ctx context.Context, prefix string) ([]ObjectInfo, error)
+	// SignedURL returns a URL that grants read access to key until expiry.
+	SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error)
+	// SignedUploadURL returns a URL that accepts a PUT of exactly size bytes
+	// of content for key until expiry, so clients upload straight to the
+	// store.
+	SignedUploadURL(ctx context.Context, key string, size int64, expiry time.Duration) (string, error)
+	// URL returns the unsigned URL of key, which only grants access when the
+	// store is public.
+	URL(key string) string
+}
@@ -0,0 +1,285 @@
+package validator
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/federicodosantos/image-smith/pkg/regex"
+)
+
+// Errors maps a JSON field path to every message that applies to it.
+type Errors map[string][]string
+
+func (e Errors) Error() string {
+	fields := make([]string, 0, len(e))
+	for field := range e {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	parts := make([]string, 0, len(fields))
+	for _, field := range fields {
+		parts = append(parts, field+": "+strings.Join(e[field], ", "))
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+func (e Errors) add(field string, message string) {
+	e[field] = append(e[field], message)
+}
+
+// Struct validates v using its `validate` struct tags and returns every
+// failure, or nil when v is valid. Supported rules are required, omitempty,
+// email, url, password, min=N, max=N, oneof=a b c and eqfield=Field. A zero
+// value fails required, skips the other rules with omitempty and is checked
+// against them otherwise, so min=1 rejects a plain 0. Nested structs and
+// struct pointers are validated with a dotted field path. A field whose tag
+// fails Check is reported as invalid.
+func Struct(v any) Errors {
+	errs := Errors{}
+
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			errs.add("body", "is required")
+			return errs
+		}
+		value = value.Elem()
+	}
+
+	if value.Kind() == reflect.Struct {
+		validateStruct(value, "", errs)
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return errs
+}
+
+// Check reports the first `validate` tag of v's type, or of the structs it
+// nests, that uses an unknown rule or a malformed parameter. Struct cannot
+// validate such a field and reports it as invalid, so every request type
+// should be checked once, such as in a test.
+func Check(v any) error {
+	typ := reflect.TypeOf(v)
+	for typ != nil && typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return nil
+	}
+
+	return checkStruct(typ, "", map[reflect.Type]bool{})
+}
+
+func checkStruct(typ reflect.Type, prefix string, seen map[reflect.Type]bool) error {
+	if seen[typ] {
+		return nil
+	}
+	seen[typ] = true
+
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := prefix + fieldName(field)
+
+		if tag := field.Tag.Get("validate"); tag != "" && tag != "-" {
+			for _, rule := range strings.Split(tag, ",") {
+				rule, param, _ := strings.Cut(rule, "=")
+				if err := checkRule(typ, name, rule, param); err != nil {
+					return err
+				}
+			}
+		}
+
+		nested := field.Type
+		if nested.Kind() == reflect.Pointer {
+			nested = nested.Elem()
+		}
+		if nested.Kind() == reflect.Struct && nested.PkgPath() != "time" {
+			if err := checkStruct(nested, name+".", seen); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkRule reports whether rule and its param are supported on the field
+// name of parent.
+func checkRule(parent reflect.Type, name string, rule string, param string) error {
+	switch rule {
+	case "required", "omitempty", "email", "url", "password":
+		return nil
+	case "min", "max":
+		if _, err := strconv.ParseFloat(param, 64); err != nil {
+			return fmt.Errorf("validator: invalid %s parameter %q on field %s", rule, param, name)
+		}
+	case "oneof":
+		if len(strings.Fields(param)) == 0 {
+			return fmt.Errorf("validator: oneof on field %s has no options", name)
+		}
+	case "eqfield":
+		if _, ok := parent.FieldByName(param); !ok {
+			return fmt.Errorf("validator: eqfield on field %s names unknown field %q", name, param)
+		}
+	default:
+		return fmt.Errorf("validator: unknown rule %q on field %s", rule, name)
+	}
+
+	return nil
+}
+
+func validateStruct(value reflect.Value, prefix string, errs Errors) {
+	typ := value.Type()
+
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := prefix + fieldName(field)
+		fieldValue := value.Field(i)
+
+		if tag := field.Tag.Get("validate"); tag != "" && tag != "-" {
+			validateField(value, fieldValue, name, tag, errs)
+		}
+
+		nested := fieldValue
+		if nested.Kind() == reflect.Pointer && !nested.IsNil() {
+			nested = nested.Elem()
+		}
+		if nested.Kind() == reflect.Struct && nested.Type().PkgPath() != "time" {
+			validateStruct(nested, name+".", errs)
+		}
+	}
+}
+
+func validateField(parent reflect.Value, value reflect.Value, name string, tag string, errs Errors) {
+	rules := strings.Split(tag, ",")
+
+	// a zero value is only checked against the other rules when it is
+	// neither required nor optional; a nil pointer has nothing to check
+	if isZero(value) {
+		switch {
+		case contains(rules, "required"):
+			errs.add(name, "is required")
+			return
+		case contains(rules, "omitempty"), !value.IsValid(), value.Kind() == reflect.Pointer:
+			return
+		}
+	}
+
+	for value.Kind() == reflect.Pointer {
+		value = value.Elem()
+	}
+
+	for _, rule := range rules {
+		rule, param, _ := strings.Cut(rule, "=")
+		if err := checkRule(parent.Type(), name, rule, param); err != nil {
+			// a broken tag must not let the field through
+			errs.add(name, "cannot be validated")
+			continue
+		}
+
+		switch rule {
+		case "required", "omitempty":
+		case "email":
+			if address, err := mail.ParseAddress(value.String()); err != nil || address.Address != value.String() {
+				errs.add(name, "must be a valid email address")
+			}
+		case "url":
+			if u, err := url.ParseRequestURI(value.String()); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				errs.add(name, "must be an absolute http or https url")
+			}
+		case "password":
+			for _, err := range regex.PasswordErrors(value.String()) {
+				errs.add(name, err.Error())
+			}
+		case "min", "max":
+			validateBound(value, name, rule, param, errs)
+		case "oneof":
+			options := strings.Fields(param)
+			if !contains(options, fmt.Sprint(value.Interface())) {
+				errs.add(name, "must be one of "+strings.Join(options, ", "))
+			}
+		case "eqfield":
+			other := parent.FieldByName(param)
+			if !reflect.DeepEqual(value.Interface(), other.Interface()) {
+				otherField, _ := parent.Type().FieldByName(param)
+				errs.add(name, "must match "+fieldName(otherField))
+			}
+		}
+	}
+}
+
+func validateBound(value reflect.Value, name string, rule string, param string, errs Errors) {
+	// checkRule already parsed it
+	limit, _ := strconv.ParseFloat(param, 64)
+
+	var actual float64
+	var unit string
+
+	switch value.Kind() {
+	case reflect.String:
+		actual = float64(utf8.RuneCountInString(value.String()))
+		unit = " characters"
+	case reflect.Slice, reflect.Map, reflect.Array:
+		actual = float64(value.Len())
+		unit = " items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		actual = float64(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		actual = float64(value.Uint())
+	case reflect.Float32, reflect.Float64:
+		actual = value.Float()
+	default:
+		return
+	}
+
+	switch {
+	case rule == "min" && actual < limit:
+		errs.add(name, "must be at least "+param+unit)
+	case rule == "max" && actual > limit:
+		errs.add(name, "must be at most "+param+unit)
+	}
+}
+
+// fieldName returns the JSON name of a struct field.
+func fieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+
+	return name
+}
+
+func isZero(value reflect.Value) bool {
+	return !value.IsValid() || value.IsZero()
+}
+
+func contains(options []string, value string) bool {
+	for _, option := range options {
+		if strings.EqualFold(option, value) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,74 @@
+package webhook
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+)
+
+// blockedPrefixes are the non-public ranges netip has no predicate for.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// NewClient returns the client deliveries are sent with. It refuses to
+// connect to loopback, private, link-local and other non-public addresses,
+// such as the cloud metadata endpoint. The check runs on the address being
+// dialed, so it also covers redirects and host names that resolve to a
+// different address after the webhook was registered.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   timeout,
+		KeepAlive: 30 * time.Second,
+		Control:   denyNonPublic,
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// a proxy would be dialed instead of the destination
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+// denyNonPublic is a net.Dialer Control function rejecting non-public
+// destinations.
+func denyNonPublic(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+
+	if !IsPublic(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", customErr.ErrForbiddenDestination, addrPort.Addr())
+	}
+
+	return nil
+}
+
+// IsPublic reports whether addr may receive webhook deliveries.
+func IsPublic(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,56 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+)
+
+const (
+	EventHeader     = "X-ImageSmith-Event"
+	DeliveryHeader  = "X-ImageSmith-Delivery"
+	TimestampHeader = "X-ImageSmith-Timestamp"
+	SignatureHeader = "X-ImageSmith-Signature"
+
+	signaturePrefix = "sha256="
+)
+
+// Sign returns the signature header value for body sent at timestamp. The
+// HMAC-SHA256 covers "<unix timestamp>.<body>" so a captured request cannot
+// be replayed with a fresh timestamp.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the timestamp and signature headers of a received delivery.
+// Receivers should pass a tolerance of a few minutes to reject replays.
+func Verify(secret string, header http.Header, body []byte, tolerance time.Duration, now time.Time) error {
+	unix, err := strconv.ParseInt(header.Get(TimestampHeader), 10, 64)
+	if err != nil {
+		return customErr.ErrInvalidSignature
+	}
+
+	timestamp := time.Unix(unix, 0)
+	if now.Sub(timestamp).Abs() > tolerance {
+		return customErr.ErrSignatureExpired
+	}
+
+	signature := header.Get(SignatureHeader)
+	if !strings.HasPrefix(signature, signaturePrefix) ||
+		!hmac.Equal([]byte(signature), []byte(Sign(secret, timestamp, body))) {
+		return customErr.ErrInvalidSignature
+	}
+
+	return nil
+}
@@ -0,0 +1,777 @@
+package delivery_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/federicodosantos/image-smith/internal/delivery"
+	"github.com/federicodosantos/image-smith/internal/dto"
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	"github.com/federicodosantos/image-smith/pkg/middleware"
+	response "github.com/federicodosantos/image-smith/pkg/response"
+	"github.com/federicodosantos/image-smith/pkg/validator"
+	"go.uber.org/mock/gomock"
+)
+
+var imagesURL = "http://0.0.0.0/images"
+
+func multipartRequest(field, filename string, content []byte) *http.Request {
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile(field, filename)
+	part.Write(content)
+	writer.Close()
+
+	r := httptest.NewRequest(postMethod, imagesURL, body)
+	r.Header.Set("Content-Type", writer.FormDataContentType())
+	return r
+}
+
+func withUser(r *http.Request) *http.Request {
+	return r.WithContext(middleware.ContextWithUserID(r.Context(), "user-id"))
+}
+
+func TestUpload(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := NewMockIImageUsecase(ctrl)
+	imageHandler := delivery.NewImageHandler(mockUsecase, NewMockIJobUsecase(ctrl), 1<<20)
+
+	uploaded := &dto.ImageUploadResponse{ID: "image-id", ImageURL: "http://localhost/files/image.png"}
+	duplicate := &dto.ImageUploadResponse{ID: "existing-id", ImageURL: "http://localhost/files/existing.png", Duplicate: true}
+
+	rawRequest := httptest.NewRequest(postMethod, imagesURL, strings.NewReader("raw bytes"))
+	rawRequest.Header.Set("Content-Type", "image/png")
+
+	textRequest := httptest.NewRequest(postMethod, imagesURL, strings.NewReader("hello"))
+	textRequest.Header.Set("Content-Type", "text/plain")
+
+	oversized := bytes.Repeat([]byte{0}, 2<<20)
+
+	declaredRequest := httptest.NewRequest(postMethod, imagesURL, bytes.NewReader(oversized))
+	declaredRequest.Header.Set("Content-Type", "image/png")
+
+	// a chunked body only reveals its size while it is read
+	streamedRequest := httptest.NewRequest(postMethod, imagesURL, bytes.NewReader(oversized))
+	streamedRequest.Header.Set("Content-Type", "image/png")
+	streamedRequest.ContentLength = -1
+
+	type TestCase struct {
+		Name           string
+		Request        *http.Request
+		mockBehavior   func(mockUsecase *MockIImageUsecase)
+		expectedStatus int
+		expectedBody   *dto.ImageUploadResponse
+	}
+
+	testCases := []TestCase{
+		{
+			Name:    "Success - Multipart upload",
+			Request: withUser(multipartRequest("image", "photo.png", []byte("content"))),
+			mockBehavior: func(mockUsecase *MockIImageUsecase) {
+				mockUsecase.EXPECT().
+					Upload(gomock.Any(), "user-id", gomock.Any()).
+					DoAndReturn(func(_ any, _ string, req *dto.ImageUploadRequest) (*dto.ImageUploadResponse, error) {
+						if req.Filename != "photo.png" {
+							t.Errorf("filename = %s, want photo.png", req.Filename)
+						}
+						return uploaded, nil
+					})
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   uploaded,
+		},
+		{
+			Name:    "Success - Raw image body",
+			Request: withUser(rawRequest),
+			mockBehavior: func(mockUsecase *MockIImageUsecase) {
+				mockUsecase.EXPECT().
+					Upload(gomock.Any(), "user-id", gomock.Any()).
+					Return(uploaded, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   uploaded,
+		},
+		{
+			Name:    "Success - Duplicate returns the existing image",
+			Request: withUser(multipartRequest("image", "photo.png", []byte("content"))),
+			mockBehavior: func(mockUsecase *MockIImageUsecase) {
+				mockUsecase.EXPECT().
+					Upload(gomock.Any(), "user-id", gomock.Any()).
+					Return(duplicate, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   duplicate,
+		},
+		{
+			Name:           "Bad Request - Missing image field",
+			Request:        withUser(multipartRequest("document", "photo.png", []byte("content"))),
+			mockBehavior:   func(mockUsecase *MockIImageUsecase) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			Name:           "Bad Request - Unsupported content type",
+			Request:        withUser(textRequest),
+			mockBehavior:   func(mockUsecase *MockIImageUsecase) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			Name:    "Bad Request - Unsupported sniffed format",
+			Request: withUser(multipartRequest("image", "photo.png", []byte("content"))),
+			mockBehavior: func(mockUsecase *MockIImageUsecase) {
+				mockUsecase.EXPECT().
+					Upload(gomock.Any(), "user-id", gomock.Any()).
+					Return(nil, customErr.ErrUnsupportedFileFormat)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			Name:           "Payload Too Large - Declared length over the limit",
+			Request:        withUser(declaredRequest),
+			mockBehavior:   func(mockUsecase *MockIImageUsecase) {},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			Name:    "Payload Too Large - Body read past the limit",
+			Request: withUser(streamedRequest),
+			mockBehavior: func(mockUsecase *MockIImageUsecase) {
+				mockUsecase.EXPECT().
+					Upload(gomock.Any(), "user-id", gomock.Any()).
+					DoAndReturn(func(_ any, _ string, req *dto.ImageUploadRequest) (*dto.ImageUploadResponse, error) {
+						_, err := io.ReadAll(req.File)
+						return nil, err
+					})
+			},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			Name:    "Unprocessable Entity - Image exceeds the decoding limits",
+			Request: withUser(multipartRequest("image", "photo.png", []byte("content"))),
+			mockBehavior: func(mockUsecase *MockIImageUsecase) {
+				mockUsecase.EXPECT().
+					Upload(gomock.Any(), "user-id", gomock.Any()).
+					Return(nil, fmt.Errorf("%w: 20000x20000", customErr.ErrImageTooManyPixels))
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			Name:           "Unauthorized - User id not in context",
+			Request:        multipartRequest("image", "photo.png", []byte("content")),
+			mockBehavior:   func(mockUsecase *MockIImageUsecase) {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.mockBehavior(mockUsecase)
+
+			rec := httptest.NewRecorder()
+			imageHandler.Upload(rec, tc.Request)
+
+			res := rec.Result()
+			if res.StatusCode != tc.expectedStatus {
+				t.Errorf("imageHandler.Upload() status code = %v, want %v", res.StatusCode, tc.expectedStatus)
+			}
+
+			var actualData response.HttpResponse
+			json.NewDecoder(res.Body).Decode(&actualData)
+
+			if tc.expectedBody == nil {
+				if actualData.Message == "" {
+					t.Errorf("Error message should not be empty for failed response")
+				}
+				return
+			}
+
+			var actualBody dto.ImageUploadResponse
+			dataJSON, _ := json.Marshal(actualData.Data)
+			json.Unmarshal(dataJSON, &actualBody)
+
+			if actualBody != *tc.expectedBody {
+				t.Errorf("response data = %+v, want %+v", actualBody, *tc.expectedBody)
+			}
+		})
+	}
+}
+
+func TestTransform(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := NewMockIImageUsecase(ctrl)
+	imageHandler := delivery.NewImageHandler(mockUsecase, NewMockIJobUsecase(ctrl), 1<<20)
+	parentID := "image-id"
+
+	transformRequest := func(body string) *http.Request {
+		r := httptest.NewRequest(postMethod, imagesURL+"/image-id/transform", strings.NewReader(body))
+		r.SetPathValue("id", "image-id")
+		return withUser(r)
+	}
+
+	type TestCase struct {
+		Name           string
+		Request        *http.Request
+		mockBehavior   func(mockUsecase *MockIImageUsecase)
+		expectedStatus int
+	}
+
+	testCases := []TestCase{
+		{
+			Name:    "Success - Transform image",
+			Request: transformRequest(`{"resize": {"width": 300, "height": 200}, "convert": "PNG"}`),
+			mockBehavior: func(mockUsecase *MockIImageUsecase) {
+				mockUsecase.EXPECT().
+					Transform(gomock.Any(), "user-id", "image-id", &dto.ImageTransformRequest{
+						Resize:  &dto.ResizeRequest{Width: 300, Height: 200},
+						Convert: "PNG",
+					}).
+					Return(&dto.ImageTransformResponse{ID: "derived-id", ParentID: &parentID}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			Name:           "Bad Request - Invalid JSON",
+			Request:        transformRequest("invalid json"),
+			mockBehavior:   func(mockUsecase *MockIImageUsecase) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			Name:    "Bad Request - Invalid transformation parameters",
+			Request: transformRequest(`{"convert": "psd"}`),
+			mockBehavior: func(mockUsecase *MockIImageUsecase) {
+				mockUsecase.EXPECT().
+					Transform(gomock.Any(), "user-id", "image-id", gomock.Any()).
+					Return(nil, customErr.ErrInvalidTransformation)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			Name:    "Not Found - Image not found",
+			Request: transformRequest(`{"convert": "png"}`),
+			mockBehavior: func(mockUsecase *MockIImageUsecase) {
+				mockUsecase.EXPECT().
+					Transform(gomock.Any(), "user-id", "image-id", gomock.Any()).
+					Return(nil, customErr.ErrImageNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.mockBehavior(mockUsecase)
+
+			rec := httptest.NewRecorder()
+			imageHandler.Transform(rec, tc.Request)
+
+			if rec.Code != tc.expectedStatus {
+				t.Errorf("imageHandler.Transform() status code = %v, want %v", rec.Code, tc.expectedStatus)
+			}
+		})
+	}
+}
+
+func TestGetAndDelete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := NewMockIImageUsecase(ctrl)
+	imageHandler := delivery.NewImageHandler(mockUsecase, NewMockIJobUsecase(ctrl), 1<<20)
+
+	imageRequest := func(method string) *http.Request {
+		r := httptest.NewRequest(method, imagesURL+"/image-id", nil)
+		r.SetPathValue("id", "image-id")
+		return withUser(r)
+	}
+
+	focalRequest := func(body string) *http.Request {
+		r := httptest.NewRequest(http.MethodPut, imagesURL+"/image-id/focal-point", strings.NewReader(body))
+		r.SetPathValue("id", "image-id")
+		return withUser(r)
+	}
+
+	type TestCase struct {
+		Name           string
+		Handler        http.HandlerFunc
+		Request        *http.Request
+		mockBehavior   func(mockUsecase *MockIImageUsecase)
+		expectedStatus int
+	}
+
+	testCases := []TestCase{
+		{
+			Name:    "Success - Get image",
+			Handler: imageHandler.Get,
+			Request: imageRequest(http.MethodGet),
+			mockBehavior: func(mockUsecase *MockIImageUsecase) {
+				mockUsecase.EXPECT().Get(gomock.Any(), "user-id", "image-id").
+					Return(&dto.ImageResponse{ID: "image-id"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			Name:    "Not Found - Get image",
+			Handler: imageHandler.Get,
+			Request: imageRequest(http.MethodGet),
+			mockBehavior: func(mockUsecase *MockIImageUsecase) {
+				mockUsecase.EXPECT().Get(gomock.Any(), "user-id", "image-id").
+					Return(nil, customErr.ErrImageNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			Name:    "Success - Set focal point",
+			Handler: imageHandler.SetFocalPoint,
+			Request: focalRequest(`{"x":0.25,"y":1}`),
+			mockBehavior: func(mockUsecase *MockIImageUsecase) {
+				mockUsecase.EXPECT().SetFocalPoint(gomock.Any(), "user-id", "image-id", gomock.Any()).
+					Return(&dto.ImageResponse{ID: "image-id", FocalPoint: &dto.FocalPoint{X: 0.25, Y: 1}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			Name:    "Unprocessable Entity - Focal point out of range",
+			Handler: imageHandler.SetFocalPoint,
+			Request: focalRequest(`{"x":1.5}`),
+			mockBehavior: func(mockUsecase *MockIImageUsecase) {
+				mockUsecase.EXPECT().SetFocalPoint(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			Name:    "Not Found - Clear focal point",
+			Handler: imageHandler.ClearFocalPoint,
+			Request: imageRequest(http.MethodDelete),
+			mockBehavior: func(mockUsecase *MockIImageUsecase) {
+				mockUsecase.EXPECT().ClearFocalPoint(gomock.Any(), "user-id", "image-id").
+					Return(nil, customErr.ErrImageNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			Name:    "Success - Delete image",
+			Handler: imageHandler.Delete,
+			Request: imageRequest(http.MethodDelete),
+			mockBehavior: func(mockUsecase *MockIImageUsecase) {
+				mockUsecase.EXPECT().Delete(gomock.Any(), "user-id", "image-id").Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			Name:    "Not Found - Delete image",
+			Handler: imageHandler.Delete,
+			Request: imageRequest(http.MethodDelete),
+			mockBehavior: func(mockUsecase *MockIImageUsecase) {
+				mockUsecase.EXPECT().Delete(gomock.Any(), "user-id", "image-id").
+					Return(customErr.ErrImageNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.mockBehavior(mockUsecase)
+
+			rec := httptest.NewRecorder()
+			tc.Handler(rec, tc.Request)
+
+			if rec.Code != tc.expectedStatus {
+				t.Errorf("status code = %v, want %v", rec.Code, tc.expectedStatus)
+			}
+		})
+	}
+}
+
+func TestList(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := NewMockIImageUsecase(ctrl)
+	imageHandler := delivery.NewImageHandler(mockUsecase, NewMockIJobUsecase(ctrl), 1<<20)
+
+	listRequest := func(query string) *http.Request {
+		return withUser(httptest.NewRequest(http.MethodGet, imagesURL+"?"+query, nil))
+	}
+
+	type TestCase struct {
+		Name               string
+		Request            *http.Request
+		mockBehavior       func(mockUsecase *MockIImageUsecase)
+		expectedStatus     int
+		expectedPagination *response.Pagination
+	}
+
+	testCases := []TestCase{
+		{
+			Name:    "Success - Filtered page",
+			Request: listRequest("limit=2&format=png&min_size=10&created_after=2025-01-01T00:00:00Z&originals_only=true&sort=size&order=asc"),
+			mockBehavior: func(mockUsecase *MockIImageUsecase) {
+				mockUsecase.EXPECT().
+					List(gomock.Any(), "user-id", gomock.Any()).
+					DoAndReturn(func(_ any, _ string, req *dto.ImageListRequest) (*dto.ImageListResponse, error) {
+						if req.Limit != 2 || req.Format != "png" || *req.MinSize != 10 || !req.OriginalsOnly ||
+							req.Sort != "size" || req.Order != "asc" || req.CreatedAfter == nil {
+							t.Errorf("unexpected list request %+v", req)
+						}
+						return &dto.ImageListResponse{
+							Images:     []*dto.ImageResponse{{ID: "a"}, {ID: "b"}},
+							Limit:      2,
+							HasMore:    true,
+							NextCursor: "next",
+						}, nil
+					})
+			},
+			expectedStatus:     http.StatusOK,
+			expectedPagination: &response.Pagination{Limit: 2, HasMore: true, NextCursor: "next"},
+		},
+		{
+			Name:           "Unprocessable - Malformed parameters",
+			Request:        listRequest("limit=ten&created_before=yesterday"),
+			mockBehavior:   func(mockUsecase *MockIImageUsecase) {},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			Name:           "Unprocessable - Unknown sort",
+			Request:        listRequest("sort=name"),
+			mockBehavior:   func(mockUsecase *MockIImageUsecase) {},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			Name:    "Unprocessable - Filter rejected by usecase",
+			Request: listRequest("cursor=bogus"),
+			mockBehavior: func(mockUsecase *MockIImageUsecase) {
+				mockUsecase.EXPECT().List(gomock.Any(), "user-id", gomock.Any()).
+					Return(nil, validator.Errors{"cursor": {"invalid cursor"}})
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.mockBehavior(mockUsecase)
+
+			rec := httptest.NewRecorder()
+			imageHandler.List(rec, tc.Request)
+
+			if rec.Code != tc.expectedStatus {
+				t.Errorf("status code = %v, want %v", rec.Code, tc.expectedStatus)
+			}
+
+			if tc.expectedPagination != nil {
+				var body response.HttpResponse
+				json.NewDecoder(rec.Body).Decode(&body)
+
+				if *body.Pagination != *tc.expectedPagination {
+					t.Errorf("pagination = %+v, want %+v", body.Pagination, tc.expectedPagination)
+				}
+			}
+		})
+	}
+}
+
+func TestSimilar(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := NewMockIImageUsecase(ctrl)
+	imageHandler := delivery.NewImageHandler(mockUsecase, NewMockIJobUsecase(ctrl), 1<<20)
+
+	similarRequest := func(query string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, imagesURL+"/image-id/similar?"+query, nil)
+		r.SetPathValue("id", "image-id")
+		return withUser(r)
+	}
+
+	type TestCase struct {
+		Name           string
+		Request        *http.Request
+		mockBehavior   func(mockUsecase *MockIImageUsecase)
+		expectedStatus int
+	}
+
+	testCases := []TestCase{
+		{
+			Name:    "Success - Images within the distance",
+			Request: similarRequest("max_distance=6&limit=5"),
+			mockBehavior: func(mockUsecase *MockIImageUsecase) {
+				mockUsecase.EXPECT().
+					Similar(gomock.Any(), "user-id", "image-id", gomock.Any()).
+					DoAndReturn(func(_ any, _ string, _ string, req *dto.SimilarImagesRequest) ([]*dto.SimilarImageResponse, error) {
+						if *req.MaxDistance != 6 || req.Limit != 5 {
+							t.Errorf("unexpected similar request %+v", req)
+						}
+						return []*dto.SimilarImageResponse{{ImageResponse: &dto.ImageResponse{ID: "other-id"}, Distance: 2}}, nil
+					})
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			Name:           "Unprocessable - Malformed distance",
+			Request:        similarRequest("max_distance=close"),
+			mockBehavior:   func(mockUsecase *MockIImageUsecase) {},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			Name:           "Unprocessable - Distance over 64 bits",
+			Request:        similarRequest("max_distance=65"),
+			mockBehavior:   func(mockUsecase *MockIImageUsecase) {},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			Name:    "Unprocessable - Image without a hash",
+			Request: similarRequest(""),
+			mockBehavior: func(mockUsecase *MockIImageUsecase) {
+				mockUsecase.EXPECT().Similar(gomock.Any(), "user-id", "image-id", gomock.Any()).
+					Return(nil, customErr.ErrImageNotHashed)
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			Name:    "Not Found - Image of another user",
+			Request: similarRequest(""),
+			mockBehavior: func(mockUsecase *MockIImageUsecase) {
+				mockUsecase.EXPECT().Similar(gomock.Any(), "user-id", "image-id", gomock.Any()).
+					Return(nil, customErr.ErrImageNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.mockBehavior(mockUsecase)
+
+			rec := httptest.NewRecorder()
+			imageHandler.Similar(rec, tc.Request)
+
+			if rec.Code != tc.expectedStatus {
+				t.Errorf("status code = %v, want %v", rec.Code, tc.expectedStatus)
+			}
+		})
+	}
+}
+
+func TestCreateUploadURL(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := NewMockIImageUsecase(ctrl)
+	imageHandler := delivery.NewImageHandler(mockUsecase, NewMockIJobUsecase(ctrl), 1<<20)
+
+	type TestCase struct {
+		Name           string
+		Body           string
+		mockBehavior   func(mockUsecase *MockIImageUsecase)
+		expectedStatus int
+	}
+
+	testCases := []TestCase{
+		{
+			Name: "Success - Upload url created",
+			Body: `{"filename": "scan.tif", "content_type": "image/tiff", "size": 4096}`,
+			mockBehavior: func(mockUsecase *MockIImageUsecase) {
+				mockUsecase.EXPECT().
+					CreateUploadURL(gomock.Any(), "user-id", &dto.ImageUploadURLRequest{Filename: "scan.tif", ContentType: "image/tiff", Size: 4096}).
+					Return(&dto.ImageUploadURLResponse{ID: "image-id", UploadURL: "http://storage/upload", Method: "PUT"}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			Name:           "Unprocessable - Missing size",
+			Body:           `{"content_type": "image/png"}`,
+			mockBehavior:   func(mockUsecase *MockIImageUsecase) {},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			Name: "Unprocessable - Unsupported content type",
+			Body: `{"content_type": "application/pdf", "size": 4096}`,
+			mockBehavior: func(mockUsecase *MockIImageUsecase) {
+				mockUsecase.EXPECT().CreateUploadURL(gomock.Any(), "user-id", gomock.Any()).
+					Return(nil, validator.Errors{"content_type": {"must be a supported image type"}})
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			Name:           "Payload Too Large - Declared size over the limit",
+			Body:           `{"content_type": "image/png", "size": 2097152}`,
+			mockBehavior:   func(mockUsecase *MockIImageUsecase) {},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.mockBehavior(mockUsecase)
+
+			rec := httptest.NewRecorder()
+			imageHandler.CreateUploadURL(rec, withUser(httptest.NewRequest(http.MethodPost, imagesURL+"/upload-url", strings.NewReader(tc.Body))))
+
+			if rec.Code != tc.expectedStatus {
+				t.Errorf("imageHandler.CreateUploadURL() status code = %v, want %v", rec.Code, tc.expectedStatus)
+			}
+		})
+	}
+}
+
+func TestComplete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := NewMockIImageUsecase(ctrl)
+	imageHandler := delivery.NewImageHandler(mockUsecase, NewMockIJobUsecase(ctrl), 1<<20)
+
+	type TestCase struct {
+		Name           string
+		mockBehavior   func(mockUsecase *MockIImageUsecase)
+		expectedStatus int
+	}
+
+	testCases := []TestCase{
+		{
+			Name: "Success - Image ready",
+			mockBehavior: func(mockUsecase *MockIImageUsecase) {
+				mockUsecase.EXPECT().Complete(gomock.Any(), "user-id", "image-id").Return(&dto.ImageUploadResponse{ID: "image-id"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			Name: "Conflict - Nothing uploaded yet",
+			mockBehavior: func(mockUsecase *MockIImageUsecase) {
+				mockUsecase.EXPECT().Complete(gomock.Any(), "user-id", "image-id").Return(nil, customErr.ErrUploadIncomplete)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			Name: "Payload Too Large - More uploaded than declared",
+			mockBehavior: func(mockUsecase *MockIImageUsecase) {
+				mockUsecase.EXPECT().Complete(gomock.Any(), "user-id", "image-id").Return(nil, customErr.ErrPayloadTooLarge)
+			},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			Name: "Bad Request - Not an image",
+			mockBehavior: func(mockUsecase *MockIImageUsecase) {
+				mockUsecase.EXPECT().Complete(gomock.Any(), "user-id", "image-id").Return(nil, customErr.ErrUnsupportedFileFormat)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			Name: "Not Found - Expired or already completed",
+			mockBehavior: func(mockUsecase *MockIImageUsecase) {
+				mockUsecase.EXPECT().Complete(gomock.Any(), "user-id", "image-id").Return(nil, customErr.ErrImageNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.mockBehavior(mockUsecase)
+
+			r := httptest.NewRequest(http.MethodPost, imagesURL+"/image-id/complete", nil)
+			r.SetPathValue("id", "image-id")
+
+			rec := httptest.NewRecorder()
+			imageHandler.Complete(rec, withUser(r))
+
+			if rec.Code != tc.expectedStatus {
+				t.Errorf("imageHandler.Complete() status code = %v, want %v", rec.Code, tc.expectedStatus)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := NewMockIImageUsecase(ctrl)
+	imageHandler := delivery.NewImageHandler(mockUsecase, NewMockIJobUsecase(ctrl), 1<<20)
+
+	renderRequest := func(query string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, imagesURL+"/image-id/render?"+query, nil)
+		r.SetPathValue("id", "image-id")
+		return withUser(r)
+	}
+
+	t.Run("Success - Streams rendered image with cache headers", func(t *testing.T) {
+		mockUsecase.EXPECT().
+			Render(gomock.Any(), "user-id", "image-id", &dto.ImageRenderRequest{Width: 100, Height: 50, Fit: "inside", Format: "png", Quality: 0}).
+			Return(&dto.ImageRenderResponse{
+				Body:        io.NopCloser(strings.NewReader("pixels")),
+				ContentType: "image/png",
+				Size:        6,
+				ETag:        `"hash"`,
+			}, nil)
+
+		rec := httptest.NewRecorder()
+		imageHandler.Render(rec, renderRequest("w=100&h=50&fit=inside&fmt=png"))
+
+		if got := rec.Code; got != http.StatusOK {
+			t.Errorf("status code = %v, want %v", got, http.StatusOK)
+		}
+		if got := rec.Header().Get("Content-Type"); got != "image/png" {
+			t.Errorf("Content-Type = %v, want %v", got, "image/png")
+		}
+		if got := rec.Header().Get("ETag"); got != `"hash"` {
+			t.Errorf("ETag = %v, want %v", got, `"hash"`)
+		}
+		if rec.Header().Get("Cache-Control") == "" {
+			t.Errorf("Cache-Control is empty")
+		}
+		if got := rec.Body.String(); got != "pixels" {
+			t.Errorf("body = %v, want %v", got, "pixels")
+		}
+	})
+
+	t.Run("Success - Not modified", func(t *testing.T) {
+		r := renderRequest("w=100")
+		r.Header.Set("If-None-Match", `"hash"`)
+
+		mockUsecase.EXPECT().
+			Render(gomock.Any(), "user-id", "image-id", &dto.ImageRenderRequest{Width: 100, IfNoneMatch: `"hash"`}).
+			Return(&dto.ImageRenderResponse{ETag: `"hash"`, NotModified: true}, nil)
+
+		rec := httptest.NewRecorder()
+		imageHandler.Render(rec, r)
+
+		if got := rec.Code; got != http.StatusNotModified {
+			t.Errorf("status code = %v, want %v", got, http.StatusNotModified)
+		}
+		if got := rec.Body.String(); got != "" {
+			t.Errorf("body = %q, want empty", got)
+		}
+	})
+
+	t.Run("Bad Request - Malformed width", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		imageHandler.Render(rec, renderRequest("w=wide"))
+
+		if got := rec.Code; got != http.StatusBadRequest {
+			t.Errorf("status code = %v, want %v", got, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("Not Found - Image of another user", func(t *testing.T) {
+		mockUsecase.EXPECT().Render(gomock.Any(), "user-id", "image-id", gomock.Any()).
+			Return(nil, customErr.ErrImageNotFound)
+
+		rec := httptest.NewRecorder()
+		imageHandler.Render(rec, renderRequest(""))
+
+		if got := rec.Code; got != http.StatusNotFound {
+			t.Errorf("status code = %v, want %v", got, http.StatusNotFound)
+		}
+	})
+}
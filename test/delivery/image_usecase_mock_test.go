@@ -0,0 +1,251 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/usecase/image_usecase.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/usecase/image_usecase.go -destination=test/delivery/image_usecase_mock_test.go -package=delivery_test
+//
+
+// Package delivery_test is a generated GoMock package.
+package delivery_test
+
+import (
+	context "context"
+	reflect "reflect"
+
+	dto "github.com/federicodosantos/image-smith/internal/dto"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIImageUsecase is a mock of IImageUsecase interface.
+type MockIImageUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockIImageUsecaseMockRecorder
+	isgomock struct{}
+}
+
+// MockIImageUsecaseMockRecorder is the mock recorder for MockIImageUsecase.
+type MockIImageUsecaseMockRecorder struct {
+	mock *MockIImageUsecase
+}
+
+// NewMockIImageUsecase creates a new mock instance.
+func NewMockIImageUsecase(ctrl *gomock.Controller) *MockIImageUsecase {
+	mock := &MockIImageUsecase{ctrl: ctrl}
+	mock.recorder = &MockIImageUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIImageUsecase) EXPECT() *MockIImageUsecaseMockRecorder {
+	return m.recorder
+}
+
+// Assemble mocks base method.
+func (m *MockIImageUsecase) Assemble(ctx context.Context, userID, filename string, parts []string) (*dto.ImageUploadResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Assemble", ctx, userID, filename, parts)
+	ret0, _ := ret[0].(*dto.ImageUploadResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Assemble indicates an expected call of Assemble.
+func (mr *MockIImageUsecaseMockRecorder) Assemble(ctx, userID, filename, parts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Assemble", reflect.TypeOf((*MockIImageUsecase)(nil).Assemble), ctx, userID, filename, parts)
+}
+
+// ClearFocalPoint mocks base method.
+func (m *MockIImageUsecase) ClearFocalPoint(ctx context.Context, userID, imageID string) (*dto.ImageResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearFocalPoint", ctx, userID, imageID)
+	ret0, _ := ret[0].(*dto.ImageResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClearFocalPoint indicates an expected call of ClearFocalPoint.
+func (mr *MockIImageUsecaseMockRecorder) ClearFocalPoint(ctx, userID, imageID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearFocalPoint", reflect.TypeOf((*MockIImageUsecase)(nil).ClearFocalPoint), ctx, userID, imageID)
+}
+
+// Complete mocks base method.
+func (m *MockIImageUsecase) Complete(ctx context.Context, userID, imageID string) (*dto.ImageUploadResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Complete", ctx, userID, imageID)
+	ret0, _ := ret[0].(*dto.ImageUploadResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Complete indicates an expected call of Complete.
+func (mr *MockIImageUsecaseMockRecorder) Complete(ctx, userID, imageID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Complete", reflect.TypeOf((*MockIImageUsecase)(nil).Complete), ctx, userID, imageID)
+}
+
+// CreateUploadURL mocks base method.
+func (m *MockIImageUsecase) CreateUploadURL(ctx context.Context, userID string, req *dto.ImageUploadURLRequest) (*dto.ImageUploadURLResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUploadURL", ctx, userID, req)
+	ret0, _ := ret[0].(*dto.ImageUploadURLResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUploadURL indicates an expected call of CreateUploadURL.
+func (mr *MockIImageUsecaseMockRecorder) CreateUploadURL(ctx, userID, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUploadURL", reflect.TypeOf((*MockIImageUsecase)(nil).CreateUploadURL), ctx, userID, req)
+}
+
+// Delete mocks base method.
+func (m *MockIImageUsecase) Delete(ctx context.Context, userID, imageID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, userID, imageID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockIImageUsecaseMockRecorder) Delete(ctx, userID, imageID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockIImageUsecase)(nil).Delete), ctx, userID, imageID)
+}
+
+// ExpirePendingImages mocks base method.
+func (m *MockIImageUsecase) ExpirePendingImages(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpirePendingImages", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpirePendingImages indicates an expected call of ExpirePendingImages.
+func (mr *MockIImageUsecaseMockRecorder) ExpirePendingImages(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpirePendingImages", reflect.TypeOf((*MockIImageUsecase)(nil).ExpirePendingImages), ctx)
+}
+
+// Get mocks base method.
+func (m *MockIImageUsecase) Get(ctx context.Context, userID, imageID string) (*dto.ImageResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, userID, imageID)
+	ret0, _ := ret[0].(*dto.ImageResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockIImageUsecaseMockRecorder) Get(ctx, userID, imageID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockIImageUsecase)(nil).Get), ctx, userID, imageID)
+}
+
+// List mocks base method.
+func (m *MockIImageUsecase) List(ctx context.Context, userID string, req *dto.ImageListRequest) (*dto.ImageListResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, userID, req)
+	ret0, _ := ret[0].(*dto.ImageListResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockIImageUsecaseMockRecorder) List(ctx, userID, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockIImageUsecase)(nil).List), ctx, userID, req)
+}
+
+// Render mocks base method.
+func (m *MockIImageUsecase) Render(ctx context.Context, userID, imageID string, req *dto.ImageRenderRequest) (*dto.ImageRenderResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Render", ctx, userID, imageID, req)
+	ret0, _ := ret[0].(*dto.ImageRenderResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Render indicates an expected call of Render.
+func (mr *MockIImageUsecaseMockRecorder) Render(ctx, userID, imageID, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Render", reflect.TypeOf((*MockIImageUsecase)(nil).Render), ctx, userID, imageID, req)
+}
+
+// RenderPublic mocks base method.
+func (m *MockIImageUsecase) RenderPublic(ctx context.Context, imageID string, req *dto.ImageRenderRequest) (*dto.ImageRenderResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenderPublic", ctx, imageID, req)
+	ret0, _ := ret[0].(*dto.ImageRenderResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RenderPublic indicates an expected call of RenderPublic.
+func (mr *MockIImageUsecaseMockRecorder) RenderPublic(ctx, imageID, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenderPublic", reflect.TypeOf((*MockIImageUsecase)(nil).RenderPublic), ctx, imageID, req)
+}
+
+// SetFocalPoint mocks base method.
+func (m *MockIImageUsecase) SetFocalPoint(ctx context.Context, userID, imageID string, req *dto.FocalPointRequest) (*dto.ImageResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetFocalPoint", ctx, userID, imageID, req)
+	ret0, _ := ret[0].(*dto.ImageResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetFocalPoint indicates an expected call of SetFocalPoint.
+func (mr *MockIImageUsecaseMockRecorder) SetFocalPoint(ctx, userID, imageID, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetFocalPoint", reflect.TypeOf((*MockIImageUsecase)(nil).SetFocalPoint), ctx, userID, imageID, req)
+}
+
+// Similar mocks base method.
+func (m *MockIImageUsecase) Similar(ctx context.Context, userID, imageID string, req *dto.SimilarImagesRequest) ([]*dto.SimilarImageResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Similar", ctx, userID, imageID, req)
+	ret0, _ := ret[0].([]*dto.SimilarImageResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Similar indicates an expected call of Similar.
+func (mr *MockIImageUsecaseMockRecorder) Similar(ctx, userID, imageID, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Similar", reflect.TypeOf((*MockIImageUsecase)(nil).Similar), ctx, userID, imageID, req)
+}
+
+// Transform mocks base method.
+func (m *MockIImageUsecase) Transform(ctx context.Context, userID, imageID string, req *dto.ImageTransformRequest) (*dto.ImageTransformResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transform", ctx, userID, imageID, req)
+	ret0, _ := ret[0].(*dto.ImageTransformResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Transform indicates an expected call of Transform.
+func (mr *MockIImageUsecaseMockRecorder) Transform(ctx, userID, imageID, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transform", reflect.TypeOf((*MockIImageUsecase)(nil).Transform), ctx, userID, imageID, req)
+}
+
+// Upload mocks base method.
+func (m *MockIImageUsecase) Upload(ctx context.Context, userID string, req *dto.ImageUploadRequest) (*dto.ImageUploadResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upload", ctx, userID, req)
+	ret0, _ := ret[0].(*dto.ImageUploadResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Upload indicates an expected call of Upload.
+func (mr *MockIImageUsecaseMockRecorder) Upload(ctx, userID, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upload", reflect.TypeOf((*MockIImageUsecase)(nil).Upload), ctx, userID, req)
+}
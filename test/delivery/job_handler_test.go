@@ -0,0 +1,127 @@
+package delivery_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/federicodosantos/image-smith/internal/delivery"
+	"github.com/federicodosantos/image-smith/internal/dto"
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	"go.uber.org/mock/gomock"
+)
+
+func TestTransformAsync(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockJobUsecase := NewMockIJobUsecase(ctrl)
+	imageHandler := delivery.NewImageHandler(NewMockIImageUsecase(ctrl), mockJobUsecase, 1<<20)
+
+	transformRequest := func(query string, body string) *http.Request {
+		r := httptest.NewRequest(postMethod, imagesURL+"/image-id/transform?"+query, strings.NewReader(body))
+		r.SetPathValue("id", "image-id")
+		return withUser(r)
+	}
+
+	type TestCase struct {
+		Name           string
+		Request        *http.Request
+		mockBehavior   func(mockJobUsecase *MockIJobUsecase)
+		expectedStatus int
+	}
+
+	testCases := []TestCase{
+		{
+			Name:    "Accepted - Transformation queued",
+			Request: transformRequest("async=true", `{"resize": {"width": 300}}`),
+			mockBehavior: func(mockJobUsecase *MockIJobUsecase) {
+				mockJobUsecase.EXPECT().
+					EnqueueTransform(gomock.Any(), "user-id", "image-id", &dto.ImageTransformRequest{
+						Resize: &dto.ResizeRequest{Width: 300},
+					}).
+					Return(&dto.JobResponse{ID: "job-id", Status: "queued"}, nil)
+			},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			Name:           "Bad Request - Malformed async flag",
+			Request:        transformRequest("async=maybe", `{"convert": "png"}`),
+			mockBehavior:   func(mockJobUsecase *MockIJobUsecase) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			Name:    "Bad Request - Invalid transformation parameters",
+			Request: transformRequest("async=1", `{}`),
+			mockBehavior: func(mockJobUsecase *MockIJobUsecase) {
+				mockJobUsecase.EXPECT().EnqueueTransform(gomock.Any(), "user-id", "image-id", gomock.Any()).
+					Return(nil, customErr.ErrInvalidTransformation)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			Name:    "Not Found - Image not found",
+			Request: transformRequest("async=true", `{"convert": "png"}`),
+			mockBehavior: func(mockJobUsecase *MockIJobUsecase) {
+				mockJobUsecase.EXPECT().EnqueueTransform(gomock.Any(), "user-id", "image-id", gomock.Any()).
+					Return(nil, customErr.ErrImageNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.mockBehavior(mockJobUsecase)
+
+			rec := httptest.NewRecorder()
+			imageHandler.Transform(rec, tc.Request)
+
+			if rec.Code != tc.expectedStatus {
+				t.Errorf("imageHandler.Transform() status code = %v, want %v", rec.Code, tc.expectedStatus)
+			}
+
+			if rec.Code == http.StatusAccepted && rec.Header().Get("Location") != "/jobs/job-id" {
+				t.Errorf("Location = %q, want /jobs/job-id", rec.Header().Get("Location"))
+			}
+		})
+	}
+}
+
+func TestGetJob(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockJobUsecase := NewMockIJobUsecase(ctrl)
+	jobHandler := delivery.NewJobHandler(mockJobUsecase)
+
+	jobRequest := func() *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "http://0.0.0.0/jobs/job-id", nil)
+		r.SetPathValue("id", "job-id")
+		return withUser(r)
+	}
+
+	t.Run("Success - Get own job", func(t *testing.T) {
+		mockJobUsecase.EXPECT().Get(gomock.Any(), "user-id", "job-id").
+			Return(&dto.JobResponse{ID: "job-id", Status: "succeeded"}, nil)
+
+		rec := httptest.NewRecorder()
+		jobHandler.Get(rec, jobRequest())
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("status code = %v, want %v", rec.Code, http.StatusOK)
+		}
+	})
+
+	t.Run("Not Found - Job of another user", func(t *testing.T) {
+		mockJobUsecase.EXPECT().Get(gomock.Any(), "user-id", "job-id").Return(nil, customErr.ErrJobNotFound)
+
+		rec := httptest.NewRecorder()
+		jobHandler.Get(rec, jobRequest())
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("status code = %v, want %v", rec.Code, http.StatusNotFound)
+		}
+	})
+}
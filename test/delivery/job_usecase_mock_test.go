@@ -0,0 +1,72 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/usecase/job_usecase.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/usecase/job_usecase.go -destination=test/delivery/job_usecase_mock_test.go -package=delivery_test
+//
+
+// Package delivery_test is a generated GoMock package.
+package delivery_test
+
+import (
+	context "context"
+	reflect "reflect"
+
+	dto "github.com/federicodosantos/image-smith/internal/dto"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIJobUsecase is a mock of IJobUsecase interface.
+type MockIJobUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockIJobUsecaseMockRecorder
+	isgomock struct{}
+}
+
+// MockIJobUsecaseMockRecorder is the mock recorder for MockIJobUsecase.
+type MockIJobUsecaseMockRecorder struct {
+	mock *MockIJobUsecase
+}
+
+// NewMockIJobUsecase creates a new mock instance.
+func NewMockIJobUsecase(ctrl *gomock.Controller) *MockIJobUsecase {
+	mock := &MockIJobUsecase{ctrl: ctrl}
+	mock.recorder = &MockIJobUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIJobUsecase) EXPECT() *MockIJobUsecaseMockRecorder {
+	return m.recorder
+}
+
+// EnqueueTransform mocks base method.
+func (m *MockIJobUsecase) EnqueueTransform(ctx context.Context, userID, imageID string, req *dto.ImageTransformRequest) (*dto.JobResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnqueueTransform", ctx, userID, imageID, req)
+	ret0, _ := ret[0].(*dto.JobResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnqueueTransform indicates an expected call of EnqueueTransform.
+func (mr *MockIJobUsecaseMockRecorder) EnqueueTransform(ctx, userID, imageID, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnqueueTransform", reflect.TypeOf((*MockIJobUsecase)(nil).EnqueueTransform), ctx, userID, imageID, req)
+}
+
+// Get mocks base method.
+func (m *MockIJobUsecase) Get(ctx context.Context, userID, jobID string) (*dto.JobResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, userID, jobID)
+	ret0, _ := ret[0].(*dto.JobResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockIJobUsecaseMockRecorder) Get(ctx, userID, jobID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockIJobUsecase)(nil).Get), ctx, userID, jobID)
+}
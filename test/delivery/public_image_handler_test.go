@@ -0,0 +1,120 @@
+package delivery_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/federicodosantos/image-smith/internal/delivery"
+	"github.com/federicodosantos/image-smith/internal/dto"
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	response "github.com/federicodosantos/image-smith/pkg/response"
+	"github.com/federicodosantos/image-smith/pkg/urlsign"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSignedURL(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := NewMockIImageUsecase(ctrl)
+	signer, _ := urlsign.NewSigner("signing-key")
+
+	router := http.NewServeMux()
+	handler := delivery.NewPublicImageHandler(mockUsecase, signer, "http://images.example/")
+	delivery.PublicImageRoutes(router, handler, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, withUser(r))
+		})
+	})
+
+	mockUsecase.EXPECT().Get(gomock.Any(), "user-id", "image-id").Return(&dto.ImageResponse{ID: "image-id"}, nil)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(postMethod, imagesURL+"/image-id/signed-url",
+		strings.NewReader(`{"w": 100, "fmt": "png", "expires_in": 60}`)))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status code = %v, want %v", rec.Code, http.StatusOK)
+	}
+
+	var body struct {
+		Obj dto.SignedURLResponse `json:"obj"`
+	}
+	json.NewDecoder(rec.Body).Decode(&body)
+
+	signedURL, err := url.Parse(body.Obj.URL)
+	if err != nil || signedURL.Host != "images.example" || signedURL.Path != "/public/images/image-id" {
+		t.Fatalf("unexpected signed url %q", body.Obj.URL)
+	}
+	if strings.Contains(signedURL.RawQuery, "user-id") {
+		t.Errorf("signed url %q reveals the owner", body.Obj.URL)
+	}
+	if until := time.Until(body.Obj.ExpiresAt); until <= 0 || until > time.Minute {
+		t.Errorf("expires_at = %v, want about a minute from now", body.Obj.ExpiresAt)
+	}
+
+	publicRequest := func(rawQuery string) *http.Request {
+		return httptest.NewRequest(http.MethodGet, "http://images.example/public/images/image-id?"+rawQuery, nil)
+	}
+
+	t.Run("Success - Signed url renders without a token", func(t *testing.T) {
+		mockUsecase.EXPECT().
+			RenderPublic(gomock.Any(), "image-id", &dto.ImageRenderRequest{Width: 100, Format: "png"}).
+			Return(&dto.ImageRenderResponse{
+				Body:        io.NopCloser(strings.NewReader("pixels")),
+				ContentType: "image/png",
+				Size:        6,
+				ETag:        `"hash"`,
+			}, nil)
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, publicRequest(signedURL.RawQuery))
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("status code = %v, want %v", rec.Code, http.StatusOK)
+		}
+		if cacheControl := rec.Header().Get("Cache-Control"); !strings.HasPrefix(cacheControl, "public, max-age=") {
+			t.Errorf("Cache-Control = %q, want public max-age", cacheControl)
+		}
+	})
+
+	t.Run("Forbidden - Tampered parameters", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, publicRequest(strings.Replace(signedURL.RawQuery, "w=100", "w=4000", 1)))
+
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("status code = %v, want %v", rec.Code, http.StatusForbidden)
+		}
+	})
+
+	t.Run("Forbidden - Expired url", func(t *testing.T) {
+		expired := signer.Sign("/public/images/image-id", url.Values{"w": {"100"}}, time.Now().Add(-time.Second))
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, publicRequest(expired.Encode()))
+
+		var body response.HttpResponse
+		json.NewDecoder(rec.Body).Decode(&body)
+
+		if rec.Code != http.StatusForbidden || body.Message != customErr.ErrSignatureExpired.Error() {
+			t.Errorf("got %v %q, want %v %q", rec.Code, body.Message, http.StatusForbidden, customErr.ErrSignatureExpired.Error())
+		}
+	})
+
+	t.Run("Not Found - Signing another user's image", func(t *testing.T) {
+		mockUsecase.EXPECT().Get(gomock.Any(), "user-id", "other-id").Return(nil, customErr.ErrImageNotFound)
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(postMethod, imagesURL+"/other-id/signed-url", strings.NewReader(`{}`)))
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("status code = %v, want %v", rec.Code, http.StatusNotFound)
+		}
+	})
+}
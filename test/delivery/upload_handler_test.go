@@ -0,0 +1,288 @@
+package delivery_test
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/federicodosantos/image-smith/internal/delivery"
+	"github.com/federicodosantos/image-smith/internal/dto"
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	"go.uber.org/mock/gomock"
+)
+
+var uploadsURL = "http://0.0.0.0/uploads"
+
+func TestCreateUpload(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUploadUsecase := NewMockIUploadUsecase(ctrl)
+	uploadHandler := delivery.NewUploadHandler(mockUploadUsecase, 1<<20)
+
+	type TestCase struct {
+		Name           string
+		Headers        map[string]string
+		mockBehavior   func(mockUploadUsecase *MockIUploadUsecase)
+		expectedStatus int
+	}
+
+	testCases := []TestCase{
+		{
+			Name: "Success - Upload created",
+			Headers: map[string]string{
+				"Upload-Length":   "5000000",
+				"Upload-Metadata": "filename " + base64.StdEncoding.EncodeToString([]byte("scan.tiff")) + ",filetype aW1hZ2UvdGlmZg==",
+			},
+			mockBehavior: func(mockUploadUsecase *MockIUploadUsecase) {
+				mockUploadUsecase.EXPECT().Create(gomock.Any(), "user-id", &dto.UploadCreateRequest{Length: 5000000, Filename: "scan.tiff"}).
+					Return(&dto.UploadSessionResponse{ID: "upload-id", Length: 5000000, ExpiresAt: time.Now()}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			Name:           "Unprocessable Entity - Missing length",
+			Headers:        map[string]string{},
+			mockBehavior:   func(mockUploadUsecase *MockIUploadUsecase) {},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			Name:           "Unprocessable Entity - Empty upload",
+			Headers:        map[string]string{"Upload-Length": "0"},
+			mockBehavior:   func(mockUploadUsecase *MockIUploadUsecase) {},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			Name: "Unprocessable Entity - Filename too long",
+			Headers: map[string]string{
+				"Upload-Length":   "5000000",
+				"Upload-Metadata": "filename " + base64.StdEncoding.EncodeToString([]byte(strings.Repeat("a", 252)+".png")),
+			},
+			mockBehavior:   func(mockUploadUsecase *MockIUploadUsecase) {},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			Name:    "Payload Too Large - Longer than the limit",
+			Headers: map[string]string{"Upload-Length": "5000000000"},
+			mockBehavior: func(mockUploadUsecase *MockIUploadUsecase) {
+				mockUploadUsecase.EXPECT().Create(gomock.Any(), "user-id", gomock.Any()).Return(nil, customErr.ErrPayloadTooLarge)
+			},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.mockBehavior(mockUploadUsecase)
+
+			req := httptest.NewRequest(http.MethodPost, uploadsURL, nil)
+			for key, value := range tc.Headers {
+				req.Header.Set(key, value)
+			}
+
+			rec := httptest.NewRecorder()
+			uploadHandler.Create(rec, withUser(req))
+
+			if rec.Code != tc.expectedStatus {
+				t.Errorf("uploadHandler.Create() status code = %v, want %v", rec.Code, tc.expectedStatus)
+			}
+
+			if tc.expectedStatus == http.StatusCreated && rec.Header().Get("Location") != "/uploads/upload-id" {
+				t.Errorf("Location = %q, want /uploads/upload-id", rec.Header().Get("Location"))
+			}
+		})
+	}
+}
+
+func TestHeadUpload(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUploadUsecase := NewMockIUploadUsecase(ctrl)
+	uploadHandler := delivery.NewUploadHandler(mockUploadUsecase, 1<<20)
+
+	mockUploadUsecase.EXPECT().Get(gomock.Any(), "user-id", "upload-id").
+		Return(&dto.UploadSessionResponse{ID: "upload-id", Length: 300, Offset: 200, ExpiresAt: time.Now()}, nil)
+
+	req := httptest.NewRequest(http.MethodHead, uploadsURL+"/upload-id", nil)
+	req.SetPathValue("id", "upload-id")
+
+	rec := httptest.NewRecorder()
+	uploadHandler.Head(rec, withUser(req))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("status code = %v, want %v", rec.Code, http.StatusOK)
+	}
+	if got := rec.Header().Get("Upload-Offset"); got != "200" {
+		t.Errorf("Upload-Offset = %q, want 200", got)
+	}
+	if got := rec.Header().Get("Tus-Resumable"); got != "1.0.0" {
+		t.Errorf("Tus-Resumable = %q, want 1.0.0", got)
+	}
+}
+
+func TestPatchUpload(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUploadUsecase := NewMockIUploadUsecase(ctrl)
+	uploadHandler := delivery.NewUploadHandler(mockUploadUsecase, 8)
+
+	type TestCase struct {
+		Name           string
+		ContentType    string
+		Offset         string
+		Body           string
+		mockBehavior   func(mockUploadUsecase *MockIUploadUsecase)
+		expectedStatus int
+		expectedOffset string
+	}
+
+	testCases := []TestCase{
+		{
+			Name:        "Success - Chunk appended",
+			ContentType: "application/offset+octet-stream",
+			Offset:      "200",
+			Body:        "chunk",
+			mockBehavior: func(mockUploadUsecase *MockIUploadUsecase) {
+				mockUploadUsecase.EXPECT().WriteChunk(gomock.Any(), "user-id", "upload-id", int64(200), gomock.Any()).
+					Return(&dto.UploadSessionResponse{ID: "upload-id", Length: 300, Offset: 205, ExpiresAt: time.Now()}, nil)
+			},
+			expectedStatus: http.StatusNoContent,
+			expectedOffset: "205",
+		},
+		{
+			Name:           "Unsupported Media Type - Not an offset stream",
+			ContentType:    "application/octet-stream",
+			Offset:         "200",
+			Body:           "chunk",
+			mockBehavior:   func(mockUploadUsecase *MockIUploadUsecase) {},
+			expectedStatus: http.StatusUnsupportedMediaType,
+		},
+		{
+			Name:           "Bad Request - Missing offset",
+			ContentType:    "application/offset+octet-stream",
+			Body:           "chunk",
+			mockBehavior:   func(mockUploadUsecase *MockIUploadUsecase) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			Name:           "Payload Too Large - Chunk over the limit",
+			ContentType:    "application/offset+octet-stream",
+			Offset:         "200",
+			Body:           "a chunk too large",
+			mockBehavior:   func(mockUploadUsecase *MockIUploadUsecase) {},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			Name:        "Conflict - Offset mismatch",
+			ContentType: "application/offset+octet-stream",
+			Offset:      "100",
+			Body:        "chunk",
+			mockBehavior: func(mockUploadUsecase *MockIUploadUsecase) {
+				mockUploadUsecase.EXPECT().WriteChunk(gomock.Any(), "user-id", "upload-id", int64(100), gomock.Any()).
+					Return(nil, customErr.ErrUploadOffsetMismatch)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			Name:        "Not Found - Expired upload",
+			ContentType: "application/offset+octet-stream",
+			Offset:      "200",
+			Body:        "chunk",
+			mockBehavior: func(mockUploadUsecase *MockIUploadUsecase) {
+				mockUploadUsecase.EXPECT().WriteChunk(gomock.Any(), "user-id", "upload-id", int64(200), gomock.Any()).
+					Return(nil, customErr.ErrUploadNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.mockBehavior(mockUploadUsecase)
+
+			req := httptest.NewRequest(http.MethodPatch, uploadsURL+"/upload-id", strings.NewReader(tc.Body))
+			req.SetPathValue("id", "upload-id")
+			req.Header.Set("Content-Type", tc.ContentType)
+			if tc.Offset != "" {
+				req.Header.Set("Upload-Offset", tc.Offset)
+			}
+
+			rec := httptest.NewRecorder()
+			uploadHandler.Patch(rec, withUser(req))
+
+			if rec.Code != tc.expectedStatus {
+				t.Errorf("uploadHandler.Patch() status code = %v, want %v", rec.Code, tc.expectedStatus)
+			}
+			if got := rec.Header().Get("Upload-Offset"); tc.expectedOffset != "" && got != tc.expectedOffset {
+				t.Errorf("Upload-Offset = %q, want %q", got, tc.expectedOffset)
+			}
+		})
+	}
+}
+
+func TestFinalizeUpload(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUploadUsecase := NewMockIUploadUsecase(ctrl)
+	uploadHandler := delivery.NewUploadHandler(mockUploadUsecase, 1<<20)
+
+	type TestCase struct {
+		Name           string
+		mockBehavior   func(mockUploadUsecase *MockIUploadUsecase)
+		expectedStatus int
+	}
+
+	testCases := []TestCase{
+		{
+			Name: "Success - Image stored",
+			mockBehavior: func(mockUploadUsecase *MockIUploadUsecase) {
+				mockUploadUsecase.EXPECT().Finalize(gomock.Any(), "user-id", "upload-id").
+					Return(&dto.ImageUploadResponse{ID: "image-id"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			Name: "Conflict - Upload incomplete",
+			mockBehavior: func(mockUploadUsecase *MockIUploadUsecase) {
+				mockUploadUsecase.EXPECT().Finalize(gomock.Any(), "user-id", "upload-id").Return(nil, customErr.ErrUploadIncomplete)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			Name: "Bad Request - Not an image",
+			mockBehavior: func(mockUploadUsecase *MockIUploadUsecase) {
+				mockUploadUsecase.EXPECT().Finalize(gomock.Any(), "user-id", "upload-id").Return(nil, customErr.ErrUnsupportedFileFormat)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			Name: "Unprocessable Entity - Over the decoding limits",
+			mockBehavior: func(mockUploadUsecase *MockIUploadUsecase) {
+				mockUploadUsecase.EXPECT().Finalize(gomock.Any(), "user-id", "upload-id").Return(nil, customErr.ErrImageTooManyPixels)
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.mockBehavior(mockUploadUsecase)
+
+			req := httptest.NewRequest(http.MethodPost, uploadsURL+"/upload-id/finalize", nil)
+			req.SetPathValue("id", "upload-id")
+
+			rec := httptest.NewRecorder()
+			uploadHandler.Finalize(rec, withUser(req))
+
+			if rec.Code != tc.expectedStatus {
+				t.Errorf("uploadHandler.Finalize() status code = %v, want %v", rec.Code, tc.expectedStatus)
+			}
+		})
+	}
+}
@@ -56,6 +56,35 @@ func (mr *MockIUserUsecaseMockRecorder) Login(ctx, req any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockIUserUsecase)(nil).Login), ctx, req)
 }
 
+// Logout mocks base method.
+func (m *MockIUserUsecase) Logout(ctx context.Context, req *dto.RefreshTokenRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Logout", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Logout indicates an expected call of Logout.
+func (mr *MockIUserUsecaseMockRecorder) Logout(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logout", reflect.TypeOf((*MockIUserUsecase)(nil).Logout), ctx, req)
+}
+
+// Refresh mocks base method.
+func (m *MockIUserUsecase) Refresh(ctx context.Context, req *dto.RefreshTokenRequest) (*dto.UserLoginResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refresh", ctx, req)
+	ret0, _ := ret[0].(*dto.UserLoginResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Refresh indicates an expected call of Refresh.
+func (mr *MockIUserUsecaseMockRecorder) Refresh(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refresh", reflect.TypeOf((*MockIUserUsecase)(nil).Refresh), ctx, req)
+}
+
 // Register mocks base method.
 func (m *MockIUserUsecase) Register(ctx context.Context, req *dto.UserRegisterRequest) (*dto.UserRegisterResponse, error) {
 	m.ctrl.T.Helper()
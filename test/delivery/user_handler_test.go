@@ -28,12 +28,25 @@ var nilLoginResponse = &dto.UserLoginResponse{}
 
 var postMethod = http.MethodPost
 
+var cookieConfig = delivery.CookieConfig{
+	Path:     "/",
+	Secure:   true,
+	HttpOnly: true,
+	SameSite: http.SameSiteLaxMode,
+	MaxAge:   time.Hour,
+}
+
+var loginHeader = http.Header{
+	"Content-Type": {"application/json"},
+	"Set-Cookie":   {"jwt-token=jwt-token; Path=/; Max-Age=3600; HttpOnly; Secure; SameSite=Lax"},
+}
+
 func TestRegister(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockUsecase := NewMockIUserUsecase(ctrl)
-	userHandler := delivery.NewUserHandler(mockUsecase)
+	userHandler := delivery.NewUserHandler(mockUsecase, cookieConfig)
 
 	type parameter struct {
 		w http.ResponseWriter
@@ -60,7 +73,8 @@ func TestRegister(t *testing.T) {
 						{
 							"name":     "Jamal",
 							"email":    "jamalunyu@gmail.com",
-							"password": "Rahasia#123"
+							"password": "Rahasia#123",
+							"confirm_password": "Rahasia#123"
 						},
 					`),
 				),
@@ -118,7 +132,8 @@ func TestRegister(t *testing.T) {
 					{
 						"name":     "Jamal",
 						"email":    "jamalunyu@gmail.com",
-						"password": "Rahasia#123"
+						"password": "Rahasia#123",
+						"confirm_password": "Rahasia#123"
 					},
 				`),
 				),
@@ -204,7 +219,7 @@ func TestLogin(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockUsecase := NewMockIUserUsecase(ctrl)
-	userHandler := delivery.NewUserHandler(mockUsecase)
+	userHandler := delivery.NewUserHandler(mockUsecase, cookieConfig)
 
 	type parameter struct {
 		w http.ResponseWriter
@@ -241,7 +256,7 @@ func TestLogin(t *testing.T) {
 						JWTToken: "jwt-token",
 					}, nil)
 			},
-			expectedHeader: jsonHeader,
+			expectedHeader: loginHeader,
 			expectedBody: response.HttpResponse{
 				Status:  http.StatusOK,
 				Message: "successfully login to account",
@@ -353,3 +368,137 @@ func TestLogin(t *testing.T) {
 		})
 	}
 }
+
+func TestLogout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := NewMockIUserUsecase(ctrl)
+	userHandler := delivery.NewUserHandler(mockUsecase, cookieConfig)
+
+	mockUsecase.EXPECT().
+		Logout(gomock.Any(), &dto.RefreshTokenRequest{RefreshToken: "refresh-token"}).
+		Return(nil)
+
+	rec := httptest.NewRecorder()
+	userHandler.Logout(rec, httptest.NewRequest(postMethod, "http://0.0.0.0/auth/logout",
+		strings.NewReader(`{"refresh_token": "refresh-token"}`)))
+
+	res := rec.Result()
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("userHandler.Logout() status code = %v, want %v", res.StatusCode, http.StatusOK)
+	}
+
+	cookies := res.Cookies()
+	if len(cookies) != 1 || cookies[0].Name != "jwt-token" || cookies[0].Value != "" || cookies[0].MaxAge >= 0 {
+		t.Errorf("userHandler.Logout() cookies = %v, want a cleared jwt-token cookie", cookies)
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUsecase := NewMockIUserUsecase(ctrl)
+	userHandler := delivery.NewUserHandler(mockUsecase, cookieConfig)
+
+	refreshURL := "http://0.0.0.0/auth/refresh"
+
+	type TestCase struct {
+		Name           string
+		Body           string
+		mockBehavior   func(mockUsecase *MockIUserUsecase)
+		expectedStatus int
+		expectedCookie bool
+	}
+
+	testCases := []TestCase{
+		{
+			Name: "Success - Rotate tokens",
+			Body: `{"refresh_token": "refresh-token"}`,
+			mockBehavior: func(mockUsecase *MockIUserUsecase) {
+				mockUsecase.EXPECT().
+					Refresh(gomock.Any(), &dto.RefreshTokenRequest{RefreshToken: "refresh-token"}).
+					Return(&dto.UserLoginResponse{JWTToken: "jwt-token", RefreshToken: "new-refresh-token"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedCookie: true,
+		},
+		{
+			Name: "Unauthorized - Reused refresh token",
+			Body: `{"refresh_token": "refresh-token"}`,
+			mockBehavior: func(mockUsecase *MockIUserUsecase) {
+				mockUsecase.EXPECT().
+					Refresh(gomock.Any(), gomock.Any()).
+					Return(nil, customErr.ErrRefreshTokenReused)
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			Name:           "Bad Request - Invalid JSON",
+			Body:           "invalid json",
+			mockBehavior:   func(mockUsecase *MockIUserUsecase) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.mockBehavior(mockUsecase)
+
+			rec := httptest.NewRecorder()
+			userHandler.Refresh(rec, httptest.NewRequest(postMethod, refreshURL, strings.NewReader(tc.Body)))
+
+			res := rec.Result()
+			if res.StatusCode != tc.expectedStatus {
+				t.Errorf("userHandler.Refresh() status code = %v, want %v", res.StatusCode, tc.expectedStatus)
+			}
+
+			if hasCookie := len(res.Cookies()) == 1; hasCookie != tc.expectedCookie {
+				t.Errorf("userHandler.Refresh() cookies = %v, want cookie %v", res.Cookies(), tc.expectedCookie)
+			}
+		})
+	}
+}
+
+func TestRegisterValidation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userHandler := delivery.NewUserHandler(NewMockIUserUsecase(ctrl), cookieConfig)
+
+	rec := httptest.NewRecorder()
+	userHandler.Register(rec, httptest.NewRequest(postMethod, registerURL, strings.NewReader(`
+		{
+			"name":             "",
+			"email":            "not-an-email",
+			"password":         "short",
+			"confirm_password": "different"
+		}
+	`)))
+
+	res := rec.Result()
+	if res.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("userHandler.Register() status code = %v, want %v", res.StatusCode, http.StatusUnprocessableEntity)
+	}
+
+	var actualData struct {
+		Message string              `json:"message"`
+		Data    map[string][]string `json:"obj"`
+	}
+	json.NewDecoder(res.Body).Decode(&actualData)
+
+	if actualData.Message != "validation failed" {
+		t.Errorf("message = %s, want validation failed", actualData.Message)
+	}
+
+	for _, field := range []string{"name", "email", "password", "confirm_password"} {
+		if len(actualData.Data[field]) == 0 {
+			t.Errorf("expected errors for field %s, got %v", field, actualData.Data)
+		}
+	}
+
+	if len(actualData.Data["password"]) < 2 {
+		t.Errorf("expected every password rule failure, got %v", actualData.Data["password"])
+	}
+}
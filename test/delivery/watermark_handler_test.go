@@ -0,0 +1,89 @@
+package delivery_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/federicodosantos/image-smith/internal/delivery"
+	"github.com/federicodosantos/image-smith/internal/dto"
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	"github.com/federicodosantos/image-smith/pkg/validator"
+	"go.uber.org/mock/gomock"
+)
+
+var watermarkURL = "http://0.0.0.0/settings/watermark"
+
+func TestSaveWatermark(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWatermarkUsecase := NewMockIWatermarkUsecase(ctrl)
+	watermarkHandler := delivery.NewWatermarkHandler(mockWatermarkUsecase)
+
+	type TestCase struct {
+		Name           string
+		Body           string
+		mockBehavior   func(mockWatermarkUsecase *MockIWatermarkUsecase)
+		expectedStatus int
+	}
+
+	testCases := []TestCase{
+		{
+			Name: "Success - Settings saved",
+			Body: `{"text": "ImageSmith", "gravity": "southwest", "opacity": 0.3}`,
+			mockBehavior: func(mockWatermarkUsecase *MockIWatermarkUsecase) {
+				mockWatermarkUsecase.EXPECT().
+					Save(gomock.Any(), "user-id", &dto.WatermarkRequest{Text: "ImageSmith", Gravity: "southwest", Opacity: 0.3}).
+					Return(&dto.WatermarkResponse{Text: "ImageSmith"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			Name:           "Unprocessable Entity - Unknown gravity and opacity above 1",
+			Body:           `{"text": "ImageSmith", "gravity": "up", "opacity": 2}`,
+			mockBehavior:   func(mockWatermarkUsecase *MockIWatermarkUsecase) {},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			Name: "Unprocessable Entity - No overlay",
+			Body: `{"gravity": "north"}`,
+			mockBehavior: func(mockWatermarkUsecase *MockIWatermarkUsecase) {
+				mockWatermarkUsecase.EXPECT().Save(gomock.Any(), "user-id", gomock.Any()).
+					Return(nil, validator.Errors{"image_id": {"exactly one of image_id or text is required"}})
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.mockBehavior(mockWatermarkUsecase)
+
+			rec := httptest.NewRecorder()
+			watermarkHandler.Save(rec, withUser(httptest.NewRequest(http.MethodPut, watermarkURL, strings.NewReader(tc.Body))))
+
+			if rec.Code != tc.expectedStatus {
+				t.Errorf("watermarkHandler.Save() status code = %v, want %v", rec.Code, tc.expectedStatus)
+			}
+		})
+	}
+}
+
+func TestGetWatermark(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWatermarkUsecase := NewMockIWatermarkUsecase(ctrl)
+	watermarkHandler := delivery.NewWatermarkHandler(mockWatermarkUsecase)
+
+	mockWatermarkUsecase.EXPECT().Get(gomock.Any(), "user-id").Return(nil, customErr.ErrWatermarkNotFound)
+
+	rec := httptest.NewRecorder()
+	watermarkHandler.Get(rec, withUser(httptest.NewRequest(http.MethodGet, watermarkURL, nil)))
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status code = %v, want %v", rec.Code, http.StatusNotFound)
+	}
+}
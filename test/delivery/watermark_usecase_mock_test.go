@@ -0,0 +1,86 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/usecase/watermark_usecase.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/usecase/watermark_usecase.go -destination=test/delivery/watermark_usecase_mock_test.go -package=delivery_test
+//
+
+// Package delivery_test is a generated GoMock package.
+package delivery_test
+
+import (
+	context "context"
+	reflect "reflect"
+
+	dto "github.com/federicodosantos/image-smith/internal/dto"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIWatermarkUsecase is a mock of IWatermarkUsecase interface.
+type MockIWatermarkUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockIWatermarkUsecaseMockRecorder
+	isgomock struct{}
+}
+
+// MockIWatermarkUsecaseMockRecorder is the mock recorder for MockIWatermarkUsecase.
+type MockIWatermarkUsecaseMockRecorder struct {
+	mock *MockIWatermarkUsecase
+}
+
+// NewMockIWatermarkUsecase creates a new mock instance.
+func NewMockIWatermarkUsecase(ctrl *gomock.Controller) *MockIWatermarkUsecase {
+	mock := &MockIWatermarkUsecase{ctrl: ctrl}
+	mock.recorder = &MockIWatermarkUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIWatermarkUsecase) EXPECT() *MockIWatermarkUsecaseMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockIWatermarkUsecase) Delete(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockIWatermarkUsecaseMockRecorder) Delete(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockIWatermarkUsecase)(nil).Delete), ctx, userID)
+}
+
+// Get mocks base method.
+func (m *MockIWatermarkUsecase) Get(ctx context.Context, userID string) (*dto.WatermarkResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, userID)
+	ret0, _ := ret[0].(*dto.WatermarkResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockIWatermarkUsecaseMockRecorder) Get(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockIWatermarkUsecase)(nil).Get), ctx, userID)
+}
+
+// Save mocks base method.
+func (m *MockIWatermarkUsecase) Save(ctx context.Context, userID string, req *dto.WatermarkRequest) (*dto.WatermarkResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, userID, req)
+	ret0, _ := ret[0].(*dto.WatermarkResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockIWatermarkUsecaseMockRecorder) Save(ctx, userID, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockIWatermarkUsecase)(nil).Save), ctx, userID, req)
+}
@@ -0,0 +1,117 @@
+package delivery_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/federicodosantos/image-smith/internal/delivery"
+	"github.com/federicodosantos/image-smith/internal/dto"
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	"github.com/federicodosantos/image-smith/pkg/validator"
+	"go.uber.org/mock/gomock"
+)
+
+var webhooksURL = "http://0.0.0.0/webhooks"
+
+func TestCreateWebhook(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWebhookUsecase := NewMockIWebhookUsecase(ctrl)
+	webhookHandler := delivery.NewWebhookHandler(mockWebhookUsecase)
+
+	type TestCase struct {
+		Name           string
+		Body           string
+		mockBehavior   func(mockWebhookUsecase *MockIWebhookUsecase)
+		expectedStatus int
+	}
+
+	testCases := []TestCase{
+		{
+			Name: "Created - Webhook registered",
+			Body: `{"url": "https://example.com/hooks", "events": ["image.uploaded"]}`,
+			mockBehavior: func(mockWebhookUsecase *MockIWebhookUsecase) {
+				mockWebhookUsecase.EXPECT().
+					Create(gomock.Any(), "user-id", &dto.WebhookRequest{URL: "https://example.com/hooks", Events: []string{"image.uploaded"}}).
+					Return(&dto.WebhookResponse{ID: "webhook-id", Secret: "whsec_secret"}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			Name:           "Unprocessable Entity - Relative url",
+			Body:           `{"url": "/hooks", "events": ["image.uploaded"]}`,
+			mockBehavior:   func(mockWebhookUsecase *MockIWebhookUsecase) {},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			Name:           "Unprocessable Entity - No events",
+			Body:           `{"url": "https://example.com/hooks", "events": []}`,
+			mockBehavior:   func(mockWebhookUsecase *MockIWebhookUsecase) {},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			Name: "Unprocessable Entity - Unknown event",
+			Body: `{"url": "https://example.com/hooks", "events": ["image.exploded"]}`,
+			mockBehavior: func(mockWebhookUsecase *MockIWebhookUsecase) {
+				mockWebhookUsecase.EXPECT().Create(gomock.Any(), "user-id", gomock.Any()).
+					Return(nil, validator.Errors{"events": {`unknown event "image.exploded"`}})
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.mockBehavior(mockWebhookUsecase)
+
+			rec := httptest.NewRecorder()
+			webhookHandler.Create(rec, withUser(httptest.NewRequest(postMethod, webhooksURL, strings.NewReader(tc.Body))))
+
+			if rec.Code != tc.expectedStatus {
+				t.Errorf("webhookHandler.Create() status code = %v, want %v", rec.Code, tc.expectedStatus)
+			}
+		})
+	}
+}
+
+func TestReplayDelivery(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWebhookUsecase := NewMockIWebhookUsecase(ctrl)
+	webhookHandler := delivery.NewWebhookHandler(mockWebhookUsecase)
+
+	replayRequest := func() *http.Request {
+		r := httptest.NewRequest(postMethod, webhooksURL+"/webhook-id/deliveries/delivery-id/replay", nil)
+		r.SetPathValue("id", "webhook-id")
+		r.SetPathValue("delivery_id", "delivery-id")
+		return withUser(r)
+	}
+
+	t.Run("Accepted - Delivery queued again", func(t *testing.T) {
+		mockWebhookUsecase.EXPECT().Replay(gomock.Any(), "user-id", "webhook-id", "delivery-id").
+			Return(&dto.WebhookDeliveryResponse{ID: "replay-id", Status: "pending"}, nil)
+
+		rec := httptest.NewRecorder()
+		webhookHandler.Replay(rec, replayRequest())
+
+		if rec.Code != http.StatusAccepted {
+			t.Errorf("status code = %v, want %v", rec.Code, http.StatusAccepted)
+		}
+	})
+
+	t.Run("Not Found - Delivery of another user", func(t *testing.T) {
+		mockWebhookUsecase.EXPECT().Replay(gomock.Any(), "user-id", "webhook-id", "delivery-id").
+			Return(nil, customErr.ErrWebhookDeliveryNotFound)
+
+		rec := httptest.NewRecorder()
+		webhookHandler.Replay(rec, replayRequest())
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("status code = %v, want %v", rec.Code, http.StatusNotFound)
+		}
+	})
+}
@@ -0,0 +1,112 @@
+package imaging_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"testing"
+
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	"github.com/federicodosantos/image-smith/pkg/imaging"
+	"github.com/stretchr/testify/assert"
+)
+
+// createAnimation encodes a GIF whose frames are filled with a different
+// color each.
+func createAnimation(t *testing.T, width, height int) []byte {
+	t.Helper()
+
+	fills := []color.Color{color.RGBA{R: 255, A: 255}, color.RGBA{G: 255, A: 255}, color.RGBA{B: 255, A: 255}}
+	g := &gif.GIF{
+		Delay:     []int{10, 20, 30},
+		Disposal:  []byte{gif.DisposalNone, gif.DisposalBackground, gif.DisposalNone},
+		LoopCount: 2,
+	}
+
+	for _, fill := range fills {
+		frame := image.NewPaletted(image.Rect(0, 0, width, height), palette.Plan9)
+		index := uint8(frame.Palette.Index(fill))
+		for i := range frame.Pix {
+			frame.Pix[i] = index
+		}
+		g.Image = append(g.Image, frame)
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, gif.EncodeAll(&buf, g))
+
+	return buf.Bytes()
+}
+
+func TestTransformAnimation(t *testing.T) {
+	src := createAnimation(t, 40, 30)
+
+	var dst bytes.Buffer
+	format, err := imaging.Transform(bytes.NewReader(src), &dst, imaging.Options{Resize: &imaging.Resize{Width: 20}})
+	assert.NoError(t, err)
+	assert.Equal(t, imaging.FormatGIF, format)
+
+	g, err := gif.DecodeAll(&dst)
+	assert.NoError(t, err)
+	assert.Len(t, g.Image, 3)
+	assert.Equal(t, []int{10, 20, 30}, g.Delay)
+	assert.Equal(t, []byte{gif.DisposalNone, gif.DisposalBackground, gif.DisposalNone}, g.Disposal)
+	assert.Equal(t, 2, g.LoopCount)
+	assert.Equal(t, 20, g.Config.Width)
+	assert.Equal(t, 15, g.Config.Height)
+
+	// every frame keeps its own content
+	r, gr, b, _ := g.Image[1].At(10, 7).RGBA()
+	assert.Equal(t, [3]uint32{0, 0xffff, 0}, [3]uint32{r, gr, b})
+}
+
+func TestTransformAnimationFrame(t *testing.T) {
+	src := createAnimation(t, 40, 30)
+
+	var dst bytes.Buffer
+	frame := 2
+	format, err := imaging.Transform(bytes.NewReader(src), &dst, imaging.Options{Frame: &frame, Format: imaging.FormatPNG})
+	assert.NoError(t, err)
+	assert.Equal(t, imaging.FormatPNG, format)
+
+	img, _, err := imaging.Decode(&dst)
+	assert.NoError(t, err)
+	r, g, b, _ := img.At(5, 5).RGBA()
+	assert.Equal(t, [3]uint32{0, 0, 0xffff}, [3]uint32{r, g, b})
+
+	// formats without animation get the first frame
+	dst.Reset()
+	_, err = imaging.Transform(bytes.NewReader(src), &dst, imaging.Options{Resize: &imaging.Resize{Width: 20}, Format: imaging.FormatJPEG})
+	assert.NoError(t, err)
+
+	frame = 3
+	_, err = imaging.Transform(bytes.NewReader(src), &dst, imaging.Options{Frame: &frame})
+	assert.ErrorIs(t, err, customErr.ErrInvalidTransformation)
+
+	frame = -1
+	_, err = imaging.Transform(bytes.NewReader(src), &dst, imaging.Options{Frame: &frame})
+	assert.ErrorIs(t, err, customErr.ErrInvalidTransformation)
+}
+
+func TestTransformAnimationLimit(t *testing.T) {
+	// tiny frames on a huge canvas compress to a few bytes
+	g := &gif.GIF{Config: image.Config{Width: 10000, Height: 10000, ColorModel: color.Palette(palette.Plan9)}}
+	for range 2 {
+		g.Image = append(g.Image, image.NewPaletted(image.Rect(0, 0, 1, 1), palette.Plan9))
+		g.Delay = append(g.Delay, 0)
+	}
+
+	var src bytes.Buffer
+	assert.NoError(t, gif.EncodeAll(&src, g))
+
+	var dst bytes.Buffer
+	_, err := imaging.Transform(&src, &dst, imaging.Options{Flip: true})
+	assert.ErrorIs(t, err, customErr.ErrInvalidTransformation)
+
+	// growing every frame past the limit is rejected as well
+	_, err = imaging.Transform(bytes.NewReader(createAnimation(t, 40, 30)), &dst,
+		imaging.Options{Resize: &imaging.Resize{Width: 8000, Height: 6000}})
+	assert.ErrorIs(t, err, customErr.ErrInvalidTransformation)
+}
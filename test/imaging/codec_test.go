@@ -0,0 +1,118 @@
+package imaging_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"testing"
+
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	"github.com/federicodosantos/image-smith/pkg/imaging"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodecRoundTrip(t *testing.T) {
+	type testCase struct {
+		name   string
+		format imaging.Format
+		opts   imaging.EncodeOptions
+	}
+
+	testCases := []testCase{
+		{name: "Success - PNG with best compression", format: imaging.FormatPNG, opts: imaging.EncodeOptions{Compression: imaging.CompressionBest}},
+		{name: "Success - Baseline JPEG", format: imaging.FormatJPEG, opts: imaging.EncodeOptions{Quality: 80}},
+		{name: "Success - Progressive JPEG", format: imaging.FormatJPEG, opts: imaging.EncodeOptions{Quality: 80, Progressive: true}},
+		{name: "Success - GIF with a small palette", format: imaging.FormatGIF, opts: imaging.EncodeOptions{Colors: 16}},
+		{name: "Success - BMP", format: imaging.FormatBMP},
+		{name: "Success - TIFF", format: imaging.FormatTIFF},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			assert.NoError(t, imaging.Encode(&buf, createImage(37, 21), tc.format, tc.opts))
+
+			sniffed, ok := imaging.Sniff(buf.Bytes())
+			assert.True(t, ok)
+			assert.Equal(t, tc.format, sniffed)
+
+			config, _, err := imaging.DecodeConfig(bytes.NewReader(buf.Bytes()))
+			assert.NoError(t, err)
+			assert.Equal(t, 37, config.Width)
+			assert.Equal(t, 21, config.Height)
+
+			decoded, format, err := imaging.Decode(&buf)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.format, format)
+			assert.Equal(t, image.Rect(0, 0, 37, 21), decoded.Bounds())
+		})
+	}
+}
+
+func TestProgressiveJPEGKeepsPixels(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 20, 20))
+	for i := 0; i < len(src.Pix); i += 4 {
+		src.Pix[i], src.Pix[i+1], src.Pix[i+2], src.Pix[i+3] = 200, 40, 90, 255
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, imaging.Encode(&buf, src, imaging.FormatJPEG, imaging.EncodeOptions{Quality: 100, Progressive: true}))
+
+	// SOF2 marks a progressive frame
+	assert.True(t, bytes.Contains(buf.Bytes(), []byte{0xff, 0xc2}))
+
+	decoded, err := jpeg.Decode(&buf)
+	assert.NoError(t, err)
+
+	r, g, b, _ := decoded.At(10, 10).RGBA()
+	assert.InDelta(t, 200, r>>8, 2)
+	assert.InDelta(t, 40, g>>8, 2)
+	assert.InDelta(t, 90, b>>8, 2)
+}
+
+func TestRegisterCodec(t *testing.T) {
+	imaging.RegisterCodec(imaging.Codec{
+		Format:  "test",
+		Aliases: []string{"tst"},
+		Magic:   []string{"TEST"},
+		Decode: func(r io.Reader) (image.Image, error) {
+			return image.NewGray(image.Rect(0, 0, 3, 2)), nil
+		},
+		DecodeConfig: func(r io.Reader) (image.Config, error) {
+			return image.Config{ColorModel: color.GrayModel, Width: 3, Height: 2}, nil
+		},
+	})
+
+	format, err := imaging.ParseFormat("TST")
+	assert.NoError(t, err)
+	assert.Equal(t, imaging.Format("test"), format)
+	assert.Equal(t, "image/test", format.MimeType())
+	assert.False(t, format.Encodable())
+
+	// read only sources are written as PNG
+	var buf bytes.Buffer
+	written, err := imaging.Transform(bytes.NewReader([]byte("TEST....")), &buf, imaging.Options{Flip: true})
+	assert.NoError(t, err)
+	assert.Equal(t, imaging.FormatPNG, written)
+
+	_, err = imaging.Transform(bytes.NewReader([]byte("TEST....")), &buf, imaging.Options{Format: format})
+	assert.ErrorIs(t, err, customErr.ErrInvalidTransformation)
+}
+
+func TestParseFormatAliases(t *testing.T) {
+	for name, want := range map[string]imaging.Format{
+		"webp":       imaging.FormatWebP,
+		"image/webp": imaging.FormatWebP,
+		"TIF":        imaging.FormatTIFF,
+		"image/bmp":  imaging.FormatBMP,
+	} {
+		format, err := imaging.ParseFormat(name)
+		assert.NoError(t, err)
+		assert.Equal(t, want, format)
+	}
+
+	_, _, err := imaging.Decode(bytes.NewReader([]byte("not an image")))
+	assert.ErrorIs(t, err, customErr.ErrUnsupportedFileFormat)
+}
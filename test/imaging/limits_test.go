@@ -0,0 +1,144 @@
+package imaging_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	"github.com/federicodosantos/image-smith/pkg/imaging"
+	"github.com/stretchr/testify/assert"
+)
+
+// withLimits applies l for the rest of the test.
+func withLimits(t *testing.T, l imaging.Limits) {
+	t.Helper()
+
+	previous := imaging.CurrentLimits()
+	imaging.SetLimits(l)
+	t.Cleanup(func() { imaging.SetLimits(previous) })
+}
+
+func TestLimitsCheck(t *testing.T) {
+	limits := imaging.Limits{MaxWidth: 1000, MaxHeight: 800, MaxPixels: 500_000, MaxFrames: 10, MaxMemory: 8 << 20}
+
+	type testCase struct {
+		name        string
+		info        imaging.Info
+		expectError error
+	}
+
+	testCases := []testCase{
+		{name: "Within every limit", info: imaging.Info{Config: image.Config{Width: 500, Height: 400}, Frames: 5}},
+		{name: "Too wide", info: imaging.Info{Config: image.Config{Width: 1001, Height: 10}, Frames: 1}, expectError: customErr.ErrImageDimensionsTooLarge},
+		{name: "Too tall", info: imaging.Info{Config: image.Config{Width: 10, Height: 801}, Frames: 1}, expectError: customErr.ErrImageDimensionsTooLarge},
+		{name: "Too many pixels", info: imaging.Info{Config: image.Config{Width: 1000, Height: 800}, Frames: 1}, expectError: customErr.ErrImageTooManyPixels},
+		{name: "Too many frames", info: imaging.Info{Config: image.Config{Width: 10, Height: 10}, Frames: 11}, expectError: customErr.ErrImageTooManyFrames},
+		{name: "Over the memory budget", info: imaging.Info{Config: image.Config{Width: 700, Height: 700}, Frames: 10}, expectError: customErr.ErrMemoryBudgetExceeded},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := limits.Check(tc.info)
+			if tc.expectError == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tc.expectError)
+		})
+	}
+
+	// zero limits are disabled
+	assert.NoError(t, imaging.Limits{}.Check(imaging.Info{Config: image.Config{Width: 1 << 20, Height: 1 << 20}, Frames: 1 << 20}))
+}
+
+func TestInspect(t *testing.T) {
+	info, err := imaging.Inspect(bytes.NewReader(createAnimation(t, 40, 30)))
+	assert.NoError(t, err)
+	assert.Equal(t, imaging.FormatGIF, info.Format)
+	assert.Equal(t, 3, info.Frames)
+	assert.Equal(t, 40, info.Config.Width)
+	assert.Equal(t, 30, info.Config.Height)
+
+	info, err = imaging.Inspect(bytes.NewReader(createPhoto(t, 6)))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, info.Frames)
+	assert.Equal(t, "Canon", info.Metadata.ParseEXIF().Make)
+
+	_, err = imaging.Inspect(bytes.NewReader([]byte("GIF89a")))
+	assert.Error(t, err)
+}
+
+func TestTransformLimits(t *testing.T) {
+	var still bytes.Buffer
+	assert.NoError(t, png.Encode(&still, createImage(400, 300)))
+
+	type testCase struct {
+		name        string
+		src         []byte
+		limits      imaging.Limits
+		expectError error
+	}
+
+	testCases := []testCase{
+		{name: "Declared size over the limit", src: still.Bytes(), limits: imaging.Limits{MaxWidth: 399}, expectError: customErr.ErrImageDimensionsTooLarge},
+		{name: "Too many pixels", src: still.Bytes(), limits: imaging.Limits{MaxPixels: 100_000}, expectError: customErr.ErrImageTooManyPixels},
+		{name: "Too many frames", src: createAnimation(t, 40, 30), limits: imaging.Limits{MaxFrames: 2}, expectError: customErr.ErrImageTooManyFrames},
+		{name: "Over the memory budget", src: createAnimation(t, 40, 30), limits: imaging.Limits{MaxMemory: 10_000}, expectError: customErr.ErrMemoryBudgetExceeded},
+		{name: "Decode timeout", src: still.Bytes(), limits: imaging.Limits{DecodeTimeout: time.Nanosecond}, expectError: customErr.ErrDecodeTimeout},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			withLimits(t, tc.limits)
+
+			var dst bytes.Buffer
+			_, err := imaging.Transform(bytes.NewReader(tc.src), &dst, imaging.Options{Flip: true})
+			assert.ErrorIs(t, err, tc.expectError)
+
+			_, _, err = imaging.Decode(bytes.NewReader(tc.src))
+			assert.ErrorIs(t, err, tc.expectError)
+		})
+	}
+}
+
+func TestDecodeMemoryIsHeldUntilTheDecoderReturns(t *testing.T) {
+	unblock := make(chan struct{})
+	var started atomic.Int32
+
+	imaging.RegisterCodec(imaging.Codec{
+		Format: "stall",
+		Magic:  []string{"STALL"},
+		Decode: func(r io.Reader) (image.Image, error) {
+			started.Add(1)
+			<-unblock
+			return image.NewGray(image.Rect(0, 0, 100, 100)), nil
+		},
+		DecodeConfig: func(r io.Reader) (image.Config, error) {
+			return image.Config{ColorModel: color.GrayModel, Width: 100, Height: 100}, nil
+		},
+	})
+
+	// room for a single 100x100 image
+	withLimits(t, imaging.Limits{MaxMemory: 50_000, DecodeTimeout: 50 * time.Millisecond})
+	src := []byte("STALL...")
+
+	_, _, err := imaging.Decode(bytes.NewReader(src))
+	assert.ErrorIs(t, err, customErr.ErrDecodeTimeout)
+
+	// the abandoned decoder still holds the budget, so no other one starts
+	_, _, err = imaging.Decode(bytes.NewReader(src))
+	assert.ErrorIs(t, err, customErr.ErrDecodeTimeout)
+	assert.Equal(t, int32(1), started.Load())
+
+	close(unblock)
+	assert.Eventually(t, func() bool {
+		_, _, err := imaging.Decode(bytes.NewReader(src))
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+}
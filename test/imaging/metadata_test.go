@@ -0,0 +1,198 @@
+package imaging_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"slices"
+	"testing"
+	"time"
+
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	"github.com/federicodosantos/image-smith/pkg/imaging"
+	"github.com/stretchr/testify/assert"
+)
+
+// exifBlock builds a big endian EXIF block with the camera, orientation and
+// capture time tags.
+func exifBlock(cameraMake, model string, orientation uint16, takenAt string) []byte {
+	be := binary.BigEndian
+	const exifIFD, values = 62, 80
+
+	var data, extra []byte
+	entry := func(tag, typ uint16, count uint32, value []byte) {
+		data = be.AppendUint16(data, tag)
+		data = be.AppendUint16(data, typ)
+		data = be.AppendUint32(data, count)
+		if len(value) <= 4 {
+			data = append(data, append(value, make([]byte, 4-len(value))...)...)
+			return
+		}
+		data = be.AppendUint32(data, uint32(values+len(extra)))
+		extra = append(extra, value...)
+	}
+
+	data = append(data, "MM\x00\x2a\x00\x00\x00\x08"...)
+	data = be.AppendUint16(data, 4)
+	entry(0x010f, 2, uint32(len(cameraMake)+1), []byte(cameraMake+"\x00"))
+	entry(0x0110, 2, uint32(len(model)+1), []byte(model+"\x00"))
+	entry(0x0112, 3, 1, be.AppendUint16(nil, orientation))
+	entry(0x8769, 4, 1, be.AppendUint32(nil, exifIFD))
+	data = be.AppendUint32(data, 0)
+
+	data = be.AppendUint16(data, 1)
+	entry(0x9003, 2, uint32(len(takenAt)+1), []byte(takenAt+"\x00"))
+	data = be.AppendUint32(data, 0)
+
+	return append(data, extra...)
+}
+
+// jpegSegment encodes a JPEG marker segment.
+func jpegSegment(marker byte, payload ...string) []byte {
+	var body []byte
+	for _, p := range payload {
+		body = append(body, p...)
+	}
+
+	return append([]byte{0xff, marker, byte((len(body) + 2) >> 8), byte(len(body) + 2)}, body...)
+}
+
+// createPhoto encodes a 40x20 JPEG whose left half is red, tagged with the
+// given orientation, an XMP packet and a color profile.
+func createPhoto(t *testing.T, orientation uint16) []byte {
+	t.Helper()
+
+	img := image.NewNRGBA(image.Rect(0, 0, 40, 20))
+	for y := range 20 {
+		for x := range 40 {
+			c := color.NRGBA{B: 255, A: 255}
+			if x < 20 {
+				c = color.NRGBA{R: 255, A: 255}
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}))
+	data := buf.Bytes()
+
+	return slices.Concat(data[:2],
+		jpegSegment(0xe1, "Exif\x00\x00", string(exifBlock("Canon", "EOS R6", orientation, "2024:05:17 14:03:21"))),
+		jpegSegment(0xe1, "http://ns.adobe.com/xap/1.0/\x00", "<x:xmpmeta/>"),
+		jpegSegment(0xe2, "ICC_PROFILE\x00\x01\x01", "fake profile"),
+		data[2:])
+}
+
+func TestReadMetadata(t *testing.T) {
+	meta, format, err := imaging.ReadMetadata(bytes.NewReader(createPhoto(t, 6)))
+	assert.NoError(t, err)
+	assert.Equal(t, imaging.FormatJPEG, format)
+	assert.Equal(t, []byte("<x:xmpmeta/>"), meta.XMP)
+	assert.Equal(t, []byte("fake profile"), meta.ICC)
+
+	exif := meta.ParseEXIF()
+	assert.Equal(t, "Canon", exif.Make)
+	assert.Equal(t, "EOS R6", exif.Model)
+	assert.Equal(t, imaging.OrientationRotated90, exif.Orientation)
+	assert.True(t, exif.Orientation.Transposed())
+	assert.Equal(t, time.Date(2024, 5, 17, 14, 3, 21, 0, time.UTC), *exif.TakenAt)
+
+	// images without EXIF default to the normal orientation
+	var buf bytes.Buffer
+	assert.NoError(t, jpeg.Encode(&buf, createImage(4, 4), nil))
+	meta, _, err = imaging.ReadMetadata(&buf)
+	assert.NoError(t, err)
+	assert.Nil(t, meta.EXIF)
+	assert.Equal(t, imaging.OrientationNormal, meta.ParseEXIF().Orientation)
+
+	_, err = imaging.ParseEXIF([]byte("garbage"))
+	assert.Error(t, err)
+}
+
+func TestDecodeOrientation(t *testing.T) {
+	type testCase struct {
+		name        string
+		orientation uint16
+		width       int
+		red         image.Point
+	}
+
+	// the red half starts on the left of the stored pixels
+	testCases := []testCase{
+		{name: "Normal", orientation: 1, width: 40, red: image.Pt(5, 10)},
+		{name: "Flopped", orientation: 2, width: 40, red: image.Pt(35, 10)},
+		{name: "Rotated 180", orientation: 3, width: 40, red: image.Pt(35, 10)},
+		{name: "Rotated 90", orientation: 6, width: 20, red: image.Pt(10, 5)},
+		{name: "Rotated 270", orientation: 8, width: 20, red: image.Pt(10, 35)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			img, _, err := imaging.Decode(bytes.NewReader(createPhoto(t, tc.orientation)))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.width, img.Bounds().Dx())
+			assert.Equal(t, 60-tc.width, img.Bounds().Dy())
+
+			r, _, b, _ := img.At(tc.red.X, tc.red.Y).RGBA()
+			assert.Greater(t, r, b)
+		})
+	}
+}
+
+func TestTransformMetadata(t *testing.T) {
+	type testCase struct {
+		name     string
+		policy   imaging.MetadataPolicy
+		format   imaging.Format
+		exif     bool
+		xmp      bool
+		icc      bool
+		hasError bool
+	}
+
+	testCases := []testCase{
+		{name: "Strip by default", format: imaging.FormatJPEG},
+		{name: "Strip explicitly", policy: imaging.MetadataStrip, format: imaging.FormatJPEG},
+		{name: "Keep only the color profile", policy: imaging.MetadataKeepICC, format: imaging.FormatJPEG, icc: true},
+		{name: "Keep everything", policy: imaging.MetadataKeep, format: imaging.FormatJPEG, exif: true, xmp: true, icc: true},
+		{name: "Keep everything in a PNG", policy: imaging.MetadataKeep, format: imaging.FormatPNG, exif: true, xmp: true, icc: true},
+		{name: "Formats without metadata drop it", policy: imaging.MetadataKeep, format: imaging.FormatBMP},
+		{name: "Unknown policy", policy: "some", format: imaging.FormatJPEG, hasError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var dst bytes.Buffer
+			_, err := imaging.Transform(bytes.NewReader(createPhoto(t, 6)), &dst, imaging.Options{Format: tc.format, Metadata: tc.policy})
+			if tc.hasError {
+				assert.ErrorIs(t, err, customErr.ErrInvalidTransformation)
+				return
+			}
+			assert.NoError(t, err)
+
+			meta, _, err := imaging.ReadMetadata(bytes.NewReader(dst.Bytes()))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.exif, meta.EXIF != nil)
+			assert.Equal(t, tc.xmp, meta.XMP != nil)
+			assert.Equal(t, tc.icc, meta.ICC != nil)
+
+			if tc.icc {
+				assert.Equal(t, []byte("fake profile"), meta.ICC)
+			}
+
+			if tc.exif {
+				// the pixels are upright, so the kept block must not rotate them again
+				exif := meta.ParseEXIF()
+				assert.Equal(t, imaging.OrientationNormal, exif.Orientation)
+				assert.Equal(t, "Canon", exif.Make)
+			}
+
+			img, _, err := imaging.Decode(&dst)
+			assert.NoError(t, err)
+			assert.Equal(t, image.Rect(0, 0, 20, 40), img.Bounds())
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package imaging_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/federicodosantos/image-smith/pkg/imaging"
+	"github.com/stretchr/testify/assert"
+)
+
+// createScene paints overlapping rectangles laid out by seed on a gray
+// canvas, so scenes with different seeds look unrelated.
+func createScene(width, height int, seed uint64) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.NRGBA{R: 128, G: 128, B: 128, A: 255}), image.Point{}, draw.Src)
+
+	r := rand.New(rand.NewPCG(seed, seed))
+	for range 12 {
+		x, y := r.IntN(width), r.IntN(height)
+		rect := image.Rect(x, y, x+width/8+r.IntN(width/3), y+height/8+r.IntN(height/3))
+		c := color.NRGBA{R: uint8(r.IntN(256)), G: uint8(r.IntN(256)), B: uint8(r.IntN(256)), A: 255}
+		draw.Draw(img, rect, image.NewUniform(c), image.Point{}, draw.Src)
+	}
+
+	return img
+}
+
+func TestHashImage(t *testing.T) {
+	original := createScene(320, 240, 1)
+	hash := imaging.HashImage(original)
+
+	var src, copied bytes.Buffer
+	assert.NoError(t, png.Encode(&src, original))
+	_, err := imaging.Transform(&src, &copied, imaging.Options{
+		Resize: &imaging.Resize{Width: 160},
+		Format: imaging.FormatJPEG,
+		Encode: imaging.EncodeOptions{Quality: 40},
+	})
+	assert.NoError(t, err)
+	recompressed, _, err := imaging.Decode(&copied)
+	assert.NoError(t, err)
+
+	type testCase struct {
+		name        string
+		img         image.Image
+		maxDistance int
+		minDistance int
+	}
+
+	testCases := []testCase{
+		{name: "Same image", img: original, maxDistance: 0},
+		{name: "Smaller and recompressed copy", img: recompressed, maxDistance: 6},
+		{name: "Different scene", img: createScene(320, 240, 2), minDistance: 16, maxDistance: 64},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			distance := hash.Distance(imaging.HashImage(tc.img))
+			assert.LessOrEqual(t, distance, tc.maxDistance)
+			assert.GreaterOrEqual(t, distance, tc.minDistance)
+		})
+	}
+
+	assert.Len(t, hash.String(), 16)
+	assert.Equal(t, 64, imaging.PerceptualHash(0).Distance(^imaging.PerceptualHash(0)))
+}
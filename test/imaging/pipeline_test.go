@@ -0,0 +1,484 @@
+package imaging_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	"github.com/federicodosantos/image-smith/pkg/imaging"
+	"github.com/stretchr/testify/assert"
+)
+
+func createImage(width, height int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		for x := range width {
+			img.Set(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	return img
+}
+
+func TestApply(t *testing.T) {
+	type testCase struct {
+		name           string
+		opts           imaging.Options
+		expectedBounds image.Rectangle
+		expectError    error
+	}
+
+	testCases := []testCase{
+		{
+			name:           "Success - Resize keeps aspect ratio when height missing",
+			opts:           imaging.Options{Resize: &imaging.Resize{Width: 50}},
+			expectedBounds: image.Rect(0, 0, 50, 25),
+		},
+		{
+			name:           "Success - Crop runs before resize",
+			opts:           imaging.Options{Crop: &imaging.Crop{X: 10, Y: 10, Width: 40, Height: 40}, Resize: &imaging.Resize{Width: 20, Height: 20}},
+			expectedBounds: image.Rect(0, 0, 20, 20),
+		},
+		{
+			name:           "Success - Fill stretches to both dimensions",
+			opts:           imaging.Options{Resize: &imaging.Resize{Width: 40, Height: 40, Fit: imaging.FitFill}},
+			expectedBounds: image.Rect(0, 0, 40, 40),
+		},
+		{
+			name:           "Success - Inside keeps aspect ratio within the box",
+			opts:           imaging.Options{Resize: &imaging.Resize{Width: 40, Height: 40, Fit: imaging.FitInside}},
+			expectedBounds: image.Rect(0, 0, 40, 20),
+		},
+		{
+			name:           "Success - Outside covers the box",
+			opts:           imaging.Options{Resize: &imaging.Resize{Width: 40, Height: 40, Fit: imaging.FitOutside}},
+			expectedBounds: image.Rect(0, 0, 80, 40),
+		},
+		{
+			name:           "Success - Cover crops to both dimensions",
+			opts:           imaging.Options{Resize: &imaging.Resize{Width: 40, Height: 40, Fit: imaging.FitCover, Filter: imaging.FilterLanczos}},
+			expectedBounds: image.Rect(0, 0, 40, 40),
+		},
+		{
+			name:           "Success - Contain letterboxes to both dimensions",
+			opts:           imaging.Options{Resize: &imaging.Resize{Width: 40, Height: 40, Fit: imaging.FitContain, Filter: imaging.FilterCatmullRom}},
+			expectedBounds: image.Rect(0, 0, 40, 40),
+		},
+		{
+			name:        "Failed - Unsupported fit",
+			opts:        imaging.Options{Resize: &imaging.Resize{Width: 40, Height: 40, Fit: "stretch"}},
+			expectError: customErr.ErrInvalidTransformation,
+		},
+		{
+			name:        "Failed - Unsupported filter",
+			opts:        imaging.Options{Resize: &imaging.Resize{Width: 40, Filter: "cubic"}},
+			expectError: customErr.ErrInvalidTransformation,
+		},
+		{
+			name:        "Failed - Focal point outside the image",
+			opts:        imaging.Options{Resize: &imaging.Resize{Width: 40, Height: 40, Fit: imaging.FitCover, Focal: &imaging.Focal{X: 1.5, Y: 0.5}}},
+			expectError: customErr.ErrInvalidTransformation,
+		},
+		{
+			name:        "Failed - Quality out of range",
+			opts:        imaging.Options{Format: imaging.FormatJPEG, Encode: imaging.EncodeOptions{Quality: 101}},
+			expectError: customErr.ErrInvalidTransformation,
+		},
+		{
+			name:        "Failed - Crop outside image",
+			opts:        imaging.Options{Crop: &imaging.Crop{X: 90, Y: 0, Width: 20, Height: 20}},
+			expectError: customErr.ErrInvalidTransformation,
+		},
+		{
+			name:        "Failed - Negative resize",
+			opts:        imaging.Options{Resize: &imaging.Resize{Width: -1, Height: 10}},
+			expectError: customErr.ErrInvalidTransformation,
+		},
+		{
+			name:        "Failed - No operation",
+			opts:        imaging.Options{},
+			expectError: customErr.ErrInvalidTransformation,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := imaging.Apply(createImage(100, 50), tc.opts)
+
+			if tc.expectError != nil {
+				assert.ErrorIs(t, err, tc.expectError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedBounds, result.Bounds())
+			}
+		})
+	}
+}
+
+func TestCropKeepsPixels(t *testing.T) {
+	result, err := imaging.Apply(createImage(100, 50), imaging.Options{Crop: &imaging.Crop{X: 10, Y: 20, Width: 5, Height: 5}})
+	assert.NoError(t, err)
+
+	r, g, _, _ := result.At(0, 0).RGBA()
+	assert.Equal(t, uint32(10), r>>8)
+	assert.Equal(t, uint32(20), g>>8)
+}
+
+func TestResizeFit(t *testing.T) {
+	type testCase struct {
+		name   string
+		resize imaging.Resize
+		check  func(t *testing.T, result image.Image)
+	}
+
+	testCases := []testCase{
+		{
+			name:   "Success - Cover keeps the center by default",
+			resize: imaging.Resize{Width: 20, Height: 20, Fit: imaging.FitCover, Filter: imaging.FilterNearest},
+			check: func(t *testing.T, result image.Image) {
+				r, _, _, _ := result.At(0, 0).RGBA()
+				assert.Equal(t, uint32(26), r>>8)
+			},
+		},
+		{
+			name:   "Success - Cover anchors to the gravity",
+			resize: imaging.Resize{Width: 20, Height: 20, Fit: imaging.FitCover, Gravity: imaging.GravityWest, Filter: imaging.FilterNearest},
+			check: func(t *testing.T, result image.Image) {
+				r, _, _, _ := result.At(0, 0).RGBA()
+				assert.Equal(t, uint32(1), r>>8)
+			},
+		},
+		{
+			name:   "Success - Cover keeps the focal point in frame",
+			resize: imaging.Resize{Width: 20, Height: 20, Fit: imaging.FitCover, Focal: &imaging.Focal{X: 1, Y: 0.5}, Filter: imaging.FilterNearest},
+			check: func(t *testing.T, result image.Image) {
+				r, _, _, _ := result.At(19, 0).RGBA()
+				assert.Equal(t, uint32(98), r>>8)
+			},
+		},
+		{
+			name:   "Success - Contain fills the letterbox with the background",
+			resize: imaging.Resize{Width: 40, Height: 40, Fit: imaging.FitContain, Background: color.NRGBA{B: 255, A: 255}},
+			check: func(t *testing.T, result image.Image) {
+				assert.Equal(t, color.NRGBA{B: 255, A: 255}, result.At(20, 5))
+				assert.Equal(t, color.NRGBA{B: 255, A: 255}, result.At(20, 34))
+				_, _, b, _ := result.At(20, 20).RGBA()
+				assert.Equal(t, uint32(128), b>>8)
+			},
+		},
+		{
+			name:   "Success - Nearest copies source pixels",
+			resize: imaging.Resize{Width: 50, Filter: imaging.FilterNearest},
+			check: func(t *testing.T, result image.Image) {
+				assert.Equal(t, color.NRGBA{R: 21, G: 21, B: 128, A: 255}, result.At(10, 10))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resize := tc.resize
+			result, err := imaging.Apply(createImage(100, 50), imaging.Options{Resize: &resize})
+			assert.NoError(t, err)
+			tc.check(t, result)
+		})
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	filter, err := imaging.ParseFilter("")
+	assert.NoError(t, err)
+	assert.Equal(t, imaging.FilterBilinear, filter)
+
+	filter, err = imaging.ParseFilter("Catmull-Rom")
+	assert.NoError(t, err)
+	assert.Equal(t, imaging.FilterCatmullRom, filter)
+
+	_, err = imaging.ParseFilter("bicubic")
+	assert.ErrorIs(t, err, customErr.ErrInvalidTransformation)
+}
+
+func TestTransformConvert(t *testing.T) {
+	var src bytes.Buffer
+	assert.NoError(t, png.Encode(&src, createImage(20, 20)))
+
+	var dst bytes.Buffer
+	format, err := imaging.Transform(&src, &dst, imaging.Options{Format: imaging.FormatJPEG})
+	assert.NoError(t, err)
+	assert.Equal(t, imaging.FormatJPEG, format)
+
+	_, decoded, err := imaging.Decode(&dst)
+	assert.NoError(t, err)
+	assert.Equal(t, imaging.FormatJPEG, decoded)
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := imaging.ParseFormat("PNG")
+	assert.NoError(t, err)
+	assert.Equal(t, imaging.FormatPNG, format)
+
+	format, err = imaging.ParseFormat("jpg")
+	assert.NoError(t, err)
+	assert.Equal(t, imaging.FormatJPEG, format)
+
+	_, err = imaging.ParseFormat("psd")
+	assert.ErrorIs(t, err, customErr.ErrInvalidTransformation)
+}
+
+func TestOperations(t *testing.T) {
+	type testCase struct {
+		name           string
+		opts           imaging.Options
+		expectedBounds image.Rectangle
+		check          func(t *testing.T, result image.Image)
+		expectError    error
+	}
+
+	source := createImage(100, 50)
+
+	testCases := []testCase{
+		{
+			name:           "Success - Quarter turn swaps dimensions",
+			opts:           imaging.Options{Rotate: &imaging.Rotate{Angle: 90}},
+			expectedBounds: image.Rect(0, 0, 50, 100),
+			check: func(t *testing.T, result image.Image) {
+				// the bottom left corner moves to the top left
+				assert.Equal(t, source.At(0, 49), result.At(0, 0))
+			},
+		},
+		{
+			name:           "Success - Negative angle turns counter-clockwise",
+			opts:           imaging.Options{Rotate: &imaging.Rotate{Angle: -90}},
+			expectedBounds: image.Rect(0, 0, 50, 100),
+			check: func(t *testing.T, result image.Image) {
+				assert.Equal(t, source.At(99, 0), result.At(0, 0))
+			},
+		},
+		{
+			name:           "Success - Arbitrary angle grows canvas and fills corners",
+			opts:           imaging.Options{Rotate: &imaging.Rotate{Angle: 45, Background: color.NRGBA{R: 255, A: 255}}},
+			expectedBounds: image.Rect(0, 0, 107, 107),
+			check: func(t *testing.T, result image.Image) {
+				assert.Equal(t, color.NRGBA{R: 255, A: 255}, result.At(0, 0))
+			},
+		},
+		{
+			name:           "Success - Flop mirrors left to right",
+			opts:           imaging.Options{Flop: true},
+			expectedBounds: image.Rect(0, 0, 100, 50),
+			check: func(t *testing.T, result image.Image) {
+				assert.Equal(t, source.At(99, 10), result.At(0, 10))
+			},
+		},
+		{
+			name:           "Success - Flip mirrors top to bottom",
+			opts:           imaging.Options{Flip: true},
+			expectedBounds: image.Rect(0, 0, 100, 50),
+			check: func(t *testing.T, result image.Image) {
+				assert.Equal(t, source.At(10, 49), result.At(10, 0))
+			},
+		},
+		{
+			name:           "Success - Grayscale equalizes channels",
+			opts:           imaging.Options{Adjust: imaging.Adjust{Grayscale: true}},
+			expectedBounds: image.Rect(0, 0, 100, 50),
+			check: func(t *testing.T, result image.Image) {
+				r, g, b, _ := result.At(40, 20).RGBA()
+				assert.Equal(t, r, g)
+				assert.Equal(t, g, b)
+			},
+		},
+		{
+			name:           "Success - Brightness lifts every channel",
+			opts:           imaging.Options{Adjust: imaging.Adjust{Brightness: 100}},
+			expectedBounds: image.Rect(0, 0, 100, 50),
+			check: func(t *testing.T, result image.Image) {
+				assert.Equal(t, color.NRGBA{R: 255, G: 255, B: 255, A: 255}, result.At(10, 10))
+			},
+		},
+		{
+			name:           "Success - Blur keeps dimensions",
+			opts:           imaging.Options{Blur: 2},
+			expectedBounds: image.Rect(0, 0, 100, 50),
+		},
+		{
+			name:           "Success - Sharpen keeps dimensions",
+			opts:           imaging.Options{Sharpen: &imaging.Sharpen{Sigma: 1, Amount: 2}},
+			expectedBounds: image.Rect(0, 0, 100, 50),
+		},
+		{
+			name:        "Failed - Blur sigma too large",
+			opts:        imaging.Options{Blur: imaging.MaxSigma + 1},
+			expectError: customErr.ErrInvalidTransformation,
+		},
+		{
+			name:        "Failed - Sharpen without sigma",
+			opts:        imaging.Options{Sharpen: &imaging.Sharpen{Amount: 1}},
+			expectError: customErr.ErrInvalidTransformation,
+		},
+		{
+			name:        "Failed - Contrast out of range",
+			opts:        imaging.Options{Adjust: imaging.Adjust{Contrast: -150}},
+			expectError: customErr.ErrInvalidTransformation,
+		},
+		{
+			name:        "Failed - Gamma out of range",
+			opts:        imaging.Options{Adjust: imaging.Adjust{Gamma: 0.01}},
+			expectError: customErr.ErrInvalidTransformation,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := imaging.Apply(source, tc.opts)
+
+			if tc.expectError != nil {
+				assert.ErrorIs(t, err, tc.expectError)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedBounds, result.Bounds())
+			if tc.check != nil {
+				tc.check(t, result)
+			}
+		})
+	}
+}
+
+func TestBlurKeepsFlatColor(t *testing.T) {
+	flat := image.NewNRGBA(image.Rect(0, 0, 20, 20))
+	for i := 0; i < len(flat.Pix); i += 4 {
+		flat.Pix[i], flat.Pix[i+1], flat.Pix[i+2], flat.Pix[i+3] = 10, 120, 200, 255
+	}
+
+	result, err := imaging.Apply(flat, imaging.Options{Blur: 3, Sharpen: &imaging.Sharpen{Sigma: 1}})
+	assert.NoError(t, err)
+	assert.Equal(t, color.NRGBA{R: 10, G: 120, B: 200, A: 255}, result.At(0, 0))
+	assert.Equal(t, color.NRGBA{R: 10, G: 120, B: 200, A: 255}, result.At(10, 10))
+}
+
+func TestParseColor(t *testing.T) {
+	c, err := imaging.ParseColor("#fff")
+	assert.NoError(t, err)
+	assert.Equal(t, color.NRGBA{R: 255, G: 255, B: 255, A: 255}, c)
+
+	c, err = imaging.ParseColor("00ff0080")
+	assert.NoError(t, err)
+	assert.Equal(t, color.NRGBA{G: 255, A: 128}, c)
+
+	c, err = imaging.ParseColor("")
+	assert.NoError(t, err)
+	assert.Equal(t, color.NRGBA{}, c)
+
+	_, err = imaging.ParseColor("#ggg")
+	assert.ErrorIs(t, err, customErr.ErrInvalidTransformation)
+}
+
+func TestWatermark(t *testing.T) {
+	base := image.NewNRGBA(image.Rect(0, 0, 100, 50))
+	mark := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	for i := 0; i < len(mark.Pix); i += 4 {
+		mark.Pix[i], mark.Pix[i+3] = 255, 255
+	}
+
+	red := color.NRGBA{R: 255, A: 255}
+
+	type testCase struct {
+		name        string
+		watermark   imaging.Watermark
+		check       func(t *testing.T, result image.Image)
+		expectError error
+	}
+
+	testCases := []testCase{
+		{
+			name:      "Success - Southeast corner with margin",
+			watermark: imaging.Watermark{Image: mark, Gravity: imaging.GravitySouthEast, Margin: 5, Opacity: 1},
+			check: func(t *testing.T, result image.Image) {
+				assert.Equal(t, red, result.At(94, 44))
+				assert.Equal(t, color.NRGBA{}, result.At(95, 45))
+				assert.Equal(t, color.NRGBA{}, result.At(84, 44))
+			},
+		},
+		{
+			name:      "Success - Opacity blends with the base",
+			watermark: imaging.Watermark{Image: mark, Gravity: imaging.GravityNorthWest, Opacity: 0.5},
+			check: func(t *testing.T, result image.Image) {
+				_, _, _, a := result.At(0, 0).RGBA()
+				assert.InDelta(t, 0x8080, a, 0x200)
+			},
+		},
+		{
+			name:      "Success - Scale is relative to the base width",
+			watermark: imaging.Watermark{Image: mark, Gravity: imaging.GravityNorthWest, Opacity: 1, Scale: 0.5},
+			check: func(t *testing.T, result image.Image) {
+				assert.Equal(t, red, result.At(49, 49))
+				assert.Equal(t, color.NRGBA{}, result.At(51, 0))
+			},
+		},
+		{
+			name:      "Success - Tiles cover the whole base",
+			watermark: imaging.Watermark{Image: mark, Opacity: 1, Tile: true, Margin: 10},
+			check: func(t *testing.T, result image.Image) {
+				assert.Equal(t, red, result.At(10, 10))
+				assert.Equal(t, red, result.At(90, 30))
+				assert.Equal(t, color.NRGBA{}, result.At(25, 25))
+			},
+		},
+		{
+			name:        "Failed - Tiles too small once scaled",
+			watermark:   imaging.Watermark{Image: mark, Opacity: 1, Tile: true, Scale: 0.01},
+			expectError: customErr.ErrInvalidTransformation,
+		},
+		{
+			name:        "Failed - Missing overlay",
+			watermark:   imaging.Watermark{Opacity: 1},
+			expectError: customErr.ErrInvalidTransformation,
+		},
+		{
+			name:        "Failed - Opacity out of range",
+			watermark:   imaging.Watermark{Image: mark, Opacity: 1.5},
+			expectError: customErr.ErrInvalidTransformation,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			watermark := tc.watermark
+			result, err := imaging.Apply(base, imaging.Options{Watermark: &watermark})
+
+			if tc.expectError != nil {
+				assert.ErrorIs(t, err, tc.expectError)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, base.Bounds(), result.Bounds())
+			tc.check(t, result)
+		})
+	}
+}
+
+func TestText(t *testing.T) {
+	mark, err := imaging.Text("ImageSmith", 24, color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+	assert.NoError(t, err)
+	assert.Greater(t, mark.Bounds().Dx(), mark.Bounds().Dy())
+
+	var opaque int
+	nrgba := mark.(*image.NRGBA)
+	for i := 3; i < len(nrgba.Pix); i += 4 {
+		if nrgba.Pix[i] > 0 {
+			opaque++
+		}
+	}
+	assert.Positive(t, opaque)
+
+	_, err = imaging.Text("   ", 24, color.NRGBA{})
+	assert.ErrorIs(t, err, customErr.ErrInvalidTransformation)
+
+	_, err = imaging.Text("ImageSmith", imaging.MaxFontSize+1, color.NRGBA{})
+	assert.ErrorIs(t, err, customErr.ErrInvalidTransformation)
+}
@@ -0,0 +1,122 @@
+package jwt_test
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/federicodosantos/image-smith/pkg/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func writePrivateKey(t *testing.T, key crypto.Signer) string {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "private.pem")
+	assert.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+	return path
+}
+
+func writePublicKey(t *testing.T, key crypto.PublicKey) string {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	assert.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "public.pem")
+	assert.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600))
+	return path
+}
+
+func newEd25519Key(t *testing.T) ed25519.PrivateKey {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	return key
+}
+
+func TestAsymmetricJwt(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	type testCase struct {
+		name      string
+		algorithm string
+		key       crypto.Signer
+		kty       string
+	}
+
+	testCases := []testCase{
+		{name: "RS256", algorithm: "RS256", key: rsaKey, kty: "RSA"},
+		{name: "EdDSA", algorithm: "EdDSA", key: newEd25519Key(t), kty: "OKP"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service, err := jwt.NewAsymmetricJwt(tc.algorithm, writePrivateKey(t, tc.key), nil, "1h")
+			assert.NoError(t, err)
+
+			token, err := service.CreateToken("user-id")
+			assert.NoError(t, err)
+
+			userID, err := service.VerifyToken(token)
+			assert.NoError(t, err)
+			assert.Equal(t, "user-id", userID)
+
+			jwks := service.JWKS()
+			assert.Len(t, jwks.Keys, 1)
+			assert.Equal(t, tc.kty, jwks.Keys[0].Kty)
+			assert.Equal(t, tc.algorithm, jwks.Keys[0].Alg)
+		})
+	}
+}
+
+func TestAsymmetricJwtAlgorithmMismatch(t *testing.T) {
+	_, err := jwt.NewAsymmetricJwt("RS256", writePrivateKey(t, newEd25519Key(t)), nil, "1h")
+	assert.Error(t, err)
+}
+
+func TestAsymmetricJwtKeyRotation(t *testing.T) {
+	oldKey := newEd25519Key(t)
+	newKey := newEd25519Key(t)
+
+	oldService, err := jwt.NewAsymmetricJwt("EdDSA", writePrivateKey(t, oldKey), nil, "1h")
+	assert.NoError(t, err)
+
+	oldToken, err := oldService.CreateToken("user-id")
+	assert.NoError(t, err)
+
+	// the new service signs with the new key and still trusts the old one
+	rotated, err := jwt.NewAsymmetricJwt("EdDSA", writePrivateKey(t, newKey), []string{writePublicKey(t, oldKey.Public())}, "1h")
+	assert.NoError(t, err)
+
+	userID, err := rotated.VerifyToken(oldToken)
+	assert.NoError(t, err)
+	assert.Equal(t, "user-id", userID)
+	assert.Len(t, rotated.JWKS().Keys, 2)
+
+	// once the old key is dropped its tokens are rejected
+	retired, err := jwt.NewAsymmetricJwt("EdDSA", writePrivateKey(t, newKey), nil, "1h")
+	assert.NoError(t, err)
+
+	_, err = retired.VerifyToken(oldToken)
+	assert.Error(t, err)
+}
+
+func TestAsymmetricJwtRejectsSymmetricToken(t *testing.T) {
+	service, err := jwt.NewAsymmetricJwt("EdDSA", writePrivateKey(t, newEd25519Key(t)), nil, "1h")
+	assert.NoError(t, err)
+
+	hmacService, err := jwt.NewJwt("secret", "1h")
+	assert.NoError(t, err)
+
+	token, err := hmacService.CreateToken("user-id")
+	assert.NoError(t, err)
+
+	_, err = service.VerifyToken(token)
+	assert.Error(t, err)
+}
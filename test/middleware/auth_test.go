@@ -0,0 +1,97 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	"github.com/federicodosantos/image-smith/pkg/jwt"
+	"github.com/federicodosantos/image-smith/pkg/middleware"
+	response "github.com/federicodosantos/image-smith/pkg/response"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthenticate(t *testing.T) {
+	jwtService, err := jwt.NewJwt("secret", "1h")
+	assert.NoError(t, err)
+
+	otherService, err := jwt.NewJwt("other-secret", "1h")
+	assert.NoError(t, err)
+
+	validToken, _ := jwtService.CreateToken("user-id")
+	foreignToken, _ := otherService.CreateToken("user-id")
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := middleware.UserIDFromContext(r.Context())
+		assert.True(t, ok)
+		response.SuccessResponse(w, http.StatusOK, "ok", userID)
+	})
+
+	handler := middleware.Authenticate(jwtService)(next)
+
+	type testCase struct {
+		name            string
+		setupRequest    func(r *http.Request)
+		expectedStatus  int
+		expectedMessage string
+	}
+
+	testCases := []testCase{
+		{
+			name: "Success - Bearer token",
+			setupRequest: func(r *http.Request) {
+				r.Header.Set("Authorization", "Bearer "+validToken)
+			},
+			expectedStatus:  http.StatusOK,
+			expectedMessage: "ok",
+		},
+		{
+			name: "Success - Token cookie",
+			setupRequest: func(r *http.Request) {
+				r.AddCookie(&http.Cookie{Name: middleware.TokenCookieName, Value: validToken})
+			},
+			expectedStatus:  http.StatusOK,
+			expectedMessage: "ok",
+		},
+		{
+			name:            "Unauthorized - Missing token",
+			setupRequest:    func(r *http.Request) {},
+			expectedStatus:  http.StatusUnauthorized,
+			expectedMessage: customErr.ErrUserIdNotFound.Error(),
+		},
+		{
+			name: "Unauthorized - Wrong scheme",
+			setupRequest: func(r *http.Request) {
+				r.Header.Set("Authorization", "Basic "+validToken)
+			},
+			expectedStatus:  http.StatusUnauthorized,
+			expectedMessage: customErr.ErrUserIdNotFound.Error(),
+		},
+		{
+			name: "Unauthorized - Token signed with another key",
+			setupRequest: func(r *http.Request) {
+				r.Header.Set("Authorization", "Bearer "+foreignToken)
+			},
+			expectedStatus:  http.StatusUnauthorized,
+			expectedMessage: customErr.ErrInvalidToken.Error(),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "http://0.0.0.0/images", nil)
+			tc.setupRequest(r)
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, r)
+
+			var body response.HttpResponse
+			json.NewDecoder(rec.Body).Decode(&body)
+
+			assert.Equal(t, tc.expectedStatus, rec.Code)
+			assert.Equal(t, tc.expectedMessage, body.Message)
+		})
+	}
+}
@@ -0,0 +1,673 @@
+package repository_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/federicodosantos/image-smith/internal/model"
+	"github.com/federicodosantos/image-smith/internal/repository"
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+var imageColumns = []string{"id", "owner_id", "parent_id", "original_filename", "storage_key", "mime_type",
+	"width", "height", "size", "checksum", "created_at", "updated_at", "focal_x", "focal_y",
+	"camera_make", "camera_model", "taken_at", "orientation", "phash"}
+
+func createImage() *model.Image {
+	now := time.Now()
+
+	return &model.Image{
+		ID:               "image-id",
+		OwnerID:          "owner-id",
+		OriginalFilename: "photo.png",
+		StorageKey:       "owner-id/image-id.png",
+		MimeType:         "image/png",
+		Width:            640,
+		Height:           480,
+		Size:             2048,
+		Checksum:         "checksum",
+		Orientation:      1,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+}
+
+func imageRow(image *model.Image) *sqlmock.Rows {
+	return sqlmock.NewRows(imageColumns).
+		AddRow(image.ID, image.OwnerID, image.ParentID, image.OriginalFilename, image.StorageKey, image.MimeType,
+			image.Width, image.Height, image.Size, image.Checksum, image.CreatedAt, image.UpdatedAt, image.FocalX, image.FocalY,
+			image.CameraMake, image.CameraModel, image.TakenAt, image.Orientation, image.PHash)
+}
+
+func TestGetImageById(t *testing.T) {
+	type testCase struct {
+		name          string
+		ownerID       string
+		setupMock     func(mock sqlmock.Sqlmock, image *model.Image)
+		expectedError error
+	}
+
+	getQuery := regexp.QuoteMeta(`SELECT * FROM images WHERE id = $1 AND owner_id = $2`)
+
+	testCases := []testCase{
+		{
+			name:    "Success - Owner reads image",
+			ownerID: "owner-id",
+			setupMock: func(mock sqlmock.Sqlmock, image *model.Image) {
+				mock.ExpectQuery(getQuery).
+					WithArgs(image.ID, "owner-id").
+					WillReturnRows(imageRow(image))
+			},
+			expectedError: nil,
+		},
+		{
+			name:    "Error - Other user gets not found",
+			ownerID: "intruder-id",
+			setupMock: func(mock sqlmock.Sqlmock, image *model.Image) {
+				mock.ExpectQuery(getQuery).
+					WithArgs(image.ID, "intruder-id").
+					WillReturnRows(sqlmock.NewRows(imageColumns))
+			},
+			expectedError: customErr.ErrImageNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := setup()
+			if err != nil {
+				t.Fatalf("Error creating sql mock and db: %s", err)
+			}
+			defer db.Close()
+
+			image := createImage()
+			tc.setupMock(mock, image)
+
+			i := repository.NewImageRepository(db)
+
+			result, err := i.GetImageById(context.Background(), image.ID, tc.ownerID)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, image, result)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestGetImageOwner(t *testing.T) {
+	db, mock, err := setup()
+	if err != nil {
+		t.Fatalf("Error creating sql mock and db: %s", err)
+	}
+	defer db.Close()
+
+	ownerQuery := regexp.QuoteMeta(`SELECT owner_id FROM images WHERE id = $1 AND status = 'ready'`)
+	mock.ExpectQuery(ownerQuery).WithArgs("image-id").WillReturnRows(sqlmock.NewRows([]string{"owner_id"}).AddRow("owner-id"))
+	mock.ExpectQuery(ownerQuery).WithArgs("missing-id").WillReturnRows(sqlmock.NewRows([]string{"owner_id"}))
+
+	i := repository.NewImageRepository(db)
+
+	ownerID, err := i.GetImageOwner(context.Background(), "image-id")
+	assert.NoError(t, err)
+	assert.Equal(t, "owner-id", ownerID)
+
+	_, err = i.GetImageOwner(context.Background(), "missing-id")
+	assert.ErrorIs(t, err, customErr.ErrImageNotFound)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %s", err)
+	}
+}
+
+func TestUpdateFocalPoint(t *testing.T) {
+	x, y := 0.25, 0.5
+
+	type testCase struct {
+		name          string
+		x, y          *float64
+		rowsAffected  int64
+		expectedError error
+	}
+
+	testCases := []testCase{
+		{name: "Success - Set focal point", x: &x, y: &y, rowsAffected: 1, expectedError: nil},
+		{name: "Success - Clear focal point", rowsAffected: 1, expectedError: nil},
+		{name: "Error - Image of another user", x: &x, y: &y, rowsAffected: 0, expectedError: customErr.ErrImageNotFound},
+		{name: "Error - Image still pending", x: &x, y: &y, rowsAffected: 0, expectedError: customErr.ErrImageNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := setup()
+			if err != nil {
+				t.Fatalf("Error creating sql mock and db: %s", err)
+			}
+			defer db.Close()
+
+			mock.ExpectExec(regexp.QuoteMeta(`UPDATE images SET focal_x = $1, focal_y = $2, updated_at = $3 WHERE id = $4 AND owner_id = $5 AND status = 'ready'`)).
+				WithArgs(tc.x, tc.y, sqlmock.AnyArg(), "image-id", "owner-id").
+				WillReturnResult(sqlmock.NewResult(0, tc.rowsAffected))
+
+			i := repository.NewImageRepository(db)
+
+			err = i.UpdateFocalPoint(context.Background(), "image-id", "owner-id", tc.x, tc.y)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestCreateImage(t *testing.T) {
+	acquireQuery := regexp.QuoteMeta(`UPDATE image_blobs SET ref_count = ref_count + 1`)
+	insertBlobQuery := regexp.QuoteMeta(`INSERT INTO image_blobs(storage_key, owner_id, checksum, ref_count) VALUES($1, $2, $3, 1)`)
+	insertQuery := regexp.QuoteMeta(`INSERT INTO images(`)
+
+	type testCase struct {
+		name          string
+		setupMock     func(mock sqlmock.Sqlmock, image *model.Image)
+		expectedKey   string
+		expectedError error
+	}
+
+	testCases := []testCase{
+		{
+			name: "Success - New content gets its own blob",
+			setupMock: func(mock sqlmock.Sqlmock, image *model.Image) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(acquireQuery).WithArgs(image.OwnerID, image.Checksum).WillReturnRows(sqlmock.NewRows([]string{"storage_key"}))
+				mock.ExpectExec(insertBlobQuery).WithArgs(image.StorageKey, image.OwnerID, image.Checksum).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(insertQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedKey: "owner-id/image-id.png",
+		},
+		{
+			name: "Success - Content the owner stored shares the blob",
+			setupMock: func(mock sqlmock.Sqlmock, image *model.Image) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(acquireQuery).WithArgs(image.OwnerID, image.Checksum).
+					WillReturnRows(sqlmock.NewRows([]string{"storage_key"}).AddRow("owner-id/earlier-id.png"))
+				mock.ExpectExec(insertQuery).
+					WithArgs(image.ID, image.OwnerID, image.ParentID, image.OriginalFilename, "owner-id/earlier-id.png", image.MimeType,
+						image.Width, image.Height, image.Size, image.Checksum, image.CameraMake, image.CameraModel, image.TakenAt,
+						image.Orientation, image.PHash, image.CreatedAt, image.UpdatedAt).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedKey: "owner-id/earlier-id.png",
+		},
+		{
+			name: "Error - Owner already has the content",
+			setupMock: func(mock sqlmock.Sqlmock, image *model.Image) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(acquireQuery).WithArgs(image.OwnerID, image.Checksum).
+					WillReturnRows(sqlmock.NewRows([]string{"storage_key"}).AddRow("owner-id/first-id.png"))
+				mock.ExpectExec(insertQuery).WillReturnError(&pq.Error{Code: "23505"})
+				mock.ExpectRollback()
+			},
+			expectedKey:   "owner-id/image-id.png",
+			expectedError: customErr.ErrDuplicateImage,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := setup()
+			if err != nil {
+				t.Fatalf("Error creating sql mock and db: %s", err)
+			}
+			defer db.Close()
+
+			image := createImage()
+			tc.setupMock(mock, image)
+
+			i := repository.NewImageRepository(db)
+
+			err = i.CreateImage(context.Background(), image)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedKey, image.StorageKey)
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestCreatePendingImage(t *testing.T) {
+	db, mock, err := setup()
+	if err != nil {
+		t.Fatalf("Error creating sql mock and db: %s", err)
+	}
+	defer db.Close()
+
+	image := createImage()
+	expiresAt := image.CreatedAt.Add(time.Hour)
+	image.UploadExpiresAt = &expiresAt
+
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO images(id, owner_id, original_filename, storage_key, mime_type, size, status, upload_expires_at`)).
+		WithArgs(image.ID, image.OwnerID, image.OriginalFilename, image.StorageKey, image.MimeType, image.Size,
+			&expiresAt, image.CreatedAt, image.UpdatedAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	i := repository.NewImageRepository(db)
+
+	assert.NoError(t, i.CreatePendingImage(context.Background(), image))
+	assert.Equal(t, model.ImageStatusPending, image.Status)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetPendingImage(t *testing.T) {
+	db, mock, err := setup()
+	if err != nil {
+		t.Fatalf("Error creating sql mock and db: %s", err)
+	}
+	defer db.Close()
+
+	image := createImage()
+	pendingQuery := regexp.QuoteMeta(`SELECT * FROM images WHERE id = $1 AND owner_id = $2 AND status = 'pending' AND upload_expires_at > $3`)
+
+	mock.ExpectQuery(pendingQuery).WithArgs("image-id", "owner-id", sqlmock.AnyArg()).WillReturnRows(imageRow(image))
+	mock.ExpectQuery(pendingQuery).WithArgs("ready-id", "owner-id", sqlmock.AnyArg()).WillReturnRows(sqlmock.NewRows(imageColumns))
+
+	i := repository.NewImageRepository(db)
+
+	found, err := i.GetPendingImage(context.Background(), "image-id", "owner-id")
+	assert.NoError(t, err)
+	assert.Equal(t, "image-id", found.ID)
+
+	_, err = i.GetPendingImage(context.Background(), "ready-id", "owner-id")
+	assert.ErrorIs(t, err, customErr.ErrImageNotFound)
+}
+
+func TestCompleteImage(t *testing.T) {
+	acquireQuery := regexp.QuoteMeta(`UPDATE image_blobs SET ref_count = ref_count + 1`)
+	insertBlobQuery := regexp.QuoteMeta(`INSERT INTO image_blobs(storage_key, owner_id, checksum, ref_count) VALUES($1, $2, $3, 1)`)
+	completeQuery := regexp.QuoteMeta(`UPDATE images SET status = 'ready', upload_expires_at = NULL`)
+
+	type testCase struct {
+		name           string
+		setupMock      func(mock sqlmock.Sqlmock, image *model.Image)
+		expectedKey    string
+		expectedStatus string
+		expectedError  error
+	}
+
+	testCases := []testCase{
+		{
+			name: "Success - New content gets its own blob",
+			setupMock: func(mock sqlmock.Sqlmock, image *model.Image) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(acquireQuery).WithArgs(image.OwnerID, image.Checksum).WillReturnRows(sqlmock.NewRows([]string{"storage_key"}))
+				mock.ExpectExec(insertBlobQuery).WithArgs(image.StorageKey, image.OwnerID, image.Checksum).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(completeQuery).
+					WithArgs(image.StorageKey, image.MimeType, image.Width, image.Height, image.Size, image.Checksum, image.CameraMake,
+						image.CameraModel, image.TakenAt, image.Orientation, image.PHash, sqlmock.AnyArg(), image.ID, image.OwnerID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedKey:    "owner-id/image-id.png",
+			expectedStatus: model.ImageStatusReady,
+		},
+		{
+			name: "Success - Content the owner stored shares the blob",
+			setupMock: func(mock sqlmock.Sqlmock, image *model.Image) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(acquireQuery).WithArgs(image.OwnerID, image.Checksum).
+					WillReturnRows(sqlmock.NewRows([]string{"storage_key"}).AddRow("owner-id/earlier-id.png"))
+				mock.ExpectExec(completeQuery).WithArgs("owner-id/earlier-id.png", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+					sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+					sqlmock.AnyArg(), sqlmock.AnyArg(), image.ID, image.OwnerID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedKey:    "owner-id/earlier-id.png",
+			expectedStatus: model.ImageStatusReady,
+		},
+		{
+			name: "Error - Owner already has the content",
+			setupMock: func(mock sqlmock.Sqlmock, image *model.Image) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(acquireQuery).WithArgs(image.OwnerID, image.Checksum).
+					WillReturnRows(sqlmock.NewRows([]string{"storage_key"}).AddRow("owner-id/first-id.png"))
+				mock.ExpectExec(completeQuery).WillReturnError(&pq.Error{Code: "23505"})
+				mock.ExpectRollback()
+			},
+			expectedKey:    "owner-id/image-id.png",
+			expectedStatus: model.ImageStatusPending,
+			expectedError:  customErr.ErrDuplicateImage,
+		},
+		{
+			name: "Error - Reaped in the meantime",
+			setupMock: func(mock sqlmock.Sqlmock, image *model.Image) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(acquireQuery).WithArgs(image.OwnerID, image.Checksum).WillReturnRows(sqlmock.NewRows([]string{"storage_key"}))
+				mock.ExpectExec(insertBlobQuery).WithArgs(image.StorageKey, image.OwnerID, image.Checksum).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(completeQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			expectedKey:    "owner-id/image-id.png",
+			expectedStatus: model.ImageStatusPending,
+			expectedError:  customErr.ErrImageNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := setup()
+			if err != nil {
+				t.Fatalf("Error creating sql mock and db: %s", err)
+			}
+			defer db.Close()
+
+			image := createImage()
+			image.Status = model.ImageStatusPending
+			tc.setupMock(mock, image)
+
+			i := repository.NewImageRepository(db)
+
+			err = i.CompleteImage(context.Background(), image)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedKey, image.StorageKey)
+			assert.Equal(t, tc.expectedStatus, image.Status)
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestExpiredPendingImages(t *testing.T) {
+	db, mock, err := setup()
+	if err != nil {
+		t.Fatalf("Error creating sql mock and db: %s", err)
+	}
+	defer db.Close()
+
+	now := time.Now()
+	image := createImage()
+	deleteQuery := regexp.QuoteMeta(`DELETE FROM images WHERE id = $1 AND owner_id = $2 AND status = 'pending'`)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM images WHERE status = 'pending' AND upload_expires_at <= $1`)).
+		WithArgs(now, 100).
+		WillReturnRows(imageRow(image))
+	mock.ExpectExec(deleteQuery).WithArgs("image-id", "owner-id").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(deleteQuery).WithArgs("image-id", "owner-id").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	i := repository.NewImageRepository(db)
+
+	images, err := i.ListExpiredPendingImages(context.Background(), now, 100)
+	assert.NoError(t, err)
+	assert.Len(t, images, 1)
+
+	assert.NoError(t, i.DeletePendingImage(context.Background(), "image-id", "owner-id"))
+	// completed or already reaped
+	assert.ErrorIs(t, i.DeletePendingImage(context.Background(), "image-id", "owner-id"), customErr.ErrImageNotFound)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetImageByChecksum(t *testing.T) {
+	db, mock, err := setup()
+	if err != nil {
+		t.Fatalf("Error creating sql mock and db: %s", err)
+	}
+	defer db.Close()
+
+	image := createImage()
+	checksumQuery := regexp.QuoteMeta(`SELECT * FROM images WHERE owner_id = $1 AND checksum = $2 AND status = 'ready' AND NOT checksum_duplicate`)
+
+	mock.ExpectQuery(checksumQuery).WithArgs("owner-id", "checksum").WillReturnRows(imageRow(image))
+	mock.ExpectQuery(checksumQuery).WithArgs("owner-id", "unknown").WillReturnRows(sqlmock.NewRows(imageColumns))
+
+	i := repository.NewImageRepository(db)
+
+	result, err := i.GetImageByChecksum(context.Background(), "owner-id", "checksum")
+	assert.NoError(t, err)
+	assert.Equal(t, image, result)
+
+	result, err = i.GetImageByChecksum(context.Background(), "owner-id", "unknown")
+	assert.ErrorIs(t, err, customErr.ErrImageNotFound)
+	assert.Nil(t, result)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %s", err)
+	}
+}
+
+func TestFindSimilarImages(t *testing.T) {
+	db, mock, err := setup()
+	if err != nil {
+		t.Fatalf("Error creating sql mock and db: %s", err)
+	}
+	defer db.Close()
+
+	similar := createImage()
+	similar.ID = "similar-id"
+	rows := sqlmock.NewRows(append(imageColumns, "distance")).
+		AddRow(similar.ID, similar.OwnerID, similar.ParentID, similar.OriginalFilename, similar.StorageKey, similar.MimeType,
+			similar.Width, similar.Height, similar.Size, similar.Checksum, similar.CreatedAt, similar.UpdatedAt, similar.FocalX, similar.FocalY,
+			similar.CameraMake, similar.CameraModel, similar.TakenAt, similar.Orientation, similar.PHash, 3)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT *, bit_count((phash # $3)::bit(64)) AS distance FROM images`)).
+		WithArgs("owner-id", "image-id", int64(-42), 10, 20).
+		WillReturnRows(rows)
+
+	i := repository.NewImageRepository(db)
+
+	result, err := i.FindSimilarImages(context.Background(), "owner-id", "image-id", -42, 10, 20)
+	assert.NoError(t, err)
+	assert.Len(t, result, 1)
+	assert.Equal(t, *similar, result[0].Image)
+	assert.Equal(t, 3, result[0].Distance)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %s", err)
+	}
+}
+
+func TestDeleteImage(t *testing.T) {
+	deleteQuery := regexp.QuoteMeta(`DELETE FROM images WHERE id = $1 AND owner_id = $2 RETURNING storage_key`)
+	releaseQuery := regexp.QuoteMeta(`UPDATE image_blobs SET ref_count = ref_count - 1 WHERE storage_key = $1 RETURNING ref_count`)
+	deleteBlobQuery := regexp.QuoteMeta(`DELETE FROM image_blobs WHERE storage_key = $1 AND ref_count = 0`)
+	storageKey := "owner-id/image-id.png"
+
+	type testCase struct {
+		name            string
+		setupMock       func(mock sqlmock.Sqlmock)
+		expectedLastRef bool
+		expectedError   error
+	}
+
+	testCases := []testCase{
+		{
+			name: "Success - Last reference releases the blob",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(deleteQuery).WithArgs("image-id", "owner-id").
+					WillReturnRows(sqlmock.NewRows([]string{"storage_key"}).AddRow(storageKey))
+				mock.ExpectQuery(releaseQuery).WithArgs(storageKey).
+					WillReturnRows(sqlmock.NewRows([]string{"ref_count"}).AddRow(0))
+				mock.ExpectExec(deleteBlobQuery).WithArgs(storageKey).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedLastRef: true,
+		},
+		{
+			name: "Success - Shared blob is kept",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(deleteQuery).WithArgs("image-id", "owner-id").
+					WillReturnRows(sqlmock.NewRows([]string{"storage_key"}).AddRow(storageKey))
+				mock.ExpectQuery(releaseQuery).WithArgs(storageKey).
+					WillReturnRows(sqlmock.NewRows([]string{"ref_count"}).AddRow(2))
+				mock.ExpectCommit()
+			},
+			expectedLastRef: false,
+		},
+		{
+			name: "Error - Image of another user",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(deleteQuery).WithArgs("image-id", "owner-id").
+					WillReturnRows(sqlmock.NewRows([]string{"storage_key"}))
+				mock.ExpectRollback()
+			},
+			expectedError: customErr.ErrImageNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := setup()
+			if err != nil {
+				t.Fatalf("Error creating sql mock and db: %s", err)
+			}
+			defer db.Close()
+
+			tc.setupMock(mock)
+
+			i := repository.NewImageRepository(db)
+
+			lastRef, err := i.DeleteImage(context.Background(), "image-id", "owner-id")
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedLastRef, lastRef)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestListImages(t *testing.T) {
+	t.Run("Success - Filters, keyset and next cursor", func(t *testing.T) {
+		db, mock, err := setup()
+		if err != nil {
+			t.Fatalf("Error creating sql mock and db: %s", err)
+		}
+		defer db.Close()
+
+		first, second, third := createImage(), createImage(), createImage()
+		first.ID, second.ID, third.ID = "a", "b", "c"
+		first.Size, second.Size, third.Size = 300, 200, 100
+
+		rows := sqlmock.NewRows(imageColumns)
+		for _, image := range []*model.Image{first, second, third} {
+			rows.AddRow(image.ID, image.OwnerID, image.ParentID, image.OriginalFilename, image.StorageKey, image.MimeType,
+				image.Width, image.Height, image.Size, image.Checksum, image.CreatedAt, image.UpdatedAt, image.FocalX, image.FocalY,
+				image.CameraMake, image.CameraModel, image.TakenAt, image.Orientation, image.PHash)
+		}
+
+		minSize := int64(50)
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM images WHERE owner_id = $1 AND status = 'ready' AND mime_type = $2 AND size >= $3 `+
+			`AND parent_id IS NULL ORDER BY size DESC, id DESC LIMIT $4`)).
+			WithArgs("owner-id", "image/png", minSize, 3).
+			WillReturnRows(rows)
+
+		i := repository.NewImageRepository(db)
+
+		page, err := i.ListImages(context.Background(), repository.ImageFilter{
+			OwnerID:       "owner-id",
+			MimeType:      "image/png",
+			MinSize:       &minSize,
+			OriginalsOnly: true,
+			SortBy:        repository.SortBySize,
+			Limit:         2,
+		})
+		assert.NoError(t, err)
+		assert.Len(t, page.Images, 2)
+		assert.True(t, page.HasMore)
+		assert.NotEmpty(t, page.NextCursor)
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM images WHERE owner_id = $1 AND status = 'ready' AND (size, id) < ($2, $3) `+
+			`ORDER BY size DESC, id DESC LIMIT $4`)).
+			WithArgs("owner-id", int64(200), "b", 3).
+			WillReturnRows(sqlmock.NewRows(imageColumns))
+
+		next, err := i.ListImages(context.Background(), repository.ImageFilter{
+			OwnerID: "owner-id",
+			SortBy:  repository.SortBySize,
+			Limit:   2,
+			Cursor:  page.NextCursor,
+		})
+		assert.NoError(t, err)
+		assert.Empty(t, next.Images)
+		assert.False(t, next.HasMore)
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("Unfulfilled expectations: %s", err)
+		}
+	})
+
+	t.Run("Error - Cursor from another sort order", func(t *testing.T) {
+		db, mock, err := setup()
+		if err != nil {
+			t.Fatalf("Error creating sql mock and db: %s", err)
+		}
+		defer db.Close()
+
+		mock.ExpectQuery(`SELECT \* FROM images`).
+			WillReturnRows(sqlmock.NewRows(imageColumns).
+				AddRow("a", "owner-id", nil, "", "", "image/png", 1, 1, 1, "", time.Now(), time.Now(), nil, nil, nil, nil, nil, 1, nil).
+				AddRow("b", "owner-id", nil, "", "", "image/png", 1, 1, 1, "", time.Now(), time.Now(), nil, nil, nil, nil, nil, 1, nil))
+
+		i := repository.NewImageRepository(db)
+
+		page, err := i.ListImages(context.Background(), repository.ImageFilter{OwnerID: "owner-id", Limit: 1})
+		assert.NoError(t, err)
+
+		_, err = i.ListImages(context.Background(), repository.ImageFilter{
+			OwnerID: "owner-id",
+			SortBy:  repository.SortBySize,
+			Limit:   1,
+			Cursor:  page.NextCursor,
+		})
+		assert.ErrorIs(t, err, customErr.ErrInvalidCursor)
+
+		_, err = i.ListImages(context.Background(), repository.ImageFilter{OwnerID: "owner-id", Limit: 1, Cursor: "!!"})
+		assert.ErrorIs(t, err, customErr.ErrInvalidCursor)
+	})
+}
@@ -0,0 +1,135 @@
+package repository_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/federicodosantos/image-smith/internal/model"
+	"github.com/federicodosantos/image-smith/internal/repository"
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	"github.com/stretchr/testify/assert"
+)
+
+var jobColumns = []string{"id", "owner_id", "image_id", "type", "payload", "status", "attempts", "max_attempts",
+	"run_at", "locked_until", "result_image_id", "last_error", "created_at", "updated_at"}
+
+func TestClaimJob(t *testing.T) {
+	// a job whose lease expired is only claimed again with attempts left
+	claimQuery := `UPDATE jobs SET status = 'running'.*locked_until < \$2 AND attempts < max_attempts.*FOR UPDATE SKIP LOCKED.*RETURNING \*`
+
+	t.Run("Success - Claims due job", func(t *testing.T) {
+		db, mock, err := setup()
+		if err != nil {
+			t.Fatalf("Error creating sql mock and db: %s", err)
+		}
+		defer db.Close()
+
+		now := time.Now()
+		mock.ExpectQuery(claimQuery).
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows(jobColumns).
+				AddRow("job-id", "owner-id", "image-id", model.JobTypeTransform, []byte(`{}`), model.JobStatusRunning,
+					1, 5, now, now.Add(time.Minute), nil, nil, now, now))
+
+		j := repository.NewJobRepository(db)
+
+		job, err := j.ClaimJob(context.Background(), time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, "job-id", job.ID)
+		assert.Equal(t, 1, job.Attempts)
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("Unfulfilled expectations: %s", err)
+		}
+	})
+
+	t.Run("Success - No job due", func(t *testing.T) {
+		db, mock, err := setup()
+		if err != nil {
+			t.Fatalf("Error creating sql mock and db: %s", err)
+		}
+		defer db.Close()
+
+		mock.ExpectQuery(claimQuery).WillReturnRows(sqlmock.NewRows(jobColumns))
+
+		j := repository.NewJobRepository(db)
+
+		job, err := j.ClaimJob(context.Background(), time.Minute)
+		assert.NoError(t, err)
+		assert.Nil(t, job)
+	})
+}
+
+func TestFailAbandonedJobs(t *testing.T) {
+	db, mock, err := setup()
+	if err != nil {
+		t.Fatalf("Error creating sql mock and db: %s", err)
+	}
+	defer db.Close()
+
+	now := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta(`UPDATE jobs SET status = 'failed'`)+`.*attempts >= max_attempts.*RETURNING \*`).
+		WithArgs("worker stopped", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows(jobColumns).
+			AddRow("job-id", "owner-id", "image-id", model.JobTypeTransform, []byte(`{}`), model.JobStatusFailed,
+				5, 5, now, nil, nil, "worker stopped", now, now))
+
+	j := repository.NewJobRepository(db)
+
+	jobs, err := j.FailAbandonedJobs(context.Background(), "worker stopped")
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 1)
+	assert.Equal(t, "job-id", jobs[0].ID)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetJobById(t *testing.T) {
+	db, mock, err := setup()
+	if err != nil {
+		t.Fatalf("Error creating sql mock and db: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM jobs WHERE id = $1 AND owner_id = $2`)).
+		WithArgs("job-id", "intruder-id").
+		WillReturnRows(sqlmock.NewRows(jobColumns))
+
+	j := repository.NewJobRepository(db)
+
+	job, err := j.GetJobById(context.Background(), "job-id", "intruder-id")
+	assert.ErrorIs(t, err, customErr.ErrJobNotFound)
+	assert.Nil(t, job)
+}
+
+func TestRetryJob(t *testing.T) {
+	db, mock, err := setup()
+	if err != nil {
+		t.Fatalf("Error creating sql mock and db: %s", err)
+	}
+	defer db.Close()
+
+	runAt := time.Now().Add(time.Minute)
+	retryQuery := regexp.QuoteMeta(`UPDATE jobs SET status = 'queued', run_at = $1`)
+	mock.ExpectExec(retryQuery).
+		WithArgs(runAt, "boom", sqlmock.AnyArg(), "job-id", 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// the lease expired and another worker claimed a third attempt
+	mock.ExpectExec(retryQuery).
+		WithArgs(runAt, "boom", sqlmock.AnyArg(), "job-id", 2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	j := repository.NewJobRepository(db)
+
+	assert.NoError(t, j.RetryJob(context.Background(), "job-id", 2, runAt, "boom"))
+	assert.ErrorIs(t, j.RetryJob(context.Background(), "job-id", 2, runAt, "boom"), customErr.ErrJobLeaseLost)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,88 @@
+package repository_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/federicodosantos/image-smith/internal/model"
+	"github.com/federicodosantos/image-smith/internal/repository"
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRotateRefreshToken(t *testing.T) {
+	type testCase struct {
+		name          string
+		setupMock     func(mock sqlmock.Sqlmock, token *model.RefreshToken)
+		expectedError error
+	}
+
+	newToken := &model.RefreshToken{
+		ID:        "new-token-id",
+		UserID:    "user-id",
+		FamilyID:  "family-id",
+		TokenHash: "hash",
+		ExpiresAt: time.Now().Add(time.Hour),
+		CreatedAt: time.Now(),
+	}
+
+	revokeQuery := regexp.QuoteMeta(`UPDATE refresh_tokens SET revoked_at = $1, replaced_by = $2 WHERE id = $3 AND revoked_at IS NULL`)
+	insertQuery := regexp.QuoteMeta(`INSERT INTO refresh_tokens(id, user_id, family_id, token_hash, expires_at, created_at) VALUES($1, $2, $3, $4, $5, $6)`)
+
+	testCases := []testCase{
+		{
+			name: "Success - Rotate refresh token",
+			setupMock: func(mock sqlmock.Sqlmock, token *model.RefreshToken) {
+				mock.ExpectBegin()
+				mock.ExpectExec(revokeQuery).
+					WithArgs(sqlmock.AnyArg(), token.ID, "old-token-id").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(insertQuery).
+					WithArgs(token.ID, token.UserID, token.FamilyID, token.TokenHash, sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			expectedError: nil,
+		},
+		{
+			name: "Error - Old token already revoked",
+			setupMock: func(mock sqlmock.Sqlmock, token *model.RefreshToken) {
+				mock.ExpectBegin()
+				mock.ExpectExec(revokeQuery).
+					WithArgs(sqlmock.AnyArg(), token.ID, "old-token-id").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			expectedError: customErr.ErrRefreshTokenReused,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := setup()
+			if err != nil {
+				t.Fatalf("Error creating sql mock and db: %s", err)
+			}
+			defer db.Close()
+
+			tc.setupMock(mock, newToken)
+
+			r := repository.NewRefreshTokenRepository(db)
+
+			err = r.RotateRefreshToken(context.Background(), "old-token-id", newToken)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
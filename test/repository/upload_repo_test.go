@@ -0,0 +1,201 @@
+package repository_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/federicodosantos/image-smith/internal/model"
+	"github.com/federicodosantos/image-smith/internal/repository"
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+var uploadColumns = []string{"id", "owner_id", "filename", "upload_length", "upload_offset", "chunk_keys",
+	"expires_at", "created_at", "updated_at"}
+
+func TestCreateUploadSession(t *testing.T) {
+	db, mock, err := setup()
+	if err != nil {
+		t.Fatalf("Error creating sql mock and db: %s", err)
+	}
+	defer db.Close()
+
+	now := time.Now()
+	session := &model.UploadSession{ID: "upload-id", OwnerID: "owner-id", Filename: "scan.tiff", Length: 1 << 20,
+		ChunkKeys: pq.StringArray{}, ExpiresAt: now.Add(time.Hour), CreatedAt: now, UpdatedAt: now}
+
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO upload_sessions`)).
+		WithArgs("upload-id", "owner-id", "scan.tiff", int64(1<<20), int64(0), pq.StringArray{}, now.Add(time.Hour), now, now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	u := repository.NewUploadRepository(db)
+
+	assert.NoError(t, u.CreateSession(context.Background(), session))
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetUploadSession(t *testing.T) {
+	getQuery := regexp.QuoteMeta(`SELECT * FROM upload_sessions WHERE id = $1 AND owner_id = $2 AND expires_at > $3`)
+
+	t.Run("Success", func(t *testing.T) {
+		db, mock, err := setup()
+		if err != nil {
+			t.Fatalf("Error creating sql mock and db: %s", err)
+		}
+		defer db.Close()
+
+		now := time.Now()
+		mock.ExpectQuery(getQuery).
+			WithArgs("upload-id", "owner-id", sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows(uploadColumns).
+				AddRow("upload-id", "owner-id", "scan.tiff", 300, 200, "{owner-id/uploads/upload-id/a,owner-id/uploads/upload-id/b}",
+					now.Add(time.Hour), now, now))
+
+		u := repository.NewUploadRepository(db)
+
+		session, err := u.GetSession(context.Background(), "upload-id", "owner-id")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(300), session.Length)
+		assert.Equal(t, int64(200), session.Offset)
+		assert.Equal(t, pq.StringArray{"owner-id/uploads/upload-id/a", "owner-id/uploads/upload-id/b"}, session.ChunkKeys)
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("Unfulfilled expectations: %s", err)
+		}
+	})
+
+	t.Run("Error - Missing or expired", func(t *testing.T) {
+		db, mock, err := setup()
+		if err != nil {
+			t.Fatalf("Error creating sql mock and db: %s", err)
+		}
+		defer db.Close()
+
+		mock.ExpectQuery(getQuery).WithArgs("upload-id", "owner-id", sqlmock.AnyArg()).WillReturnRows(sqlmock.NewRows(uploadColumns))
+
+		u := repository.NewUploadRepository(db)
+
+		session, err := u.GetSession(context.Background(), "upload-id", "owner-id")
+		assert.ErrorIs(t, err, customErr.ErrUploadNotFound)
+		assert.Nil(t, session)
+	})
+}
+
+func TestAppendUploadChunk(t *testing.T) {
+	appendQuery := regexp.QuoteMeta(`UPDATE upload_sessions SET upload_offset = upload_offset + $1`)
+	expiresAt := time.Now().Add(time.Hour)
+
+	type testCase struct {
+		name         string
+		rowsAffected int64
+		expectError  error
+	}
+
+	testCases := []testCase{
+		{name: "Success", rowsAffected: 1},
+		{name: "Error - Offset moved", rowsAffected: 0, expectError: customErr.ErrUploadOffsetMismatch},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := setup()
+			if err != nil {
+				t.Fatalf("Error creating sql mock and db: %s", err)
+			}
+			defer db.Close()
+
+			session := &model.UploadSession{ID: "upload-id", OwnerID: "owner-id", Length: 300, Offset: 100,
+				ChunkKeys: pq.StringArray{"chunk-0"}}
+
+			mock.ExpectExec(appendQuery).
+				WithArgs(int64(100), "chunk-1", expiresAt, sqlmock.AnyArg(), "upload-id", "owner-id", int64(100)).
+				WillReturnResult(sqlmock.NewResult(0, tc.rowsAffected))
+
+			u := repository.NewUploadRepository(db)
+
+			err = u.AppendChunk(context.Background(), session, 100, "chunk-1", expiresAt)
+			if tc.expectError != nil {
+				assert.ErrorIs(t, err, tc.expectError)
+				assert.Equal(t, int64(100), session.Offset)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, int64(200), session.Offset)
+			assert.Equal(t, pq.StringArray{"chunk-0", "chunk-1"}, session.ChunkKeys)
+			assert.Equal(t, expiresAt, session.ExpiresAt)
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestListExpiredUploadSessions(t *testing.T) {
+	db, mock, err := setup()
+	if err != nil {
+		t.Fatalf("Error creating sql mock and db: %s", err)
+	}
+	defer db.Close()
+
+	now := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM upload_sessions WHERE expires_at <= $1 ORDER BY expires_at LIMIT $2`)).
+		WithArgs(now, 100).
+		WillReturnRows(sqlmock.NewRows(uploadColumns).
+			AddRow("upload-id", "owner-id", "", 300, 0, "{}", now.Add(-time.Minute), now, now))
+
+	u := repository.NewUploadRepository(db)
+
+	sessions, err := u.ListExpiredSessions(context.Background(), now, 100)
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 1)
+	assert.Empty(t, sessions[0].ChunkKeys)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %s", err)
+	}
+}
+
+func TestDeleteUploadSession(t *testing.T) {
+	deleteQuery := regexp.QuoteMeta(`DELETE FROM upload_sessions WHERE id = $1 AND owner_id = $2`)
+
+	type testCase struct {
+		name         string
+		rowsAffected int64
+		expectError  error
+	}
+
+	testCases := []testCase{
+		{name: "Success", rowsAffected: 1},
+		{name: "Error - Not found", rowsAffected: 0, expectError: customErr.ErrUploadNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := setup()
+			if err != nil {
+				t.Fatalf("Error creating sql mock and db: %s", err)
+			}
+			defer db.Close()
+
+			mock.ExpectExec(deleteQuery).WithArgs("upload-id", "owner-id").WillReturnResult(sqlmock.NewResult(0, tc.rowsAffected))
+
+			u := repository.NewUploadRepository(db)
+
+			err = u.DeleteSession(context.Background(), "upload-id", "owner-id")
+			if tc.expectError != nil {
+				assert.ErrorIs(t, err, tc.expectError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
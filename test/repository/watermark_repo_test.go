@@ -0,0 +1,87 @@
+package repository_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/federicodosantos/image-smith/internal/model"
+	"github.com/federicodosantos/image-smith/internal/repository"
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	"github.com/stretchr/testify/assert"
+)
+
+var watermarkColumns = []string{"owner_id", "image_id", "text", "color", "font_size", "gravity", "margin", "opacity",
+	"scale", "tile", "created_at", "updated_at"}
+
+func TestGetWatermarkSettings(t *testing.T) {
+	getQuery := regexp.QuoteMeta(`SELECT * FROM watermark_settings WHERE owner_id = $1`)
+
+	t.Run("Success - Saved settings", func(t *testing.T) {
+		db, mock, err := setup()
+		if err != nil {
+			t.Fatalf("Error creating sql mock and db: %s", err)
+		}
+		defer db.Close()
+
+		now := time.Now()
+		mock.ExpectQuery(getQuery).
+			WithArgs("owner-id").
+			WillReturnRows(sqlmock.NewRows(watermarkColumns).
+				AddRow("owner-id", nil, "ImageSmith", "#fff", 32.0, "southeast", 8, 0.5, 0.2, true, now, now))
+
+		w := repository.NewWatermarkRepository(db)
+
+		settings, err := w.GetSettings(context.Background(), "owner-id")
+		assert.NoError(t, err)
+		assert.Equal(t, "ImageSmith", settings.Text)
+		assert.Nil(t, settings.ImageID)
+		assert.True(t, settings.Tile)
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("Unfulfilled expectations: %s", err)
+		}
+	})
+
+	t.Run("Error - No settings", func(t *testing.T) {
+		db, mock, err := setup()
+		if err != nil {
+			t.Fatalf("Error creating sql mock and db: %s", err)
+		}
+		defer db.Close()
+
+		mock.ExpectQuery(getQuery).WithArgs("owner-id").WillReturnRows(sqlmock.NewRows(watermarkColumns))
+
+		w := repository.NewWatermarkRepository(db)
+
+		settings, err := w.GetSettings(context.Background(), "owner-id")
+		assert.ErrorIs(t, err, customErr.ErrWatermarkNotFound)
+		assert.Nil(t, settings)
+	})
+}
+
+func TestSaveWatermarkSettings(t *testing.T) {
+	db, mock, err := setup()
+	if err != nil {
+		t.Fatalf("Error creating sql mock and db: %s", err)
+	}
+	defer db.Close()
+
+	now := time.Now()
+	imageID := "mark-id"
+	settings := &model.WatermarkSettings{OwnerID: "owner-id", ImageID: &imageID, Opacity: 0.5, CreatedAt: now, UpdatedAt: now}
+
+	mock.ExpectExec(`INSERT INTO watermark_settings.*ON CONFLICT \(owner_id\) DO UPDATE`).
+		WithArgs("owner-id", &imageID, "", "", 0.0, "", 0, 0.5, 0.0, false, now, now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	w := repository.NewWatermarkRepository(db)
+
+	assert.NoError(t, w.SaveSettings(context.Background(), settings))
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %s", err)
+	}
+}
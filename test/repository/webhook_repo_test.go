@@ -0,0 +1,157 @@
+package repository_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/federicodosantos/image-smith/internal/model"
+	"github.com/federicodosantos/image-smith/internal/repository"
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	"github.com/stretchr/testify/assert"
+)
+
+var webhookColumns = []string{"id", "owner_id", "url", "secret", "events", "created_at", "updated_at"}
+
+var deliveryColumns = []string{"id", "webhook_id", "owner_id", "event", "payload", "status", "attempts", "max_attempts",
+	"next_attempt_at", "locked_until", "response_status", "last_error", "delivered_at", "created_at", "updated_at"}
+
+func TestListWebhooksForEvent(t *testing.T) {
+	db, mock, err := setup()
+	if err != nil {
+		t.Fatalf("Error creating sql mock and db: %s", err)
+	}
+	defer db.Close()
+
+	now := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM webhooks WHERE owner_id = $1 AND $2 = ANY(events)`)).
+		WithArgs("owner-id", model.EventImageUploaded).
+		WillReturnRows(sqlmock.NewRows(webhookColumns).
+			AddRow("webhook-id", "owner-id", "https://example.com/hooks", "whsec_secret",
+				"{image.uploaded,image.deleted}", now, now))
+
+	w := repository.NewWebhookRepository(db)
+
+	webhooks, err := w.ListWebhooksForEvent(context.Background(), "owner-id", model.EventImageUploaded)
+	assert.NoError(t, err)
+	assert.Len(t, webhooks, 1)
+	assert.Equal(t, []string{model.EventImageUploaded, model.EventImageDeleted}, []string(webhooks[0].Events))
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %s", err)
+	}
+}
+
+func TestClaimDelivery(t *testing.T) {
+	// a delivery whose lease expired is only claimed again with attempts left
+	claimQuery := `UPDATE webhook_deliveries SET status = 'sending'.*locked_until < \$2 AND attempts < max_attempts.*FOR UPDATE SKIP LOCKED.*RETURNING \*`
+
+	t.Run("Success - Claims due delivery", func(t *testing.T) {
+		db, mock, err := setup()
+		if err != nil {
+			t.Fatalf("Error creating sql mock and db: %s", err)
+		}
+		defer db.Close()
+
+		now := time.Now()
+		mock.ExpectQuery(claimQuery).
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows(deliveryColumns).
+				AddRow("delivery-id", "webhook-id", "owner-id", model.EventImageUploaded, []byte(`{}`), model.DeliveryStatusSending,
+					1, 8, now, now.Add(time.Minute), nil, nil, nil, now, now))
+
+		w := repository.NewWebhookRepository(db)
+
+		delivery, err := w.ClaimDelivery(context.Background(), time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, "delivery-id", delivery.ID)
+		assert.Equal(t, 1, delivery.Attempts)
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("Unfulfilled expectations: %s", err)
+		}
+	})
+
+	t.Run("Success - No delivery due", func(t *testing.T) {
+		db, mock, err := setup()
+		if err != nil {
+			t.Fatalf("Error creating sql mock and db: %s", err)
+		}
+		defer db.Close()
+
+		mock.ExpectQuery(claimQuery).WillReturnRows(sqlmock.NewRows(deliveryColumns))
+
+		w := repository.NewWebhookRepository(db)
+
+		delivery, err := w.ClaimDelivery(context.Background(), time.Minute)
+		assert.NoError(t, err)
+		assert.Nil(t, delivery)
+	})
+}
+
+func TestFailAbandonedDeliveries(t *testing.T) {
+	db, mock, err := setup()
+	if err != nil {
+		t.Fatalf("Error creating sql mock and db: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE webhook_deliveries SET status = 'failed'`)+`.*attempts >= max_attempts`).
+		WithArgs("worker stopped", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	w := repository.NewWebhookRepository(db)
+
+	assert.NoError(t, w.FailAbandonedDeliveries(context.Background(), "worker stopped"))
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %s", err)
+	}
+}
+
+func TestUpdateDelivery(t *testing.T) {
+	db, mock, err := setup()
+	if err != nil {
+		t.Fatalf("Error creating sql mock and db: %s", err)
+	}
+	defer db.Close()
+
+	delivery := &model.WebhookDelivery{ID: "delivery-id", Status: model.DeliveryStatusSucceeded, Attempts: 2}
+	updateQuery := regexp.QuoteMeta(`UPDATE webhook_deliveries SET status = $1`) + `.*WHERE id = \$7 AND status = 'sending' AND attempts = \$8`
+	mock.ExpectExec(updateQuery).
+		WithArgs(model.DeliveryStatusSucceeded, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "delivery-id", 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// the lease expired and another dispatcher claimed a third attempt
+	mock.ExpectExec(updateQuery).
+		WithArgs(model.DeliveryStatusSucceeded, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "delivery-id", 2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	w := repository.NewWebhookRepository(db)
+
+	assert.NoError(t, w.UpdateDelivery(context.Background(), delivery))
+	assert.ErrorIs(t, w.UpdateDelivery(context.Background(), delivery), customErr.ErrDeliveryLeaseLost)
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetDeliveryById(t *testing.T) {
+	db, mock, err := setup()
+	if err != nil {
+		t.Fatalf("Error creating sql mock and db: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM webhook_deliveries WHERE id = $1 AND webhook_id = $2 AND owner_id = $3`)).
+		WithArgs("delivery-id", "webhook-id", "intruder-id").
+		WillReturnRows(sqlmock.NewRows(deliveryColumns))
+
+	w := repository.NewWebhookRepository(db)
+
+	delivery, err := w.GetDeliveryById(context.Background(), "delivery-id", "webhook-id", "intruder-id")
+	assert.ErrorIs(t, err, customErr.ErrWebhookDeliveryNotFound)
+	assert.Nil(t, delivery)
+}
@@ -0,0 +1,140 @@
+package storage_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	"github.com/federicodosantos/image-smith/pkg/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+var CTX = context.Background()
+
+func TestLocalStorage(t *testing.T) {
+	local, err := storage.NewLocalStorage(t.TempDir(), "http://localhost/files", "signing-key")
+	assert.NoError(t, err)
+
+	assert.NoError(t, local.Put(CTX, "user-a/one.png", strings.NewReader("one"), "image/png"))
+	assert.NoError(t, local.Put(CTX, "user-a/two.jpg", strings.NewReader("second"), "image/jpeg"))
+	assert.NoError(t, local.Put(CTX, "user-b/three.png", strings.NewReader("three"), "image/png"))
+
+	object, err := local.Get(CTX, "user-a/one.png")
+	assert.NoError(t, err)
+	content, _ := io.ReadAll(object)
+	object.Close()
+	assert.Equal(t, "one", string(content))
+
+	info, err := local.Stat(CTX, "user-a/two.jpg")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(6), info.Size)
+	assert.Equal(t, "image/jpeg", info.ContentType)
+
+	objects, err := local.List(CTX, "user-a/")
+	assert.NoError(t, err)
+	assert.Len(t, objects, 2)
+	assert.Equal(t, "user-a/one.png", objects[0].Key)
+
+	assert.NoError(t, local.Delete(CTX, "user-a/one.png"))
+	assert.NoError(t, local.Delete(CTX, "user-a/one.png"))
+
+	_, err = local.Get(CTX, "user-a/one.png")
+	assert.ErrorIs(t, err, customErr.ErrObjectNotFound)
+
+	_, err = local.Stat(CTX, "../outside")
+	assert.ErrorIs(t, err, customErr.ErrObjectNotFound)
+}
+
+func TestLocalStorageCompose(t *testing.T) {
+	local, err := storage.NewLocalStorage(t.TempDir(), "http://localhost/files", "signing-key")
+	assert.NoError(t, err)
+
+	assert.NoError(t, local.Put(CTX, "chunks/a", strings.NewReader("first "), "application/octet-stream"))
+	assert.NoError(t, local.Put(CTX, "chunks/b", strings.NewReader("second"), "application/octet-stream"))
+
+	assert.NoError(t, local.Compose(CTX, "user-a/joined", []string{"chunks/a", "chunks/b"}, "image/tiff"))
+
+	object, err := local.Get(CTX, "user-a/joined")
+	assert.NoError(t, err)
+	content, _ := io.ReadAll(object)
+	object.Close()
+	assert.Equal(t, "first second", string(content))
+
+	err = local.Compose(CTX, "user-a/missing", []string{"chunks/a", "chunks/none"}, "image/tiff")
+	assert.ErrorIs(t, err, customErr.ErrObjectNotFound)
+
+	_, err = local.Stat(CTX, "user-a/missing")
+	assert.ErrorIs(t, err, customErr.ErrObjectNotFound)
+}
+
+func TestLocalStorageSignedURL(t *testing.T) {
+	local, err := storage.NewLocalStorage(t.TempDir(), "http://localhost/files", "signing-key")
+	assert.NoError(t, err)
+	assert.NoError(t, local.Put(CTX, "user-a/one.png", strings.NewReader("one"), "image/png"))
+
+	signed, err := local.SignedURL(CTX, "user-a/one.png", time.Minute)
+	assert.NoError(t, err)
+
+	serve := func(rawURL string) int {
+		u, _ := url.Parse(rawURL)
+		r := httptest.NewRequest(http.MethodGet, strings.TrimPrefix(u.RequestURI(), "/files"), nil)
+		rec := httptest.NewRecorder()
+		local.ServeHTTP(rec, r)
+		return rec.Code
+	}
+
+	missing, err := local.SignedURL(CTX, "user-a/missing.png", time.Minute)
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, serve(signed))
+	assert.Equal(t, http.StatusForbidden, serve(strings.Replace(signed, "signature=", "signature=00", 1)))
+	// objects are never served without a signature
+	assert.Equal(t, http.StatusForbidden, serve("http://localhost/files/user-a/one.png"))
+	assert.Equal(t, http.StatusNotFound, serve(missing))
+
+	_, err = storage.NewLocalStorage(t.TempDir(), "http://localhost/files", "")
+	assert.Error(t, err)
+}
+
+func TestLocalStorageSignedUploadURL(t *testing.T) {
+	local, err := storage.NewLocalStorage(t.TempDir(), "http://localhost/files", "signing-key")
+	assert.NoError(t, err)
+
+	upload, err := local.SignedUploadURL(CTX, "user-a/new.png", 3, time.Minute)
+	assert.NoError(t, err)
+	read, err := local.SignedURL(CTX, "user-a/new.png", time.Minute)
+	assert.NoError(t, err)
+
+	put := func(rawURL string, body string, contentLength int64) int {
+		u, _ := url.Parse(rawURL)
+		r := httptest.NewRequest(http.MethodPut, strings.TrimPrefix(u.RequestURI(), "/files"), strings.NewReader(body))
+		r.Header.Set("Content-Type", "image/png")
+		r.ContentLength = contentLength
+		rec := httptest.NewRecorder()
+		local.ServeHTTP(rec, r)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusForbidden, put("http://localhost/files/user-a/new.png", "new", 3))
+	// a read signature does not allow writing
+	assert.Equal(t, http.StatusForbidden, put(read, "new", 3))
+	// nor does changing the signed size
+	assert.Equal(t, http.StatusForbidden, put(strings.Replace(upload, "size=3", "size=9", 1), "new", 3))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, put(upload, "larger", 6))
+	// a body without a declared length is cut at the signed size
+	assert.Equal(t, http.StatusRequestEntityTooLarge, put(upload, "larger", -1))
+	_, err = local.Stat(CTX, "user-a/new.png")
+	assert.ErrorIs(t, err, customErr.ErrObjectNotFound)
+
+	assert.Equal(t, http.StatusOK, put(upload, "new", 3))
+
+	info, err := local.Stat(CTX, "user-a/new.png")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), info.Size)
+}
@@ -0,0 +1,309 @@
+package storage_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	"github.com/federicodosantos/image-smith/pkg/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeObject struct {
+	body        []byte
+	contentType string
+}
+
+type fakeUpload struct {
+	key         string
+	contentType string
+	parts       map[int][]byte
+}
+
+// fakeS3 is a minimal in-memory implementation of the S3 object API.
+type fakeS3 struct {
+	mu      sync.Mutex
+	bucket  string
+	objects map[string]fakeObject
+	uploads map[string]*fakeUpload
+	// failPart makes uploads of that part number fail
+	failPart int
+	// copies counts the parts copied from other objects
+	copies int
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=access-key/") &&
+		r.URL.Query().Get("X-Amz-Signature") == "" {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	key := strings.TrimPrefix(r.URL.Path, "/"+f.bucket)
+	key = strings.TrimPrefix(key, "/")
+
+	query := r.URL.Query()
+
+	switch {
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		id := strconv.Itoa(len(f.uploads) + 1)
+		f.uploads[id] = &fakeUpload{key: key, contentType: r.Header.Get("Content-Type"), parts: map[int][]byte{}}
+		fmt.Fprintf(w, "<InitiateMultipartUploadResult><UploadId>%s</UploadId></InitiateMultipartUploadResult>", id)
+	case r.Method == http.MethodPut && query.Has("uploadId"):
+		upload, ok := f.uploads[query.Get("uploadId")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		number, _ := strconv.Atoi(query.Get("partNumber"))
+		if number == f.failPart {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if source := r.Header.Get("X-Amz-Copy-Source"); source != "" {
+			object, ok := f.objects[strings.TrimPrefix(source, "/"+f.bucket+"/")]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			upload.parts[number] = byteRange(object.body, r.Header.Get("X-Amz-Copy-Source-Range"))
+			f.copies++
+			fmt.Fprintf(w, `<CopyPartResult><ETag>"etag-%d"</ETag></CopyPartResult>`, number)
+			return
+		}
+		upload.parts[number], _ = io.ReadAll(r.Body)
+		w.Header().Set("ETag", fmt.Sprintf(`"etag-%d"`, number))
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		upload, ok := f.uploads[query.Get("uploadId")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var complete struct {
+			Parts []struct {
+				PartNumber int    `xml:"PartNumber"`
+				ETag       string `xml:"ETag"`
+			} `xml:"Part"`
+		}
+		xml.NewDecoder(r.Body).Decode(&complete)
+		var body bytes.Buffer
+		for i, part := range complete.Parts {
+			if part.ETag != fmt.Sprintf(`"etag-%d"`, part.PartNumber) {
+				fmt.Fprint(w, "<Error><Code>InvalidPart</Code></Error>")
+				return
+			}
+			if i < len(complete.Parts)-1 && len(upload.parts[part.PartNumber]) < 5<<20 {
+				fmt.Fprint(w, "<Error><Code>EntityTooSmall</Code></Error>")
+				return
+			}
+			body.Write(upload.parts[part.PartNumber])
+		}
+		f.objects[upload.key] = fakeObject{body: body.Bytes(), contentType: upload.contentType}
+		delete(f.uploads, query.Get("uploadId"))
+		fmt.Fprint(w, "<CompleteMultipartUploadResult></CompleteMultipartUploadResult>")
+	case r.Method == http.MethodDelete && query.Has("uploadId"):
+		delete(f.uploads, query.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		f.objects[key] = fakeObject{body: body, contentType: r.Header.Get("Content-Type")}
+	case r.Method == http.MethodGet && key == "":
+		type content struct {
+			Key  string `xml:"Key"`
+			Size int64  `xml:"Size"`
+		}
+		var result struct {
+			XMLName  xml.Name  `xml:"ListBucketResult"`
+			Contents []content `xml:"Contents"`
+		}
+		for k, object := range f.objects {
+			if strings.HasPrefix(k, r.URL.Query().Get("prefix")) {
+				result.Contents = append(result.Contents, content{Key: k, Size: int64(len(object.body))})
+			}
+		}
+		sort.Slice(result.Contents, func(i, j int) bool { return result.Contents[i].Key < result.Contents[j].Key })
+		xml.NewEncoder(w).Encode(result)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		object, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		body := byteRange(object.body, r.Header.Get("Range"))
+		w.Header().Set("Content-Type", object.contentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		w.Write(body)
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// byteRange returns the part of body selected by a "bytes=first-last" header,
+// or all of it when there is none.
+func byteRange(body []byte, header string) []byte {
+	var first, last int
+	if _, err := fmt.Sscanf(header, "bytes=%d-%d", &first, &last); err != nil {
+		return body
+	}
+	return body[first : last+1]
+}
+
+func TestS3Storage(t *testing.T) {
+	fake := &fakeS3{bucket: "images", objects: map[string]fakeObject{}, uploads: map[string]*fakeUpload{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	s3, err := storage.NewS3Storage(storage.S3Config{
+		Endpoint:        server.URL,
+		Bucket:          "images",
+		AccessKeyID:     "access-key",
+		SecretAccessKey: "secret-key",
+	}, server.Client())
+	assert.NoError(t, err)
+
+	assert.NoError(t, s3.Put(CTX, "user-a/one.png", strings.NewReader("one"), "image/png"))
+	assert.NoError(t, s3.Put(CTX, "user-a/two.png", strings.NewReader("second"), "image/png"))
+	assert.NoError(t, s3.Put(CTX, "user-b/three.png", strings.NewReader("three"), "image/png"))
+
+	object, err := s3.Get(CTX, "user-a/one.png")
+	assert.NoError(t, err)
+	content, _ := io.ReadAll(object)
+	object.Close()
+	assert.Equal(t, "one", string(content))
+
+	info, err := s3.Stat(CTX, "user-a/two.png")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(6), info.Size)
+	assert.Equal(t, "image/png", info.ContentType)
+
+	objects, err := s3.List(CTX, "user-a/")
+	assert.NoError(t, err)
+	assert.Len(t, objects, 2)
+
+	assert.NoError(t, s3.Delete(CTX, "user-a/one.png"))
+	_, err = s3.Get(CTX, "user-a/one.png")
+	assert.ErrorIs(t, err, customErr.ErrObjectNotFound)
+
+	_, err = s3.Stat(CTX, "user-a/one.png")
+	assert.ErrorIs(t, err, customErr.ErrObjectNotFound)
+
+	assert.Equal(t, server.URL+"/images/user-a/two.png", s3.URL("user-a/two.png"))
+
+	signed, err := s3.SignedURL(CTX, "user-a/two.png", time.Minute)
+	assert.NoError(t, err)
+	assert.Contains(t, signed, "X-Amz-Signature=")
+
+	res, err := server.Client().Get(signed)
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	assert.Equal(t, "second", string(body))
+
+	upload, err := s3.SignedUploadURL(CTX, "user-a/three.png", 5, time.Minute)
+	assert.NoError(t, err)
+	assert.Contains(t, upload, "X-Amz-Signature=")
+	// S3 refuses a body of another length
+	assert.Contains(t, upload, "X-Amz-SignedHeaders=content-length%3Bhost")
+
+	req, _ := http.NewRequest(http.MethodPut, upload, strings.NewReader("third"))
+	res, err = server.Client().Do(req)
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, "third", string(fake.objects["user-a/three.png"].body))
+}
+
+func TestS3StorageLargeObject(t *testing.T) {
+	fake := &fakeS3{bucket: "images", objects: map[string]fakeObject{}, uploads: map[string]*fakeUpload{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	s3, err := storage.NewS3Storage(storage.S3Config{
+		Endpoint:        server.URL,
+		Bucket:          "images",
+		AccessKeyID:     "access-key",
+		SecretAccessKey: "secret-key",
+	}, server.Client())
+	assert.NoError(t, err)
+
+	// larger than two parts
+	content := bytes.Repeat([]byte("0123456789abcdef"), 17<<16)
+
+	assert.NoError(t, s3.Put(CTX, "user-a/large.tiff", bytes.NewReader(content), "image/tiff"))
+	assert.True(t, bytes.Equal(content, fake.objects["user-a/large.tiff"].body), "the stored object differs")
+	assert.Equal(t, "image/tiff", fake.objects["user-a/large.tiff"].contentType)
+	assert.Empty(t, fake.uploads)
+
+	// a failed part aborts the upload
+	fake.failPart = 2
+	err = s3.Put(CTX, "user-a/failed.tiff", bytes.NewReader(content), "image/tiff")
+	assert.Error(t, err)
+	assert.NotContains(t, fake.objects, "user-a/failed.tiff")
+	assert.Empty(t, fake.uploads)
+}
+
+func TestS3StorageCompose(t *testing.T) {
+	fake := &fakeS3{bucket: "images", objects: map[string]fakeObject{}, uploads: map[string]*fakeUpload{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	s3, err := storage.NewS3Storage(storage.S3Config{
+		Endpoint:        server.URL,
+		Bucket:          "images",
+		AccessKeyID:     "access-key",
+		SecretAccessKey: "secret-key",
+	}, server.Client())
+	assert.NoError(t, err)
+
+	put := func(key string, size int, fill byte) []byte {
+		content := bytes.Repeat([]byte{fill}, size)
+		fake.objects[key] = fakeObject{body: content}
+		return content
+	}
+
+	t.Run("Small sources", func(t *testing.T) {
+		expected := append(put("chunks/a", 3, 'a'), put("chunks/b", 4, 'b')...)
+
+		assert.NoError(t, s3.Compose(CTX, "small.tiff", []string{"chunks/a", "chunks/b"}, "image/tiff"))
+		assert.Equal(t, expected, fake.objects["small.tiff"].body)
+		assert.Equal(t, "image/tiff", fake.objects["small.tiff"].contentType)
+	})
+
+	t.Run("Large sources", func(t *testing.T) {
+		// small sources are buffered until they fill a part, large ones copied
+		sizes := []int{6 << 20, 5<<20 + 1, 3, 12 << 20, 1 << 20, 1}
+		var sources []string
+		var expected []byte
+		for i, size := range sizes {
+			key := fmt.Sprintf("chunks/%d", i)
+			sources = append(sources, key)
+			expected = append(expected, put(key, size, byte('a'+i))...)
+		}
+
+		assert.NoError(t, s3.Compose(CTX, "large.tiff", sources, "image/tiff"))
+		assert.True(t, bytes.Equal(expected, fake.objects["large.tiff"].body), "the joined object differs")
+		assert.Equal(t, "image/tiff", fake.objects["large.tiff"].contentType)
+		assert.Empty(t, fake.uploads)
+		// the first two and the rest of the fourth after topping up a part
+		assert.Equal(t, 3, fake.copies)
+	})
+
+	t.Run("Missing source", func(t *testing.T) {
+		err := s3.Compose(CTX, "missing.tiff", []string{"chunks/a", "chunks/none"}, "image/tiff")
+		assert.ErrorIs(t, err, customErr.ErrObjectNotFound)
+		assert.NotContains(t, fake.objects, "missing.tiff")
+	})
+}
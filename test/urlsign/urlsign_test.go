@@ -0,0 +1,101 @@
+package urlsign_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	"github.com/federicodosantos/image-smith/pkg/urlsign"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	signer, err := urlsign.NewSigner("signing-key")
+	assert.NoError(t, err)
+
+	now := time.Unix(1_700_000_000, 0)
+	path := "/public/images/image-id"
+	signed := signer.Sign(path, url.Values{"w": {"200"}, "u": {"owner-id"}}, now.Add(time.Hour))
+
+	type testCase struct {
+		name        string
+		path        string
+		query       func() url.Values
+		now         time.Time
+		expectError error
+	}
+
+	testCases := []testCase{
+		{
+			name:  "Success - Untouched url",
+			path:  path,
+			query: func() url.Values { return signed },
+			now:   now,
+		},
+		{
+			name: "Failed - Changed parameter",
+			path: path,
+			query: func() url.Values {
+				tampered, _ := url.ParseQuery(signed.Encode())
+				tampered.Set("w", "4000")
+				return tampered
+			},
+			now:         now,
+			expectError: customErr.ErrInvalidSignature,
+		},
+		{
+			name: "Failed - Added parameter",
+			path: path,
+			query: func() url.Values {
+				tampered, _ := url.ParseQuery(signed.Encode())
+				tampered.Set("fmt", "png")
+				return tampered
+			},
+			now:         now,
+			expectError: customErr.ErrInvalidSignature,
+		},
+		{
+			name:        "Failed - Other image",
+			path:        "/public/images/other-id",
+			query:       func() url.Values { return signed },
+			now:         now,
+			expectError: customErr.ErrInvalidSignature,
+		},
+		{
+			name:        "Failed - Expired",
+			path:        path,
+			query:       func() url.Values { return signed },
+			now:         now.Add(time.Hour),
+			expectError: customErr.ErrSignatureExpired,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			expiresAt, err := signer.Verify(tc.path, tc.query(), tc.now)
+
+			if tc.expectError != nil {
+				assert.ErrorIs(t, err, tc.expectError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, now.Add(time.Hour), expiresAt)
+			}
+		})
+	}
+}
+
+func TestRotatedKeyRevokes(t *testing.T) {
+	oldSigner, _ := urlsign.NewSigner("old-key")
+	newSigner, _ := urlsign.NewSigner("new-key")
+
+	signed := oldSigner.Sign("/public/images/image-id", url.Values{}, time.Now().Add(time.Hour))
+
+	_, err := newSigner.Verify("/public/images/image-id", signed, time.Now())
+	assert.ErrorIs(t, err, customErr.ErrInvalidSignature)
+}
+
+func TestEmptyKey(t *testing.T) {
+	_, err := urlsign.NewSigner("")
+	assert.Error(t, err)
+}
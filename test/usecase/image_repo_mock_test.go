@@ -0,0 +1,234 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/image_repo.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/repository/image_repo.go -destination=test/usecase/image_repo_mock_test.go -package=usecase_test
+//
+
+// Package usecase_test is a generated GoMock package.
+package usecase_test
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	model "github.com/federicodosantos/image-smith/internal/model"
+	repository "github.com/federicodosantos/image-smith/internal/repository"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIImageRepository is a mock of IImageRepository interface.
+type MockIImageRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIImageRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockIImageRepositoryMockRecorder is the mock recorder for MockIImageRepository.
+type MockIImageRepositoryMockRecorder struct {
+	mock *MockIImageRepository
+}
+
+// NewMockIImageRepository creates a new mock instance.
+func NewMockIImageRepository(ctrl *gomock.Controller) *MockIImageRepository {
+	mock := &MockIImageRepository{ctrl: ctrl}
+	mock.recorder = &MockIImageRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIImageRepository) EXPECT() *MockIImageRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CompleteImage mocks base method.
+func (m *MockIImageRepository) CompleteImage(ctx context.Context, image *model.Image) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteImage", ctx, image)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteImage indicates an expected call of CompleteImage.
+func (mr *MockIImageRepositoryMockRecorder) CompleteImage(ctx, image any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteImage", reflect.TypeOf((*MockIImageRepository)(nil).CompleteImage), ctx, image)
+}
+
+// CreateImage mocks base method.
+func (m *MockIImageRepository) CreateImage(ctx context.Context, image *model.Image) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateImage", ctx, image)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateImage indicates an expected call of CreateImage.
+func (mr *MockIImageRepositoryMockRecorder) CreateImage(ctx, image any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateImage", reflect.TypeOf((*MockIImageRepository)(nil).CreateImage), ctx, image)
+}
+
+// CreatePendingImage mocks base method.
+func (m *MockIImageRepository) CreatePendingImage(ctx context.Context, image *model.Image) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePendingImage", ctx, image)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePendingImage indicates an expected call of CreatePendingImage.
+func (mr *MockIImageRepositoryMockRecorder) CreatePendingImage(ctx, image any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePendingImage", reflect.TypeOf((*MockIImageRepository)(nil).CreatePendingImage), ctx, image)
+}
+
+// DeleteImage mocks base method.
+func (m *MockIImageRepository) DeleteImage(ctx context.Context, id, ownerID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteImage", ctx, id, ownerID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteImage indicates an expected call of DeleteImage.
+func (mr *MockIImageRepositoryMockRecorder) DeleteImage(ctx, id, ownerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteImage", reflect.TypeOf((*MockIImageRepository)(nil).DeleteImage), ctx, id, ownerID)
+}
+
+// DeletePendingImage mocks base method.
+func (m *MockIImageRepository) DeletePendingImage(ctx context.Context, id, ownerID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePendingImage", ctx, id, ownerID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePendingImage indicates an expected call of DeletePendingImage.
+func (mr *MockIImageRepositoryMockRecorder) DeletePendingImage(ctx, id, ownerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePendingImage", reflect.TypeOf((*MockIImageRepository)(nil).DeletePendingImage), ctx, id, ownerID)
+}
+
+// FindSimilarImages mocks base method.
+func (m *MockIImageRepository) FindSimilarImages(ctx context.Context, ownerID, imageID string, hash int64, maxDistance, limit int) ([]repository.SimilarImage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindSimilarImages", ctx, ownerID, imageID, hash, maxDistance, limit)
+	ret0, _ := ret[0].([]repository.SimilarImage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindSimilarImages indicates an expected call of FindSimilarImages.
+func (mr *MockIImageRepositoryMockRecorder) FindSimilarImages(ctx, ownerID, imageID, hash, maxDistance, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSimilarImages", reflect.TypeOf((*MockIImageRepository)(nil).FindSimilarImages), ctx, ownerID, imageID, hash, maxDistance, limit)
+}
+
+// GetImageByChecksum mocks base method.
+func (m *MockIImageRepository) GetImageByChecksum(ctx context.Context, ownerID, checksum string) (*model.Image, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetImageByChecksum", ctx, ownerID, checksum)
+	ret0, _ := ret[0].(*model.Image)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetImageByChecksum indicates an expected call of GetImageByChecksum.
+func (mr *MockIImageRepositoryMockRecorder) GetImageByChecksum(ctx, ownerID, checksum any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetImageByChecksum", reflect.TypeOf((*MockIImageRepository)(nil).GetImageByChecksum), ctx, ownerID, checksum)
+}
+
+// GetImageById mocks base method.
+func (m *MockIImageRepository) GetImageById(ctx context.Context, id, ownerID string) (*model.Image, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetImageById", ctx, id, ownerID)
+	ret0, _ := ret[0].(*model.Image)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetImageById indicates an expected call of GetImageById.
+func (mr *MockIImageRepositoryMockRecorder) GetImageById(ctx, id, ownerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetImageById", reflect.TypeOf((*MockIImageRepository)(nil).GetImageById), ctx, id, ownerID)
+}
+
+// GetImageOwner mocks base method.
+func (m *MockIImageRepository) GetImageOwner(ctx context.Context, id string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetImageOwner", ctx, id)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetImageOwner indicates an expected call of GetImageOwner.
+func (mr *MockIImageRepositoryMockRecorder) GetImageOwner(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetImageOwner", reflect.TypeOf((*MockIImageRepository)(nil).GetImageOwner), ctx, id)
+}
+
+// GetPendingImage mocks base method.
+func (m *MockIImageRepository) GetPendingImage(ctx context.Context, id, ownerID string) (*model.Image, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingImage", ctx, id, ownerID)
+	ret0, _ := ret[0].(*model.Image)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPendingImage indicates an expected call of GetPendingImage.
+func (mr *MockIImageRepositoryMockRecorder) GetPendingImage(ctx, id, ownerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingImage", reflect.TypeOf((*MockIImageRepository)(nil).GetPendingImage), ctx, id, ownerID)
+}
+
+// ListExpiredPendingImages mocks base method.
+func (m *MockIImageRepository) ListExpiredPendingImages(ctx context.Context, now time.Time, limit int) ([]model.Image, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListExpiredPendingImages", ctx, now, limit)
+	ret0, _ := ret[0].([]model.Image)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListExpiredPendingImages indicates an expected call of ListExpiredPendingImages.
+func (mr *MockIImageRepositoryMockRecorder) ListExpiredPendingImages(ctx, now, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExpiredPendingImages", reflect.TypeOf((*MockIImageRepository)(nil).ListExpiredPendingImages), ctx, now, limit)
+}
+
+// ListImages mocks base method.
+func (m *MockIImageRepository) ListImages(ctx context.Context, filter repository.ImageFilter) (*repository.ImagePage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListImages", ctx, filter)
+	ret0, _ := ret[0].(*repository.ImagePage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListImages indicates an expected call of ListImages.
+func (mr *MockIImageRepositoryMockRecorder) ListImages(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListImages", reflect.TypeOf((*MockIImageRepository)(nil).ListImages), ctx, filter)
+}
+
+// UpdateFocalPoint mocks base method.
+func (m *MockIImageRepository) UpdateFocalPoint(ctx context.Context, id, ownerID string, x, y *float64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateFocalPoint", ctx, id, ownerID, x, y)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateFocalPoint indicates an expected call of UpdateFocalPoint.
+func (mr *MockIImageRepositoryMockRecorder) UpdateFocalPoint(ctx, id, ownerID, x, y any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFocalPoint", reflect.TypeOf((*MockIImageRepository)(nil).UpdateFocalPoint), ctx, id, ownerID, x, y)
+}
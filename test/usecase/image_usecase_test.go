@@ -0,0 +1,1206 @@
+package usecase_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/federicodosantos/image-smith/internal/dto"
+	"github.com/federicodosantos/image-smith/internal/model"
+	"github.com/federicodosantos/image-smith/internal/repository"
+	"github.com/federicodosantos/image-smith/internal/usecase"
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	"github.com/federicodosantos/image-smith/pkg/imaging"
+	"github.com/federicodosantos/image-smith/pkg/storage"
+	"github.com/federicodosantos/image-smith/pkg/validator"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func pngBytes() []byte {
+	var buf bytes.Buffer
+	png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 4)))
+	return buf.Bytes()
+}
+
+func tiffBytes() []byte {
+	var buf bytes.Buffer
+	imaging.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 4)), imaging.FormatTIFF, imaging.EncodeOptions{})
+	return buf.Bytes()
+}
+
+// photoBytes encodes a 4x2 JPEG whose EXIF block names the camera and says it
+// must be rotated by a quarter turn.
+func photoBytes() []byte {
+	return photoByCamera("Canon")
+}
+
+// photoByCamera is photoBytes with the camera make given.
+func photoByCamera(cameraMake string) []byte {
+	var buf bytes.Buffer
+	jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 2)), nil)
+	data := buf.Bytes()
+
+	count := binary.LittleEndian.AppendUint32(nil, uint32(len(cameraMake)+1))
+	exif := "II\x2a\x00\x08\x00\x00\x00" + "\x02\x00" +
+		"\x0f\x01\x02\x00" + string(count) + "\x26\x00\x00\x00" +
+		"\x12\x01\x03\x00\x01\x00\x00\x00\x06\x00\x00\x00" +
+		"\x00\x00\x00\x00" + cameraMake + "\x00"
+	payload := "Exif\x00\x00" + exif
+	segment := append([]byte{0xff, 0xe1, byte((len(payload) + 2) >> 8), byte(len(payload) + 2)}, payload...)
+
+	return append(append([]byte{0xff, 0xd8}, segment...), data[2:]...)
+}
+
+// drainPut stands in for a storage backend that consumes the whole body.
+func drainPut(ctx context.Context, key string, r io.Reader, contentType string) error {
+	_, err := io.Copy(io.Discard, r)
+	return err
+}
+
+func TestUpload(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockIImageRepository(ctrl)
+	mockStorage := NewMockStorage(ctrl)
+	mockWatermarkRepo := NewMockIWatermarkRepository(ctrl)
+	mockEvents := NewMockEventPublisher(ctrl)
+
+	imageUsecase := usecase.NewImageUsecase(mockRepo, mockWatermarkRepo, mockStorage, mockEvents)
+
+	userID := "user-id"
+	content := pngBytes()
+	sum := sha256.Sum256(content)
+	checksum := hex.EncodeToString(sum[:])
+
+	type testCase struct {
+		name         string
+		input        *dto.ImageUploadRequest
+		mockBehavior func(mockRepo *MockIImageRepository, mockStorage *MockStorage)
+		expectError  error
+	}
+
+	testCases := []testCase{
+		{
+			name:  "Success - Upload png image",
+			input: &dto.ImageUploadRequest{Filename: "photo.png", File: bytes.NewReader(content)},
+			mockBehavior: func(mockRepo *MockIImageRepository, mockStorage *MockStorage) {
+				mockStorage.EXPECT().Put(CTX, gomock.Any(), gomock.Any(), "image/png").
+					DoAndReturn(func(ctx context.Context, key string, r io.Reader, contentType string) error {
+						body, _ := io.ReadAll(r)
+						assert.Equal(t, content, body)
+						assert.True(t, strings.HasPrefix(key, userID+"/"))
+						assert.True(t, strings.HasSuffix(key, ".png"))
+						return nil
+					})
+
+				mockRepo.EXPECT().CreateImage(CTX, gomock.Any()).
+					DoAndReturn(func(ctx context.Context, image *model.Image) error {
+						assert.Equal(t, userID, image.OwnerID)
+						assert.Equal(t, "image/png", image.MimeType)
+						assert.Equal(t, int64(len(content)), image.Size)
+						assert.Equal(t, "photo.png", image.OriginalFilename)
+						assert.Equal(t, 4, image.Width)
+						assert.Equal(t, 4, image.Height)
+						assert.Equal(t, checksum, image.Checksum)
+						assert.NotNil(t, image.PHash)
+						return nil
+					})
+
+				mockStorage.EXPECT().SignedURL(CTX, gomock.Any(), usecase.ImageURLExpiry).Return("http://localhost/files/image.png", nil).Times(2)
+				mockEvents.EXPECT().Publish(CTX, userID, model.EventImageUploaded, gomock.Any()).Return(nil)
+			},
+			expectError: nil,
+		},
+		{
+			name:  "Success - Upload tiff image",
+			input: &dto.ImageUploadRequest{Filename: "scan.tif", File: bytes.NewReader(tiffBytes())},
+			mockBehavior: func(mockRepo *MockIImageRepository, mockStorage *MockStorage) {
+				mockStorage.EXPECT().Put(CTX, gomock.Any(), gomock.Any(), "image/tiff").
+					DoAndReturn(func(ctx context.Context, key string, r io.Reader, contentType string) error {
+						assert.True(t, strings.HasSuffix(key, ".tif"))
+						return drainPut(ctx, key, r, contentType)
+					})
+				mockRepo.EXPECT().CreateImage(CTX, gomock.Any()).
+					DoAndReturn(func(ctx context.Context, image *model.Image) error {
+						assert.Equal(t, 4, image.Width)
+						return nil
+					})
+				mockStorage.EXPECT().SignedURL(CTX, gomock.Any(), usecase.ImageURLExpiry).Return("http://localhost/files/image.png", nil).Times(2)
+				mockEvents.EXPECT().Publish(CTX, userID, model.EventImageUploaded, gomock.Any()).Return(nil)
+			},
+			expectError: nil,
+		},
+		{
+			name:  "Success - Upload photo records its EXIF fields",
+			input: &dto.ImageUploadRequest{Filename: "photo.jpg", File: bytes.NewReader(photoBytes())},
+			mockBehavior: func(mockRepo *MockIImageRepository, mockStorage *MockStorage) {
+				mockStorage.EXPECT().Put(CTX, gomock.Any(), gomock.Any(), "image/jpeg").DoAndReturn(drainPut)
+				mockRepo.EXPECT().CreateImage(CTX, gomock.Any()).
+					DoAndReturn(func(ctx context.Context, image *model.Image) error {
+						// the stored dimensions are the upright ones
+						assert.Equal(t, 2, image.Width)
+						assert.Equal(t, 4, image.Height)
+						assert.Equal(t, 6, image.Orientation)
+						assert.Equal(t, "Canon", *image.CameraMake)
+						assert.Nil(t, image.CameraModel)
+						assert.Nil(t, image.TakenAt)
+						return nil
+					})
+				mockStorage.EXPECT().SignedURL(CTX, gomock.Any(), usecase.ImageURLExpiry).Return("http://localhost/files/image.png", nil).Times(2)
+				mockEvents.EXPECT().Publish(CTX, userID, model.EventImageUploaded, gomock.Any()).Return(nil)
+			},
+			expectError: nil,
+		},
+		{
+			name:  "Success - Oversized camera make is cleaned up",
+			input: &dto.ImageUploadRequest{Filename: "photo.jpg", File: bytes.NewReader(photoByCamera("Canon\xff" + strings.Repeat("a", 1000)))},
+			mockBehavior: func(mockRepo *MockIImageRepository, mockStorage *MockStorage) {
+				mockStorage.EXPECT().Put(CTX, gomock.Any(), gomock.Any(), "image/jpeg").DoAndReturn(drainPut)
+				mockRepo.EXPECT().CreateImage(CTX, gomock.Any()).
+					DoAndReturn(func(ctx context.Context, image *model.Image) error {
+						// it fits the camera_make column
+						assert.Equal(t, "Canon"+strings.Repeat("a", 250), *image.CameraMake)
+						return nil
+					})
+				mockStorage.EXPECT().SignedURL(CTX, gomock.Any(), usecase.ImageURLExpiry).Return("http://localhost/files/image.png", nil).Times(2)
+				mockEvents.EXPECT().Publish(CTX, userID, model.EventImageUploaded, gomock.Any()).Return(nil)
+			},
+			expectError: nil,
+		},
+		{
+			name:  "Failed - Unsupported file format",
+			input: &dto.ImageUploadRequest{Filename: "notes.png", File: strings.NewReader("definitely not an image")},
+			mockBehavior: func(mockRepo *MockIImageRepository, mockStorage *MockStorage) {
+				mockStorage.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			expectError: customErr.ErrUnsupportedFileFormat,
+		},
+		{
+			name:  "Failed - Database error removes stored object",
+			input: &dto.ImageUploadRequest{Filename: "photo.png", File: bytes.NewReader(content)},
+			mockBehavior: func(mockRepo *MockIImageRepository, mockStorage *MockStorage) {
+				mockStorage.EXPECT().Put(CTX, gomock.Any(), gomock.Any(), "image/png").DoAndReturn(drainPut)
+				mockRepo.EXPECT().CreateImage(CTX, gomock.Any()).Return(customErr.ErrDatabase)
+				mockStorage.EXPECT().Delete(CTX, gomock.Any()).Return(nil)
+			},
+			expectError: customErr.ErrDatabase,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mockBehavior(mockRepo, mockStorage)
+
+			response, err := imageUsecase.Upload(CTX, userID, tc.input)
+
+			if tc.expectError != nil {
+				assert.ErrorIs(t, err, tc.expectError)
+				assert.Nil(t, response)
+			} else {
+				assert.NoError(t, err)
+				assert.NotEmpty(t, response.ID)
+				assert.Equal(t, "http://localhost/files/image.png", response.ImageURL)
+			}
+		})
+	}
+}
+
+func TestUploadLimits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockIImageRepository(ctrl)
+	mockStorage := NewMockStorage(ctrl)
+	imageUsecase := usecase.NewImageUsecase(mockRepo, NewMockIWatermarkRepository(ctrl), mockStorage, NewMockEventPublisher(ctrl))
+
+	previous := imaging.CurrentLimits()
+	imaging.SetLimits(imaging.Limits{MaxWidth: 3})
+	defer imaging.SetLimits(previous)
+
+	mockStorage.EXPECT().Put(CTX, gomock.Any(), gomock.Any(), "image/png").DoAndReturn(drainPut)
+	mockStorage.EXPECT().Delete(CTX, gomock.Any()).Return(nil)
+	mockRepo.EXPECT().CreateImage(gomock.Any(), gomock.Any()).Times(0)
+
+	response, err := imageUsecase.Upload(CTX, "user-id", &dto.ImageUploadRequest{Filename: "photo.png", File: bytes.NewReader(pngBytes())})
+	assert.ErrorIs(t, err, customErr.ErrImageDimensionsTooLarge)
+	assert.Nil(t, response)
+}
+
+func TestUploadLargeImageIsNotHashed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockIImageRepository(ctrl)
+	mockStorage := NewMockStorage(ctrl)
+	mockEvents := NewMockEventPublisher(ctrl)
+	imageUsecase := usecase.NewImageUsecase(mockRepo, NewMockIWatermarkRepository(ctrl), mockStorage, mockEvents)
+
+	// trailing bytes make the upload too large to keep in memory while its
+	// header still describes a small image
+	content := append(pngBytes(), make([]byte, usecase.PerceptualHashMaxSize)...)
+
+	mockStorage.EXPECT().Put(CTX, gomock.Any(), gomock.Any(), "image/png").DoAndReturn(drainPut)
+	mockRepo.EXPECT().CreateImage(CTX, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, image *model.Image) error {
+			assert.Equal(t, int64(len(content)), image.Size)
+			assert.Equal(t, 4, image.Width)
+			assert.Nil(t, image.PHash)
+			return nil
+		})
+	mockStorage.EXPECT().SignedURL(CTX, gomock.Any(), usecase.ImageURLExpiry).Return("http://localhost/files/image.png", nil).Times(2)
+	mockEvents.EXPECT().Publish(CTX, "user-id", model.EventImageUploaded, gomock.Any()).Return(nil)
+
+	_, err := imageUsecase.Upload(CTX, "user-id", &dto.ImageUploadRequest{Filename: "photo.png", File: bytes.NewReader(content)})
+	assert.NoError(t, err)
+}
+
+func TestUploadDeduplication(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockIImageRepository(ctrl)
+	mockStorage := NewMockStorage(ctrl)
+	mockEvents := NewMockEventPublisher(ctrl)
+	imageUsecase := usecase.NewImageUsecase(mockRepo, NewMockIWatermarkRepository(ctrl), mockStorage, mockEvents)
+
+	userID := "user-id"
+	content := pngBytes()
+	sum := sha256.Sum256(content)
+	checksum := hex.EncodeToString(sum[:])
+
+	t.Run("Success - Same content returns the existing image", func(t *testing.T) {
+		existing := &model.Image{ID: "existing-id", OwnerID: userID, StorageKey: "user-id/existing-id.png", Checksum: checksum}
+
+		var uploadedKey string
+		mockStorage.EXPECT().Put(CTX, gomock.Any(), gomock.Any(), "image/png").
+			DoAndReturn(func(ctx context.Context, key string, r io.Reader, contentType string) error {
+				uploadedKey = key
+				return drainPut(ctx, key, r, contentType)
+			})
+		mockRepo.EXPECT().CreateImage(CTX, gomock.Any()).Return(customErr.ErrDuplicateImage)
+		mockStorage.EXPECT().Delete(CTX, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, key string) error {
+				assert.Equal(t, uploadedKey, key)
+				return nil
+			})
+		mockRepo.EXPECT().GetImageByChecksum(CTX, userID, checksum).Return(existing, nil)
+		mockStorage.EXPECT().SignedURL(CTX, existing.StorageKey, usecase.ImageURLExpiry).Return("http://localhost/files/existing.png", nil)
+		mockEvents.EXPECT().Publish(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+		response, err := imageUsecase.Upload(CTX, userID, &dto.ImageUploadRequest{Filename: "again.png", File: bytes.NewReader(content)})
+		assert.NoError(t, err)
+		assert.Equal(t, "existing-id", response.ID)
+		assert.Equal(t, "http://localhost/files/existing.png", response.ImageURL)
+		assert.True(t, response.Duplicate)
+	})
+
+	t.Run("Success - Content the owner stored is shared", func(t *testing.T) {
+		sharedKey := "user-id/earlier-id.png"
+
+		var uploadedKey string
+		mockStorage.EXPECT().Put(CTX, gomock.Any(), gomock.Any(), "image/png").
+			DoAndReturn(func(ctx context.Context, key string, r io.Reader, contentType string) error {
+				uploadedKey = key
+				return drainPut(ctx, key, r, contentType)
+			})
+		mockRepo.EXPECT().CreateImage(CTX, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, image *model.Image) error {
+				image.StorageKey = sharedKey
+				return nil
+			})
+		mockStorage.EXPECT().Delete(CTX, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, key string) error {
+				assert.Equal(t, uploadedKey, key)
+				return nil
+			})
+		mockStorage.EXPECT().SignedURL(CTX, sharedKey, usecase.ImageURLExpiry).Return("http://localhost/files/shared.png", nil).Times(2)
+		mockEvents.EXPECT().Publish(CTX, userID, model.EventImageUploaded, gomock.Any()).Return(nil)
+
+		response, err := imageUsecase.Upload(CTX, userID, &dto.ImageUploadRequest{Filename: "photo.png", File: bytes.NewReader(content)})
+		assert.NoError(t, err)
+		assert.NotEqual(t, "first-id", response.ID)
+		assert.Equal(t, "http://localhost/files/shared.png", response.ImageURL)
+		assert.False(t, response.Duplicate)
+	})
+}
+
+func TestCreateUploadURL(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockIImageRepository(ctrl)
+	mockStorage := NewMockStorage(ctrl)
+	imageUsecase := usecase.NewImageUsecase(mockRepo, NewMockIWatermarkRepository(ctrl), mockStorage, NewMockEventPublisher(ctrl))
+
+	t.Run("Success", func(t *testing.T) {
+		var uploadKey string
+		mockStorage.EXPECT().SignedUploadURL(CTX, gomock.Any(), int64(4096), usecase.UploadURLExpiry).
+			DoAndReturn(func(ctx context.Context, key string, size int64, expiry time.Duration) (string, error) {
+				uploadKey = key
+				return "http://localhost/files/" + key + "?signature=abc", nil
+			})
+		mockRepo.EXPECT().CreatePendingImage(CTX, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, image *model.Image) error {
+				// the URL never grants writes to the image's own key
+				assert.Equal(t, "pending/"+image.ID, uploadKey)
+				assert.True(t, strings.HasSuffix(image.StorageKey, ".tif"))
+				assert.Equal(t, "image/tiff", image.MimeType)
+				assert.Equal(t, int64(4096), image.Size)
+				assert.Equal(t, "scan.tif", image.OriginalFilename)
+				assert.WithinDuration(t, time.Now().Add(usecase.PendingImageTTL), *image.UploadExpiresAt, time.Minute)
+				return nil
+			})
+
+		upload, err := imageUsecase.CreateUploadURL(CTX, "user-id", &dto.ImageUploadURLRequest{Filename: "scan.tif", ContentType: "image/tiff", Size: 4096})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, upload.ID)
+		assert.Equal(t, "PUT", upload.Method)
+		assert.Equal(t, "image/tiff", upload.ContentType)
+		assert.Contains(t, upload.UploadURL, "signature=")
+	})
+
+	t.Run("Error - Unsupported content type", func(t *testing.T) {
+		upload, err := imageUsecase.CreateUploadURL(CTX, "user-id", &dto.ImageUploadURLRequest{ContentType: "application/pdf", Size: 4096})
+		var fieldErrs validator.Errors
+		assert.ErrorAs(t, err, &fieldErrs)
+		assert.Contains(t, fieldErrs, "content_type")
+		assert.Nil(t, upload)
+	})
+}
+
+func TestComplete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockIImageRepository(ctrl)
+	mockStorage := NewMockStorage(ctrl)
+	mockEvents := NewMockEventPublisher(ctrl)
+	imageUsecase := usecase.NewImageUsecase(mockRepo, NewMockIWatermarkRepository(ctrl), mockStorage, mockEvents)
+
+	userID := "user-id"
+	content := pngBytes()
+	sum := sha256.Sum256(content)
+	checksum := hex.EncodeToString(sum[:])
+	key := "user-id/image-id.png"
+	uploadKey := "pending/image-id"
+
+	pending := func(mimeType string) *model.Image {
+		expiresAt := time.Now().Add(time.Hour)
+		return &model.Image{ID: "image-id", OwnerID: userID, StorageKey: key, MimeType: mimeType, Size: int64(len(content)),
+			Status: model.ImageStatusPending, UploadExpiresAt: &expiresAt}
+	}
+
+	type testCase struct {
+		name          string
+		mockBehavior  func()
+		expectID      string
+		expectDup     bool
+		expectedError error
+	}
+
+	testCases := []testCase{
+		{
+			name: "Success - Content verified",
+			mockBehavior: func() {
+				mockRepo.EXPECT().GetPendingImage(CTX, "image-id", userID).Return(pending("image/png"), nil)
+				mockStorage.EXPECT().Stat(CTX, uploadKey).Return(&storage.ObjectInfo{Key: uploadKey, Size: int64(len(content))}, nil)
+				mockStorage.EXPECT().Get(CTX, uploadKey).Return(io.NopCloser(bytes.NewReader(content)), nil)
+				mockStorage.EXPECT().Put(CTX, key, gomock.Any(), "image/png").DoAndReturn(drainPut)
+				mockRepo.EXPECT().CompleteImage(CTX, gomock.Any()).
+					DoAndReturn(func(ctx context.Context, image *model.Image) error {
+						assert.Equal(t, 4, image.Width)
+						assert.Equal(t, 4, image.Height)
+						assert.Equal(t, checksum, image.Checksum)
+						assert.NotNil(t, image.PHash)
+						image.Status = model.ImageStatusReady
+						return nil
+					})
+				mockStorage.EXPECT().Delete(CTX, uploadKey).Return(nil)
+				mockStorage.EXPECT().SignedURL(CTX, key, usecase.ImageURLExpiry).Return("http://localhost/files/"+key, nil).Times(2)
+				mockEvents.EXPECT().Publish(CTX, userID, model.EventImageUploaded, gomock.Any()).Return(nil)
+			},
+			expectID: "image-id",
+		},
+		{
+			name: "Success - Owner already has the content",
+			mockBehavior: func() {
+				existing := &model.Image{ID: "existing-id", OwnerID: userID, StorageKey: "user-id/existing-id.png"}
+
+				mockRepo.EXPECT().GetPendingImage(CTX, "image-id", userID).Return(pending("image/png"), nil)
+				mockStorage.EXPECT().Stat(CTX, uploadKey).Return(&storage.ObjectInfo{Key: uploadKey, Size: int64(len(content))}, nil)
+				mockStorage.EXPECT().Get(CTX, uploadKey).Return(io.NopCloser(bytes.NewReader(content)), nil)
+				mockStorage.EXPECT().Put(CTX, key, gomock.Any(), "image/png").DoAndReturn(drainPut)
+				mockRepo.EXPECT().CompleteImage(CTX, gomock.Any()).Return(customErr.ErrDuplicateImage)
+				mockStorage.EXPECT().Delete(CTX, key).Return(nil)
+				mockRepo.EXPECT().GetImageByChecksum(CTX, userID, checksum).Return(existing, nil)
+				mockRepo.EXPECT().DeletePendingImage(CTX, "image-id", userID).Return(nil)
+				mockStorage.EXPECT().Delete(CTX, uploadKey).Return(nil)
+				mockStorage.EXPECT().SignedURL(CTX, existing.StorageKey, usecase.ImageURLExpiry).Return("http://localhost/files/existing.png", nil)
+			},
+			expectID:  "existing-id",
+			expectDup: true,
+		},
+		{
+			name: "Error - Nothing uploaded yet",
+			mockBehavior: func() {
+				mockRepo.EXPECT().GetPendingImage(CTX, "image-id", userID).Return(pending("image/png"), nil)
+				mockStorage.EXPECT().Stat(CTX, uploadKey).Return(nil, customErr.ErrObjectNotFound)
+			},
+			expectedError: customErr.ErrUploadIncomplete,
+		},
+		{
+			name: "Error - Larger than declared",
+			mockBehavior: func() {
+				mockRepo.EXPECT().GetPendingImage(CTX, "image-id", userID).Return(pending("image/png"), nil)
+				mockStorage.EXPECT().Stat(CTX, uploadKey).Return(&storage.ObjectInfo{Key: uploadKey, Size: int64(len(content)) + 1}, nil)
+			},
+			expectedError: customErr.ErrPayloadTooLarge,
+		},
+		{
+			name: "Error - Content of another type than declared",
+			mockBehavior: func() {
+				mockRepo.EXPECT().GetPendingImage(CTX, "image-id", userID).Return(pending("image/jpeg"), nil)
+				mockStorage.EXPECT().Stat(CTX, uploadKey).Return(&storage.ObjectInfo{Key: uploadKey, Size: int64(len(content))}, nil)
+				mockStorage.EXPECT().Get(CTX, uploadKey).Return(io.NopCloser(bytes.NewReader(content)), nil)
+			},
+			expectedError: customErr.ErrUnsupportedFileFormat,
+		},
+		{
+			name: "Error - Not pending",
+			mockBehavior: func() {
+				mockRepo.EXPECT().GetPendingImage(CTX, "image-id", userID).Return(nil, customErr.ErrImageNotFound)
+			},
+			expectedError: customErr.ErrImageNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mockBehavior()
+
+			response, err := imageUsecase.Complete(CTX, userID, "image-id")
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, response)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectID, response.ID)
+			assert.Equal(t, tc.expectDup, response.Duplicate)
+		})
+	}
+}
+
+func TestAssemble(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockIImageRepository(ctrl)
+	mockStorage := NewMockStorage(ctrl)
+	mockEvents := NewMockEventPublisher(ctrl)
+	imageUsecase := usecase.NewImageUsecase(mockRepo, NewMockIWatermarkRepository(ctrl), mockStorage, mockEvents)
+
+	userID := "user-id"
+	content := tiffBytes()
+	sum := sha256.Sum256(content)
+	checksum := hex.EncodeToString(sum[:])
+	parts := []string{"chunk-0", "chunk-1", "chunk-2"}
+	chunks := [][]byte{content[:10], content[10:25], content[25:]}
+
+	// expectAssembled expects the chunks to be sniffed, then joined by the
+	// store and read back from the image's key
+	expectAssembled := func(data []byte) {
+		for i, chunk := range chunks {
+			mockStorage.EXPECT().Get(CTX, parts[i]).Return(io.NopCloser(bytes.NewReader(chunk)), nil)
+		}
+
+		var assembled string
+		mockStorage.EXPECT().Compose(CTX, gomock.Any(), parts, "image/tiff").
+			DoAndReturn(func(ctx context.Context, key string, sources []string, contentType string) error {
+				assert.True(t, strings.HasPrefix(key, userID+"/"))
+				assert.True(t, strings.HasSuffix(key, ".tif"))
+				assembled = key
+				return nil
+			})
+		mockStorage.EXPECT().Get(CTX, gomock.Cond(func(key string) bool { return key == assembled })).
+			Return(io.NopCloser(bytes.NewReader(data)), nil)
+	}
+
+	type testCase struct {
+		name          string
+		mockBehavior  func()
+		expectID      string
+		expectDup     bool
+		expectedError error
+	}
+
+	testCases := []testCase{
+		{
+			name: "Success - Chunks joined by the store",
+			mockBehavior: func() {
+				expectAssembled(content)
+				mockRepo.EXPECT().CreateImage(CTX, gomock.Any()).
+					DoAndReturn(func(ctx context.Context, image *model.Image) error {
+						assert.Equal(t, "scan.tiff", image.OriginalFilename)
+						assert.Equal(t, "image/tiff", image.MimeType)
+						assert.Equal(t, int64(len(content)), image.Size)
+						assert.Equal(t, 4, image.Width)
+						assert.Equal(t, checksum, image.Checksum)
+						image.ID = "image-id"
+						return nil
+					})
+				mockStorage.EXPECT().SignedURL(CTX, gomock.Any(), usecase.ImageURLExpiry).Return("http://localhost/files/image.tiff", nil).Times(2)
+				mockEvents.EXPECT().Publish(CTX, userID, model.EventImageUploaded, gomock.Any()).Return(nil)
+			},
+			expectID: "image-id",
+		},
+		{
+			name: "Success - Owner already has the content",
+			mockBehavior: func() {
+				existing := &model.Image{ID: "existing-id", OwnerID: userID, StorageKey: "user-id/existing-id.tiff"}
+
+				expectAssembled(content)
+				mockRepo.EXPECT().CreateImage(CTX, gomock.Any()).Return(customErr.ErrDuplicateImage)
+				mockStorage.EXPECT().Delete(CTX, gomock.Any()).Return(nil)
+				mockRepo.EXPECT().GetImageByChecksum(CTX, userID, checksum).Return(existing, nil)
+				mockStorage.EXPECT().SignedURL(CTX, existing.StorageKey, usecase.ImageURLExpiry).Return("http://localhost/files/existing.tiff", nil)
+			},
+			expectID:  "existing-id",
+			expectDup: true,
+		},
+		{
+			name: "Error - Assembled content cannot be decoded",
+			mockBehavior: func() {
+				// the header is sniffed as tiff but the rest is not an image
+				expectAssembled(append(content[:10:10], "garbage"...))
+				mockStorage.EXPECT().Delete(CTX, gomock.Any()).Return(nil)
+			},
+			expectedError: customErr.ErrUnsupportedFileFormat,
+		},
+		{
+			name: "Error - Store fails to join the chunks",
+			mockBehavior: func() {
+				for i, chunk := range chunks {
+					mockStorage.EXPECT().Get(CTX, parts[i]).Return(io.NopCloser(bytes.NewReader(chunk)), nil)
+				}
+				mockStorage.EXPECT().Compose(CTX, gomock.Any(), parts, "image/tiff").Return(customErr.ErrObjectNotFound)
+			},
+			expectedError: customErr.ErrObjectNotFound,
+		},
+		{
+			name: "Error - Not an image",
+			mockBehavior: func() {
+				mockStorage.EXPECT().Get(CTX, parts[0]).Return(io.NopCloser(strings.NewReader("plain text")), nil)
+				mockStorage.EXPECT().Get(CTX, parts[1]).Return(io.NopCloser(strings.NewReader("")), nil)
+				mockStorage.EXPECT().Get(CTX, parts[2]).Return(io.NopCloser(strings.NewReader("")), nil)
+			},
+			expectedError: customErr.ErrUnsupportedFileFormat,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mockBehavior()
+
+			response, err := imageUsecase.Assemble(CTX, userID, "scan.tiff", parts)
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, response)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectID, response.ID)
+			assert.Equal(t, tc.expectDup, response.Duplicate)
+		})
+	}
+}
+
+func TestExpirePendingImages(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockIImageRepository(ctrl)
+	mockStorage := NewMockStorage(ctrl)
+	imageUsecase := usecase.NewImageUsecase(mockRepo, NewMockIWatermarkRepository(ctrl), mockStorage, NewMockEventPublisher(ctrl))
+
+	expired := []model.Image{
+		{ID: "first-id", OwnerID: "user-id", StorageKey: "user-id/first-id.png"},
+		{ID: "second-id", OwnerID: "user-id", StorageKey: "user-id/second-id.png"},
+	}
+
+	mockRepo.EXPECT().ListExpiredPendingImages(CTX, gomock.Any(), 100).Return(expired, nil)
+	mockRepo.EXPECT().DeletePendingImage(CTX, "first-id", "user-id").Return(nil)
+	mockStorage.EXPECT().Delete(CTX, "pending/first-id").Return(nil)
+	// completed while being reaped, so its content is kept
+	mockRepo.EXPECT().DeletePendingImage(CTX, "second-id", "user-id").Return(customErr.ErrImageNotFound)
+	mockStorage.EXPECT().List(CTX, "pending/").Return([]storage.ObjectInfo{
+		{Key: "pending/second-id", ModTime: time.Now()},
+		// sent again after its image was completed
+		{Key: "pending/third-id", ModTime: time.Now().Add(-2 * usecase.PendingImageTTL)},
+	}, nil)
+	mockStorage.EXPECT().Delete(CTX, "pending/third-id").Return(nil)
+
+	removed, err := imageUsecase.ExpirePendingImages(CTX)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, removed)
+}
+
+func TestTransform(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockIImageRepository(ctrl)
+	mockStorage := NewMockStorage(ctrl)
+	mockWatermarkRepo := NewMockIWatermarkRepository(ctrl)
+	mockEvents := NewMockEventPublisher(ctrl)
+
+	imageUsecase := usecase.NewImageUsecase(mockRepo, mockWatermarkRepo, mockStorage, mockEvents)
+
+	userID := "user-id"
+	source := &model.Image{ID: "image-id", OwnerID: userID, StorageKey: "user-id/image-id.png", MimeType: "image/png"}
+	strip := true
+
+	type testCase struct {
+		name         string
+		input        *dto.ImageTransformRequest
+		mockBehavior func(mockRepo *MockIImageRepository, mockStorage *MockStorage)
+		expectError  error
+	}
+
+	testCases := []testCase{
+		{
+			name: "Success - Resize and convert",
+			input: &dto.ImageTransformRequest{
+				Resize:  &dto.ResizeRequest{Width: 2, Height: 2},
+				Convert: "JPEG",
+				Encode:  &dto.EncodeRequest{Quality: 70, Progressive: true},
+			},
+			mockBehavior: func(mockRepo *MockIImageRepository, mockStorage *MockStorage) {
+				mockRepo.EXPECT().GetImageById(CTX, "image-id", userID).Return(source, nil)
+				mockStorage.EXPECT().Get(CTX, source.StorageKey).Return(io.NopCloser(bytes.NewReader(pngBytes())), nil)
+				mockStorage.EXPECT().Put(CTX, gomock.Any(), gomock.Any(), "image/jpeg").
+					DoAndReturn(func(ctx context.Context, key string, r io.Reader, contentType string) error {
+						assert.True(t, strings.HasSuffix(key, ".jpg"))
+						return drainPut(ctx, key, r, contentType)
+					})
+				mockRepo.EXPECT().CreateImage(CTX, gomock.Any()).
+					DoAndReturn(func(ctx context.Context, image *model.Image) error {
+						assert.Equal(t, source.ID, *image.ParentID)
+						assert.Equal(t, userID, image.OwnerID)
+						assert.Equal(t, 2, image.Width)
+						assert.Equal(t, 2, image.Height)
+						return nil
+					})
+				mockStorage.EXPECT().SignedURL(CTX, gomock.Any(), usecase.ImageURLExpiry).Return("http://localhost/files/derived.jpg", nil).Times(2)
+				mockEvents.EXPECT().Publish(CTX, userID, model.EventImageTransformed, gomock.Any()).Return(nil)
+			},
+			expectError: nil,
+		},
+		{
+			name:  "Success - Stripped copy of a photo",
+			input: &dto.ImageTransformRequest{StripMetadata: &strip},
+			mockBehavior: func(mockRepo *MockIImageRepository, mockStorage *MockStorage) {
+				photo := &model.Image{ID: "image-id", OwnerID: userID, StorageKey: "user-id/image-id.jpg", MimeType: "image/jpeg"}
+
+				mockRepo.EXPECT().GetImageById(CTX, "image-id", userID).Return(photo, nil)
+				mockStorage.EXPECT().Get(CTX, photo.StorageKey).Return(io.NopCloser(bytes.NewReader(photoBytes())), nil)
+				mockStorage.EXPECT().Put(CTX, gomock.Any(), gomock.Any(), "image/jpeg").DoAndReturn(drainPut)
+				mockRepo.EXPECT().CreateImage(CTX, gomock.Any()).
+					DoAndReturn(func(ctx context.Context, image *model.Image) error {
+						// rotated upright and without the EXIF block
+						assert.Equal(t, 2, image.Width)
+						assert.Equal(t, 4, image.Height)
+						assert.Equal(t, 1, image.Orientation)
+						assert.Nil(t, image.CameraMake)
+						return nil
+					})
+				mockStorage.EXPECT().SignedURL(CTX, gomock.Any(), usecase.ImageURLExpiry).Return("http://localhost/files/derived.jpg", nil).Times(2)
+				mockEvents.EXPECT().Publish(CTX, userID, model.EventImageTransformed, gomock.Any()).Return(nil)
+			},
+			expectError: nil,
+		},
+		{
+			name:  "Failed - Invalid parameters",
+			input: &dto.ImageTransformRequest{Convert: "psd"},
+			mockBehavior: func(mockRepo *MockIImageRepository, mockStorage *MockStorage) {
+				mockRepo.EXPECT().GetImageById(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			expectError: customErr.ErrInvalidTransformation,
+		},
+		{
+			name:  "Success - Cover resize around the focal point",
+			input: &dto.ImageTransformRequest{Resize: &dto.ResizeRequest{Width: 2, Height: 1, Fit: "cover", Filter: "lanczos"}},
+			mockBehavior: func(mockRepo *MockIImageRepository, mockStorage *MockStorage) {
+				x, y := 0.9, 0.1
+				focused := *source
+				focused.FocalX, focused.FocalY = &x, &y
+
+				mockRepo.EXPECT().GetImageById(CTX, "image-id", userID).Return(&focused, nil)
+				mockStorage.EXPECT().Get(CTX, source.StorageKey).Return(io.NopCloser(bytes.NewReader(pngBytes())), nil)
+				mockStorage.EXPECT().Put(CTX, gomock.Any(), gomock.Any(), "image/png").DoAndReturn(drainPut)
+				mockRepo.EXPECT().CreateImage(CTX, gomock.Any()).
+					DoAndReturn(func(ctx context.Context, image *model.Image) error {
+						assert.Equal(t, 2, image.Width)
+						assert.Equal(t, 1, image.Height)
+						return nil
+					})
+				mockStorage.EXPECT().SignedURL(CTX, gomock.Any(), usecase.ImageURLExpiry).Return("http://localhost/files/derived.png", nil).Times(2)
+				mockEvents.EXPECT().Publish(CTX, userID, model.EventImageTransformed, gomock.Any()).Return(nil)
+			},
+			expectError: nil,
+		},
+		{
+			name:  "Failed - Invalid resize gravity",
+			input: &dto.ImageTransformRequest{Resize: &dto.ResizeRequest{Width: 2, Height: 2, Fit: "cover", Gravity: "up"}},
+			mockBehavior: func(mockRepo *MockIImageRepository, mockStorage *MockStorage) {
+				mockRepo.EXPECT().GetImageById(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			expectError: customErr.ErrInvalidTransformation,
+		},
+		{
+			name:  "Failed - Unknown png compression",
+			input: &dto.ImageTransformRequest{Convert: "png", Encode: &dto.EncodeRequest{Compression: "maximum"}},
+			mockBehavior: func(mockRepo *MockIImageRepository, mockStorage *MockStorage) {
+				mockRepo.EXPECT().GetImageById(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			expectError: customErr.ErrInvalidTransformation,
+		},
+		{
+			name:  "Failed - Invalid rotate background",
+			input: &dto.ImageTransformRequest{Rotate: &dto.RotateRequest{Angle: 30, Background: "blue-ish"}},
+			mockBehavior: func(mockRepo *MockIImageRepository, mockStorage *MockStorage) {
+				mockRepo.EXPECT().GetImageById(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			expectError: customErr.ErrInvalidTransformation,
+		},
+		{
+			name:  "Failed - Crop outside image",
+			input: &dto.ImageTransformRequest{Crop: &dto.CropRequest{X: 2, Y: 2, Width: 10, Height: 10}},
+			mockBehavior: func(mockRepo *MockIImageRepository, mockStorage *MockStorage) {
+				mockRepo.EXPECT().GetImageById(CTX, "image-id", userID).Return(source, nil)
+				mockStorage.EXPECT().Get(CTX, source.StorageKey).Return(io.NopCloser(bytes.NewReader(pngBytes())), nil)
+			},
+			expectError: customErr.ErrInvalidTransformation,
+		},
+		{
+			name:  "Success - Watermark falls back to saved settings",
+			input: &dto.ImageTransformRequest{Watermark: &dto.WatermarkRequest{Opacity: 0.8}},
+			mockBehavior: func(mockRepo *MockIImageRepository, mockStorage *MockStorage) {
+				markID := "mark-id"
+				mark := &model.Image{ID: markID, OwnerID: userID, StorageKey: "user-id/mark-id.png", MimeType: "image/png"}
+
+				mockRepo.EXPECT().GetImageById(CTX, "image-id", userID).Return(source, nil)
+				mockWatermarkRepo.EXPECT().GetSettings(CTX, userID).
+					Return(&model.WatermarkSettings{OwnerID: userID, ImageID: &markID, Gravity: "north"}, nil)
+				mockRepo.EXPECT().GetImageById(CTX, markID, userID).Return(mark, nil)
+				mockStorage.EXPECT().Get(CTX, mark.StorageKey).Return(io.NopCloser(bytes.NewReader(pngBytes())), nil)
+				mockStorage.EXPECT().Get(CTX, source.StorageKey).Return(io.NopCloser(bytes.NewReader(pngBytes())), nil)
+				mockStorage.EXPECT().Put(CTX, gomock.Any(), gomock.Any(), "image/png").DoAndReturn(drainPut)
+				mockRepo.EXPECT().CreateImage(CTX, gomock.Any()).Return(nil)
+				mockStorage.EXPECT().SignedURL(CTX, gomock.Any(), usecase.ImageURLExpiry).Return("http://localhost/files/derived.png", nil).Times(2)
+				mockEvents.EXPECT().Publish(CTX, userID, model.EventImageTransformed, gomock.Any()).Return(nil)
+			},
+			expectError: nil,
+		},
+		{
+			name:  "Failed - Watermark without image or text",
+			input: &dto.ImageTransformRequest{Watermark: &dto.WatermarkRequest{}},
+			mockBehavior: func(mockRepo *MockIImageRepository, mockStorage *MockStorage) {
+				mockRepo.EXPECT().GetImageById(CTX, "image-id", userID).Return(source, nil)
+				mockWatermarkRepo.EXPECT().GetSettings(CTX, userID).Return(nil, customErr.ErrWatermarkNotFound)
+			},
+			expectError: customErr.ErrInvalidTransformation,
+		},
+		{
+			name:  "Failed - Image not found",
+			input: &dto.ImageTransformRequest{Resize: &dto.ResizeRequest{Width: 2}},
+			mockBehavior: func(mockRepo *MockIImageRepository, mockStorage *MockStorage) {
+				mockRepo.EXPECT().GetImageById(CTX, "image-id", userID).Return(nil, customErr.ErrImageNotFound)
+			},
+			expectError: customErr.ErrImageNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mockBehavior(mockRepo, mockStorage)
+
+			response, err := imageUsecase.Transform(CTX, userID, "image-id", tc.input)
+
+			if tc.expectError != nil {
+				assert.ErrorIs(t, err, tc.expectError)
+				assert.Nil(t, response)
+			} else {
+				assert.NoError(t, err)
+				assert.NotEmpty(t, response.ID)
+				assert.Equal(t, source.ID, *response.ParentID)
+			}
+		})
+	}
+
+	t.Run("Success - Same content returns the existing image", func(t *testing.T) {
+		// an earlier copy of the source, made without a parent
+		existing := &model.Image{ID: "existing-id", OwnerID: userID, StorageKey: "user-id/existing-id.png"}
+
+		mockRepo.EXPECT().GetImageById(CTX, "image-id", userID).Return(source, nil)
+		mockStorage.EXPECT().Get(CTX, source.StorageKey).Return(io.NopCloser(bytes.NewReader(pngBytes())), nil)
+		mockStorage.EXPECT().Put(CTX, gomock.Any(), gomock.Any(), "image/png").DoAndReturn(drainPut)
+		mockRepo.EXPECT().CreateImage(CTX, gomock.Any()).Return(customErr.ErrDuplicateImage)
+		mockStorage.EXPECT().Delete(CTX, gomock.Any()).Return(nil)
+		mockRepo.EXPECT().GetImageByChecksum(CTX, userID, gomock.Any()).Return(existing, nil)
+		mockStorage.EXPECT().SignedURL(CTX, existing.StorageKey, usecase.ImageURLExpiry).Return("http://localhost/files/existing.png", nil)
+
+		response, err := imageUsecase.Transform(CTX, userID, "image-id", &dto.ImageTransformRequest{StripMetadata: &strip})
+		assert.NoError(t, err)
+		assert.Equal(t, "existing-id", response.ID)
+		assert.Nil(t, response.ParentID)
+		assert.True(t, response.Duplicate)
+	})
+}
+
+func TestGetAndDelete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockIImageRepository(ctrl)
+	mockStorage := NewMockStorage(ctrl)
+	mockWatermarkRepo := NewMockIWatermarkRepository(ctrl)
+	mockEvents := NewMockEventPublisher(ctrl)
+
+	imageUsecase := usecase.NewImageUsecase(mockRepo, mockWatermarkRepo, mockStorage, mockEvents)
+
+	image := &model.Image{ID: "image-id", OwnerID: "owner-id", StorageKey: "owner-id/image-id.png", Width: 4, Height: 4}
+
+	t.Run("Success - Get own image", func(t *testing.T) {
+		mockRepo.EXPECT().GetImageById(CTX, "image-id", "owner-id").Return(image, nil)
+		mockStorage.EXPECT().SignedURL(CTX, image.StorageKey, usecase.ImageURLExpiry).Return("http://localhost/files/owner-id/image-id.png", nil)
+
+		response, err := imageUsecase.Get(CTX, "owner-id", "image-id")
+		assert.NoError(t, err)
+		assert.Equal(t, 4, response.Width)
+		assert.Equal(t, "http://localhost/files/owner-id/image-id.png", response.ImageURL)
+	})
+
+	t.Run("Failed - Get another user's image", func(t *testing.T) {
+		mockRepo.EXPECT().GetImageById(CTX, "image-id", "intruder-id").Return(nil, customErr.ErrImageNotFound)
+
+		response, err := imageUsecase.Get(CTX, "intruder-id", "image-id")
+		assert.ErrorIs(t, err, customErr.ErrImageNotFound)
+		assert.Nil(t, response)
+	})
+
+	t.Run("Success - Delete removes record, renders and blob", func(t *testing.T) {
+		renderKey := "owner-id/renders/image-id/variant.png"
+
+		mockRepo.EXPECT().GetImageById(CTX, "image-id", "owner-id").Return(image, nil)
+		mockRepo.EXPECT().DeleteImage(CTX, "image-id", "owner-id").Return(true, nil)
+		mockStorage.EXPECT().List(CTX, "owner-id/renders/image-id/").Return([]storage.ObjectInfo{{Key: renderKey}}, nil)
+		mockStorage.EXPECT().Delete(CTX, renderKey).Return(nil)
+		mockStorage.EXPECT().Delete(CTX, image.StorageKey).Return(nil)
+		mockStorage.EXPECT().SignedURL(CTX, image.StorageKey, usecase.ImageURLExpiry).Return("http://localhost/files/owner-id/image-id.png", nil)
+		mockEvents.EXPECT().Publish(CTX, "owner-id", model.EventImageDeleted, gomock.Any()).Return(nil)
+
+		assert.NoError(t, imageUsecase.Delete(CTX, "owner-id", "image-id"))
+	})
+
+	t.Run("Success - Delete keeps a blob other images share", func(t *testing.T) {
+		mockRepo.EXPECT().GetImageById(CTX, "image-id", "owner-id").Return(image, nil)
+		mockRepo.EXPECT().DeleteImage(CTX, "image-id", "owner-id").Return(false, nil)
+		mockStorage.EXPECT().List(CTX, "owner-id/renders/image-id/").Return(nil, nil)
+		mockStorage.EXPECT().Delete(CTX, image.StorageKey).Times(0)
+		mockStorage.EXPECT().SignedURL(CTX, image.StorageKey, usecase.ImageURLExpiry).Return("http://localhost/files/owner-id/image-id.png", nil)
+		mockEvents.EXPECT().Publish(CTX, "owner-id", model.EventImageDeleted, gomock.Any()).Return(nil)
+
+		assert.NoError(t, imageUsecase.Delete(CTX, "owner-id", "image-id"))
+	})
+
+	t.Run("Failed - Delete another user's image", func(t *testing.T) {
+		mockRepo.EXPECT().GetImageById(CTX, "image-id", "intruder-id").Return(nil, customErr.ErrImageNotFound)
+
+		assert.ErrorIs(t, imageUsecase.Delete(CTX, "intruder-id", "image-id"), customErr.ErrImageNotFound)
+	})
+}
+
+func TestSimilar(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockIImageRepository(ctrl)
+	mockStorage := NewMockStorage(ctrl)
+	imageUsecase := usecase.NewImageUsecase(mockRepo, NewMockIWatermarkRepository(ctrl), mockStorage, NewMockEventPublisher(ctrl))
+
+	phash := int64(-42)
+	image := &model.Image{ID: "image-id", OwnerID: "owner-id", StorageKey: "owner-id/image-id.png", PHash: &phash}
+	other := model.Image{ID: "other-id", OwnerID: "owner-id", StorageKey: "owner-id/other-id.png", PHash: &phash}
+	maxDistance := 4
+
+	t.Run("Success - Defaults", func(t *testing.T) {
+		mockRepo.EXPECT().GetImageById(CTX, "image-id", "owner-id").Return(image, nil)
+		mockRepo.EXPECT().FindSimilarImages(CTX, "owner-id", "image-id", phash, usecase.DefaultSimilarDistance, usecase.DefaultListLimit).
+			Return([]repository.SimilarImage{{Image: other, Distance: 3}}, nil)
+		mockStorage.EXPECT().SignedURL(CTX, other.StorageKey, usecase.ImageURLExpiry).Return("http://localhost/files/other.png", nil)
+
+		similar, err := imageUsecase.Similar(CTX, "owner-id", "image-id", &dto.SimilarImagesRequest{})
+		assert.NoError(t, err)
+		assert.Len(t, similar, 1)
+		assert.Equal(t, "other-id", similar[0].ID)
+		assert.Equal(t, 3, similar[0].Distance)
+		assert.Equal(t, "ffffffffffffffd6", *similar[0].PerceptualHash)
+	})
+
+	t.Run("Success - Requested distance and limit", func(t *testing.T) {
+		mockRepo.EXPECT().GetImageById(CTX, "image-id", "owner-id").Return(image, nil)
+		mockRepo.EXPECT().FindSimilarImages(CTX, "owner-id", "image-id", phash, 4, 5).Return([]repository.SimilarImage{}, nil)
+
+		similar, err := imageUsecase.Similar(CTX, "owner-id", "image-id", &dto.SimilarImagesRequest{MaxDistance: &maxDistance, Limit: 5})
+		assert.NoError(t, err)
+		assert.Empty(t, similar)
+	})
+
+	t.Run("Failed - Image stored before hashing", func(t *testing.T) {
+		mockRepo.EXPECT().GetImageById(CTX, "image-id", "owner-id").
+			Return(&model.Image{ID: "image-id", OwnerID: "owner-id"}, nil)
+
+		similar, err := imageUsecase.Similar(CTX, "owner-id", "image-id", nil)
+		assert.ErrorIs(t, err, customErr.ErrImageNotHashed)
+		assert.Nil(t, similar)
+	})
+}
+
+func TestList(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockIImageRepository(ctrl)
+	mockStorage := NewMockStorage(ctrl)
+	mockWatermarkRepo := NewMockIWatermarkRepository(ctrl)
+	mockEvents := NewMockEventPublisher(ctrl)
+
+	imageUsecase := usecase.NewImageUsecase(mockRepo, mockWatermarkRepo, mockStorage, mockEvents)
+
+	t.Run("Success - Request mapped to filter", func(t *testing.T) {
+		minSize := int64(100)
+		req := &dto.ImageListRequest{Format: "jpg", MinSize: &minSize, ParentID: "parent-id", Sort: "size", Limit: 5}
+
+		mockRepo.EXPECT().ListImages(CTX, repository.ImageFilter{
+			OwnerID:  "owner-id",
+			MimeType: "image/jpeg",
+			MinSize:  &minSize,
+			ParentID: "parent-id",
+			SortBy:   "size",
+			Limit:    5,
+		}).Return(&repository.ImagePage{
+			Images:     []model.Image{{ID: "image-id", StorageKey: "owner-id/image-id.jpg"}},
+			HasMore:    true,
+			NextCursor: "next",
+		}, nil)
+		mockStorage.EXPECT().SignedURL(CTX, "owner-id/image-id.jpg", usecase.ImageURLExpiry).Return("http://localhost/files/owner-id/image-id.jpg", nil)
+
+		page, err := imageUsecase.List(CTX, "owner-id", req)
+		assert.NoError(t, err)
+		assert.Len(t, page.Images, 1)
+		assert.Equal(t, 5, page.Limit)
+		assert.True(t, page.HasMore)
+		assert.Equal(t, "next", page.NextCursor)
+	})
+
+	t.Run("Success - Default limit", func(t *testing.T) {
+		mockRepo.EXPECT().ListImages(CTX, repository.ImageFilter{OwnerID: "owner-id", Limit: usecase.DefaultListLimit}).
+			Return(&repository.ImagePage{}, nil)
+
+		page, err := imageUsecase.List(CTX, "owner-id", &dto.ImageListRequest{})
+		assert.NoError(t, err)
+		assert.Empty(t, page.Images)
+	})
+
+	t.Run("Failed - Conflicting filters", func(t *testing.T) {
+		minSize, maxSize := int64(10), int64(5)
+		req := &dto.ImageListRequest{Format: "psd", MinSize: &minSize, MaxSize: &maxSize, OriginalsOnly: true, ParentID: "parent-id"}
+
+		page, err := imageUsecase.List(CTX, "owner-id", req)
+
+		var errs validator.Errors
+		assert.ErrorAs(t, err, &errs)
+		assert.Contains(t, errs, "format")
+		assert.Contains(t, errs, "max_size")
+		assert.Contains(t, errs, "parent_id")
+		assert.Nil(t, page)
+	})
+
+	t.Run("Failed - Invalid cursor", func(t *testing.T) {
+		mockRepo.EXPECT().ListImages(CTX, gomock.Any()).Return(nil, customErr.ErrInvalidCursor)
+
+		_, err := imageUsecase.List(CTX, "owner-id", &dto.ImageListRequest{Cursor: "bogus"})
+
+		var errs validator.Errors
+		assert.ErrorAs(t, err, &errs)
+		assert.Contains(t, errs, "cursor")
+	})
+}
+
+func TestRender(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockIImageRepository(ctrl)
+	mockStorage := NewMockStorage(ctrl)
+	mockWatermarkRepo := NewMockIWatermarkRepository(ctrl)
+	mockEvents := NewMockEventPublisher(ctrl)
+
+	imageUsecase := usecase.NewImageUsecase(mockRepo, mockWatermarkRepo, mockStorage, mockEvents)
+
+	content := pngBytes()
+	image := &model.Image{ID: "image-id", OwnerID: "owner-id", StorageKey: "owner-id/image-id.png", MimeType: "image/png", Checksum: "checksum"}
+	req := &dto.ImageRenderRequest{Width: 2, Fit: "inside", Format: "jpg", Quality: 80}
+
+	var cachedKey, etag string
+
+	t.Run("Success - Cache miss renders and stores variant", func(t *testing.T) {
+		mockRepo.EXPECT().GetImageById(CTX, "image-id", "owner-id").Return(image, nil)
+		mockStorage.EXPECT().Stat(CTX, gomock.Any()).Return(nil, customErr.ErrObjectNotFound)
+		mockStorage.EXPECT().Get(CTX, image.StorageKey).Return(io.NopCloser(bytes.NewReader(content)), nil)
+		mockStorage.EXPECT().Put(CTX, gomock.Any(), gomock.Any(), "image/jpeg").
+			DoAndReturn(func(_ context.Context, key string, r io.Reader, _ string) error {
+				cachedKey = key
+				return drainPut(CTX, key, r, "")
+			})
+
+		rendered, err := imageUsecase.Render(CTX, "owner-id", "image-id", req)
+		assert.NoError(t, err)
+		defer rendered.Body.Close()
+
+		assert.True(t, strings.HasPrefix(cachedKey, "owner-id/renders/image-id/"))
+		assert.True(t, strings.HasSuffix(cachedKey, ".jpg"))
+		assert.Equal(t, "image/jpeg", rendered.ContentType)
+
+		decoded, _, err := imaging.Decode(rendered.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, decoded.Bounds().Dx())
+
+		etag = rendered.ETag
+	})
+
+	t.Run("Success - Cache hit streams stored variant", func(t *testing.T) {
+		mockRepo.EXPECT().GetImageById(CTX, "image-id", "owner-id").Return(image, nil)
+		mockStorage.EXPECT().Stat(CTX, cachedKey).Return(&storage.ObjectInfo{Key: cachedKey, Size: 3}, nil)
+		mockStorage.EXPECT().Get(CTX, cachedKey).Return(io.NopCloser(strings.NewReader("abc")), nil)
+
+		rendered, err := imageUsecase.Render(CTX, "owner-id", "image-id", req)
+		assert.NoError(t, err)
+		assert.Equal(t, etag, rendered.ETag)
+		assert.Equal(t, int64(3), rendered.Size)
+	})
+
+	t.Run("Success - Matching ETag is not modified", func(t *testing.T) {
+		mockRepo.EXPECT().GetImageById(CTX, "image-id", "owner-id").Return(image, nil)
+
+		rendered, err := imageUsecase.Render(CTX, "owner-id", "image-id",
+			&dto.ImageRenderRequest{Width: 2, Fit: "INSIDE", Format: "jpeg", Quality: 80, IfNoneMatch: `"other", ` + etag})
+		assert.NoError(t, err)
+		assert.True(t, rendered.NotModified)
+		assert.Nil(t, rendered.Body)
+	})
+
+	t.Run("Success - Focal point changes the variant", func(t *testing.T) {
+		x, y := 0.25, 0.75
+		focused := *image
+		focused.FocalX, focused.FocalY = &x, &y
+
+		mockRepo.EXPECT().GetImageById(CTX, "image-id", "owner-id").Return(&focused, nil)
+		mockStorage.EXPECT().Stat(CTX, gomock.Not(cachedKey)).Return(&storage.ObjectInfo{Size: 3}, nil)
+		mockStorage.EXPECT().Get(CTX, gomock.Not(cachedKey)).Return(io.NopCloser(strings.NewReader("abc")), nil)
+
+		rendered, err := imageUsecase.Render(CTX, "owner-id", "image-id",
+			&dto.ImageRenderRequest{Width: 2, Fit: "inside", Format: "jpg", Quality: 80, IfNoneMatch: etag})
+		assert.NoError(t, err)
+		assert.False(t, rendered.NotModified)
+		assert.NotEqual(t, etag, rendered.ETag)
+		rendered.Body.Close()
+	})
+
+	t.Run("Failed - Invalid parameters", func(t *testing.T) {
+		mockRepo.EXPECT().GetImageById(CTX, "image-id", "owner-id").Return(image, nil)
+
+		rendered, err := imageUsecase.Render(CTX, "owner-id", "image-id", &dto.ImageRenderRequest{Width: 10, Fit: "squash"})
+		assert.ErrorIs(t, err, customErr.ErrInvalidTransformation)
+		assert.Nil(t, rendered)
+	})
+
+	t.Run("Success - Public render finds the owner", func(t *testing.T) {
+		mockRepo.EXPECT().GetImageOwner(CTX, "image-id").Return("owner-id", nil)
+		mockRepo.EXPECT().GetImageById(CTX, "image-id", "owner-id").Return(image, nil)
+		mockStorage.EXPECT().Stat(CTX, cachedKey).Return(&storage.ObjectInfo{Key: cachedKey, Size: 3}, nil)
+		mockStorage.EXPECT().Get(CTX, cachedKey).Return(io.NopCloser(strings.NewReader("abc")), nil)
+
+		rendered, err := imageUsecase.RenderPublic(CTX, "image-id", req)
+		assert.NoError(t, err)
+		assert.Equal(t, etag, rendered.ETag)
+	})
+
+	t.Run("Failed - Public render of a missing image", func(t *testing.T) {
+		mockRepo.EXPECT().GetImageOwner(CTX, "missing-id").Return("", customErr.ErrImageNotFound)
+
+		rendered, err := imageUsecase.RenderPublic(CTX, "missing-id", req)
+		assert.ErrorIs(t, err, customErr.ErrImageNotFound)
+		assert.Nil(t, rendered)
+	})
+}
+
+func TestFocalPoint(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockIImageRepository(ctrl)
+	mockStorage := NewMockStorage(ctrl)
+	mockWatermarkRepo := NewMockIWatermarkRepository(ctrl)
+	mockEvents := NewMockEventPublisher(ctrl)
+
+	imageUsecase := usecase.NewImageUsecase(mockRepo, mockWatermarkRepo, mockStorage, mockEvents)
+
+	x, y := 0.3, 0.6
+	image := &model.Image{ID: "image-id", OwnerID: "owner-id", StorageKey: "owner-id/image-id.png", FocalX: &x, FocalY: &y}
+
+	t.Run("Success - Set focal point", func(t *testing.T) {
+		mockRepo.EXPECT().UpdateFocalPoint(CTX, "image-id", "owner-id", &x, &y).Return(nil)
+		mockRepo.EXPECT().GetImageById(CTX, "image-id", "owner-id").Return(image, nil)
+		mockStorage.EXPECT().SignedURL(CTX, image.StorageKey, usecase.ImageURLExpiry).Return("http://localhost/files/owner-id/image-id.png", nil)
+
+		response, err := imageUsecase.SetFocalPoint(CTX, "owner-id", "image-id", &dto.FocalPointRequest{X: &x, Y: &y})
+		assert.NoError(t, err)
+		assert.Equal(t, &dto.FocalPoint{X: 0.3, Y: 0.6}, response.FocalPoint)
+	})
+
+	t.Run("Success - Clear focal point", func(t *testing.T) {
+		cleared := *image
+		cleared.FocalX, cleared.FocalY = nil, nil
+
+		mockRepo.EXPECT().UpdateFocalPoint(CTX, "image-id", "owner-id", nil, nil).Return(nil)
+		mockRepo.EXPECT().GetImageById(CTX, "image-id", "owner-id").Return(&cleared, nil)
+		mockStorage.EXPECT().SignedURL(CTX, image.StorageKey, usecase.ImageURLExpiry).Return("http://localhost/files/owner-id/image-id.png", nil)
+
+		response, err := imageUsecase.ClearFocalPoint(CTX, "owner-id", "image-id")
+		assert.NoError(t, err)
+		assert.Nil(t, response.FocalPoint)
+	})
+
+	t.Run("Failed - Another user's image", func(t *testing.T) {
+		mockRepo.EXPECT().UpdateFocalPoint(CTX, "image-id", "intruder-id", &x, &y).Return(customErr.ErrImageNotFound)
+
+		response, err := imageUsecase.SetFocalPoint(CTX, "intruder-id", "image-id", &dto.FocalPointRequest{X: &x, Y: &y})
+		assert.ErrorIs(t, err, customErr.ErrImageNotFound)
+		assert.Nil(t, response)
+	})
+}
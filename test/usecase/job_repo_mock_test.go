@@ -0,0 +1,144 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/job_repo.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/repository/job_repo.go -destination=test/usecase/job_repo_mock_test.go -package=usecase_test
+//
+
+// Package usecase_test is a generated GoMock package.
+package usecase_test
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	model "github.com/federicodosantos/image-smith/internal/model"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIJobRepository is a mock of IJobRepository interface.
+type MockIJobRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIJobRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockIJobRepositoryMockRecorder is the mock recorder for MockIJobRepository.
+type MockIJobRepositoryMockRecorder struct {
+	mock *MockIJobRepository
+}
+
+// NewMockIJobRepository creates a new mock instance.
+func NewMockIJobRepository(ctrl *gomock.Controller) *MockIJobRepository {
+	mock := &MockIJobRepository{ctrl: ctrl}
+	mock.recorder = &MockIJobRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIJobRepository) EXPECT() *MockIJobRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ClaimJob mocks base method.
+func (m *MockIJobRepository) ClaimJob(ctx context.Context, lease time.Duration) (*model.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimJob", ctx, lease)
+	ret0, _ := ret[0].(*model.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimJob indicates an expected call of ClaimJob.
+func (mr *MockIJobRepositoryMockRecorder) ClaimJob(ctx, lease any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimJob", reflect.TypeOf((*MockIJobRepository)(nil).ClaimJob), ctx, lease)
+}
+
+// CompleteJob mocks base method.
+func (m *MockIJobRepository) CompleteJob(ctx context.Context, id string, attempt int, resultImageID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteJob", ctx, id, attempt, resultImageID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteJob indicates an expected call of CompleteJob.
+func (mr *MockIJobRepositoryMockRecorder) CompleteJob(ctx, id, attempt, resultImageID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteJob", reflect.TypeOf((*MockIJobRepository)(nil).CompleteJob), ctx, id, attempt, resultImageID)
+}
+
+// CreateJob mocks base method.
+func (m *MockIJobRepository) CreateJob(ctx context.Context, job *model.Job) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateJob", ctx, job)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateJob indicates an expected call of CreateJob.
+func (mr *MockIJobRepositoryMockRecorder) CreateJob(ctx, job any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateJob", reflect.TypeOf((*MockIJobRepository)(nil).CreateJob), ctx, job)
+}
+
+// FailAbandonedJobs mocks base method.
+func (m *MockIJobRepository) FailAbandonedJobs(ctx context.Context, lastError string) ([]model.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailAbandonedJobs", ctx, lastError)
+	ret0, _ := ret[0].([]model.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FailAbandonedJobs indicates an expected call of FailAbandonedJobs.
+func (mr *MockIJobRepositoryMockRecorder) FailAbandonedJobs(ctx, lastError any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailAbandonedJobs", reflect.TypeOf((*MockIJobRepository)(nil).FailAbandonedJobs), ctx, lastError)
+}
+
+// FailJob mocks base method.
+func (m *MockIJobRepository) FailJob(ctx context.Context, id string, attempt int, lastError string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailJob", ctx, id, attempt, lastError)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FailJob indicates an expected call of FailJob.
+func (mr *MockIJobRepositoryMockRecorder) FailJob(ctx, id, attempt, lastError any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailJob", reflect.TypeOf((*MockIJobRepository)(nil).FailJob), ctx, id, attempt, lastError)
+}
+
+// GetJobById mocks base method.
+func (m *MockIJobRepository) GetJobById(ctx context.Context, id, ownerID string) (*model.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJobById", ctx, id, ownerID)
+	ret0, _ := ret[0].(*model.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJobById indicates an expected call of GetJobById.
+func (mr *MockIJobRepositoryMockRecorder) GetJobById(ctx, id, ownerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJobById", reflect.TypeOf((*MockIJobRepository)(nil).GetJobById), ctx, id, ownerID)
+}
+
+// RetryJob mocks base method.
+func (m *MockIJobRepository) RetryJob(ctx context.Context, id string, attempt int, runAt time.Time, lastError string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetryJob", ctx, id, attempt, runAt, lastError)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RetryJob indicates an expected call of RetryJob.
+func (mr *MockIJobRepositoryMockRecorder) RetryJob(ctx, id, attempt, runAt, lastError any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetryJob", reflect.TypeOf((*MockIJobRepository)(nil).RetryJob), ctx, id, attempt, runAt, lastError)
+}
@@ -0,0 +1,64 @@
+package usecase_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/federicodosantos/image-smith/internal/dto"
+	"github.com/federicodosantos/image-smith/internal/model"
+	"github.com/federicodosantos/image-smith/internal/usecase"
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestEnqueueTransform(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockJobRepo := NewMockIJobRepository(ctrl)
+	mockImageRepo := NewMockIImageRepository(ctrl)
+
+	jobUsecase := usecase.NewJobUsecase(mockJobRepo, mockImageRepo, 3)
+
+	req := &dto.ImageTransformRequest{Resize: &dto.ResizeRequest{Width: 100}}
+
+	t.Run("Success - Job queued with payload", func(t *testing.T) {
+		mockImageRepo.EXPECT().GetImageById(CTX, "image-id", "owner-id").Return(&model.Image{ID: "image-id"}, nil)
+		mockJobRepo.EXPECT().CreateJob(CTX, gomock.Any()).DoAndReturn(func(_ any, job *model.Job) error {
+			var payload dto.ImageTransformRequest
+			assert.NoError(t, json.Unmarshal(job.Payload, &payload))
+			assert.Equal(t, *req, payload)
+			assert.Equal(t, model.JobTypeTransform, job.Type)
+			assert.Equal(t, 3, job.MaxAttempts)
+			return nil
+		})
+
+		job, err := jobUsecase.EnqueueTransform(CTX, "owner-id", "image-id", req)
+		assert.NoError(t, err)
+		assert.Equal(t, model.JobStatusQueued, job.Status)
+		assert.Equal(t, "image-id", job.ImageID)
+	})
+
+	t.Run("Failed - Invalid parameters are rejected before queueing", func(t *testing.T) {
+		job, err := jobUsecase.EnqueueTransform(CTX, "owner-id", "image-id", &dto.ImageTransformRequest{})
+		assert.ErrorIs(t, err, customErr.ErrInvalidTransformation)
+		assert.Nil(t, job)
+	})
+
+	t.Run("Failed - Image of another user", func(t *testing.T) {
+		mockImageRepo.EXPECT().GetImageById(CTX, "image-id", "intruder-id").Return(nil, customErr.ErrImageNotFound)
+
+		job, err := jobUsecase.EnqueueTransform(CTX, "intruder-id", "image-id", req)
+		assert.ErrorIs(t, err, customErr.ErrImageNotFound)
+		assert.Nil(t, job)
+	})
+
+	t.Run("Failed - Job of another user", func(t *testing.T) {
+		mockJobRepo.EXPECT().GetJobById(CTX, "job-id", "intruder-id").Return(nil, customErr.ErrJobNotFound)
+
+		job, err := jobUsecase.Get(CTX, "intruder-id", "job-id")
+		assert.ErrorIs(t, err, customErr.ErrJobNotFound)
+		assert.Nil(t, job)
+	})
+}
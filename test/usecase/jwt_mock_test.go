@@ -11,6 +11,7 @@ package usecase_test
 
 import (
 	reflect "reflect"
+	time "time"
 
 	gomock "go.uber.org/mock/gomock"
 )
@@ -54,6 +55,20 @@ func (mr *MockJWTItfMockRecorder) CreateToken(userID any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateToken", reflect.TypeOf((*MockJWTItf)(nil).CreateToken), userID)
 }
 
+// ExpiresIn mocks base method.
+func (m *MockJWTItf) ExpiresIn() time.Duration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpiresIn")
+	ret0, _ := ret[0].(time.Duration)
+	return ret0
+}
+
+// ExpiresIn indicates an expected call of ExpiresIn.
+func (mr *MockJWTItfMockRecorder) ExpiresIn() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpiresIn", reflect.TypeOf((*MockJWTItf)(nil).ExpiresIn))
+}
+
 // VerifyToken mocks base method.
 func (m *MockJWTItf) VerifyToken(tokenString string) (string, error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,99 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/refresh_token_repo.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/repository/refresh_token_repo.go -destination=test/usecase/refresh_token_repo_mock_test.go -package=usecase_test
+//
+
+// Package usecase_test is a generated GoMock package.
+package usecase_test
+
+import (
+	context "context"
+	reflect "reflect"
+
+	model "github.com/federicodosantos/image-smith/internal/model"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIRefreshTokenRepository is a mock of IRefreshTokenRepository interface.
+type MockIRefreshTokenRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIRefreshTokenRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockIRefreshTokenRepositoryMockRecorder is the mock recorder for MockIRefreshTokenRepository.
+type MockIRefreshTokenRepositoryMockRecorder struct {
+	mock *MockIRefreshTokenRepository
+}
+
+// NewMockIRefreshTokenRepository creates a new mock instance.
+func NewMockIRefreshTokenRepository(ctrl *gomock.Controller) *MockIRefreshTokenRepository {
+	mock := &MockIRefreshTokenRepository{ctrl: ctrl}
+	mock.recorder = &MockIRefreshTokenRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIRefreshTokenRepository) EXPECT() *MockIRefreshTokenRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateRefreshToken mocks base method.
+func (m *MockIRefreshTokenRepository) CreateRefreshToken(ctx context.Context, token *model.RefreshToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRefreshToken", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateRefreshToken indicates an expected call of CreateRefreshToken.
+func (mr *MockIRefreshTokenRepositoryMockRecorder) CreateRefreshToken(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRefreshToken", reflect.TypeOf((*MockIRefreshTokenRepository)(nil).CreateRefreshToken), ctx, token)
+}
+
+// GetRefreshTokenByHash mocks base method.
+func (m *MockIRefreshTokenRepository) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*model.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRefreshTokenByHash", ctx, tokenHash)
+	ret0, _ := ret[0].(*model.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRefreshTokenByHash indicates an expected call of GetRefreshTokenByHash.
+func (mr *MockIRefreshTokenRepositoryMockRecorder) GetRefreshTokenByHash(ctx, tokenHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefreshTokenByHash", reflect.TypeOf((*MockIRefreshTokenRepository)(nil).GetRefreshTokenByHash), ctx, tokenHash)
+}
+
+// RevokeTokenFamily mocks base method.
+func (m *MockIRefreshTokenRepository) RevokeTokenFamily(ctx context.Context, familyID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeTokenFamily", ctx, familyID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeTokenFamily indicates an expected call of RevokeTokenFamily.
+func (mr *MockIRefreshTokenRepositoryMockRecorder) RevokeTokenFamily(ctx, familyID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeTokenFamily", reflect.TypeOf((*MockIRefreshTokenRepository)(nil).RevokeTokenFamily), ctx, familyID)
+}
+
+// RotateRefreshToken mocks base method.
+func (m *MockIRefreshTokenRepository) RotateRefreshToken(ctx context.Context, oldID string, newToken *model.RefreshToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateRefreshToken", ctx, oldID, newToken)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RotateRefreshToken indicates an expected call of RotateRefreshToken.
+func (mr *MockIRefreshTokenRepositoryMockRecorder) RotateRefreshToken(ctx, oldID, newToken any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateRefreshToken", reflect.TypeOf((*MockIRefreshTokenRepository)(nil).RotateRefreshToken), ctx, oldID, newToken)
+}
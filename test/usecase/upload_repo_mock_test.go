@@ -0,0 +1,115 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/upload_repo.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/repository/upload_repo.go -destination=test/usecase/upload_repo_mock_test.go -package=usecase_test
+//
+
+// Package usecase_test is a generated GoMock package.
+package usecase_test
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	model "github.com/federicodosantos/image-smith/internal/model"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIUploadRepository is a mock of IUploadRepository interface.
+type MockIUploadRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIUploadRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockIUploadRepositoryMockRecorder is the mock recorder for MockIUploadRepository.
+type MockIUploadRepositoryMockRecorder struct {
+	mock *MockIUploadRepository
+}
+
+// NewMockIUploadRepository creates a new mock instance.
+func NewMockIUploadRepository(ctrl *gomock.Controller) *MockIUploadRepository {
+	mock := &MockIUploadRepository{ctrl: ctrl}
+	mock.recorder = &MockIUploadRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIUploadRepository) EXPECT() *MockIUploadRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AppendChunk mocks base method.
+func (m *MockIUploadRepository) AppendChunk(ctx context.Context, session *model.UploadSession, size int64, chunkKey string, expiresAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AppendChunk", ctx, session, size, chunkKey, expiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AppendChunk indicates an expected call of AppendChunk.
+func (mr *MockIUploadRepositoryMockRecorder) AppendChunk(ctx, session, size, chunkKey, expiresAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AppendChunk", reflect.TypeOf((*MockIUploadRepository)(nil).AppendChunk), ctx, session, size, chunkKey, expiresAt)
+}
+
+// CreateSession mocks base method.
+func (m *MockIUploadRepository) CreateSession(ctx context.Context, session *model.UploadSession) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSession", ctx, session)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateSession indicates an expected call of CreateSession.
+func (mr *MockIUploadRepositoryMockRecorder) CreateSession(ctx, session any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockIUploadRepository)(nil).CreateSession), ctx, session)
+}
+
+// DeleteSession mocks base method.
+func (m *MockIUploadRepository) DeleteSession(ctx context.Context, id, ownerID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSession", ctx, id, ownerID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSession indicates an expected call of DeleteSession.
+func (mr *MockIUploadRepositoryMockRecorder) DeleteSession(ctx, id, ownerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSession", reflect.TypeOf((*MockIUploadRepository)(nil).DeleteSession), ctx, id, ownerID)
+}
+
+// GetSession mocks base method.
+func (m *MockIUploadRepository) GetSession(ctx context.Context, id, ownerID string) (*model.UploadSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSession", ctx, id, ownerID)
+	ret0, _ := ret[0].(*model.UploadSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSession indicates an expected call of GetSession.
+func (mr *MockIUploadRepositoryMockRecorder) GetSession(ctx, id, ownerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockIUploadRepository)(nil).GetSession), ctx, id, ownerID)
+}
+
+// ListExpiredSessions mocks base method.
+func (m *MockIUploadRepository) ListExpiredSessions(ctx context.Context, now time.Time, limit int) ([]model.UploadSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListExpiredSessions", ctx, now, limit)
+	ret0, _ := ret[0].([]model.UploadSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListExpiredSessions indicates an expected call of ListExpiredSessions.
+func (mr *MockIUploadRepositoryMockRecorder) ListExpiredSessions(ctx, now, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExpiredSessions", reflect.TypeOf((*MockIUploadRepository)(nil).ListExpiredSessions), ctx, now, limit)
+}
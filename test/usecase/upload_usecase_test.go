@@ -0,0 +1,222 @@
+package usecase_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/federicodosantos/image-smith/internal/dto"
+	"github.com/federicodosantos/image-smith/internal/model"
+	"github.com/federicodosantos/image-smith/internal/usecase"
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	"github.com/federicodosantos/image-smith/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreateUpload(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockIUploadRepository(ctrl)
+	uploadUsecase := usecase.NewUploadUsecase(mockRepo, NewMockIImageUsecase(ctrl), NewMockStorage(ctrl), 1<<20, time.Hour)
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo.EXPECT().CreateSession(CTX, gomock.Any()).DoAndReturn(func(ctx context.Context, session *model.UploadSession) error {
+			assert.Equal(t, "user-id", session.OwnerID)
+			assert.Equal(t, "scan.tiff", session.Filename)
+			assert.Equal(t, int64(1000), session.Length)
+			assert.WithinDuration(t, time.Now().Add(time.Hour), session.ExpiresAt, time.Minute)
+			return nil
+		})
+
+		upload, err := uploadUsecase.Create(CTX, "user-id", &dto.UploadCreateRequest{Length: 1000, Filename: "scan.tiff"})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, upload.ID)
+		assert.Equal(t, int64(0), upload.Offset)
+	})
+
+	t.Run("Error - Longer than the limit", func(t *testing.T) {
+		upload, err := uploadUsecase.Create(CTX, "user-id", &dto.UploadCreateRequest{Length: 1<<20 + 1})
+		assert.ErrorIs(t, err, customErr.ErrPayloadTooLarge)
+		assert.Nil(t, upload)
+	})
+}
+
+func TestWriteChunk(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockIUploadRepository(ctrl)
+	mockStorage := NewMockStorage(ctrl)
+	uploadUsecase := usecase.NewUploadUsecase(mockRepo, NewMockIImageUsecase(ctrl), mockStorage, 0, time.Hour)
+
+	newSession := func() *model.UploadSession {
+		return &model.UploadSession{ID: "upload-id", OwnerID: "user-id", Length: 10, Offset: 4, ExpiresAt: time.Now().Add(time.Minute)}
+	}
+
+	type testCase struct {
+		name         string
+		offset       int64
+		body         string
+		mockBehavior func()
+		expectOffset int64
+		expectError  error
+	}
+
+	testCases := []testCase{
+		{
+			name:   "Success",
+			offset: 4,
+			body:   "abc",
+			mockBehavior: func() {
+				mockRepo.EXPECT().GetSession(CTX, "upload-id", "user-id").Return(newSession(), nil)
+				mockStorage.EXPECT().Put(CTX, gomock.Any(), gomock.Any(), "application/octet-stream").
+					DoAndReturn(func(ctx context.Context, key string, r io.Reader, contentType string) error {
+						assert.True(t, strings.HasPrefix(key, "user-id/uploads/upload-id/00000000000000000004-"))
+						data, err := io.ReadAll(r)
+						assert.Equal(t, "abc", string(data))
+						return err
+					})
+				mockRepo.EXPECT().AppendChunk(CTX, gomock.Any(), int64(3), gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, session *model.UploadSession, size int64, key string, expiresAt time.Time) error {
+						assert.True(t, expiresAt.After(time.Now().Add(time.Hour-time.Minute)))
+						session.Offset += size
+						return nil
+					})
+			},
+			expectOffset: 7,
+		},
+		{
+			name:   "Error - Offset behind the upload",
+			offset: 0,
+			body:   "abc",
+			mockBehavior: func() {
+				mockRepo.EXPECT().GetSession(CTX, "upload-id", "user-id").Return(newSession(), nil)
+			},
+			expectError: customErr.ErrUploadOffsetMismatch,
+		},
+		{
+			name:   "Error - Past the upload length",
+			offset: 4,
+			body:   "abcdefg",
+			mockBehavior: func() {
+				mockRepo.EXPECT().GetSession(CTX, "upload-id", "user-id").Return(newSession(), nil)
+				mockStorage.EXPECT().Put(CTX, gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(drainPut)
+				mockStorage.EXPECT().Delete(CTX, gomock.Any()).Return(nil)
+			},
+			expectError: customErr.ErrPayloadTooLarge,
+		},
+		{
+			name:   "Error - Concurrent chunk won",
+			offset: 4,
+			body:   "abc",
+			mockBehavior: func() {
+				mockRepo.EXPECT().GetSession(CTX, "upload-id", "user-id").Return(newSession(), nil)
+				mockStorage.EXPECT().Put(CTX, gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(drainPut)
+				mockRepo.EXPECT().AppendChunk(CTX, gomock.Any(), int64(3), gomock.Any(), gomock.Any()).Return(customErr.ErrUploadOffsetMismatch)
+				mockStorage.EXPECT().Delete(CTX, gomock.Any()).Return(nil)
+			},
+			expectError: customErr.ErrUploadOffsetMismatch,
+		},
+		{
+			name:   "Error - Upload not found",
+			offset: 4,
+			body:   "abc",
+			mockBehavior: func() {
+				mockRepo.EXPECT().GetSession(CTX, "upload-id", "user-id").Return(nil, customErr.ErrUploadNotFound)
+			},
+			expectError: customErr.ErrUploadNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mockBehavior()
+
+			upload, err := uploadUsecase.WriteChunk(CTX, "user-id", "upload-id", tc.offset, strings.NewReader(tc.body))
+			if tc.expectError != nil {
+				assert.ErrorIs(t, err, tc.expectError)
+				assert.Nil(t, upload)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectOffset, upload.Offset)
+		})
+	}
+}
+
+func TestFinalizeUpload(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockIUploadRepository(ctrl)
+	mockStorage := NewMockStorage(ctrl)
+	mockImageUsecase := NewMockIImageUsecase(ctrl)
+	uploadUsecase := usecase.NewUploadUsecase(mockRepo, mockImageUsecase, mockStorage, 0, time.Hour)
+
+	session := &model.UploadSession{ID: "upload-id", OwnerID: "user-id", Filename: "scan.tiff",
+		Length: 100, Offset: 100, ChunkKeys: []string{"chunk-0", "chunk-1", "chunk-2"}}
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo.EXPECT().GetSession(CTX, "upload-id", "user-id").Return(session, nil)
+		mockImageUsecase.EXPECT().Assemble(CTX, "user-id", "scan.tiff", session.ChunkKeys).
+			Return(&dto.ImageUploadResponse{ID: "image-id"}, nil)
+		mockStorage.EXPECT().List(CTX, "user-id/uploads/upload-id/").
+			Return([]storage.ObjectInfo{{Key: "chunk-0"}, {Key: "chunk-1"}, {Key: "chunk-2"}}, nil)
+		mockStorage.EXPECT().Delete(CTX, gomock.Any()).Return(nil).Times(3)
+		mockRepo.EXPECT().DeleteSession(CTX, "upload-id", "user-id").Return(nil)
+
+		image, err := uploadUsecase.Finalize(CTX, "user-id", "upload-id")
+		assert.NoError(t, err)
+		assert.Equal(t, "image-id", image.ID)
+	})
+
+	t.Run("Error - Chunks missing", func(t *testing.T) {
+		partial := *session
+		partial.Offset = 10
+		mockRepo.EXPECT().GetSession(CTX, "upload-id", "user-id").Return(&partial, nil)
+
+		image, err := uploadUsecase.Finalize(CTX, "user-id", "upload-id")
+		assert.ErrorIs(t, err, customErr.ErrUploadIncomplete)
+		assert.Nil(t, image)
+	})
+
+	t.Run("Error - Not an image", func(t *testing.T) {
+		mockRepo.EXPECT().GetSession(CTX, "upload-id", "user-id").Return(session, nil)
+		mockImageUsecase.EXPECT().Assemble(CTX, "user-id", "scan.tiff", session.ChunkKeys).Return(nil, customErr.ErrUnsupportedFileFormat)
+
+		image, err := uploadUsecase.Finalize(CTX, "user-id", "upload-id")
+		assert.ErrorIs(t, err, customErr.ErrUnsupportedFileFormat)
+		assert.Nil(t, image)
+	})
+}
+
+func TestExpireUploadSessions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockIUploadRepository(ctrl)
+	mockStorage := NewMockStorage(ctrl)
+	uploadUsecase := usecase.NewUploadUsecase(mockRepo, NewMockIImageUsecase(ctrl), mockStorage, 0, time.Hour)
+
+	expired := []model.UploadSession{
+		{ID: "first-id", OwnerID: "user-id"},
+		{ID: "second-id", OwnerID: "other-id"},
+	}
+
+	mockRepo.EXPECT().ListExpiredSessions(CTX, gomock.Any(), 100).Return(expired, nil)
+	mockStorage.EXPECT().List(CTX, "user-id/uploads/first-id/").Return([]storage.ObjectInfo{{Key: "user-id/uploads/first-id/chunk"}}, nil)
+	mockStorage.EXPECT().Delete(CTX, "user-id/uploads/first-id/chunk").Return(nil)
+	mockRepo.EXPECT().DeleteSession(CTX, "first-id", "user-id").Return(nil)
+	mockStorage.EXPECT().List(CTX, "other-id/uploads/second-id/").Return(nil, nil)
+	// finalized while being reaped
+	mockRepo.EXPECT().DeleteSession(CTX, "second-id", "other-id").Return(customErr.ErrUploadNotFound)
+
+	removed, err := uploadUsecase.ExpireSessions(CTX)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, removed)
+}
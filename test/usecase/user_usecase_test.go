@@ -2,6 +2,8 @@ package usecase_test
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"testing"
 	"time"
@@ -18,6 +20,8 @@ import (
 
 var CTX = context.TODO()
 
+var refreshTokenTTL = 24 * time.Hour
+
 func createUser() *model.User {
 	now := time.Now()
 	password := "Rahasia#123"
@@ -38,9 +42,10 @@ func TestRegister(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := NewMockIUserRepository(ctrl)
+	mockTokenRepo := NewMockIRefreshTokenRepository(ctrl)
 	mockJWT := NewMockJWTItf(ctrl)
 
-	userUsecase := usecase.NewUserUsecase(mockRepo, mockJWT)
+	userUsecase := usecase.NewUserUsecase(mockRepo, mockTokenRepo, mockJWT, refreshTokenTTL)
 
 	type testCase struct {
 		name             string
@@ -137,9 +142,10 @@ func TestLogin(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := NewMockIUserRepository(ctrl)
+	mockTokenRepo := NewMockIRefreshTokenRepository(ctrl)
 	mockJWT := NewMockJWTItf(ctrl)
 
-	userUsecase := usecase.NewUserUsecase(mockRepo, mockJWT)
+	userUsecase := usecase.NewUserUsecase(mockRepo, mockTokenRepo, mockJWT, refreshTokenTTL)
 
 	type testCase struct {
 		name             string
@@ -163,6 +169,14 @@ func TestLogin(t *testing.T) {
 					GetUserByEmail(CTX, "jamalunyu@gmail.com").
 					Return(user, nil)
 
+				mockTokenRepo.EXPECT().CreateRefreshToken(CTX, gomock.Any()).
+					DoAndReturn(func(ctx context.Context, token *model.RefreshToken) error {
+						assert.Equal(t, user.ID, token.UserID)
+						assert.NotEmpty(t, token.FamilyID)
+						assert.Len(t, token.TokenHash, 64)
+						return nil
+					})
+
 				mockJWT.EXPECT().CreateToken(user.ID).Return("jwt-token", nil)
 			},
 			expectedResponse: &dto.UserLoginResponse{
@@ -170,6 +184,20 @@ func TestLogin(t *testing.T) {
 			},
 			expectError: nil,
 		},
+		{
+			name: "Failed - Incorrect password",
+			input: &dto.UserLoginRequest{
+				Email:    "jamalunyu@gmail.com",
+				Password: "Salah#1234",
+			},
+			mockBehavior: func(mockRepo *MockIUserRepository, mockJWT *MockJWTItf) {
+				mockRepo.EXPECT().
+					GetUserByEmail(CTX, "jamalunyu@gmail.com").
+					Return(user, nil)
+			},
+			expectedResponse: nil,
+			expectError:      customErr.ErrIncorrectPassword,
+		},
 		{
 			name: "Failed - Email not found",
 			input: &dto.UserLoginRequest{
@@ -194,11 +222,120 @@ func TestLogin(t *testing.T) {
 			response, err := userUsecase.Login(ctx, tc.input)
 
 			if tc.expectError != nil {
-				assert.Error(t, err)
+				assert.ErrorIs(t, err, tc.expectError)
 			} else {
 				assert.NoError(t, err)
 				assert.NotNil(t, response)
 				assert.NotEmpty(t, response.JWTToken)
+				assert.NotEmpty(t, response.RefreshToken)
+			}
+		})
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockIUserRepository(ctrl)
+	mockTokenRepo := NewMockIRefreshTokenRepository(ctrl)
+	mockJWT := NewMockJWTItf(ctrl)
+
+	userUsecase := usecase.NewUserUsecase(mockRepo, mockTokenRepo, mockJWT, refreshTokenTTL)
+
+	plainToken := "plain-refresh-token"
+	sum := sha256.Sum256([]byte(plainToken))
+	tokenHash := hex.EncodeToString(sum[:])
+
+	revokedAt := time.Now().Add(-time.Minute)
+
+	activeToken := func() *model.RefreshToken {
+		return &model.RefreshToken{
+			ID:        "token-id",
+			UserID:    "user-id",
+			FamilyID:  "family-id",
+			TokenHash: tokenHash,
+			ExpiresAt: time.Now().Add(time.Hour),
+		}
+	}
+
+	type testCase struct {
+		name         string
+		input        *dto.RefreshTokenRequest
+		mockBehavior func()
+		expectError  error
+	}
+
+	testCases := []testCase{
+		{
+			name:  "Success - Rotate refresh token",
+			input: &dto.RefreshTokenRequest{RefreshToken: plainToken},
+			mockBehavior: func() {
+				mockTokenRepo.EXPECT().GetRefreshTokenByHash(CTX, tokenHash).Return(activeToken(), nil)
+				mockTokenRepo.EXPECT().RotateRefreshToken(CTX, "token-id", gomock.Any()).
+					DoAndReturn(func(ctx context.Context, oldID string, token *model.RefreshToken) error {
+						assert.Equal(t, "family-id", token.FamilyID)
+						assert.Equal(t, "user-id", token.UserID)
+						assert.NotEqual(t, tokenHash, token.TokenHash)
+						return nil
+					})
+				mockJWT.EXPECT().CreateToken("user-id").Return("jwt-token", nil)
+			},
+			expectError: nil,
+		},
+		{
+			name:  "Failed - Reused token revokes family",
+			input: &dto.RefreshTokenRequest{RefreshToken: plainToken},
+			mockBehavior: func() {
+				token := activeToken()
+				token.RevokedAt = &revokedAt
+				mockTokenRepo.EXPECT().GetRefreshTokenByHash(CTX, tokenHash).Return(token, nil)
+				mockTokenRepo.EXPECT().RevokeTokenFamily(CTX, "family-id").Return(nil)
+			},
+			expectError: customErr.ErrRefreshTokenReused,
+		},
+		{
+			name:  "Failed - Concurrent rotation revokes family",
+			input: &dto.RefreshTokenRequest{RefreshToken: plainToken},
+			mockBehavior: func() {
+				mockTokenRepo.EXPECT().GetRefreshTokenByHash(CTX, tokenHash).Return(activeToken(), nil)
+				mockTokenRepo.EXPECT().RotateRefreshToken(CTX, "token-id", gomock.Any()).Return(customErr.ErrRefreshTokenReused)
+				mockTokenRepo.EXPECT().RevokeTokenFamily(CTX, "family-id").Return(nil)
+			},
+			expectError: customErr.ErrRefreshTokenReused,
+		},
+		{
+			name:  "Failed - Expired token",
+			input: &dto.RefreshTokenRequest{RefreshToken: plainToken},
+			mockBehavior: func() {
+				token := activeToken()
+				token.ExpiresAt = time.Now().Add(-time.Second)
+				mockTokenRepo.EXPECT().GetRefreshTokenByHash(CTX, tokenHash).Return(token, nil)
+			},
+			expectError: customErr.ErrInvalidRefreshToken,
+		},
+		{
+			name:         "Failed - Empty token",
+			input:        &dto.RefreshTokenRequest{},
+			mockBehavior: func() {},
+			expectError:  customErr.ErrInvalidRefreshToken,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mockBehavior()
+
+			response, err := userUsecase.Refresh(CTX, tc.input)
+
+			if tc.expectError != nil {
+				assert.ErrorIs(t, err, tc.expectError)
+				assert.Nil(t, response)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "jwt-token", response.JWTToken)
+				assert.NotEmpty(t, response.RefreshToken)
+				assert.NotEqual(t, plainToken, response.RefreshToken)
 			}
 		})
 	}
@@ -0,0 +1,85 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/watermark_repo.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/repository/watermark_repo.go -destination=test/usecase/watermark_repo_mock_test.go -package=usecase_test
+//
+
+// Package usecase_test is a generated GoMock package.
+package usecase_test
+
+import (
+	context "context"
+	reflect "reflect"
+
+	model "github.com/federicodosantos/image-smith/internal/model"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIWatermarkRepository is a mock of IWatermarkRepository interface.
+type MockIWatermarkRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIWatermarkRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockIWatermarkRepositoryMockRecorder is the mock recorder for MockIWatermarkRepository.
+type MockIWatermarkRepositoryMockRecorder struct {
+	mock *MockIWatermarkRepository
+}
+
+// NewMockIWatermarkRepository creates a new mock instance.
+func NewMockIWatermarkRepository(ctrl *gomock.Controller) *MockIWatermarkRepository {
+	mock := &MockIWatermarkRepository{ctrl: ctrl}
+	mock.recorder = &MockIWatermarkRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIWatermarkRepository) EXPECT() *MockIWatermarkRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteSettings mocks base method.
+func (m *MockIWatermarkRepository) DeleteSettings(ctx context.Context, ownerID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSettings", ctx, ownerID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSettings indicates an expected call of DeleteSettings.
+func (mr *MockIWatermarkRepositoryMockRecorder) DeleteSettings(ctx, ownerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSettings", reflect.TypeOf((*MockIWatermarkRepository)(nil).DeleteSettings), ctx, ownerID)
+}
+
+// GetSettings mocks base method.
+func (m *MockIWatermarkRepository) GetSettings(ctx context.Context, ownerID string) (*model.WatermarkSettings, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSettings", ctx, ownerID)
+	ret0, _ := ret[0].(*model.WatermarkSettings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSettings indicates an expected call of GetSettings.
+func (mr *MockIWatermarkRepositoryMockRecorder) GetSettings(ctx, ownerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSettings", reflect.TypeOf((*MockIWatermarkRepository)(nil).GetSettings), ctx, ownerID)
+}
+
+// SaveSettings mocks base method.
+func (m *MockIWatermarkRepository) SaveSettings(ctx context.Context, settings *model.WatermarkSettings) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSettings", ctx, settings)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSettings indicates an expected call of SaveSettings.
+func (mr *MockIWatermarkRepositoryMockRecorder) SaveSettings(ctx, settings any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSettings", reflect.TypeOf((*MockIWatermarkRepository)(nil).SaveSettings), ctx, settings)
+}
@@ -0,0 +1,105 @@
+package usecase_test
+
+import (
+	"testing"
+
+	"github.com/federicodosantos/image-smith/internal/dto"
+	"github.com/federicodosantos/image-smith/internal/model"
+	"github.com/federicodosantos/image-smith/internal/usecase"
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	"github.com/federicodosantos/image-smith/pkg/validator"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSaveWatermark(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWatermarkRepo := NewMockIWatermarkRepository(ctrl)
+	mockImageRepo := NewMockIImageRepository(ctrl)
+
+	watermarkUsecase := usecase.NewWatermarkUsecase(mockWatermarkRepo, mockImageRepo)
+
+	tile := true
+
+	type testCase struct {
+		name         string
+		input        *dto.WatermarkRequest
+		mockBehavior func()
+		expectFields []string
+	}
+
+	testCases := []testCase{
+		{
+			name:  "Success - Save text watermark",
+			input: &dto.WatermarkRequest{Text: "© ImageSmith", Color: "#fff", Opacity: 0.4, Tile: &tile},
+			mockBehavior: func() {
+				mockWatermarkRepo.EXPECT().SaveSettings(CTX, gomock.Any()).DoAndReturn(func(_ any, settings *model.WatermarkSettings) error {
+					assert.Equal(t, "owner-id", settings.OwnerID)
+					assert.Nil(t, settings.ImageID)
+					assert.Equal(t, "© ImageSmith", settings.Text)
+					assert.True(t, settings.Tile)
+					return nil
+				})
+			},
+		},
+		{
+			name:  "Success - Save own image watermark",
+			input: &dto.WatermarkRequest{ImageID: "mark-id", Gravity: "southwest"},
+			mockBehavior: func() {
+				mockImageRepo.EXPECT().GetImageById(CTX, "mark-id", "owner-id").Return(&model.Image{ID: "mark-id"}, nil)
+				mockWatermarkRepo.EXPECT().SaveSettings(CTX, gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:         "Failed - Both image and text",
+			input:        &dto.WatermarkRequest{ImageID: "mark-id", Text: "ImageSmith"},
+			mockBehavior: func() { mockImageRepo.EXPECT().GetImageById(CTX, "mark-id", "owner-id").Return(&model.Image{}, nil) },
+			expectFields: []string{"image_id"},
+		},
+		{
+			name:  "Failed - Image of another user and invalid color",
+			input: &dto.WatermarkRequest{ImageID: "mark-id", Color: "blue-ish"},
+			mockBehavior: func() {
+				mockImageRepo.EXPECT().GetImageById(CTX, "mark-id", "owner-id").Return(nil, customErr.ErrImageNotFound)
+			},
+			expectFields: []string{"color", "image_id"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mockBehavior()
+
+			settings, err := watermarkUsecase.Save(CTX, "owner-id", tc.input)
+
+			if tc.expectFields != nil {
+				var fieldErrs validator.Errors
+				assert.ErrorAs(t, err, &fieldErrs)
+				for _, field := range tc.expectFields {
+					assert.Contains(t, fieldErrs, field)
+				}
+				assert.Nil(t, settings)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, settings)
+			}
+		})
+	}
+}
+
+func TestGetWatermark(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWatermarkRepo := NewMockIWatermarkRepository(ctrl)
+
+	watermarkUsecase := usecase.NewWatermarkUsecase(mockWatermarkRepo, NewMockIImageRepository(ctrl))
+
+	mockWatermarkRepo.EXPECT().GetSettings(CTX, "owner-id").Return(nil, customErr.ErrWatermarkNotFound)
+
+	settings, err := watermarkUsecase.Get(CTX, "owner-id")
+	assert.ErrorIs(t, err, customErr.ErrWatermarkNotFound)
+	assert.Nil(t, settings)
+}
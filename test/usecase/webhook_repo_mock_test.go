@@ -0,0 +1,203 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/webhook_repo.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/repository/webhook_repo.go -destination=test/usecase/webhook_repo_mock_test.go -package=usecase_test
+//
+
+// Package usecase_test is a generated GoMock package.
+package usecase_test
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	model "github.com/federicodosantos/image-smith/internal/model"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIWebhookRepository is a mock of IWebhookRepository interface.
+type MockIWebhookRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIWebhookRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockIWebhookRepositoryMockRecorder is the mock recorder for MockIWebhookRepository.
+type MockIWebhookRepositoryMockRecorder struct {
+	mock *MockIWebhookRepository
+}
+
+// NewMockIWebhookRepository creates a new mock instance.
+func NewMockIWebhookRepository(ctrl *gomock.Controller) *MockIWebhookRepository {
+	mock := &MockIWebhookRepository{ctrl: ctrl}
+	mock.recorder = &MockIWebhookRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIWebhookRepository) EXPECT() *MockIWebhookRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ClaimDelivery mocks base method.
+func (m *MockIWebhookRepository) ClaimDelivery(ctx context.Context, lease time.Duration) (*model.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDelivery", ctx, lease)
+	ret0, _ := ret[0].(*model.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDelivery indicates an expected call of ClaimDelivery.
+func (mr *MockIWebhookRepositoryMockRecorder) ClaimDelivery(ctx, lease any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDelivery", reflect.TypeOf((*MockIWebhookRepository)(nil).ClaimDelivery), ctx, lease)
+}
+
+// CreateDelivery mocks base method.
+func (m *MockIWebhookRepository) CreateDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDelivery", ctx, delivery)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateDelivery indicates an expected call of CreateDelivery.
+func (mr *MockIWebhookRepositoryMockRecorder) CreateDelivery(ctx, delivery any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDelivery", reflect.TypeOf((*MockIWebhookRepository)(nil).CreateDelivery), ctx, delivery)
+}
+
+// CreateWebhook mocks base method.
+func (m *MockIWebhookRepository) CreateWebhook(ctx context.Context, webhook *model.Webhook) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhook", ctx, webhook)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook.
+func (mr *MockIWebhookRepositoryMockRecorder) CreateWebhook(ctx, webhook any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MockIWebhookRepository)(nil).CreateWebhook), ctx, webhook)
+}
+
+// DeleteWebhook mocks base method.
+func (m *MockIWebhookRepository) DeleteWebhook(ctx context.Context, id, ownerID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", ctx, id, ownerID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockIWebhookRepositoryMockRecorder) DeleteWebhook(ctx, id, ownerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockIWebhookRepository)(nil).DeleteWebhook), ctx, id, ownerID)
+}
+
+// FailAbandonedDeliveries mocks base method.
+func (m *MockIWebhookRepository) FailAbandonedDeliveries(ctx context.Context, lastError string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailAbandonedDeliveries", ctx, lastError)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FailAbandonedDeliveries indicates an expected call of FailAbandonedDeliveries.
+func (mr *MockIWebhookRepositoryMockRecorder) FailAbandonedDeliveries(ctx, lastError any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailAbandonedDeliveries", reflect.TypeOf((*MockIWebhookRepository)(nil).FailAbandonedDeliveries), ctx, lastError)
+}
+
+// GetDeliveryById mocks base method.
+func (m *MockIWebhookRepository) GetDeliveryById(ctx context.Context, id, webhookID, ownerID string) (*model.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeliveryById", ctx, id, webhookID, ownerID)
+	ret0, _ := ret[0].(*model.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeliveryById indicates an expected call of GetDeliveryById.
+func (mr *MockIWebhookRepositoryMockRecorder) GetDeliveryById(ctx, id, webhookID, ownerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeliveryById", reflect.TypeOf((*MockIWebhookRepository)(nil).GetDeliveryById), ctx, id, webhookID, ownerID)
+}
+
+// GetWebhookById mocks base method.
+func (m *MockIWebhookRepository) GetWebhookById(ctx context.Context, id, ownerID string) (*model.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookById", ctx, id, ownerID)
+	ret0, _ := ret[0].(*model.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookById indicates an expected call of GetWebhookById.
+func (mr *MockIWebhookRepositoryMockRecorder) GetWebhookById(ctx, id, ownerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookById", reflect.TypeOf((*MockIWebhookRepository)(nil).GetWebhookById), ctx, id, ownerID)
+}
+
+// ListDeliveries mocks base method.
+func (m *MockIWebhookRepository) ListDeliveries(ctx context.Context, webhookID, ownerID string) ([]model.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeliveries", ctx, webhookID, ownerID)
+	ret0, _ := ret[0].([]model.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeliveries indicates an expected call of ListDeliveries.
+func (mr *MockIWebhookRepositoryMockRecorder) ListDeliveries(ctx, webhookID, ownerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeliveries", reflect.TypeOf((*MockIWebhookRepository)(nil).ListDeliveries), ctx, webhookID, ownerID)
+}
+
+// ListWebhooks mocks base method.
+func (m *MockIWebhookRepository) ListWebhooks(ctx context.Context, ownerID string) ([]model.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhooks", ctx, ownerID)
+	ret0, _ := ret[0].([]model.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhooks indicates an expected call of ListWebhooks.
+func (mr *MockIWebhookRepositoryMockRecorder) ListWebhooks(ctx, ownerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhooks", reflect.TypeOf((*MockIWebhookRepository)(nil).ListWebhooks), ctx, ownerID)
+}
+
+// ListWebhooksForEvent mocks base method.
+func (m *MockIWebhookRepository) ListWebhooksForEvent(ctx context.Context, ownerID, event string) ([]model.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhooksForEvent", ctx, ownerID, event)
+	ret0, _ := ret[0].([]model.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhooksForEvent indicates an expected call of ListWebhooksForEvent.
+func (mr *MockIWebhookRepositoryMockRecorder) ListWebhooksForEvent(ctx, ownerID, event any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhooksForEvent", reflect.TypeOf((*MockIWebhookRepository)(nil).ListWebhooksForEvent), ctx, ownerID, event)
+}
+
+// UpdateDelivery mocks base method.
+func (m *MockIWebhookRepository) UpdateDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDelivery", ctx, delivery)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateDelivery indicates an expected call of UpdateDelivery.
+func (mr *MockIWebhookRepositoryMockRecorder) UpdateDelivery(ctx, delivery any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDelivery", reflect.TypeOf((*MockIWebhookRepository)(nil).UpdateDelivery), ctx, delivery)
+}
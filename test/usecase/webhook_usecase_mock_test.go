@@ -0,0 +1,168 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/usecase/webhook_usecase.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/usecase/webhook_usecase.go -destination=test/usecase/webhook_usecase_mock_test.go -package=usecase_test
+//
+
+// Package usecase_test is a generated GoMock package.
+package usecase_test
+
+import (
+	context "context"
+	reflect "reflect"
+
+	dto "github.com/federicodosantos/image-smith/internal/dto"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockEventPublisher is a mock of EventPublisher interface.
+type MockEventPublisher struct {
+	ctrl     *gomock.Controller
+	recorder *MockEventPublisherMockRecorder
+	isgomock struct{}
+}
+
+// MockEventPublisherMockRecorder is the mock recorder for MockEventPublisher.
+type MockEventPublisherMockRecorder struct {
+	mock *MockEventPublisher
+}
+
+// NewMockEventPublisher creates a new mock instance.
+func NewMockEventPublisher(ctrl *gomock.Controller) *MockEventPublisher {
+	mock := &MockEventPublisher{ctrl: ctrl}
+	mock.recorder = &MockEventPublisherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEventPublisher) EXPECT() *MockEventPublisherMockRecorder {
+	return m.recorder
+}
+
+// Publish mocks base method.
+func (m *MockEventPublisher) Publish(ctx context.Context, ownerID, event string, data any) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", ctx, ownerID, event, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockEventPublisherMockRecorder) Publish(ctx, ownerID, event, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockEventPublisher)(nil).Publish), ctx, ownerID, event, data)
+}
+
+// MockIWebhookUsecase is a mock of IWebhookUsecase interface.
+type MockIWebhookUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockIWebhookUsecaseMockRecorder
+	isgomock struct{}
+}
+
+// MockIWebhookUsecaseMockRecorder is the mock recorder for MockIWebhookUsecase.
+type MockIWebhookUsecaseMockRecorder struct {
+	mock *MockIWebhookUsecase
+}
+
+// NewMockIWebhookUsecase creates a new mock instance.
+func NewMockIWebhookUsecase(ctrl *gomock.Controller) *MockIWebhookUsecase {
+	mock := &MockIWebhookUsecase{ctrl: ctrl}
+	mock.recorder = &MockIWebhookUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIWebhookUsecase) EXPECT() *MockIWebhookUsecaseMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockIWebhookUsecase) Create(ctx context.Context, userID string, req *dto.WebhookRequest) (*dto.WebhookResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, userID, req)
+	ret0, _ := ret[0].(*dto.WebhookResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockIWebhookUsecaseMockRecorder) Create(ctx, userID, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockIWebhookUsecase)(nil).Create), ctx, userID, req)
+}
+
+// Delete mocks base method.
+func (m *MockIWebhookUsecase) Delete(ctx context.Context, userID, webhookID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, userID, webhookID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockIWebhookUsecaseMockRecorder) Delete(ctx, userID, webhookID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockIWebhookUsecase)(nil).Delete), ctx, userID, webhookID)
+}
+
+// List mocks base method.
+func (m *MockIWebhookUsecase) List(ctx context.Context, userID string) ([]*dto.WebhookResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, userID)
+	ret0, _ := ret[0].([]*dto.WebhookResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockIWebhookUsecaseMockRecorder) List(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockIWebhookUsecase)(nil).List), ctx, userID)
+}
+
+// ListDeliveries mocks base method.
+func (m *MockIWebhookUsecase) ListDeliveries(ctx context.Context, userID, webhookID string) ([]*dto.WebhookDeliveryResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeliveries", ctx, userID, webhookID)
+	ret0, _ := ret[0].([]*dto.WebhookDeliveryResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeliveries indicates an expected call of ListDeliveries.
+func (mr *MockIWebhookUsecaseMockRecorder) ListDeliveries(ctx, userID, webhookID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeliveries", reflect.TypeOf((*MockIWebhookUsecase)(nil).ListDeliveries), ctx, userID, webhookID)
+}
+
+// Publish mocks base method.
+func (m *MockIWebhookUsecase) Publish(ctx context.Context, ownerID, event string, data any) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", ctx, ownerID, event, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockIWebhookUsecaseMockRecorder) Publish(ctx, ownerID, event, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockIWebhookUsecase)(nil).Publish), ctx, ownerID, event, data)
+}
+
+// Replay mocks base method.
+func (m *MockIWebhookUsecase) Replay(ctx context.Context, userID, webhookID, deliveryID string) (*dto.WebhookDeliveryResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Replay", ctx, userID, webhookID, deliveryID)
+	ret0, _ := ret[0].(*dto.WebhookDeliveryResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Replay indicates an expected call of Replay.
+func (mr *MockIWebhookUsecaseMockRecorder) Replay(ctx, userID, webhookID, deliveryID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Replay", reflect.TypeOf((*MockIWebhookUsecase)(nil).Replay), ctx, userID, webhookID, deliveryID)
+}
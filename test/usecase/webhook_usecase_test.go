@@ -0,0 +1,124 @@
+package usecase_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/federicodosantos/image-smith/internal/dto"
+	"github.com/federicodosantos/image-smith/internal/model"
+	"github.com/federicodosantos/image-smith/internal/usecase"
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	"github.com/federicodosantos/image-smith/pkg/validator"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreateWebhook(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWebhookRepo := NewMockIWebhookRepository(ctrl)
+
+	webhookUsecase := usecase.NewWebhookUsecase(mockWebhookRepo, 8)
+
+	t.Run("Success - Secret is returned once", func(t *testing.T) {
+		mockWebhookRepo.EXPECT().CreateWebhook(CTX, gomock.Any()).DoAndReturn(func(_ any, webhook *model.Webhook) error {
+			assert.Equal(t, "owner-id", webhook.OwnerID)
+			assert.Equal(t, []string{model.EventImageDeleted, model.EventImageUploaded}, []string(webhook.Events))
+			assert.True(t, strings.HasPrefix(webhook.Secret, "whsec_"))
+			return nil
+		})
+
+		webhook, err := webhookUsecase.Create(CTX, "owner-id", &dto.WebhookRequest{
+			URL:    "https://example.com/hooks",
+			Events: []string{model.EventImageUploaded, model.EventImageDeleted, model.EventImageUploaded},
+		})
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(webhook.Secret, "whsec_"))
+	})
+
+	t.Run("Failed - Unknown event", func(t *testing.T) {
+		webhook, err := webhookUsecase.Create(CTX, "owner-id", &dto.WebhookRequest{
+			URL:    "https://example.com/hooks",
+			Events: []string{"image.exploded"},
+		})
+
+		var fieldErrs validator.Errors
+		assert.ErrorAs(t, err, &fieldErrs)
+		assert.Contains(t, fieldErrs, "events")
+		assert.Nil(t, webhook)
+	})
+}
+
+func TestPublish(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWebhookRepo := NewMockIWebhookRepository(ctrl)
+
+	webhookUsecase := usecase.NewWebhookUsecase(mockWebhookRepo, 8)
+
+	t.Run("Success - One delivery per subscribed webhook", func(t *testing.T) {
+		mockWebhookRepo.EXPECT().ListWebhooksForEvent(CTX, "owner-id", model.EventImageUploaded).
+			Return([]model.Webhook{{ID: "first"}, {ID: "second"}}, nil)
+		mockWebhookRepo.EXPECT().CreateDelivery(CTX, gomock.Any()).DoAndReturn(func(_ any, delivery *model.WebhookDelivery) error {
+			var event dto.WebhookEvent
+			assert.NoError(t, json.Unmarshal(delivery.Payload, &event))
+			assert.Equal(t, model.EventImageUploaded, event.Type)
+			assert.Equal(t, map[string]any{"id": "image-id"}, event.Data)
+			assert.Equal(t, model.DeliveryStatusPending, delivery.Status)
+			assert.Equal(t, 8, delivery.MaxAttempts)
+			return nil
+		}).Times(2)
+
+		err := webhookUsecase.Publish(CTX, "owner-id", model.EventImageUploaded, map[string]string{"id": "image-id"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Success - No subscribers", func(t *testing.T) {
+		mockWebhookRepo.EXPECT().ListWebhooksForEvent(CTX, "owner-id", model.EventJobFailed).Return(nil, nil)
+
+		assert.NoError(t, webhookUsecase.Publish(CTX, "owner-id", model.EventJobFailed, nil))
+	})
+}
+
+func TestReplay(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWebhookRepo := NewMockIWebhookRepository(ctrl)
+
+	webhookUsecase := usecase.NewWebhookUsecase(mockWebhookRepo, 8)
+
+	t.Run("Success - Queues a copy of the delivery", func(t *testing.T) {
+		original := &model.WebhookDelivery{
+			ID:        "delivery-id",
+			WebhookID: "webhook-id",
+			Event:     model.EventImageDeleted,
+			Payload:   []byte(`{"type":"image.deleted"}`),
+			Status:    model.DeliveryStatusFailed,
+		}
+
+		mockWebhookRepo.EXPECT().GetDeliveryById(CTX, "delivery-id", "webhook-id", "owner-id").Return(original, nil)
+		mockWebhookRepo.EXPECT().CreateDelivery(CTX, gomock.Any()).DoAndReturn(func(_ any, delivery *model.WebhookDelivery) error {
+			assert.NotEqual(t, original.ID, delivery.ID)
+			assert.Equal(t, original.Payload, delivery.Payload)
+			return nil
+		})
+
+		delivery, err := webhookUsecase.Replay(CTX, "owner-id", "webhook-id", "delivery-id")
+		assert.NoError(t, err)
+		assert.Equal(t, model.DeliveryStatusPending, delivery.Status)
+		assert.Equal(t, model.EventImageDeleted, delivery.Event)
+	})
+
+	t.Run("Failed - Delivery of another user", func(t *testing.T) {
+		mockWebhookRepo.EXPECT().GetDeliveryById(CTX, "delivery-id", "webhook-id", "intruder-id").
+			Return(nil, customErr.ErrWebhookDeliveryNotFound)
+
+		delivery, err := webhookUsecase.Replay(CTX, "intruder-id", "webhook-id", "delivery-id")
+		assert.ErrorIs(t, err, customErr.ErrWebhookDeliveryNotFound)
+		assert.Nil(t, delivery)
+	})
+}
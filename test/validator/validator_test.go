@@ -0,0 +1,194 @@
+package validator_test
+
+import (
+	"testing"
+
+	"github.com/federicodosantos/image-smith/internal/dto"
+	"github.com/federicodosantos/image-smith/pkg/validator"
+	"github.com/stretchr/testify/assert"
+)
+
+type address struct {
+	City string `json:"city" validate:"required"`
+}
+
+type request struct {
+	Name     string   `json:"name" validate:"required,min=2,max=5"`
+	Email    string   `json:"email" validate:"omitempty,email"`
+	Website  string   `json:"website" validate:"omitempty,url"`
+	Password string   `json:"password" validate:"password"`
+	Confirm  string   `json:"confirm" validate:"eqfield=Password"`
+	Format   string   `json:"format" validate:"omitempty,oneof=png jpeg"`
+	Age      int      `json:"age" validate:"omitempty,min=18"`
+	Address  *address `json:"address"`
+}
+
+func TestStruct(t *testing.T) {
+	type testCase struct {
+		name           string
+		input          any
+		expectedFields map[string]int
+	}
+
+	testCases := []testCase{
+		{
+			name: "Success - Valid request",
+			input: &request{
+				Name:     "Jamal",
+				Email:    "jamal@gmail.com",
+				Website:  "https://jamal.dev/hooks",
+				Password: "Rahasia#123",
+				Confirm:  "Rahasia#123",
+				Format:   "PNG",
+				Age:      20,
+				Address:  &address{City: "Malang"},
+			},
+			expectedFields: nil,
+		},
+		{
+			name: "Failed - Collects every field error",
+			input: &request{
+				Name:     "Jamaludin",
+				Email:    "Jamal <jamal@gmail.com>",
+				Website:  "ftp://jamal.dev",
+				Password: "rahasia",
+				Confirm:  "other",
+				Format:   "bmp",
+				Age:      12,
+				Address:  &address{},
+			},
+			expectedFields: map[string]int{
+				"name":         1,
+				"email":        1,
+				"website":      1,
+				"password":     4,
+				"confirm":      1,
+				"format":       1,
+				"age":          1,
+				"address.city": 1,
+			},
+		},
+		{
+			name:           "Failed - Missing required field",
+			input:          request{Password: "Rahasia#123", Confirm: "Rahasia#123"},
+			expectedFields: map[string]int{"name": 1},
+		},
+		{
+			name:           "Failed - Nil body",
+			input:          (*request)(nil),
+			expectedFields: map[string]int{"body": 1},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := validator.Struct(tc.input)
+
+			if tc.expectedFields == nil {
+				assert.Nil(t, errs)
+				return
+			}
+
+			assert.Len(t, errs, len(tc.expectedFields))
+			for field, count := range tc.expectedFields {
+				assert.Len(t, errs[field], count, field)
+			}
+		})
+	}
+}
+
+type brokenRequest struct {
+	Name  string `json:"name" validate:"required,slug"`
+	Count int    `json:"count" validate:"max=ten"`
+}
+
+func TestStructZeroValues(t *testing.T) {
+	type counts struct {
+		Plain    int  `json:"plain" validate:"min=1"`
+		Optional int  `json:"optional" validate:"omitempty,min=1"`
+		Required int  `json:"required" validate:"required,min=1"`
+		Pointer  *int `json:"pointer" validate:"min=1"`
+	}
+
+	zero := 0
+	errs := validator.Struct(&counts{})
+	assert.Equal(t, validator.Errors{
+		"plain":    {"must be at least 1"},
+		"required": {"is required"},
+	}, errs)
+
+	errs = validator.Struct(&counts{Plain: 1, Required: 1, Pointer: &zero})
+	assert.Equal(t, validator.Errors{"pointer": {"must be at least 1"}}, errs)
+}
+
+func TestStructWithBrokenTags(t *testing.T) {
+	errs := validator.Struct(&brokenRequest{Name: "jamal", Count: 3})
+
+	assert.Equal(t, validator.Errors{
+		"name":  {"cannot be validated"},
+		"count": {"cannot be validated"},
+	}, errs)
+}
+
+func TestCheck(t *testing.T) {
+	type nested struct {
+		Inner *struct {
+			Mode string `json:"mode" validate:"oneof="`
+		} `json:"inner"`
+	}
+
+	type testCase struct {
+		name        string
+		input       any
+		expectError string
+	}
+
+	testCases := []testCase{
+		{name: "Success - Supported rules", input: &request{}},
+		{name: "Failed - Unknown rule", input: brokenRequest{}, expectError: `validator: unknown rule "slug" on field name`},
+		{name: "Failed - Malformed bound", input: struct {
+			Count int `json:"count" validate:"max=ten"`
+		}{}, expectError: `validator: invalid max parameter "ten" on field count`},
+		{name: "Failed - Unknown eqfield", input: struct {
+			Confirm string `json:"confirm" validate:"eqfield=Password"`
+		}{}, expectError: `validator: eqfield on field confirm names unknown field "Password"`},
+		{name: "Failed - Nested struct", input: nested{}, expectError: "validator: oneof on field inner.mode has no options"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validator.Check(tc.input)
+
+			if tc.expectError == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.EqualError(t, err, tc.expectError)
+		})
+	}
+}
+
+// TestRequestTags keeps a typo in a request DTO from reaching a handler.
+func TestRequestTags(t *testing.T) {
+	requests := []any{
+		dto.ImageUploadRequest{},
+		dto.ImageUploadURLRequest{},
+		dto.ImageTransformRequest{},
+		dto.FocalPointRequest{},
+		dto.SimilarImagesRequest{},
+		dto.ImageListRequest{},
+		dto.ImageRenderRequest{},
+		dto.SignedURLRequest{},
+		dto.UploadCreateRequest{},
+		dto.UserRegisterRequest{},
+		dto.UserLoginRequest{},
+		dto.RefreshTokenRequest{},
+		dto.WatermarkRequest{},
+		dto.WebhookRequest{},
+	}
+
+	for _, request := range requests {
+		assert.NoError(t, validator.Check(request), "%T", request)
+	}
+}
@@ -0,0 +1,49 @@
+package webhook_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	"github.com/federicodosantos/image-smith/pkg/webhook"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsPublic(t *testing.T) {
+	testCases := map[string]bool{
+		"93.184.216.34":          true,
+		"2606:2800:220:1::1":     true,
+		"127.0.0.1":              false,
+		"::1":                    false,
+		"10.1.2.3":               false,
+		"172.16.0.1":             false,
+		"192.168.1.1":            false,
+		"169.254.169.254":        false,
+		"fe80::1":                false,
+		"fc00::1":                false,
+		"0.0.0.0":                false,
+		"100.64.0.1":             false,
+		"224.0.0.1":              false,
+		"::ffff:127.0.0.1":       false,
+		"::ffff:169.254.169.254": false,
+	}
+
+	for address, expect := range testCases {
+		t.Run(address, func(t *testing.T) {
+			assert.Equal(t, expect, webhook.IsPublic(netip.MustParseAddr(address)))
+		})
+	}
+}
+
+func TestClientRefusesNonPublicDestination(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the request must not reach a loopback server")
+	}))
+	defer server.Close()
+
+	_, err := webhook.NewClient(time.Second).Post(server.URL, "application/json", nil)
+	assert.ErrorIs(t, err, customErr.ErrForbiddenDestination)
+}
@@ -0,0 +1,71 @@
+package webhook_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	"github.com/federicodosantos/image-smith/pkg/webhook"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	body := []byte(`{"type":"image.uploaded"}`)
+
+	signedHeader := func(secret string, timestamp time.Time) http.Header {
+		header := http.Header{}
+		header.Set(webhook.TimestampHeader, fmt.Sprint(timestamp.Unix()))
+		header.Set(webhook.SignatureHeader, webhook.Sign(secret, timestamp, body))
+		return header
+	}
+
+	type testCase struct {
+		name        string
+		header      http.Header
+		body        []byte
+		expectError error
+	}
+
+	testCases := []testCase{
+		{name: "Success - Fresh delivery", header: signedHeader("whsec_secret", now), body: body},
+		{
+			name:        "Failed - Body changed",
+			header:      signedHeader("whsec_secret", now),
+			body:        []byte(`{"type":"image.deleted"}`),
+			expectError: customErr.ErrInvalidSignature,
+		},
+		{
+			name:        "Failed - Other secret",
+			header:      signedHeader("whsec_other", now),
+			body:        body,
+			expectError: customErr.ErrInvalidSignature,
+		},
+		{
+			name:        "Failed - Timestamp outside tolerance",
+			header:      signedHeader("whsec_secret", now.Add(-10*time.Minute)),
+			body:        body,
+			expectError: customErr.ErrSignatureExpired,
+		},
+		{
+			name:        "Failed - Missing headers",
+			header:      http.Header{},
+			body:        body,
+			expectError: customErr.ErrInvalidSignature,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := webhook.Verify("whsec_secret", tc.header, tc.body, 5*time.Minute, now)
+
+			if tc.expectError != nil {
+				assert.ErrorIs(t, err, tc.expectError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package worker_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/federicodosantos/image-smith/internal/model"
+	"github.com/federicodosantos/image-smith/internal/worker"
+	"github.com/federicodosantos/image-smith/pkg/webhook"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func pendingDelivery(attempts int) *model.WebhookDelivery {
+	return &model.WebhookDelivery{
+		ID:          "delivery-id",
+		WebhookID:   "webhook-id",
+		OwnerID:     "owner-id",
+		Event:       model.EventImageUploaded,
+		Payload:     []byte(`{"type":"image.uploaded"}`),
+		Status:      model.DeliveryStatusSending,
+		Attempts:    attempts,
+		MaxAttempts: 3,
+	}
+}
+
+func TestDispatcherRunOnce(t *testing.T) {
+	type testCase struct {
+		name           string
+		responseStatus int
+		attempts       int
+		expectStatus   string
+	}
+
+	testCases := []testCase{
+		{name: "Success - Endpoint accepts delivery", responseStatus: http.StatusNoContent, attempts: 1, expectStatus: model.DeliveryStatusSucceeded},
+		{name: "Success - Server error is retried", responseStatus: http.StatusInternalServerError, attempts: 1, expectStatus: model.DeliveryStatusPending},
+		{name: "Failed - Last attempt fails the delivery", responseStatus: http.StatusBadGateway, attempts: 3, expectStatus: model.DeliveryStatusFailed},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				assert.NoError(t, webhook.Verify("whsec_secret", r.Header, body, time.Minute, time.Now()))
+				assert.Equal(t, model.EventImageUploaded, r.Header.Get(webhook.EventHeader))
+				assert.Equal(t, "delivery-id", r.Header.Get(webhook.DeliveryHeader))
+				w.WriteHeader(tc.responseStatus)
+			}))
+			defer server.Close()
+
+			mockWebhookRepo := NewMockIWebhookRepository(ctrl)
+			mockWebhookRepo.EXPECT().FailAbandonedDeliveries(gomock.Any(), gomock.Any()).Return(nil)
+			mockWebhookRepo.EXPECT().ClaimDelivery(gomock.Any(), time.Minute).Return(pendingDelivery(tc.attempts), nil)
+			mockWebhookRepo.EXPECT().GetWebhookById(gomock.Any(), "webhook-id", "owner-id").
+				Return(&model.Webhook{ID: "webhook-id", URL: server.URL, Secret: "whsec_secret"}, nil)
+			mockWebhookRepo.EXPECT().UpdateDelivery(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, delivery *model.WebhookDelivery) error {
+					assert.Equal(t, tc.expectStatus, delivery.Status)
+					assert.Equal(t, tc.responseStatus, *delivery.ResponseStatus)
+
+					switch tc.expectStatus {
+					case model.DeliveryStatusSucceeded:
+						assert.NotNil(t, delivery.DeliveredAt)
+						assert.Nil(t, delivery.LastError)
+					case model.DeliveryStatusPending:
+						assert.WithinDuration(t, time.Now().Add(worker.Backoff(tc.attempts)), delivery.NextAttemptAt, time.Second)
+						assert.NotNil(t, delivery.LastError)
+					}
+					return nil
+				})
+
+			dispatcher := worker.NewDispatcher(mockWebhookRepo, server.Client(), config)
+
+			processed, err := dispatcher.RunOnce(CTX)
+			assert.NoError(t, err)
+			assert.True(t, processed)
+		})
+	}
+
+	t.Run("Success - No delivery due", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockWebhookRepo := NewMockIWebhookRepository(ctrl)
+		mockWebhookRepo.EXPECT().FailAbandonedDeliveries(gomock.Any(), gomock.Any()).Return(nil)
+		mockWebhookRepo.EXPECT().ClaimDelivery(gomock.Any(), time.Minute).Return(nil, nil)
+
+		dispatcher := worker.NewDispatcher(mockWebhookRepo, http.DefaultClient, config)
+
+		processed, err := dispatcher.RunOnce(CTX)
+		assert.NoError(t, err)
+		assert.False(t, processed)
+	})
+}
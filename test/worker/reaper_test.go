@@ -0,0 +1,75 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/federicodosantos/image-smith/internal/worker"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestReaperRunOnce(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUploadUsecase := NewMockIUploadUsecase(ctrl)
+	mockImageUsecase := NewMockIImageUsecase(ctrl)
+	reaper := worker.NewReaper(mockUploadUsecase, mockImageUsecase, time.Minute)
+
+	t.Run("Success", func(t *testing.T) {
+		mockUploadUsecase.EXPECT().ExpireSessions(gomock.Any()).Return(3, nil)
+		mockImageUsecase.EXPECT().ExpirePendingImages(gomock.Any()).Return(2, nil)
+
+		removed, err := reaper.RunOnce(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 5, removed)
+	})
+
+	t.Run("Error - Sessions fail, pending images are still reaped", func(t *testing.T) {
+		failure := errors.New("database is down")
+		mockUploadUsecase.EXPECT().ExpireSessions(gomock.Any()).Return(0, failure)
+		mockImageUsecase.EXPECT().ExpirePendingImages(gomock.Any()).Return(1, nil)
+
+		removed, err := reaper.RunOnce(context.Background())
+		assert.ErrorIs(t, err, failure)
+		assert.Equal(t, 1, removed)
+	})
+}
+
+func TestReaperRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUploadUsecase := NewMockIUploadUsecase(ctrl)
+	mockImageUsecase := NewMockIImageUsecase(ctrl)
+	reaper := worker.NewReaper(mockUploadUsecase, mockImageUsecase, time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mockImageUsecase.EXPECT().ExpirePendingImages(gomock.Any()).Return(0, nil).Times(2)
+
+	// a failed sweep is retried on the next tick
+	gomock.InOrder(
+		mockUploadUsecase.EXPECT().ExpireSessions(gomock.Any()).Return(0, errors.New("database is down")),
+		mockUploadUsecase.EXPECT().ExpireSessions(gomock.Any()).DoAndReturn(func(ctx context.Context) (int, error) {
+			cancel()
+			return 1, nil
+		}),
+	)
+
+	done := make(chan struct{})
+	go func() {
+		reaper.Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("reaper did not stop after ctx was cancelled")
+	}
+}
@@ -0,0 +1,118 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/usecase/upload_usecase.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/usecase/upload_usecase.go -destination=test/worker/upload_usecase_mock_test.go -package=worker_test
+//
+
+// Package worker_test is a generated GoMock package.
+package worker_test
+
+import (
+	context "context"
+	io "io"
+	reflect "reflect"
+
+	dto "github.com/federicodosantos/image-smith/internal/dto"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIUploadUsecase is a mock of IUploadUsecase interface.
+type MockIUploadUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockIUploadUsecaseMockRecorder
+	isgomock struct{}
+}
+
+// MockIUploadUsecaseMockRecorder is the mock recorder for MockIUploadUsecase.
+type MockIUploadUsecaseMockRecorder struct {
+	mock *MockIUploadUsecase
+}
+
+// NewMockIUploadUsecase creates a new mock instance.
+func NewMockIUploadUsecase(ctrl *gomock.Controller) *MockIUploadUsecase {
+	mock := &MockIUploadUsecase{ctrl: ctrl}
+	mock.recorder = &MockIUploadUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIUploadUsecase) EXPECT() *MockIUploadUsecaseMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockIUploadUsecase) Create(ctx context.Context, userID string, req *dto.UploadCreateRequest) (*dto.UploadSessionResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, userID, req)
+	ret0, _ := ret[0].(*dto.UploadSessionResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockIUploadUsecaseMockRecorder) Create(ctx, userID, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockIUploadUsecase)(nil).Create), ctx, userID, req)
+}
+
+// ExpireSessions mocks base method.
+func (m *MockIUploadUsecase) ExpireSessions(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireSessions", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpireSessions indicates an expected call of ExpireSessions.
+func (mr *MockIUploadUsecaseMockRecorder) ExpireSessions(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireSessions", reflect.TypeOf((*MockIUploadUsecase)(nil).ExpireSessions), ctx)
+}
+
+// Finalize mocks base method.
+func (m *MockIUploadUsecase) Finalize(ctx context.Context, userID, uploadID string) (*dto.ImageUploadResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Finalize", ctx, userID, uploadID)
+	ret0, _ := ret[0].(*dto.ImageUploadResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Finalize indicates an expected call of Finalize.
+func (mr *MockIUploadUsecaseMockRecorder) Finalize(ctx, userID, uploadID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Finalize", reflect.TypeOf((*MockIUploadUsecase)(nil).Finalize), ctx, userID, uploadID)
+}
+
+// Get mocks base method.
+func (m *MockIUploadUsecase) Get(ctx context.Context, userID, uploadID string) (*dto.UploadSessionResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, userID, uploadID)
+	ret0, _ := ret[0].(*dto.UploadSessionResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockIUploadUsecaseMockRecorder) Get(ctx, userID, uploadID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockIUploadUsecase)(nil).Get), ctx, userID, uploadID)
+}
+
+// WriteChunk mocks base method.
+func (m *MockIUploadUsecase) WriteChunk(ctx context.Context, userID, uploadID string, offset int64, r io.Reader) (*dto.UploadSessionResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteChunk", ctx, userID, uploadID, offset, r)
+	ret0, _ := ret[0].(*dto.UploadSessionResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WriteChunk indicates an expected call of WriteChunk.
+func (mr *MockIUploadUsecaseMockRecorder) WriteChunk(ctx, userID, uploadID, offset, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteChunk", reflect.TypeOf((*MockIUploadUsecase)(nil).WriteChunk), ctx, userID, uploadID, offset, r)
+}
@@ -0,0 +1,175 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/federicodosantos/image-smith/internal/dto"
+	"github.com/federicodosantos/image-smith/internal/model"
+	"github.com/federicodosantos/image-smith/internal/worker"
+	customErr "github.com/federicodosantos/image-smith/pkg/error"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+var CTX = context.Background()
+
+var config = worker.Config{Concurrency: 1, PollInterval: time.Millisecond, Lease: time.Minute}
+
+func transformJob(attempts int) *model.Job {
+	return &model.Job{
+		ID:          "job-id",
+		OwnerID:     "owner-id",
+		ImageID:     "image-id",
+		Type:        model.JobTypeTransform,
+		Payload:     []byte(`{"resize": {"width": 10}}`),
+		Status:      model.JobStatusRunning,
+		Attempts:    attempts,
+		MaxAttempts: 3,
+	}
+}
+
+func TestRunOnce(t *testing.T) {
+	type testCase struct {
+		name         string
+		mockBehavior func(mockJobRepo *MockIJobRepository, mockImageUsecase *MockIImageUsecase)
+		processed    bool
+		failed       bool
+	}
+
+	testCases := []testCase{
+		{
+			name: "Success - Completed with derived image",
+			mockBehavior: func(mockJobRepo *MockIJobRepository, mockImageUsecase *MockIImageUsecase) {
+				mockJobRepo.EXPECT().ClaimJob(gomock.Any(), time.Minute).Return(transformJob(1), nil)
+				mockImageUsecase.EXPECT().
+					Transform(gomock.Any(), "owner-id", "image-id", &dto.ImageTransformRequest{Resize: &dto.ResizeRequest{Width: 10}}).
+					Return(&dto.ImageTransformResponse{ID: "derived-id"}, nil)
+				mockJobRepo.EXPECT().CompleteJob(gomock.Any(), "job-id", 1, "derived-id").Return(nil)
+			},
+			processed: true,
+		},
+		{
+			name: "Success - Transient failure is retried with backoff",
+			mockBehavior: func(mockJobRepo *MockIJobRepository, mockImageUsecase *MockIImageUsecase) {
+				mockJobRepo.EXPECT().ClaimJob(gomock.Any(), time.Minute).Return(transformJob(2), nil)
+				mockImageUsecase.EXPECT().Transform(gomock.Any(), "owner-id", "image-id", gomock.Any()).
+					Return(nil, errors.New("storage unavailable"))
+				mockJobRepo.EXPECT().RetryJob(gomock.Any(), "job-id", 2, gomock.Any(), "storage unavailable").
+					DoAndReturn(func(_ context.Context, _ string, _ int, runAt time.Time, _ string) error {
+						assert.WithinDuration(t, time.Now().Add(worker.Backoff(2)), runAt, time.Second)
+						return nil
+					})
+			},
+			processed: true,
+		},
+		{
+			name: "Failed - Last attempt fails the job",
+			mockBehavior: func(mockJobRepo *MockIJobRepository, mockImageUsecase *MockIImageUsecase) {
+				mockJobRepo.EXPECT().ClaimJob(gomock.Any(), time.Minute).Return(transformJob(3), nil)
+				mockImageUsecase.EXPECT().Transform(gomock.Any(), "owner-id", "image-id", gomock.Any()).
+					Return(nil, errors.New("storage unavailable"))
+				mockJobRepo.EXPECT().FailJob(gomock.Any(), "job-id", 3, "storage unavailable").Return(nil)
+			},
+			processed: true,
+			failed:    true,
+		},
+		{
+			name: "Failed - Permanent error is not retried",
+			mockBehavior: func(mockJobRepo *MockIJobRepository, mockImageUsecase *MockIImageUsecase) {
+				mockJobRepo.EXPECT().ClaimJob(gomock.Any(), time.Minute).Return(transformJob(1), nil)
+				mockImageUsecase.EXPECT().Transform(gomock.Any(), "owner-id", "image-id", gomock.Any()).
+					Return(nil, customErr.ErrImageNotFound)
+				mockJobRepo.EXPECT().FailJob(gomock.Any(), "job-id", 1, customErr.ErrImageNotFound.Error()).Return(nil)
+			},
+			processed: true,
+			failed:    true,
+		},
+		{
+			name: "Failed - Lease lost to another worker",
+			mockBehavior: func(mockJobRepo *MockIJobRepository, mockImageUsecase *MockIImageUsecase) {
+				mockJobRepo.EXPECT().ClaimJob(gomock.Any(), time.Minute).Return(transformJob(3), nil)
+				mockImageUsecase.EXPECT().Transform(gomock.Any(), "owner-id", "image-id", gomock.Any()).
+					Return(nil, errors.New("storage unavailable"))
+				// the other worker reports the outcome
+				mockJobRepo.EXPECT().FailJob(gomock.Any(), "job-id", 3, "storage unavailable").Return(customErr.ErrJobLeaseLost)
+			},
+			processed: true,
+		},
+		{
+			name: "Failed - Job abandoned on its last attempt",
+			mockBehavior: func(mockJobRepo *MockIJobRepository, mockImageUsecase *MockIImageUsecase) {
+				mockJobRepo.EXPECT().FailAbandonedJobs(gomock.Any(), gomock.Any()).Return([]model.Job{*transformJob(3)}, nil)
+				mockJobRepo.EXPECT().ClaimJob(gomock.Any(), time.Minute).Return(nil, nil)
+			},
+			processed: false,
+			failed:    true,
+		},
+		{
+			name: "Success - No job due",
+			mockBehavior: func(mockJobRepo *MockIJobRepository, mockImageUsecase *MockIImageUsecase) {
+				mockJobRepo.EXPECT().ClaimJob(gomock.Any(), time.Minute).Return(nil, nil)
+			},
+			processed: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockJobRepo := NewMockIJobRepository(ctrl)
+			mockImageUsecase := NewMockIImageUsecase(ctrl)
+			mockEvents := NewMockEventPublisher(ctrl)
+			tc.mockBehavior(mockJobRepo, mockImageUsecase)
+			mockJobRepo.EXPECT().FailAbandonedJobs(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+
+			if tc.failed {
+				mockEvents.EXPECT().Publish(gomock.Any(), "owner-id", model.EventJobFailed, gomock.Any()).Return(nil)
+			}
+
+			pool := worker.NewPool(mockJobRepo, mockImageUsecase, mockEvents, config)
+
+			processed, err := pool.RunOnce(CTX)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.processed, processed)
+		})
+	}
+}
+
+func TestRunStopsOnCancel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockJobRepo := NewMockIJobRepository(ctrl)
+	mockJobRepo.EXPECT().FailAbandonedJobs(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	mockJobRepo.EXPECT().ClaimJob(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+
+	pool := worker.NewPool(mockJobRepo, NewMockIImageUsecase(ctrl), NewMockEventPublisher(ctrl), worker.Config{Concurrency: 3, PollInterval: time.Millisecond})
+
+	ctx, cancel := context.WithCancel(CTX)
+	done := make(chan struct{})
+	go func() {
+		pool.Run(ctx)
+		close(done)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("pool did not stop after cancel")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 10*time.Second, worker.Backoff(1))
+	assert.Equal(t, 20*time.Second, worker.Backoff(2))
+	assert.Equal(t, 80*time.Second, worker.Backoff(4))
+	assert.Equal(t, 10*time.Minute, worker.Backoff(20))
+}